@@ -8,6 +8,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/andygrunwald/go-jira"
 	"github.com/sirupsen/logrus"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -36,6 +37,12 @@ type options struct {
 	gracePeriod  time.Duration
 	bugzilla     prowflagutil.BugzillaOptions
 	pluginConfig string
+
+	jira prowflagutil.JiraOptions
+	// useJira is set when a Jira endpoint is configured
+	useJira bool
+
+	jiraTargetVersionField string
 }
 
 func gatherOptions() (options, error) {
@@ -45,14 +52,17 @@ func gatherOptions() (options, error) {
 	fs.StringVar(&o.address, "address", ":8080", "Address to run server on")
 	fs.DurationVar(&o.gracePeriod, "gracePeriod", time.Second*10, "Grace period for server shutdown")
 	fs.StringVar(&o.pluginConfig, "plugin-config", "/etc/plugins/plugins.yaml", "Path to plugin config file.")
+	fs.StringVar(&o.jiraTargetVersionField, "jira-target-version-field", "customfield_12319940", "The ID of the Jira field holding the release an issue targets")
 
-	for _, group := range []flagutil.OptionGroup{&o.bugzilla} {
+	for _, group := range []flagutil.OptionGroup{&o.bugzilla, &o.jira} {
 		group.AddFlags(fs)
 	}
 	err := fs.Parse(os.Args[1:])
 	if err != nil {
 		return o, err
 	}
+	// clones are found and created in Jira instead of Bugzilla when it is configured
+	o.useJira = fs.Lookup("jira-endpoint").Value.String() != ""
 	return o, nil
 }
 
@@ -94,7 +104,43 @@ func processOptions(o options) error {
 		return fmt.Errorf("invalid --log-level '%s': %w", o.logLevel, err)
 	}
 	logrus.SetLevel(level)
-	return nil
+	return o.jira.Validate(false)
+}
+
+// jiraClientTransport sends requests through the Jira client, which retries,
+// instruments and authenticates them
+type jiraClientTransport struct {
+	client *jira.Client
+}
+
+func (t *jiraClientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := t.client.Do(req, nil)
+	if response == nil {
+		return nil, err
+	}
+	// error statuses are for the caller to handle, as with any transport
+	return response.Response, nil
+}
+
+func getTracker(o options, secretAgent *secret.Agent) (backporter.Tracker, error) {
+	if o.useJira {
+		client, err := o.jira.Client(secretAgent)
+		if err != nil {
+			return nil, fmt.Errorf("error getting Jira client: %w", err)
+		}
+		transport := backporter.NewCachingTransport(&jiraClientTransport{client: client.JiraClient()})
+		jiraClient, err := jira.NewClient(&http.Client{Transport: transport}, client.JiraURL())
+		if err != nil {
+			return nil, fmt.Errorf("error getting Jira client: %w", err)
+		}
+		return backporter.NewJiraTracker(jiraClient, o.jiraTargetVersionField), nil
+	}
+	bugzillaClient, err := o.bugzilla.BugzillaClient(secretAgent)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Bugzilla client: %w", err)
+	}
+	bugzillaClient.SetRoundTripper(backporter.NewCachingTransport(http.DefaultTransport))
+	return backporter.NewBugzillaTracker(bugzillaClient), nil
 }
 
 // l and v keep the simplifier tree legible
@@ -116,17 +162,20 @@ func main() {
 		logrus.Fatalf("invalid options: %v", err)
 	}
 
-	// Start the bugzilla secrets agent
-	tokens := []string{o.bugzilla.ApiKeyPath}
+	// Start the secrets agent for the issue tracker
+	// the Jira client adds its password file to the agent itself
+	var tokens []string
+	if !o.useJira {
+		tokens = []string{o.bugzilla.ApiKeyPath}
+	}
 	secretAgent := &secret.Agent{}
 	if err := secretAgent.Start(tokens); err != nil {
 		logrus.WithError(err).Fatal("Error starting secrets agent.")
 	}
-	bugzillaClient, err := o.bugzilla.BugzillaClient(secretAgent)
+	tracker, err := getTracker(o, secretAgent)
 	if err != nil {
-		logrus.WithError(err).Fatal("Error getting Bugzilla client.")
+		logrus.WithError(err).Fatal("Error getting issue tracker.")
 	}
-	bugzillaClient.SetRoundTripper(backporter.NewCachingTransport())
 	health := pjutil.NewHealth()
 	metrics.ExposeMetrics("ci-operator-bugzilla-backporter", prowConfig.PushGateway{}, prowflagutil.DefaultMetricsPort)
 	allTargetVersions, err := getAllTargetVersions(o.pluginConfig)
@@ -138,16 +187,18 @@ func main() {
 		l("clones",
 			v("ID"),
 			l("create"),
+			l("bulk"),
 		),
 		l("bug"),
 	))
 	handler := metrics.TraceHandler(simplifier, bzbpMetrics.HTTPRequestDuration, bzbpMetrics.HTTPResponseSize)
 	http.HandleFunc("/", handler(backporter.GetLandingHandler(bzbpMetrics)).ServeHTTP)
-	http.HandleFunc("/clones", handler(backporter.GetClonesHandler(bugzillaClient, allTargetVersions, bzbpMetrics)).ServeHTTP)
-	http.HandleFunc("/clones/create", handler(backporter.CreateCloneHandler(bugzillaClient, allTargetVersions, bzbpMetrics)).ServeHTTP)
+	http.HandleFunc("/clones", handler(backporter.GetClonesHandler(tracker, allTargetVersions, bzbpMetrics)).ServeHTTP)
+	http.HandleFunc("/clones/create", handler(backporter.CreateCloneHandler(tracker, allTargetVersions, bzbpMetrics)).ServeHTTP)
+	http.HandleFunc("/clones/bulk", handler(backporter.BulkCloneHandler(tracker, allTargetVersions, bzbpMetrics)).ServeHTTP)
 	// Leaving this in here to help with future debugging. This will return bug details in JSON format
 	http.HandleFunc("/help", handler(backporter.GetHelpHandler(bzbpMetrics)).ServeHTTP)
-	http.HandleFunc("/bug", handler(backporter.GetBugHandler(bugzillaClient, bzbpMetrics)).ServeHTTP)
+	http.HandleFunc("/bug", handler(backporter.GetBugHandler(tracker, bzbpMetrics)).ServeHTTP)
 	interrupts.ListenAndServe(&http.Server{Addr: o.address}, o.gracePeriod)
 
 	health.ServeReady()
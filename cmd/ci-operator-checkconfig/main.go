@@ -5,21 +5,23 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/promotion"
 	"github.com/openshift/ci-tools/pkg/registry"
-	"github.com/openshift/ci-tools/pkg/steps/release"
+	"github.com/openshift/ci-tools/pkg/release"
+	"github.com/openshift/ci-tools/pkg/release/resolve"
 )
 
-type tagSet map[api.ImageStreamTagReference][]*config.Info
-
 func main() {
-	var configDir, registryDir string
+	var configDir, registryDir, releaseFixturesDir string
 	flag.StringVar(&configDir, "config-dir", "", "The directory containing configuration files.")
 	flag.StringVar(&registryDir, "registry", "", "Path to the step registry directory")
+	flag.StringVar(&releaseFixturesDir, "release-fixtures-dir", "", "Directory of recorded responses of the release endpoints. If set, the releases of every configuration must resolve against them.")
 	flag.Parse()
 
 	if configDir == "" {
@@ -31,7 +33,11 @@ func main() {
 		fmt.Fprintf(os.Stderr, "failed to load registry: %v\n", err)
 		os.Exit(1)
 	}
-	seen := tagSet{}
+	var releaseClient release.HTTPClient
+	if releaseFixturesDir != "" {
+		releaseClient = release.NewReplayingHTTPClient(releaseFixturesDir)
+	}
+	targets := promotion.Targets{}
 	if err := config.OperateOnCIOperatorConfigDir(configDir, func(configuration *api.ReleaseBuildConfiguration, repoInfo *config.Info) error {
 		// basic validation of the configuration is implicit in the iteration
 		if resolver != nil {
@@ -39,9 +45,12 @@ func main() {
 				return err
 			}
 		}
-		for _, tag := range release.PromotedTags(configuration) {
-			seen[tag] = append(seen[tag], repoInfo)
+		if releaseClient != nil {
+			if err := utilerrors.NewAggregate(resolve.Releases(releaseClient, configuration)); err != nil {
+				return fmt.Errorf("%s: %w", repoInfo.Filename, err)
+			}
 		}
+		targets.Record(configuration, repoInfo)
 		if configuration.PromotionConfiguration != nil && configuration.PromotionConfiguration.RegistryOverride != "" {
 			return errors.New("setting promotion.registry_override is not allowed")
 		}
@@ -50,10 +59,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "error validating configuration files: %v\n", err)
 		os.Exit(1)
 	}
-	if dupes := validateTags(seen); len(dupes) > 0 {
+	if conflicts := targets.Conflicts(); len(conflicts) > 0 {
 		fmt.Fprintln(os.Stderr, "non-unique image publication found: ")
-		for _, dupe := range dupes {
-			fmt.Fprintf(os.Stderr, "ERROR: %v\n", dupe)
+		for _, conflict := range conflicts {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", conflict)
 		}
 		os.Exit(1)
 	}
@@ -69,22 +78,3 @@ func loadResolver(path string) (registry.Resolver, error) {
 	}
 	return registry.NewResolver(refs, chains, workflows, observers), nil
 }
-
-func validateTags(seen tagSet) []error {
-	var dupes []error
-	for tag, infos := range seen {
-		if len(infos) <= 1 {
-			continue
-		}
-		formatted := []string{}
-		for _, info := range infos {
-			identifier := fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch)
-			if info.Variant != "" {
-				identifier = fmt.Sprintf("%s [%s]", identifier, info.Variant)
-			}
-			formatted = append(formatted, identifier)
-		}
-		dupes = append(dupes, fmt.Errorf("output tag %s is promoted from more than one place: %v", tag.ISTagName(), strings.Join(formatted, ", ")))
-	}
-	return dupes
-}
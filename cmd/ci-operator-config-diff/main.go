@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	pjdwapi "k8s.io/test-infra/prow/pod-utils/downwardapi"
+
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/diffs"
+)
+
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+type options struct {
+	releaseRepoPath string
+	baseRevision    string
+	headRevision    string
+	outputFormat    string
+	outputPath      string
+}
+
+func gatherOptions() options {
+	o := options{}
+	fs := flag.CommandLine
+	fs.StringVar(&o.releaseRepoPath, "release-repo-path", "", "Path to a openshift/release working copy")
+	fs.StringVar(&o.baseRevision, "base-revision", "", "Revision of the release repo to compare against. If unset, the base SHA of the presubmit in $JOB_SPEC is used.")
+	fs.StringVar(&o.headRevision, "head-revision", "", "Revision of the release repo with the changes. If unset, the current working copy is used.")
+	fs.StringVar(&o.outputFormat, "output-format", formatMarkdown, fmt.Sprintf("Format of the summary, one of %s or %s", formatMarkdown, formatJSON))
+	fs.StringVar(&o.outputPath, "output-path", "", "File to write the summary to. If unset, the summary is printed to stdout.")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatal("could not parse args")
+	}
+	return o
+}
+
+func (o *options) validate() error {
+	if o.releaseRepoPath == "" {
+		return errors.New("--release-repo-path is required")
+	}
+	if o.outputFormat != formatMarkdown && o.outputFormat != formatJSON {
+		return fmt.Errorf("--output-format must be one of %s or %s", formatMarkdown, formatJSON)
+	}
+	return nil
+}
+
+func (o *options) resolveBaseRevision() error {
+	if o.baseRevision != "" {
+		return nil
+	}
+	jobSpec, err := pjdwapi.ResolveSpecFromEnv()
+	if err != nil {
+		return fmt.Errorf("--base-revision was not set and $JOB_SPEC could not be read: %w", err)
+	}
+	if jobSpec.Refs == nil || jobSpec.Refs.BaseSHA == "" {
+		return errors.New("--base-revision was not set and $JOB_SPEC has no base SHA")
+	}
+	o.baseRevision = jobSpec.Refs.BaseSHA
+	return nil
+}
+
+func loadRevision(releaseRepoPath, revision string, logger *logrus.Entry) (config.DataByFilename, error) {
+	var releaseRepoConfig *config.ReleaseRepoConfig
+	if revision == "" {
+		releaseRepoConfig = config.GetAllConfigs(releaseRepoPath, logger)
+	} else {
+		var err error
+		if releaseRepoConfig, err = config.GetAllConfigsFromSHA(releaseRepoPath, revision, logger); err != nil {
+			return nil, err
+		}
+	}
+	if releaseRepoConfig.CiOperator == nil {
+		return nil, fmt.Errorf("could not load ci-operator configuration")
+	}
+	return releaseRepoConfig.CiOperator, nil
+}
+
+func main() {
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid options")
+	}
+	if err := o.resolveBaseRevision(); err != nil {
+		logrus.WithError(err).Fatal("Could not determine the base revision")
+	}
+	logger := logrus.WithField("release-repo", o.releaseRepoPath)
+
+	baseConfig, err := loadRevision(o.releaseRepoPath, o.baseRevision, logger.WithField("revision", o.baseRevision))
+	if err != nil {
+		logger.WithError(err).Fatal("Could not load configuration from the base revision")
+	}
+	headConfig, err := loadRevision(o.releaseRepoPath, o.headRevision, logger.WithField("revision", o.headRevision))
+	if err != nil {
+		logger.WithError(err).Fatal("Could not load configuration from the head revision")
+	}
+
+	summaries := diffs.SummarizeCiopConfigChanges(baseConfig, headConfig)
+	var output []byte
+	switch o.outputFormat {
+	case formatJSON:
+		if output, err = json.MarshalIndent(summaries, "", "  "); err != nil {
+			logger.WithError(err).Fatal("Could not marshal the summary")
+		}
+	case formatMarkdown:
+		output = []byte(diffs.FormatConfigSummaries(summaries))
+	}
+
+	if o.outputPath == "" {
+		fmt.Println(string(output))
+		return
+	}
+	if err := ioutil.WriteFile(o.outputPath, output, 0644); err != nil {
+		logger.WithError(err).Fatal("Could not write the summary")
+	}
+}
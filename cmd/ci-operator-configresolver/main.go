@@ -22,6 +22,7 @@ import (
 	"k8s.io/test-infra/prow/simplifypath"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
 	"github.com/openshift/ci-tools/pkg/load/agents"
 	"github.com/openshift/ci-tools/pkg/webreg"
 )
@@ -37,6 +38,7 @@ type options struct {
 	gracePeriod            time.Duration
 	validateOnly           bool
 	flatRegistry           bool
+	history                int
 	instrumentationOptions flagutil.InstrumentationOptions
 }
 
@@ -58,6 +60,7 @@ func gatherOptions() (options, error) {
 	_ = fs.Duration("cycle", time.Minute*2, "Legacy flag kept for compatibility. Does nothing")
 	fs.BoolVar(&o.validateOnly, "validate-only", false, "Load the config and registry, validate them and exit.")
 	fs.BoolVar(&o.flatRegistry, "flat-registry", false, "Disable directory structure based registry validation")
+	fs.IntVar(&o.history, "history", 1, "Number of generations of the configs and the registry, including the current one, to retain for requests with the configGeneration, registryGeneration or sha queries")
 	o.instrumentationOptions.AddFlags(fs)
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -88,6 +91,9 @@ func validateOptions(o options) error {
 		}
 		return fmt.Errorf("Error getting stat info for --registry directory: %w", err)
 	}
+	if o.history < 1 {
+		return errors.New("--history must be at least 1")
+	}
 	if o.validateOnly && o.flatRegistry {
 		return errors.New("--validate-only and --flat-registry flags cannot be set simultaneously")
 	}
@@ -107,7 +113,8 @@ func resolveConfig(configAgent agents.ConfigAgent, registryAgent agents.Registry
 		}
 		logger := logrus.WithFields(api.LogFieldsFor(metadata))
 
-		config, err := configAgent.GetMatchingConfig(metadata)
+		configRevision, registryRevision := load.RevisionsFromQuery(r.URL.Query())
+		config, generation, err := configAgent.GetMatchingConfigAt(metadata, configRevision)
 		if err != nil {
 			metrics.RecordError("config not found", configresolverMetrics.ErrorRate)
 			w.WriteHeader(http.StatusNotFound)
@@ -115,7 +122,8 @@ func resolveConfig(configAgent agents.ConfigAgent, registryAgent agents.Registry
 			logger.WithError(err).Warning("failed to get config")
 			return
 		}
-		resolveAndRespond(registryAgent, config, w, logger)
+		load.SetGenerationHeaders(w.Header(), load.ConfigGenerationHeader, load.ConfigSHAHeader, generation)
+		resolveAndRespond(registryAgent, config, registryRevision, w, logger)
 	}
 }
 
@@ -140,15 +148,20 @@ func resolveLiteralConfig(registryAgent agents.RegistryAgent) http.HandlerFunc {
 			_, _ = w.Write([]byte("Could not parse request body as unresolved config."))
 			return
 		}
-		resolveAndRespond(registryAgent, unresolvedConfig, w, logger)
+		_, registryRevision := load.RevisionsFromQuery(r.URL.Query())
+		resolveAndRespond(registryAgent, unresolvedConfig, registryRevision, w, logger)
 	}
 }
 
-func resolveAndRespond(registryAgent agents.RegistryAgent, config api.ReleaseBuildConfiguration, w http.ResponseWriter, logger *logrus.Entry) {
-	config, err := registryAgent.ResolveConfig(config)
+func resolveAndRespond(registryAgent agents.RegistryAgent, config api.ReleaseBuildConfiguration, registryRevision string, w http.ResponseWriter, logger *logrus.Entry) {
+	config, generation, err := registryAgent.ResolveConfigAt(config, registryRevision)
 	if err != nil {
 		metrics.RecordError("failed to resolve config with registry", configresolverMetrics.ErrorRate)
-		w.WriteHeader(http.StatusBadRequest)
+		status := http.StatusBadRequest
+		if errors.Is(err, agents.ErrGenerationNotRetained) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
 		if _, writeErr := w.Write([]byte(fmt.Sprintf("failed to resolve config: %v", err))); writeErr != nil {
 			logger.WithError(writeErr).Warning("failed to write body after config resolving failed")
 		}
@@ -164,6 +177,7 @@ func resolveAndRespond(registryAgent agents.RegistryAgent, config api.ReleaseBui
 		logger.WithError(err).Errorf("failed to marshal config to JSON")
 		return
 	}
+	load.SetGenerationHeaders(w.Header(), load.RegistryGenerationHeader, load.RegistrySHAHeader, generation)
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(jsonConfig); err != nil {
 		logrus.WithError(err).Error("Failed to write response")
@@ -189,6 +203,10 @@ func l(fragment string, children ...simplifypath.Node) simplifypath.Node {
 	return simplifypath.L(fragment, children...)
 }
 
+func v(fragment string, children ...simplifypath.Node) simplifypath.Node {
+	return simplifypath.V(fragment, children...)
+}
+
 func main() {
 	logrusutil.ComponentInit()
 	o, err := gatherOptions()
@@ -201,12 +219,12 @@ func main() {
 	level, _ := logrus.ParseLevel(o.logLevel)
 	logrus.SetLevel(level)
 
-	configAgent, err := agents.NewConfigAgent(o.configPath, agents.WithConfigMetrics(configresolverMetrics.ErrorRate))
+	configAgent, err := agents.NewConfigAgent(o.configPath, agents.WithConfigMetrics(configresolverMetrics.ErrorRate), agents.WithConfigHistory(o.history))
 	if err != nil {
 		logrus.Fatalf("Failed to get config agent: %v", err)
 	}
 
-	registryAgent, err := agents.NewRegistryAgent(o.registryPath, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry))
+	registryAgent, err := agents.NewRegistryAgent(o.registryPath, agents.WithRegistryMetrics(configresolverMetrics.ErrorRate), agents.WithRegistryFlat(o.flatRegistry), agents.WithRegistryHistory(o.history))
 	if err != nil {
 		logrus.Fatalf("Failed to get registry agent: %v", err)
 	}
@@ -221,6 +239,20 @@ func main() {
 		l("resolve"),
 		l("configGeneration"),
 		l("registryGeneration"),
+		l("api",
+			l("v1",
+				l("generations"),
+				l("configs",
+					l("unresolved"),
+					l("resolved"),
+				),
+				l("registry",
+					l("references", v("name", l("jobs"))),
+					l("chains", v("name", l("jobs"))),
+					l("workflows", v("name", l("jobs"))),
+				),
+			),
+		),
 	))
 
 	uisimplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
@@ -233,6 +265,8 @@ func main() {
 		),
 		l("search"),
 		l("job"),
+		l("compose"),
+		l("usage"),
 		l("reference"),
 		l("chain"),
 		l("workflow"),
@@ -245,6 +279,7 @@ func main() {
 	http.HandleFunc("/resolve", handler(resolveLiteralConfig(registryAgent)).ServeHTTP)
 	http.HandleFunc("/configGeneration", handler(getConfigGeneration(configAgent)).ServeHTTP)
 	http.HandleFunc("/registryGeneration", handler(getRegistryGeneration(registryAgent)).ServeHTTP)
+	http.HandleFunc(webreg.APIPrefix, handler(webreg.APIHandler(registryAgent, configAgent)).ServeHTTP)
 	interrupts.ListenAndServe(&http.Server{Addr: ":" + strconv.Itoa(o.port)}, o.gracePeriod)
 	uiServer := &http.Server{
 		Addr:    ":" + strconv.Itoa(o.uiPort),
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/test-infra/prow/logrusutil"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/promotionhistory"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/steps/release"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+type options struct {
+	configPath string
+	kubeconfig string
+	commit     string
+	reason     string
+	unpin      bool
+	confirm    bool
+}
+
+func gatherOptions() options {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.configPath, "config", "", "Path to the ci-operator configuration file whose promoted tags should be rolled back")
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file for the cluster hosting the image streams images are promoted to")
+	fs.StringVar(&o.commit, "commit", "", "Roll back to the images promoted from this commit instead of the previous promotion")
+	fs.StringVar(&o.reason, "reason", "", "Reason for the rollback, recorded on the rollback pin")
+	fs.BoolVar(&o.unpin, "unpin", false, "Remove the rollback pins of the promoted tags instead of rolling back, allowing them to be promoted again")
+	fs.BoolVar(&o.confirm, "confirm", false, "Set true to actually change the image streams")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatal("could not parse input")
+	}
+	return o
+}
+
+func (o *options) validate() error {
+	if o.configPath == "" {
+		return errors.New("--config is required")
+	}
+	if o.kubeconfig == "" {
+		return errors.New("--kubeconfig is required")
+	}
+	if o.unpin && (o.commit != "" || o.reason != "") {
+		return errors.New("--unpin cannot be used together with --commit or --reason")
+	}
+	return nil
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("invalid options")
+	}
+
+	var configuration *api.ReleaseBuildConfiguration
+	if err := config.OperateOnCIOperatorConfig(o.configPath, func(c *api.ReleaseBuildConfiguration, _ *config.Info) error {
+		configuration = c
+		return nil
+	}); err != nil {
+		logrus.WithError(err).Fatal("could not load ci-operator configuration")
+	}
+	tags := release.PromotedTags(configuration)
+	if len(tags) == 0 {
+		logrus.Fatal("the configuration does not promote any images")
+	}
+
+	kubeconfigs, _, err := util.LoadKubeConfigs(o.kubeconfig, nil)
+	if err != nil {
+		logrus.WithError(err).Fatal("could not load kubeconfig")
+	}
+	if len(kubeconfigs) != 1 {
+		logrus.Fatalf("found %d contexts in kubeconfig %s: must be %s only", len(kubeconfigs), o.kubeconfig, api.ClusterAPPCI)
+	}
+	var client ctrlruntimeclient.Client
+	for _, kubeconfig := range kubeconfigs {
+		if client, err = ctrlruntimeclient.New(kubeconfig, ctrlruntimeclient.Options{}); err != nil {
+			logrus.WithError(err).Fatal("could not create client")
+		}
+	}
+	if !o.confirm {
+		client = ctrlruntimeclient.NewDryRunClient(client)
+	}
+
+	ctx := context.Background()
+	var failed bool
+	for key, streamTags := range tagsByStream(tags) {
+		logger := logrus.WithField("imagestream", key.String())
+		if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			stream := &imagev1.ImageStream{}
+			if err := client.Get(ctx, key, stream); err != nil {
+				return fmt.Errorf("could not get imagestream: %w", err)
+			}
+			if o.unpin {
+				for _, tag := range streamTags {
+					if err := promotionhistory.Unpin(stream, tag); err != nil {
+						return err
+					}
+					logger.WithField("tag", tag).Info("Removing rollback pin.")
+				}
+			} else {
+				changes, err := rollback(stream, streamTags, o.commit, o.reason, time.Now().UTC())
+				if err != nil {
+					return err
+				}
+				for _, change := range changes {
+					logger.WithFields(logrus.Fields{"tag": change.tag, "from": change.from, "to": change.to}).Info("Rolling back tag.")
+				}
+				if len(changes) == 0 {
+					logger.Info("Nothing to roll back.")
+					return nil
+				}
+			}
+			return client.Update(ctx, stream)
+		}); err != nil {
+			logger.WithError(err).Error("Failed to roll back imagestream.")
+			failed = true
+		}
+	}
+	if failed {
+		logrus.Fatal("Failed to roll back all promoted tags.")
+	}
+	if !o.confirm {
+		logrus.Info("Running without --confirm, no changes were made.")
+	}
+}
+
+// tagsByStream groups promoted tags by the image stream they belong to
+func tagsByStream(tags []api.ImageStreamTagReference) map[ctrlruntimeclient.ObjectKey][]string {
+	streams := map[ctrlruntimeclient.ObjectKey][]string{}
+	for _, tag := range tags {
+		key := ctrlruntimeclient.ObjectKey{Namespace: tag.Namespace, Name: tag.Name}
+		streams[key] = append(streams[key], tag.Tag)
+	}
+	return streams
+}
+
+type change struct {
+	tag, from, to string
+}
+
+// rollback points the tags of the stream to the image of an earlier promotion,
+// records the rollback in the promotion history and pins the tags so they are
+// not promoted again automatically. Without a commit, every tag is rolled back
+// to the image it pointed to before the promotion of its current image.
+func rollback(stream *imagev1.ImageStream, tags []string, commit, reason string, now time.Time) ([]change, error) {
+	history, err := promotionhistory.History(stream)
+	if err != nil {
+		return nil, err
+	}
+	var changes []change
+	for _, tag := range tags {
+		current := promotionhistory.CurrentDigest(stream, tag)
+		target, found := rollbackTarget(history[tag], current, commit)
+		if !found {
+			if commit != "" {
+				return nil, fmt.Errorf("no promotion of %s:%s from commit %s is recorded", stream.Name, tag, commit)
+			}
+			return nil, fmt.Errorf("no promotion of %s:%s before its current image %s is recorded", stream.Name, tag, current)
+		}
+		if target.Digest == current {
+			continue
+		}
+		setTagReference(stream, tag, fmt.Sprintf("%s@%s", stream.Name, target.Digest))
+		if err := promotionhistory.AddRecord(stream, tag, promotionhistory.Record{
+			Digest:         target.Digest,
+			PreviousDigest: current,
+			Commit:         target.Commit,
+			Time:           now,
+			RolledBack:     true,
+		}); err != nil {
+			return nil, err
+		}
+		if err := promotionhistory.SetPin(stream, tag, promotionhistory.Pin{Digest: target.Digest, Reason: reason, Time: now}); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change{tag: tag, from: current, to: target.Digest})
+	}
+	return changes, nil
+}
+
+// rollbackTarget finds the record to roll back to: the newest promotion from the
+// commit if one is given, otherwise the image the current image replaced
+func rollbackTarget(records []promotionhistory.Record, current, commit string) (promotionhistory.Record, bool) {
+	for i, record := range records {
+		if record.RolledBack {
+			continue
+		}
+		if commit != "" {
+			if record.Commit == commit {
+				return record, true
+			}
+			continue
+		}
+		if record.Digest != current || record.PreviousDigest == "" {
+			continue
+		}
+		// the commit of the previous image is known if it was promoted by us
+		for _, older := range records[i+1:] {
+			if older.Digest == record.PreviousDigest && !older.RolledBack {
+				return older, true
+			}
+		}
+		return promotionhistory.Record{Digest: record.PreviousDigest}, true
+	}
+	return promotionhistory.Record{}, false
+}
+
+// setTagReference points the tag at the image, the way `oc tag` does
+func setTagReference(stream *imagev1.ImageStream, tag, image string) {
+	from := &corev1.ObjectReference{Kind: "ImageStreamImage", Namespace: stream.Namespace, Name: image}
+	for i := range stream.Spec.Tags {
+		if stream.Spec.Tags[i].Name == tag {
+			stream.Spec.Tags[i].From = from
+			return
+		}
+	}
+	stream.Spec.Tags = append(stream.Spec.Tags, imagev1.TagReference{Name: tag, From: from})
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api/promotionhistory"
+)
+
+func TestRollback(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	stream := func(current string, records ...promotionhistory.Record) *imagev1.ImageStream {
+		s := &imagev1.ImageStream{
+			ObjectMeta: meta.ObjectMeta{Namespace: "ocp", Name: "4.9"},
+			Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+				{Tag: "foo", Items: []imagev1.TagEvent{{Image: current}}},
+			}},
+		}
+		for i := len(records) - 1; i >= 0; i-- {
+			if err := promotionhistory.AddRecord(s, "foo", records[i]); err != nil {
+				t.Fatalf("failed to add record: %v", err)
+			}
+		}
+		return s
+	}
+	testCases := []struct {
+		name            string
+		stream          *imagev1.ImageStream
+		commit          string
+		expected        []change
+		expectedTag     *corev1.ObjectReference
+		expectedCommit  string
+		expectedErr     string
+		expectUnchanged bool
+	}{
+		{
+			name:           "previous promotion",
+			stream:         stream("sha256:b", promotionhistory.Record{Digest: "sha256:b", PreviousDigest: "sha256:a", Commit: "2"}, promotionhistory.Record{Digest: "sha256:a", Commit: "1"}),
+			expected:       []change{{tag: "foo", from: "sha256:b", to: "sha256:a"}},
+			expectedTag:    &corev1.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.9@sha256:a"},
+			expectedCommit: "1",
+		},
+		{
+			name: "rolling back again goes further back",
+			stream: stream("sha256:a",
+				promotionhistory.Record{Digest: "sha256:a", PreviousDigest: "sha256:b", Commit: "1", RolledBack: true},
+				promotionhistory.Record{Digest: "sha256:b", PreviousDigest: "sha256:a", Commit: "2"},
+				promotionhistory.Record{Digest: "sha256:a", PreviousDigest: "sha256:z"},
+			),
+			expected:    []change{{tag: "foo", from: "sha256:a", to: "sha256:z"}},
+			expectedTag: &corev1.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.9@sha256:z"},
+		},
+		{
+			name:           "promotion from commit",
+			stream:         stream("sha256:c", promotionhistory.Record{Digest: "sha256:c", PreviousDigest: "sha256:b", Commit: "3"}, promotionhistory.Record{Digest: "sha256:b", PreviousDigest: "sha256:a", Commit: "2"}, promotionhistory.Record{Digest: "sha256:a", Commit: "1"}),
+			commit:         "1",
+			expected:       []change{{tag: "foo", from: "sha256:c", to: "sha256:a"}},
+			expectedTag:    &corev1.ObjectReference{Kind: "ImageStreamImage", Namespace: "ocp", Name: "4.9@sha256:a"},
+			expectedCommit: "1",
+		},
+		{
+			name:            "already at the commit",
+			stream:          stream("sha256:a", promotionhistory.Record{Digest: "sha256:a", Commit: "1"}),
+			commit:          "1",
+			expectUnchanged: true,
+		},
+		{
+			name:        "no history",
+			stream:      stream("sha256:a"),
+			expectedErr: "no promotion of 4.9:foo before its current image sha256:a is recorded",
+		},
+		{
+			name:        "unknown commit",
+			stream:      stream("sha256:a", promotionhistory.Record{Digest: "sha256:a", Commit: "1"}),
+			commit:      "2",
+			expectedErr: "no promotion of 4.9:foo from commit 2 is recorded",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := rollback(tc.stream, []string{"foo"}, tc.commit, "broken", now)
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, changes, cmp.AllowUnexported(change{})); diff != "" {
+				t.Errorf("unexpected changes: %s", diff)
+			}
+			pins, err := promotionhistory.Pins(tc.stream)
+			if err != nil {
+				t.Fatalf("failed to get pins: %v", err)
+			}
+			if tc.expectUnchanged {
+				if len(tc.stream.Spec.Tags) != 0 || len(pins) != 0 {
+					t.Errorf("expected the stream to be unchanged, got tags %v and pins %v", tc.stream.Spec.Tags, pins)
+				}
+				return
+			}
+			if diff := cmp.Diff(tc.expectedTag, tc.stream.Spec.Tags[0].From); diff != "" {
+				t.Errorf("unexpected tag reference: %s", diff)
+			}
+			expectedPin := promotionhistory.Pin{Digest: tc.expected[0].to, Reason: "broken", Time: now}
+			if diff := cmp.Diff(expectedPin, pins["foo"]); diff != "" {
+				t.Errorf("unexpected pin: %s", diff)
+			}
+			history, err := promotionhistory.History(tc.stream)
+			if err != nil {
+				t.Fatalf("failed to get history: %v", err)
+			}
+			expectedRecord := promotionhistory.Record{Digest: tc.expected[0].to, PreviousDigest: tc.expected[0].from, Commit: tc.expectedCommit, Time: now, RolledBack: true}
+			if diff := cmp.Diff(expectedRecord, history["foo"][0]); diff != "" {
+				t.Errorf("unexpected history record: %s", diff)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"io"
 	"io/fs"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
@@ -74,9 +75,11 @@ import (
 	"github.com/openshift/ci-tools/pkg/junit"
 	"github.com/openshift/ci-tools/pkg/lease"
 	"github.com/openshift/ci-tools/pkg/load"
+	releaseclient "github.com/openshift/ci-tools/pkg/release"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps"
+	"github.com/openshift/ci-tools/pkg/steps/release"
 	"github.com/openshift/ci-tools/pkg/util"
 	"github.com/openshift/ci-tools/pkg/validation"
 )
@@ -343,6 +346,7 @@ type options struct {
 
 	targets stringSlice
 	promote bool
+	dryRun  bool
 
 	verbose bool
 	help    bool
@@ -383,6 +387,9 @@ type options struct {
 	branch          string
 	variant         string
 
+	resolverConfigGeneration   string
+	resolverRegistryGeneration string
+
 	metadataRevision int
 
 	pullSecretPath string
@@ -403,6 +410,16 @@ type options struct {
 	hiveKubeconfigPath string
 	hiveKubeconfig     *rest.Config
 
+	registryKubeconfigPath string
+	registryKubeconfig     *rest.Config
+
+	pipelineImageCacheName string
+	pipelineImageCache     *steps.PipelineImageCache
+
+	releaseFixturesDir    string
+	recordReleaseFixtures bool
+	releaseHTTPClient     releaseclient.HTTPClient
+
 	multiStageParamOverrides stringSlice
 	dependencyOverrides      stringSlice
 }
@@ -443,6 +460,7 @@ func bindOptions(flag *flag.FlagSet) *options {
 
 	// actions to add to the graph
 	flag.BoolVar(&opt.promote, "promote", false, "When all other targets complete, publish the set of images built by this job into the release configuration.")
+	flag.BoolVar(&opt.dryRun, "dry-run", false, "Together with --promote, print which images would be promoted and where they would be mirrored to, then exit without building anything.")
 
 	// output control
 	flag.StringVar(&opt.artifactDir, "artifact-dir", "", "DEPRECATED. Does nothing, set $ARTIFACTS instead.")
@@ -459,12 +477,18 @@ func bindOptions(flag *flag.FlagSet) *options {
 	flag.StringVar(&opt.repo, "repo", "", "Repo of the project (used by configresolver)")
 	flag.StringVar(&opt.branch, "branch", "", "Branch of the project (used by configresolver)")
 	flag.StringVar(&opt.variant, "variant", "", "Variant of the project's ci-operator config (used by configresolver)")
+	flag.StringVar(&opt.resolverConfigGeneration, "resolver-config-generation", "", "Generation number or git SHA of the configs to request from the configresolver, if retained (used by configresolver)")
+	flag.StringVar(&opt.resolverRegistryGeneration, "resolver-registry-generation", "", "Generation number or git SHA of the step registry to resolve the config with, if retained (used by configresolver)")
 
 	flag.StringVar(&opt.pullSecretPath, "image-import-pull-secret", "", "A set of dockercfg credentials used to import images for the tag_specification.")
 	flag.StringVar(&opt.pushSecretPath, "image-mirror-push-secret", "", "A set of dockercfg credentials used to mirror images for the promotion.")
 	flag.StringVar(&opt.uploadSecretPath, "gcs-upload-secret", "", "GCS credentials used to upload logs and artifacts.")
 
 	flag.StringVar(&opt.hiveKubeconfigPath, "hive-kubeconfig", "", "Path to the kubeconfig file to use for requests to Hive.")
+	flag.StringVar(&opt.pipelineImageCacheName, "pipeline-image-cache", "", "Image stream shared between jobs on the build cluster, as namespace/name, in which pipeline images are cached by the inputs they are built from. If set, images built from the same inputs before are reused instead of being built.")
+	flag.StringVar(&opt.releaseFixturesDir, "release-fixtures-dir", "", "Directory of recorded responses of the release endpoints. If set, releases are resolved from the recorded responses instead of the live endpoints.")
+	flag.BoolVar(&opt.recordReleaseFixtures, "record-release-fixtures", false, "Resolve releases from the live endpoints and record their responses into --release-fixtures-dir.")
+	flag.StringVar(&opt.registryKubeconfigPath, "registry-kubeconfig", "", "Path to the kubeconfig file for the cluster hosting the image streams images are promoted to. If set, promotions are recorded in the history of the promoted tags.")
 
 	flag.Var(&opt.multiStageParamOverrides, "multi-stage-param", "A repeatable option where one or more environment parameters can be passed down to the multi-stage steps. This parameter should be in the format NAME=VAL. e.g --multi-stage-param PARAM1=VAL1 --multi-stage-param PARAM2=VAL2.")
 	flag.Var(&opt.dependencyOverrides, "dependency-override-param", "A repeatable option used to override dependencies with external pull specs. This parameter should be in the format ENVVARNAME=PULLSPEC, e.g. --dependency-override-param=OO_INDEX=registry.mydomain.com:5000/pushed/myimage. This would override the value for the OO_INDEX environment variable for any tests/steps that currently have that dependency configured.")
@@ -474,6 +498,9 @@ func bindOptions(flag *flag.FlagSet) *options {
 }
 
 func (o *options) Complete() error {
+	if o.dryRun && !o.promote {
+		return errors.New("--dry-run is only supported together with --promote")
+	}
 	jobSpec, err := api.ResolveSpecFromEnv()
 	if err != nil {
 		if len(o.gitRef) == 0 {
@@ -510,10 +537,13 @@ func (o *options) Complete() error {
 		return errors.New("cannot request resolved config with --unresolved-config unless providing --resolver-address")
 	}
 
-	config, err := load.Config(o.configSpecPath, o.unresolvedConfigPath, o.registryPath, info)
+	config, generations, err := load.Config(o.configSpecPath, o.unresolvedConfigPath, o.registryPath, info)
 	if err != nil {
 		return results.ForReason("loading_config").WithError(err).Errorf("failed to load configuration: %v", err)
 	}
+	if generations != nil {
+		o.recordResolverGenerations(generations)
+	}
 	if len(o.gitRef) != 0 && config.CanonicalGoRepository != nil {
 		o.jobSpec.Refs.PathAlias = *config.CanonicalGoRepository
 	}
@@ -522,6 +552,10 @@ func (o *options) Complete() error {
 	if err := validation.IsValidResolvedConfiguration(o.configSpec); err != nil {
 		return results.ForReason("validating_config").ForError(err)
 	}
+	if o.dryRun {
+		// the promotion plan is determined by the configuration alone
+		return nil
+	}
 
 	if o.verbose {
 		config, _ := yaml.Marshal(o.configSpec)
@@ -656,6 +690,39 @@ func (o *options) Complete() error {
 		}
 	}
 
+	if o.pipelineImageCacheName != "" {
+		parts := strings.Split(o.pipelineImageCacheName, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("--pipeline-image-cache must be an image stream in the form namespace/name, not %q", o.pipelineImageCacheName)
+		}
+		o.pipelineImageCache = &steps.PipelineImageCache{Namespace: parts[0], Name: parts[1]}
+	}
+
+	if o.recordReleaseFixtures && o.releaseFixturesDir == "" {
+		return errors.New("--record-release-fixtures requires --release-fixtures-dir")
+	}
+	if o.releaseFixturesDir != "" {
+		if o.recordReleaseFixtures {
+			o.releaseHTTPClient = releaseclient.NewRecordingHTTPClient(&http.Client{}, o.releaseFixturesDir)
+		} else {
+			o.releaseHTTPClient = releaseclient.NewReplayingHTTPClient(o.releaseFixturesDir)
+		}
+	}
+
+	if o.registryKubeconfigPath != "" {
+		kubeConfigs, _, err := util.LoadKubeConfigs(o.registryKubeconfigPath, nil)
+		if err != nil {
+			return fmt.Errorf("could not load registry kube config from path %s: %w", o.registryKubeconfigPath, err)
+		}
+		if len(kubeConfigs) != 1 {
+			return fmt.Errorf("found %d contexts in registry kube config %s: must be %s only", len(kubeConfigs), o.registryKubeconfigPath, string(api.ClusterAPPCI))
+		}
+		for k := range kubeConfigs {
+			o.registryKubeconfig = kubeConfigs[k]
+			break
+		}
+	}
+
 	if err := overrideMultiStageParams(o); err != nil {
 		return err
 	}
@@ -795,12 +862,18 @@ func (o *options) Run() []error {
 		logrus.Infof("error: Process interrupted with signal %s, cancelling execution...", s)
 		cancel()
 	}
+	if o.dryRun {
+		if err := printPromotionPlan(os.Stdout, o.configSpec, o.targets.values); err != nil {
+			return []error{results.ForReason("defaulting_config").WithError(err).Errorf("could not determine the promotion plan: %v", err)}
+		}
+		return nil
+	}
 	var leaseClient *lease.Client
 	if o.leaseServer != "" && o.leaseServerCredentialsFile != "" {
 		leaseClient = &o.leaseClient
 	}
 	// load the graph from the configuration
-	buildSteps, postSteps, err := defaults.FromConfig(ctx, o.configSpec, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig)
+	buildSteps, postSteps, err := defaults.FromConfig(ctx, o.configSpec, o.jobSpec, o.templates, o.writeParams, o.promote, o.clusterConfig, leaseClient, o.targets.values, o.cloneAuthConfig, o.pullSecret, o.pushSecret, o.censor, o.hiveKubeconfig, o.registryKubeconfig, o.pipelineImageCache, o.releaseHTTPClient)
 	if err != nil {
 		return []error{results.ForReason("defaulting_config").WithError(err).Errorf("failed to generate steps from config: %v", err)}
 	}
@@ -1815,6 +1888,42 @@ func printDigraph(w io.Writer, steps []api.Step) error {
 	return nil
 }
 
+// printPromotionPlan prints the destinations every promoted image would be
+// mirrored to, one per line
+func printPromotionPlan(w io.Writer, config *api.ReleaseBuildConfiguration, targets []string) error {
+	if config.PromotionConfiguration == nil {
+		return errors.New("cannot promote images, no promotion configuration defined")
+	}
+	plan := release.PromotionPlan(config, sets.NewString(targets...))
+	if len(plan) == 0 {
+		_, err := fmt.Fprintln(w, "No images would be promoted.")
+		return err
+	}
+	var sources []string
+	for source := range plan {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		for _, destination := range plan[source] {
+			if _, err := fmt.Fprintf(w, "%s:%s -> %s\n", api.PipelineImageStream, source, destination); err != nil {
+				return err
+			}
+		}
+	}
+	if signing := config.PromotionConfiguration.Signing; signing != nil {
+		if _, err := fmt.Fprintf(w, "Images pushed to external destinations would be signed with the key in the %s secret.\n", signing.KeySecret); err != nil {
+			return err
+		}
+		if signing.SBOMPath != "" {
+			if _, err := fmt.Fprintf(w, "The SBOM at %s in each image would be attached to it and signed.\n", signing.SBOMPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func printExecutionOrder(nodes []*api.StepNode) error {
 	ordered, err := topologicalSort(nodes)
 	if err != nil {
@@ -1985,8 +2094,10 @@ func getSecret(name, filename string) (*coreapi.Secret, error) {
 func (o *options) getResolverInfo(jobSpec *api.JobSpec) *load.ResolverInfo {
 	// address and variant can only be set via options
 	info := &load.ResolverInfo{
-		Address: o.resolverAddress,
-		Variant: o.variant,
+		Address:            o.resolverAddress,
+		Variant:            o.variant,
+		ConfigGeneration:   o.resolverConfigGeneration,
+		RegistryGeneration: o.resolverRegistryGeneration,
 	}
 
 	allRefs := jobSpec.ExtraRefs
@@ -2017,6 +2128,32 @@ func (o *options) getResolverInfo(jobSpec *api.JobSpec) *load.ResolverInfo {
 	return info
 }
 
+const resolverGenerationsJSONFile = "ci-operator-resolver-generations.json"
+
+// recordResolverGenerations logs and saves the generations of the configs and the
+// registry the configresolver served the config from, so the same config can be
+// requested again with --resolver-config-generation and --resolver-registry-generation
+func (o *options) recordResolverGenerations(generations *load.ResolvedGenerations) {
+	fields := logrus.Fields{}
+	if generations.Config != nil {
+		fields["config-generation"] = generations.Config.Generation
+		fields["config-sha"] = generations.Config.SHA
+	}
+	if generations.Registry != nil {
+		fields["registry-generation"] = generations.Registry.Generation
+		fields["registry-sha"] = generations.Registry.SHA
+	}
+	logrus.WithFields(fields).Debug("Loaded configuration from the configresolver.")
+	data, err := json.MarshalIndent(generations, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Warn("Could not marshal the configresolver generations.")
+		return
+	}
+	if err := api.SaveArtifact(o.censor, resolverGenerationsJSONFile, data); err != nil {
+		logrus.WithError(err).Warn("Could not save the configresolver generations.")
+	}
+}
+
 func monitorNamespace(ctx context.Context, cancel func(), namespace string, client coreclientset.NamespaceInterface) {
 reset:
 	for {
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -414,7 +415,7 @@ func TestBuildPartialGraph(t *testing.T) {
 						},
 						To: api.PipelineImageStreamTagReference("oc-bin-image"),
 					},
-					&api.ReleaseBuildConfiguration{}, api.ResourceConfiguration{}, nil, nil, nil,
+					&api.ReleaseBuildConfiguration{}, api.ResourceConfiguration{}, nil, nil, nil, nil,
 				),
 				steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil),
 				steps.ImagesReadyStep(steps.OutputImageTagStep(api.OutputImageTagStepConfiguration{From: api.PipelineImageStreamTagReference("oc-bin-image")}, nil, nil).Creates()),
@@ -841,3 +842,60 @@ func TestDependencyOverrides(t *testing.T) {
 		})
 	}
 }
+
+func TestPrintPromotionPlan(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      *api.ReleaseBuildConfiguration
+		targets     []string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:        "no promotion configuration",
+			config:      &api.ReleaseBuildConfiguration{},
+			expectedErr: errors.New("cannot promote images, no promotion configuration defined"),
+		},
+		{
+			name: "nothing to promote",
+			config: &api.ReleaseBuildConfiguration{
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ci", Name: "4.8", Disabled: true},
+				Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{{To: "foo"}},
+			},
+			expected: "No images would be promoted.\n",
+		},
+		{
+			name: "promotion to external destinations with signing",
+			config: &api.ReleaseBuildConfiguration{
+				PromotionConfiguration: &api.PromotionConfiguration{
+					Namespace:            "ci",
+					Name:                 "4.8",
+					ExcludedImages:       []string{"excluded"},
+					ExternalDestinations: []api.ExternalPromotionDestination{{Repository: "quay.io/org", Credentials: "quay"}},
+					Signing:              &api.PromotionSigningConfiguration{KeySecret: "cosign", SBOMPath: "/sbom.json"},
+				},
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "foo"}, {To: "excluded"}, {To: "optional", Optional: true}, {To: "required", Optional: true}},
+			},
+			targets: []string{"required"},
+			expected: `pipeline:foo -> registry.ci.openshift.org/ci/4.8:foo
+pipeline:foo -> quay.io/org/4.8:foo
+pipeline:required -> registry.ci.openshift.org/ci/4.8:required
+pipeline:required -> quay.io/org/4.8:required
+Images pushed to external destinations would be signed with the key in the cosign secret.
+The SBOM at /sbom.json in each image would be attached to it and signed.
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := printPromotionPlan(&out, tc.config, tc.targets)
+			if diff := cmp.Diff(tc.expectedErr, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, out.String()); diff != "" {
+				t.Errorf("unexpected output: %s", diff)
+			}
+		})
+	}
+}
@@ -3,27 +3,17 @@ package main
 import (
 	"flag"
 	"fmt"
-	"strings"
 
 	"github.com/sirupsen/logrus"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/test-infra/prow/flagutil"
-	utilpointer "k8s.io/utils/pointer"
 
 	"github.com/openshift/ci-tools/pkg/api"
 	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/migrate"
 )
 
-const (
-	openshiftInstallerCustomTestImageTemplateName = "openshift_installer_custom_test_image"
-	OpenshiftInstallerUPITemplateName             = "openshift_installer_upi"
-	OpenShiftInstallerTemplateName                = "openshift_installer"
-)
-
-var validTemplateMigrations = sets.NewString(openshiftInstallerCustomTestImageTemplateName, OpenshiftInstallerUPITemplateName, OpenShiftInstallerTemplateName)
-
 type options struct {
 	config.ConfirmableOptions
 	enabledTemplateMigrations               flagutil.Strings
@@ -38,18 +28,23 @@ func (o options) validate() error {
 	if err := o.ConfirmableOptions.Validate(); err != nil {
 		errs = append(errs, err)
 	}
-	if diff := sets.NewString(o.enabledTemplateMigrations.Strings()...).Difference(validTemplateMigrations); len(diff) != 0 {
-		errs = append(errs, fmt.Errorf("invalid values %v for --enabled-template-migration, valid values: %v", diff.List(), validTemplateMigrations.List()))
+	if _, err := o.migrations(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid --enabled-template-migration: %w", err))
 	}
 
 	return utilerrors.NewAggregate(errs)
 }
 
+func (o options) migrations() ([]migrate.Migration, error) {
+	all := migrate.TemplateMigrations(o.templateMigrationAllowedClusterProfiles.StringSet())
+	return migrate.Select(all, o.enabledTemplateMigrations.StringSet())
+}
+
 func gatherOptions() options {
 	o := options{}
 	o.Bind(flag.CommandLine)
-	flag.Var(&o.enabledTemplateMigrations, "enabled-template-migration", fmt.Sprintf("The enabled template migrations. Can be passed multiple times. Valid values are %v", validTemplateMigrations.List()))
-	flag.IntVar(&o.templateMigrationCeiling, "template-migration-ceiling", 10, "The maximum number of templates to migrate")
+	flag.Var(&o.enabledTemplateMigrations, "enabled-template-migration", fmt.Sprintf("The enabled template migrations. Can be passed multiple times. Valid values are %v", []string{migrate.OpenshiftInstallerCustomTestImageTemplateName, migrate.OpenshiftInstallerUPITemplateName, migrate.OpenShiftInstallerTemplateName}))
+	flag.IntVar(&o.templateMigrationCeiling, "template-migration-ceiling", 10, "The maximum number of files to migrate templates in")
 	flag.Var(&o.templateMigrationAllowedBranches, "template-migration-allowed-branch", "Allowed branches to automigrate templates on. Can be passed multiple times. All branches are allowed if unset.")
 	flag.Var(&o.templateMigrationAllowedOrgs, "template-migration-allowed-org", "Allowed orgs to automigrate templates on. Can be passed multiple times. All orgs are allowed if unset.")
 	flag.Var(&o.templateMigrationAllowedClusterProfiles, "template-migration-allowed-cluster-profile", "Allowed cluster profiles to automigrate templates on. Can be passed multiple times. All cluster profiles are allowed if unset.")
@@ -64,26 +59,31 @@ func main() {
 		logrus.Fatalf("Invalid options: %v", err)
 	}
 
-	var migratedCount int
+	migrations, err := o.migrations()
+	if err != nil {
+		logrus.WithError(err).Fatal("Could not determine migrations.")
+	}
+	migrator := migrate.NewMigrator(migrations, migrate.AllowList{
+		Orgs:     o.templateMigrationAllowedOrgs.StringSet(),
+		Branches: o.templateMigrationAllowedBranches.StringSet(),
+	}, o.templateMigrationCeiling)
+
 	var toCommit []config.DataWithInfo
 	if err := o.OperateOnCIOperatorConfigDir(o.ConfigDir, func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
 		output := config.DataWithInfo{Configuration: *configuration, Info: *info}
+		result, err := migrator.Migrate(&output)
+		if err != nil {
+			return err
+		}
 		if !o.Confirm {
+			if result.Changed() {
+				output.Logger().WithField("migrations", result.Changes).Infof("Would migrate file:\n%s", result.Diff)
+			}
 			output.Logger().Info("Would re-format file.")
 			return nil
 		}
-
-		allowedBranches := o.templateMigrationAllowedBranches.StringSet()
-		allowedOrgs := o.templateMigrationAllowedOrgs.StringSet()
-		allowedClusterProfiles := o.templateMigrationAllowedClusterProfiles.StringSet()
-		if sets.NewString(o.enabledTemplateMigrations.Strings()...).Has(openshiftInstallerCustomTestImageTemplateName) && migratedCount <= o.templateMigrationCeiling {
-			migratedCount += migrateOpenshiftInstallerCustomTestImageTemplates(&output, allowedBranches, allowedOrgs, allowedClusterProfiles)
-		}
-		if o.enabledTemplateMigrations.StringSet().Has(OpenshiftInstallerUPITemplateName) && migratedCount <= o.templateMigrationCeiling {
-			migratedCount += migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(&output, allowedBranches, allowedOrgs, allowedClusterProfiles)
-		}
-		if o.enabledTemplateMigrations.StringSet().Has(OpenShiftInstallerTemplateName) && migratedCount <= o.templateMigrationCeiling {
-			migratedCount += migrateOpenShiftInstallerTemplates(&output, allowedBranches, allowedOrgs, allowedClusterProfiles)
+		if result.Changed() {
+			output.Logger().WithField("migrations", result.Changes).Info("Migrated file.")
 		}
 
 		// we treat the filepath as the ultimate source of truth for this
@@ -105,171 +105,3 @@ func main() {
 		}
 	}
 }
-
-func upgradeWorkflowForClusterProfile(clusterProfile api.ClusterProfile) string {
-	return fmt.Sprintf("openshift-upgrade-%s", clusterProfile)
-}
-
-func e2eWorkflowForClusterProfile(clusterProfile api.ClusterProfile) string {
-	return fmt.Sprintf("openshift-e2e-%s", clusterProfile)
-}
-
-func migrateOpenShiftInstallerTemplates(
-	configuration *config.DataWithInfo,
-	allowedBranches sets.String,
-	allowedOrgs sets.String,
-	allowedCloudproviders sets.String,
-) (migratedCount int) {
-	if (len(allowedBranches) != 0 && !allowedBranches.Has(configuration.Info.Branch)) || (len(allowedOrgs) != 0 && !allowedOrgs.Has(configuration.Info.Org)) {
-		return 0
-	}
-
-	for idx, test := range configuration.Configuration.Tests {
-		if test.OpenshiftInstallerClusterTestConfiguration == nil ||
-			(len(allowedCloudproviders) != 0 && !allowedCloudproviders.Has(string(test.OpenshiftInstallerClusterTestConfiguration.ClusterProfile))) {
-			continue
-		}
-
-		clusterProfile := test.OpenshiftInstallerClusterTestConfiguration.ClusterProfile
-		switch {
-		case test.OpenshiftInstallerClusterTestConfiguration.Upgrade:
-			test.OpenshiftInstallerClusterTestConfiguration = nil
-			test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
-				ClusterProfile: clusterProfile,
-				Workflow:       utilpointer.StringPtr(upgradeWorkflowForClusterProfile(clusterProfile)),
-			}
-		case test.Commands == "setup_ssh_bastion; TEST_SUITE=openshift/disruptive run-tests; TEST_SUITE=openshift/conformance/parallel run-tests":
-			// TODO(muller): Unfortunately there is no easy way to express this ("run same step twice")
-			continue
-		default:
-			test.OpenshiftInstallerClusterTestConfiguration = nil
-			test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
-				ClusterProfile: clusterProfile,
-				Workflow:       utilpointer.StringPtr(e2eWorkflowForClusterProfile(clusterProfile)),
-			}
-		}
-		test.Commands = ""
-		configuration.Configuration.Tests[idx] = test
-		migratedCount++
-	}
-
-	return migratedCount
-}
-
-func migrateOpenshiftInstallerCustomTestImageTemplates(
-	configuration *config.DataWithInfo,
-	allowedBranches sets.String,
-	allowedOrgs sets.String,
-	allowedCloudproviders sets.String,
-) (migratedCount int) {
-	if (len(allowedBranches) != 0 && !allowedBranches.Has(configuration.Info.Branch)) || (len(allowedOrgs) != 0 && !allowedOrgs.Has(configuration.Info.Org)) {
-		return 0
-	}
-
-	for idx, test := range configuration.Configuration.Tests {
-		if test.OpenshiftInstallerCustomTestImageClusterTestConfiguration == nil ||
-			(len(allowedCloudproviders) != 0 && !allowedCloudproviders.Has(string(test.OpenshiftInstallerCustomTestImageClusterTestConfiguration.ClusterProfile))) {
-			continue
-		}
-
-		clusterProfile := test.OpenshiftInstallerCustomTestImageClusterTestConfiguration.ClusterProfile
-		fromImage := test.OpenshiftInstallerCustomTestImageClusterTestConfiguration.From
-		test.OpenshiftInstallerCustomTestImageClusterTestConfiguration = nil
-		test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
-			ClusterProfile: clusterProfile,
-			Test: []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{
-				As:       "test",
-				From:     fromImage,
-				Commands: test.Commands,
-				Cli:      api.LatestReleaseName,
-				Resources: api.ResourceRequirements{
-					Requests: api.ResourceList{"cpu": "100m"},
-				},
-			}}},
-			Workflow: utilpointer.StringPtr(ipiWorkflowForClusterProfile(clusterProfile)),
-		}
-		test.Commands = ""
-
-		configuration.Configuration.Tests[idx] = test
-		migratedCount++
-
-	}
-
-	return migratedCount
-}
-
-func providerNameForProfile(clusterProfile api.ClusterProfile) string {
-	if clusterProfile == api.ClusterProfileAzure4 {
-		return "azure"
-	}
-	return string(clusterProfile)
-}
-
-func ipiWorkflowForClusterProfile(clusterProfile api.ClusterProfile) string {
-	return fmt.Sprintf("ipi-%s", providerNameForProfile(clusterProfile))
-}
-
-func migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(
-	configuration *config.DataWithInfo,
-	allowedBranches sets.String,
-	allowedOrgs sets.String,
-	allowedCloudproviders sets.String,
-) (migratedCount int) {
-	if (len(allowedBranches) != 0 && !allowedBranches.Has(configuration.Info.Branch)) || (len(allowedOrgs) != 0 && !allowedOrgs.Has(configuration.Info.Org)) {
-		return 0
-	}
-
-	log := logrus.WithField("file", configuration.Info.Filename)
-
-	for idx, test := range configuration.Configuration.Tests {
-		if test.OpenshiftInstallerUPIClusterTestConfiguration == nil ||
-			(len(allowedCloudproviders) != 0 && !allowedCloudproviders.Has(string(test.OpenshiftInstallerUPIClusterTestConfiguration.ClusterProfile))) {
-			continue
-		}
-		log := log.WithField("field", fmt.Sprintf("tests.%d", idx))
-
-		commandFields := strings.Fields(test.Commands)
-		if n := len(commandFields); n != 2 {
-			log.Warnf("command %q didn't have exactly two fields, skipping migration of openshift_installer_upi template", test.Commands)
-			continue
-		}
-		equalSignSplit := strings.Split(commandFields[0], "=")
-		if n := len(equalSignSplit); n != 2 {
-			log.Warnf("splitting first field of command %q by = didn't yield exactly two results, skipping migration of openshift_installer_upi template", test.Commands)
-			continue
-		}
-
-		var testTypeEnv string
-		switch commandFields[1] {
-		case "run-tests":
-			testTypeEnv = ""
-		case "run-upgrade":
-			testTypeEnv = "upgrade"
-		default:
-			log.Warnf("command %q has unrecognized command element %q, known elements: ['run-tests', 'run-upgrade'], skipping migration of openshift_installer_upi template", test.Commands, commandFields[1])
-			continue
-		}
-
-		clusterProfile := test.OpenshiftInstallerUPIClusterTestConfiguration.ClusterProfile
-		test.OpenshiftInstallerUPIClusterTestConfiguration = nil
-		test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
-			ClusterProfile: clusterProfile,
-			Environment: api.TestEnvironment{
-				// https://github.com/openshift/release/blob/ea3cc4842843c941e9fa1e71ce8a4dc3ce841184/ci-operator/step-registry/openshift/e2e/test/openshift-e2e-test-ref.yaml#L10
-				"TEST_SUITE": equalSignSplit[1],
-			},
-			Workflow: utilpointer.StringPtr(fmt.Sprintf("openshift-e2e-%s-upi", providerNameForProfile(clusterProfile))),
-		}
-		if testTypeEnv != "" {
-			// https://github.com/openshift/release/blob/ea3cc4842843c941e9fa1e71ce8a4dc3ce841184/ci-operator/step-registry/openshift/e2e/test/openshift-e2e-test-ref.yaml#L7
-			test.MultiStageTestConfiguration.Environment["TEST_TYPE"] = testTypeEnv
-		}
-		test.Commands = ""
-
-		configuration.Configuration.Tests[idx] = test
-		migratedCount++
-
-	}
-
-	return migratedCount
-}
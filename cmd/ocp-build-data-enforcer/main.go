@@ -31,6 +31,8 @@ type options struct {
 	majorMinor          ocpbuilddata.MajorMinor
 	createPRs           bool
 	prCreationCeiling   int
+	configDir           string
+	reportDir           string
 	*prcreation.PRCreationOptions
 }
 
@@ -41,8 +43,19 @@ func gatherOptions() (*options, error) {
 	flag.StringVar(&o.majorMinor.Minor, "minor", "6", "The minor version to target")
 	flag.BoolVar(&o.createPRs, "create-prs", false, "If the tool should create PRs")
 	flag.IntVar(&o.prCreationCeiling, "pr-creation-ceiling", 5, "The maximum number of PRs to upsert")
+	flag.StringVar(&o.configDir, "config-dir", "", "The directory with the ci-operator configs. Required when --report-dir is set")
+	flag.StringVar(&o.reportDir, "report-dir", "", "If set, do not enforce anything but write a JSON and HTML report of the drift from ocp-build-data into this directory")
 	flag.Parse()
 
+	if o.reportDir != "" {
+		if o.configDir == "" {
+			return nil, errors.New("--config-dir is required when --report-dir is set")
+		}
+		if o.createPRs {
+			return nil, errors.New("--create-prs and --report-dir are mutually exclusive")
+		}
+	}
+
 	if o.createPRs {
 		if err := o.PRCreationOptions.Finalize(); err != nil {
 			return nil, fmt.Errorf("failed to finalize pr creation options: %w", err)
@@ -75,6 +88,22 @@ func main() {
 		logrus.Fatal("Encountered errors")
 	}
 
+	if opts.reportDir != "" {
+		builds, err := promotedBuilds(opts.configDir, opts.majorMinor)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load promoted images")
+		}
+		report, err := generateReport(configs, builds, opts.majorMinor, github.FileGetterFactory)
+		if err != nil {
+			logrus.WithError(err).Error("Encountered errors while generating the report, it may be incomplete")
+		}
+		if err := writeReport(opts.reportDir, report); err != nil {
+			logrus.WithError(err).Fatal("Failed to write report")
+		}
+		logrus.Infof("%d of %d components are aligned with ocp-build-data", report.Aligned, len(report.Components))
+		return
+	}
+
 	clientFactory, err := git.NewClientFactory()
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to construct git client factory")
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/imagebuilder"
+	dockercmd "github.com/openshift/imagebuilder/dockerfile/command"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/github"
+	"github.com/openshift/ci-tools/pkg/steps/release"
+)
+
+// DriftKind describes how a component deviates from its ocp-build-data config
+type DriftKind string
+
+const (
+	// DriftMissingPromotion means no ci-operator config promotes the component
+	DriftMissingPromotion DriftKind = "missing_promotion"
+	// DriftDockerfilePath means the promoting image is built from a different Dockerfile
+	DriftDockerfilePath DriftKind = "dockerfile_path"
+	// DriftBuilder means a builder stage of the Dockerfile uses a different image
+	DriftBuilder DriftKind = "builder"
+	// DriftFrom means the final stage of the Dockerfile uses a different image
+	DriftFrom DriftKind = "from"
+)
+
+// Drift is a single deviation of a component from its ocp-build-data config
+type Drift struct {
+	Kind DriftKind `json:"kind"`
+	// CIOperatorConfig is the ci-operator config the deviation was found in, if any
+	CIOperatorConfig string `json:"ci_operator_config,omitempty"`
+	Expected         string `json:"expected"`
+	Actual           string `json:"actual,omitempty"`
+}
+
+// Build is an image built by a ci-operator config that is promoted as a component
+type Build struct {
+	CIOperatorConfig string `json:"ci_operator_config"`
+	Image            string `json:"image"`
+	Dockerfile       string `json:"dockerfile"`
+}
+
+// Component is the alignment status of one image configured in ocp-build-data
+type Component struct {
+	Name             string  `json:"name"`
+	OCPBuildDataFile string  `json:"ocp_build_data_file"`
+	Repository       string  `json:"repository"`
+	PromotesTo       string  `json:"promotes_to"`
+	Builds           []Build `json:"builds,omitempty"`
+	Drifts           []Drift `json:"drifts,omitempty"`
+}
+
+// Aligned determines if the component matches its ocp-build-data config
+func (c Component) Aligned() bool {
+	return len(c.Drifts) == 0
+}
+
+// Report is the drift of all components of a release from ocp-build-data
+type Report struct {
+	Version    string      `json:"version"`
+	Components []Component `json:"components"`
+	Aligned    int         `json:"aligned"`
+	Drifted    int         `json:"drifted"`
+}
+
+// promotedBuild is an image of a ci-operator config with the tag it promotes to
+type promotedBuild struct {
+	Build
+	org, repo, branch string
+}
+
+// promotedBuilds indexes the images of all ci-operator configs by the pull spec
+// they are promoted to in the integration stream of the release
+func promotedBuilds(configDir string, majorMinor ocpbuilddata.MajorMinor) (map[string][]promotedBuild, error) {
+	builds := map[string][]promotedBuild{}
+	if err := config.OperateOnCIOperatorConfigDir(configDir, func(configuration *api.ReleaseBuildConfiguration, info *config.Info) error {
+		promoted, _ := release.PromotedTagsWithRequiredImages(configuration, sets.NewString())
+		for _, image := range configuration.Images {
+			tag, ok := promoted[string(image.To)]
+			if !ok || tag.Namespace != "ocp" || tag.Name != majorMinor.String() {
+				continue
+			}
+			target := fmt.Sprintf("registry.ci.openshift.org/%s", tag.ISTagName())
+			builds[target] = append(builds[target], promotedBuild{
+				Build: Build{
+					CIOperatorConfig: info.Basename(),
+					Image:            string(image.To),
+					Dockerfile:       dockerfilePath(image.ContextDir, image.DockerfilePath),
+				},
+				org:    info.Org,
+				repo:   info.Repo,
+				branch: info.Branch,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to load ci-operator configs: %w", err)
+	}
+	for target := range builds {
+		sort.Slice(builds[target], func(i, j int) bool {
+			return builds[target][i].CIOperatorConfig < builds[target][j].CIOperatorConfig
+		})
+	}
+	return builds, nil
+}
+
+func dockerfilePath(contextDir, dockerfile string) string {
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	return filepath.Join(contextDir, dockerfile)
+}
+
+// generateReport compares every ocp-build-data image config with the ci-operator
+// configs that promote it and with the Dockerfiles they build from
+func generateReport(
+	configs []ocpbuilddata.OCPImageConfig,
+	builds map[string][]promotedBuild,
+	majorMinor ocpbuilddata.MajorMinor,
+	githubFileGetterFactory func(org, repo, branch string, opts ...github.Opt) github.FileGetter,
+) (Report, error) {
+	report := Report{Version: majorMinor.String()}
+	lock := &sync.Mutex{}
+	errGroup := &errgroup.Group{}
+	for idx := range configs {
+		cfg := configs[idx]
+		errGroup.Go(func() error {
+			component, err := componentDrift(cfg, builds[cfg.PromotesTo()], githubFileGetterFactory)
+			if err != nil {
+				return fmt.Errorf("failed to determine drift for %s: %w", cfg.SourceFileName, err)
+			}
+			lock.Lock()
+			report.Components = append(report.Components, component)
+			lock.Unlock()
+			return nil
+		})
+	}
+	err := errGroup.Wait()
+
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].OCPBuildDataFile < report.Components[j].OCPBuildDataFile
+	})
+	for _, component := range report.Components {
+		if component.Aligned() {
+			report.Aligned++
+		} else {
+			report.Drifted++
+		}
+	}
+	return report, err
+}
+
+func componentDrift(
+	cfg ocpbuilddata.OCPImageConfig,
+	builds []promotedBuild,
+	githubFileGetterFactory func(org, repo, branch string, opts ...github.Opt) github.FileGetter,
+) (Component, error) {
+	component := Component{
+		Name:             cfg.Name,
+		OCPBuildDataFile: cfg.SourceFileName,
+		Repository:       cfg.PublicRepo.String(),
+		PromotesTo:       cfg.PromotesTo(),
+	}
+	expectedDockerfile := cfg.Dockerfile()
+	if len(builds) == 0 {
+		component.Drifts = append(component.Drifts, Drift{Kind: DriftMissingPromotion, Expected: component.PromotesTo})
+		return component, nil
+	}
+	stages, err := cfg.Stages()
+	if err != nil {
+		return component, fmt.Errorf("failed to get stages: %w", err)
+	}
+
+	var errs []error
+	for _, build := range builds {
+		component.Builds = append(component.Builds, build.Build)
+		if build.Dockerfile != expectedDockerfile {
+			component.Drifts = append(component.Drifts, Drift{
+				Kind:             DriftDockerfilePath,
+				CIOperatorConfig: build.CIOperatorConfig,
+				Expected:         expectedDockerfile,
+				Actual:           build.Dockerfile,
+			})
+		}
+		dockerfile, err := githubFileGetterFactory(build.org, build.repo, build.branch)(expectedDockerfile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get %s from %s/%s@%s: %w", expectedDockerfile, build.org, build.repo, build.branch, err))
+			continue
+		}
+		if len(dockerfile) == 0 {
+			continue
+		}
+		drifts, err := stageDrift(dockerfile, stages)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compare stages of %s in %s: %w", expectedDockerfile, build.CIOperatorConfig, err))
+			continue
+		}
+		for _, drift := range drifts {
+			drift.CIOperatorConfig = build.CIOperatorConfig
+			component.Drifts = append(component.Drifts, drift)
+		}
+	}
+	return component, utilerrors.NewAggregate(errs)
+}
+
+// stageDrift compares the images the stages of the Dockerfile are built from with
+// the builders and the base image configured in ocp-build-data
+func stageDrift(dockerfile []byte, expected []string) ([]Drift, error) {
+	rootNode, err := imagebuilder.ParseDockerfile(bytes.NewBuffer(dockerfile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Dockerfile: %w", err)
+	}
+	var actual []string
+	for _, child := range rootNode.Children {
+		if child.Value != dockercmd.From || child.Next == nil {
+			continue
+		}
+		actual = append(actual, child.Next.Value)
+	}
+
+	var drifts []Drift
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		var want, got string
+		if i < len(expected) {
+			want = expected[i]
+		}
+		if i < len(actual) {
+			got = actual[i]
+		}
+		if want == got {
+			continue
+		}
+		kind := DriftBuilder
+		if i == len(expected)-1 {
+			kind = DriftFrom
+		}
+		drifts = append(drifts, Drift{Kind: kind, Expected: want, Actual: got})
+	}
+	return drifts, nil
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>ocp-build-data drift for {{ .Version }}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.aligned { background-color: #dff0d8; }
+.drifted { background-color: #f2dede; }
+</style>
+</head>
+<body>
+<h1>ocp-build-data drift for {{ .Version }}</h1>
+<p>{{ .Aligned }} components are aligned, {{ .Drifted }} components drifted.</p>
+<table>
+<tr><th>Component</th><th>Repository</th><th>Promotes to</th><th>Builds</th><th>Drift</th></tr>
+{{- range .Components }}
+<tr class="{{ if .Aligned }}aligned{{ else }}drifted{{ end }}">
+<td>{{ .Name }}<br><small>{{ .OCPBuildDataFile }}</small></td>
+<td>{{ .Repository }}</td>
+<td>{{ .PromotesTo }}</td>
+<td>{{ range .Builds }}{{ .CIOperatorConfig }}: {{ .Image }} ({{ .Dockerfile }})<br>{{ end }}</td>
+<td>{{ range .Drifts }}<b>{{ .Kind }}</b>{{ with .CIOperatorConfig }} in {{ . }}{{ end }}: expected {{ .Expected }}{{ with .Actual }}, got {{ . }}{{ end }}<br>{{ end }}</td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+// writeReport stores the report as report.json and report.html in the directory
+func writeReport(dir string, report Report) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize report: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "report.json"), raw, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	var html bytes.Buffer
+	if err := reportTemplate.Execute(&html, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "report.html"), html.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api/ocpbuilddata"
+	"github.com/openshift/ci-tools/pkg/github"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestStageDrift(t *testing.T) {
+	testCases := []struct {
+		name       string
+		dockerfile string
+		expected   []string
+		drifts     []Drift
+	}{
+		{
+			name:       "aligned",
+			dockerfile: "FROM builder AS build\nRUN make\nFROM base\nCOPY --from=build /bin/app /bin/app\n",
+			expected:   []string{"builder", "base"},
+		},
+		{
+			name:       "builder and base image differ",
+			dockerfile: "FROM golang AS build\nRUN make\nFROM ubi\n",
+			expected:   []string{"builder", "base"},
+			drifts: []Drift{
+				{Kind: DriftBuilder, Expected: "builder", Actual: "golang"},
+				{Kind: DriftFrom, Expected: "base", Actual: "ubi"},
+			},
+		},
+		{
+			name:       "missing builder stage",
+			dockerfile: "FROM base\n",
+			expected:   []string{"builder", "base"},
+			drifts: []Drift{
+				{Kind: DriftBuilder, Expected: "builder", Actual: "base"},
+				{Kind: DriftFrom, Expected: "base"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			drifts, err := stageDrift([]byte(tc.dockerfile), tc.expected)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.drifts, drifts); diff != "" {
+				t.Errorf("unexpected drift: %s", diff)
+			}
+		})
+	}
+}
+
+func imageConfig(name, path string, builders ...string) ocpbuilddata.OCPImageConfig {
+	cfg := ocpbuilddata.OCPImageConfig{
+		Content:        &ocpbuilddata.OCPImageConfigContent{Source: ocpbuilddata.OCPImageConfigSource{Path: path}},
+		Name:           "openshift/ose-" + name,
+		SourceFileName: "images/ose-" + name + ".yml",
+		Version:        ocpbuilddata.MajorMinor{Major: "4", Minor: "6"},
+		PublicRepo:     ocpbuilddata.OrgRepo{Org: "openshift", Repo: name},
+	}
+	for _, builder := range builders {
+		cfg.From.Builder = append(cfg.From.Builder, ocpbuilddata.OCPImageConfigFromStream{Stream: builder})
+	}
+	cfg.From.Stream = "registry.ci.openshift.org/ocp/4.6:base"
+	return cfg
+}
+
+func TestGenerateReport(t *testing.T) {
+	configs := []ocpbuilddata.OCPImageConfig{
+		imageConfig("aligned", "", "registry.ci.openshift.org/ocp/builder:golang-1.15"),
+		imageConfig("drifted", "images/drifted", "registry.ci.openshift.org/ocp/builder:golang-1.15"),
+		imageConfig("unpromoted", ""),
+	}
+	builds := map[string][]promotedBuild{
+		"registry.ci.openshift.org/ocp/4.6:aligned": {{
+			Build: Build{CIOperatorConfig: "openshift-aligned-master.yaml", Image: "aligned", Dockerfile: "Dockerfile"},
+			org:   "openshift", repo: "aligned", branch: "master",
+		}},
+		"registry.ci.openshift.org/ocp/4.6:drifted": {{
+			Build: Build{CIOperatorConfig: "openshift-drifted-master.yaml", Image: "drifted", Dockerfile: "Dockerfile"},
+			org:   "openshift", repo: "drifted", branch: "master",
+		}},
+	}
+	dockerfiles := map[string]string{
+		"openshift/aligned/master/Dockerfile":                "FROM registry.ci.openshift.org/ocp/builder:golang-1.15 AS build\nFROM registry.ci.openshift.org/ocp/4.6:base\n",
+		"openshift/drifted/master/images/drifted/Dockerfile": "FROM registry.ci.openshift.org/ocp/builder:golang-1.14 AS build\nFROM registry.ci.openshift.org/ocp/4.6:base\n",
+	}
+	getter := func(org, repo, branch string, _ ...github.Opt) github.FileGetter {
+		return func(path string) ([]byte, error) {
+			dockerfile, ok := dockerfiles[filepath.Join(org, repo, branch, path)]
+			if !ok {
+				return nil, errors.New("not found")
+			}
+			return []byte(dockerfile), nil
+		}
+	}
+
+	report, err := generateReport(configs, builds, ocpbuilddata.MajorMinor{Major: "4", Minor: "6"}, getter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testhelper.CompareWithFixture(t, report)
+
+	dir := t.TempDir()
+	if err := writeReport(dir, report); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+	html, err := ioutil.ReadFile(filepath.Join(dir, "report.html"))
+	if err != nil {
+		t.Fatalf("failed to read HTML report: %v", err)
+	}
+	testhelper.CompareWithFixture(t, html, testhelper.WithSuffix(".html"))
+}
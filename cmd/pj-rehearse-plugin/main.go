@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/test-infra/prow/config/secret"
+	prowflagutil "k8s.io/test-infra/prow/flagutil"
+	"k8s.io/test-infra/prow/githubeventserver"
+	"k8s.io/test-infra/prow/interrupts"
+	"k8s.io/test-infra/prow/logrusutil"
+	"k8s.io/test-infra/prow/pjutil"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/rehearse"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+type options struct {
+	webhookSecretFile  string
+	prowjobKubeconfig  string
+	gcsCredentialsFile string
+
+	noTemplates       bool
+	noRegistry        bool
+	noClusterProfiles bool
+
+	rehearsalLimit   int
+	moreLimit        int
+	rehearsalContext string
+
+	githubEventServerOptions githubeventserver.Options
+	github                   prowflagutil.GitHubOptions
+	git                      prowflagutil.GitOptions
+
+	dryRun bool
+}
+
+func gatherOptions() options {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.BoolVar(&o.dryRun, "dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
+
+	fs.StringVar(&o.webhookSecretFile, "hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
+	fs.StringVar(&o.prowjobKubeconfig, "prowjob-kubeconfig", "", "Path to the prowjob kubeconfig. If unset, default kubeconfig will be used for prowjobs.")
+	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File with GCS credentials used to read the artifacts of the rehearsals. If unset, artifacts are read anonymously.")
+
+	fs.BoolVar(&o.noTemplates, "no-templates", false, "If true, do not attempt to compare templates")
+	fs.BoolVar(&o.noRegistry, "no-registry", false, "If true, do not attempt to compare step registry content")
+	fs.BoolVar(&o.noClusterProfiles, "no-cluster-profiles", false, "If true, do not attempt to compare cluster profiles")
+
+	fs.IntVar(&o.rehearsalLimit, "rehearsal-limit", 35, "Upper limit of jobs rehearsed for a plain /pj-rehearse command")
+	fs.IntVar(&o.moreLimit, "more-limit", 100, "Upper limit of jobs rehearsed for a /pj-rehearse more command")
+	fs.StringVar(&o.rehearsalContext, "rehearsal-context", "ci/prow/pj-rehearse", "Status context of the pj-rehearse presubmit, marked as successful by /pj-rehearse skip")
+
+	o.github.AddFlags(fs)
+	o.git.AddFlags(fs)
+	o.githubEventServerOptions.Bind(fs)
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatalf("cannot parse args: '%s'", os.Args[1:])
+	}
+	return o
+}
+
+func (o *options) validate() error {
+	if o.rehearsalLimit <= 0 {
+		return errors.New("--rehearsal-limit must be positive")
+	}
+	if o.moreLimit < o.rehearsalLimit {
+		return errors.New("--more-limit must not be lower than --rehearsal-limit")
+	}
+	if o.rehearsalContext == "" {
+		return errors.New("--rehearsal-context is required")
+	}
+	if err := o.github.Validate(o.dryRun); err != nil {
+		return err
+	}
+	if err := o.git.Validate(o.dryRun); err != nil {
+		return err
+	}
+	return o.githubEventServerOptions.DefaultAndValidate()
+}
+
+func prowJobKubeconfig(path string, defaultKubeconfig *rest.Config) (*rest.Config, error) {
+	if path == "" {
+		return defaultKubeconfig, nil
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: path},
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	logger := logrus.WithField("plugin", "pj-rehearse")
+
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logger.WithError(err).Fatal("Invalid options")
+	}
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		logger.WithError(err).Fatal("Failed to register imagev1 scheme")
+	}
+
+	secretAgent := &secret.Agent{}
+	if err := secretAgent.Start([]string{o.github.TokenPath, o.webhookSecretFile}); err != nil {
+		logger.WithError(err).Fatal("Error starting secrets agent.")
+	}
+
+	githubClient, err := o.github.GitHubClient(secretAgent, o.dryRun)
+	if err != nil {
+		logger.WithError(err).Fatal("Error getting GitHub client.")
+	}
+	gitClient, err := o.git.GitClient(githubClient, secretAgent.GetTokenGenerator(o.github.TokenPath), secretAgent.Censor, o.dryRun)
+	if err != nil {
+		logger.WithError(err).Fatal("Error getting Git client.")
+	}
+
+	buildClusterConfigs := map[string]*rest.Config{}
+	var prowJobConfig *rest.Config
+	if !o.dryRun {
+		if buildClusterConfigs, _, err = util.LoadKubeConfigs("", nil); err != nil {
+			logger.WithError(err).Fatal("Could not load kubeconfigs")
+		}
+		if prowJobConfig, err = prowJobKubeconfig(o.prowjobKubeconfig, buildClusterConfigs["app.ci"]); err != nil {
+			logger.WithError(err).Fatal("Could not load prowjob kubeconfig")
+		}
+	}
+	pjclient, err := rehearse.NewProwJobClient(prowJobConfig, o.dryRun)
+	if err != nil {
+		logger.WithError(err).Fatal("Could not create a ProwJob client")
+	}
+
+	gcsOption := option.WithoutAuthentication()
+	if o.gcsCredentialsFile != "" {
+		gcsOption = option.WithCredentialsFile(o.gcsCredentialsFile)
+	}
+	gcsClient, err := storage.NewClient(interrupts.Context(), gcsOption)
+	if err != nil {
+		logger.WithError(err).Fatal("Could not create a GCS client")
+	}
+
+	serv := &server{
+		ghc:                 githubClient,
+		gc:                  gitClient,
+		pjclient:            pjclient,
+		buildClusterConfigs: buildClusterConfigs,
+		junit:               rehearse.NewGCSJUnitGetter(gcsClient),
+		candidateOptions: rehearse.CandidateOptions{
+			NoTemplates:       o.noTemplates,
+			NoRegistry:        o.noRegistry,
+			NoClusterProfiles: o.noClusterProfiles,
+		},
+		rehearsalLimit:   o.rehearsalLimit,
+		moreLimit:        o.moreLimit,
+		rehearsalContext: o.rehearsalContext,
+		dryRun:           o.dryRun,
+	}
+	serv.rehearse = serv.rehearsePullRequest
+
+	eventServer := githubeventserver.New(o.githubEventServerOptions, secretAgent.GetTokenGenerator(o.webhookSecretFile), logger)
+	eventServer.RegisterHandleIssueCommentEvent(serv.handleIssueComment)
+	eventServer.RegisterHelpProvider(helpProvider, logger)
+
+	interrupts.OnInterrupt(func() {
+		eventServer.GracefulShutdown()
+		if err := gitClient.Clean(); err != nil {
+			logger.WithError(err).Error("Could not clean up git client cache.")
+		}
+	})
+
+	health := pjutil.NewHealth()
+	health.ServeReady()
+
+	interrupts.ListenAndServe(eventServer, time.Second*30)
+	interrupts.WaitForGracefulShutdown()
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/client-go/rest"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/git/v2"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/pluginhelp"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/rehearse"
+)
+
+type githubClient interface {
+	IsMember(org, user string) (bool, error)
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	CreateComment(owner, repo string, number int, comment string) error
+	CreateStatus(org, repo, ref string, s github.Status) error
+	BotUserChecker() (func(candidate string) bool, error)
+	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	EditComment(org, repo string, id int, comment string) error
+}
+
+func helpProvider(_ []prowconfig.OrgRepo) (*pluginhelp.PluginHelp, error) {
+	pluginHelp := &pluginhelp.PluginHelp{
+		Description: `The pj-rehearse plugin rehearses jobs affected by a change to the CI configuration on request.`,
+	}
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/pj-rehearse [job-name...|more|skip]",
+		Description: "Rehearse the affected jobs. Without arguments, the same jobs as the pj-rehearse presubmit are rehearsed. With job names, only these jobs are rehearsed. 'more' rehearses jobs beyond the regular rehearsal limit. 'skip' marks the rehearsals as not needed.",
+		WhoCanUse:   "Members of the organization the repository belongs to.",
+		Examples:    []string{"/pj-rehearse", "/pj-rehearse pull-ci-openshift-ci-tools-master-unit", "/pj-rehearse more", "/pj-rehearse skip"},
+	})
+	return pluginHelp, nil
+}
+
+// rehearsalRequest is a rehearsal command for a specific pull request
+type rehearsalRequest struct {
+	pr      *github.PullRequest
+	command *rehearse.Command
+	user    string
+}
+
+type server struct {
+	ghc githubClient
+	gc  git.ClientFactory
+
+	pjclient            ctrlruntimeclient.Client
+	buildClusterConfigs map[string]*rest.Config
+	junit               rehearse.JUnitGetter
+
+	candidateOptions rehearse.CandidateOptions
+	rehearsalLimit   int
+	moreLimit        int
+	rehearsalContext string
+
+	dryRun bool
+
+	// rehearse runs the requested rehearsals, allows faking in tests
+	rehearse func(request rehearsalRequest, logger *logrus.Entry) error
+}
+
+func (s *server) handleIssueComment(l *logrus.Entry, ic github.IssueCommentEvent) {
+	if ic.Action != github.IssueCommentActionCreated || !ic.Issue.IsPullRequest() {
+		return
+	}
+	command, ok := rehearse.ParseCommand(ic.Comment.Body)
+	if !ok {
+		return
+	}
+
+	org, repo, number := ic.Repo.Owner.Login, ic.Repo.Name, ic.Issue.Number
+	logger := l.WithFields(logrus.Fields{
+		github.OrgLogField:  org,
+		github.RepoLogField: repo,
+		github.PrLogField:   number,
+	})
+
+	// Rehearsals run arbitrary code from the pull request, so only trusted
+	// users may trigger them
+	member, err := s.ghc.IsMember(org, ic.Comment.User.Login)
+	if err != nil {
+		logger.WithError(err).Warn("could not check organization membership")
+		s.createComment(ic, fmt.Sprintf("could not check organization membership: %v", err), logger)
+		return
+	}
+	if !member {
+		s.createComment(ic, fmt.Sprintf("only [%s](https://github.com/orgs/%s/people) org members may request rehearsals", org, org), logger)
+		return
+	}
+
+	pr, err := s.ghc.GetPullRequest(org, repo, number)
+	if err != nil {
+		logger.WithError(err).Warn("could not get pull request")
+		s.createComment(ic, fmt.Sprintf("could not get pull request: %v", err), logger)
+		return
+	}
+
+	if command.Skip {
+		status := github.Status{
+			State:       github.StatusSuccess,
+			Context:     s.rehearsalContext,
+			Description: fmt.Sprintf("Rehearsals skipped by %s", ic.Comment.User.Login),
+		}
+		if err := s.ghc.CreateStatus(org, repo, pr.Head.SHA, status); err != nil {
+			logger.WithError(err).Warn("could not set the rehearsal status")
+			s.createComment(ic, fmt.Sprintf("could not skip the rehearsals: %v", err), logger)
+			return
+		}
+		s.createComment(ic, fmt.Sprintf("rehearsals were skipped and the `%s` context was marked as successful.", s.rehearsalContext), logger)
+		return
+	}
+
+	if err := s.rehearse(rehearsalRequest{pr: pr, command: command, user: ic.Comment.User.Login}, logger); err != nil {
+		logger.WithError(err).Warn("could not rehearse jobs")
+		s.createComment(ic, fmt.Sprintf("could not rehearse jobs: %v", err), logger)
+	}
+}
+
+// selectRehearsals decides which of the candidate rehearsals to run for the command
+func (s *server) selectRehearsals(command *rehearse.Command, candidates []*prowconfig.Presubmit, ciopConfigs config.DataByFilename) ([]rehearse.SelectedRehearsal, error) {
+	if len(command.Jobs) > 0 {
+		selected, unknown := rehearse.SelectRequestedRehearsals(candidates, command.Jobs)
+		if len(unknown) > 0 {
+			return nil, fmt.Errorf("the following jobs are not affected by this pull request and cannot be rehearsed: %s", strings.Join(unknown, ", "))
+		}
+		return selected, nil
+	}
+	limit := s.rehearsalLimit
+	if command.More {
+		limit = s.moreLimit
+	}
+	return rehearse.SelectRehearsalsByCoverage(candidates, ciopConfigs, nil, limit), nil
+}
+
+// rehearsePullRequest checks out the pull request, determines the affected
+// jobs the same way the pj-rehearse presubmit does and runs the selected
+// subset of their rehearsals, summarizing the results in a comment
+func (s *server) rehearsePullRequest(request rehearsalRequest, logger *logrus.Entry) error {
+	pr := request.pr
+	org, repo := pr.Base.Repo.Owner.Login, pr.Base.Repo.Name
+	repoClient, err := s.gc.ClientFor(org, repo)
+	if err != nil {
+		return fmt.Errorf("could not clone %s/%s: %w", org, repo, err)
+	}
+	defer func() {
+		if err := repoClient.Clean(); err != nil {
+			logger.WithError(err).Error("could not clean up the repository clone")
+		}
+	}()
+	if err := repoClient.CheckoutPullRequest(pr.Number); err != nil {
+		return fmt.Errorf("could not check out the pull request: %w", err)
+	}
+
+	refs := &pjapi.Refs{
+		Org:     org,
+		Repo:    repo,
+		BaseRef: pr.Base.Ref,
+		BaseSHA: pr.Base.SHA,
+		Pulls:   []pjapi.Pull{{Number: pr.Number, Author: pr.User.Login, SHA: pr.Head.SHA}},
+	}
+	loggers := rehearse.Loggers{Job: logger, Debug: logger}
+	// The head SHA identifies the temporary ConfigMaps of this rehearsal run
+	buildID := pr.Head.SHA
+	if len(buildID) > 8 {
+		buildID = buildID[:8]
+	}
+	candidates, err := rehearse.DetermineCandidates(repoClient.Directory(), refs, buildID, s.candidateOptions, logger, loggers)
+	if err != nil {
+		return fmt.Errorf("could not determine the jobs to rehearse: %w", err)
+	}
+	if len(candidates.Presubmits) == 0 {
+		return errors.New("no jobs affected by this pull request were found")
+	}
+	selected, err := s.selectRehearsals(request.command, candidates.Presubmits, candidates.Config.CiOperator)
+	if err != nil {
+		return err
+	}
+	var presubmits []*prowconfig.Presubmit
+	rationales := map[string]string{}
+	for _, selection := range selected {
+		presubmits = append(presubmits, selection.Job)
+		rationales[selection.Job.Name] = selection.Rationale
+	}
+	if err := candidates.PrepareRehearsals(presubmits, org, repo); err != nil {
+		return fmt.Errorf("rehearsal jobs are invalid: %w", err)
+	}
+
+	namespace := candidates.Config.Prow.ProwJobNamespace
+	cleanup, err := rehearse.SetupDependencies(presubmits, pr.Number, s.buildClusterConfigs, logger, namespace, s.pjclient, candidates.Config.Prow.PodNamespace,
+		candidates.ConfigUpdater, repoClient.Directory(), s.dryRun, candidates.Templates, candidates.ClusterProfiles, candidates.ImageStreamTags)
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if err != nil {
+		return fmt.Errorf("could not set up the dependencies of the rehearsals: %w", err)
+	}
+
+	if err := s.ghc.CreateComment(org, repo, pr.Number, fmt.Sprintf("@%s: rehearsing %d of %d affected jobs, the results will be summarized in a comment once they finish.", request.user, len(presubmits), len(candidates.Presubmits))); err != nil {
+		logger.WithError(err).Warn("could not create comment")
+	}
+	executor := rehearse.NewExecutor(presubmits, pr.Number, repoClient.Directory(), refs, s.dryRun, loggers, s.pjclient, namespace)
+	if _, err := executor.ExecuteJobs(); err != nil {
+		logger.WithError(err).Warn("failed to rehearse jobs")
+	}
+	reporter := rehearse.NewReporter(s.ghc, s.pjclient, s.junit, namespace, org, repo, pr.Number, rationales, logger)
+	return reporter.Report(executor.ProwJobs())
+}
+
+func (s *server) createComment(ic github.IssueCommentEvent, message string, logger *logrus.Entry) {
+	if err := s.ghc.CreateComment(ic.Repo.Owner.Login, ic.Repo.Name, ic.Issue.Number, fmt.Sprintf("@%s: %s", ic.Comment.User.Login, message)); err != nil {
+		logger.WithError(err).Warn("could not create comment")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/github/fakegithub"
+
+	"github.com/openshift/ci-tools/pkg/rehearse"
+)
+
+func TestHandleIssueComment(t *testing.T) {
+	testCases := []struct {
+		name              string
+		body              string
+		user              string
+		notPullRequest    bool
+		rehearseErr       error
+		expectedComments  []string
+		expectedStatuses  []github.Status
+		expectedRehearsal *rehearse.Command
+	}{
+		{
+			name: "unrelated comment is ignored",
+			body: "/lgtm",
+			user: "member",
+		},
+		{
+			name:           "comment on an issue is ignored",
+			body:           "/pj-rehearse",
+			user:           "member",
+			notPullRequest: true,
+		},
+		{
+			name:             "non-members cannot trigger rehearsals",
+			body:             "/pj-rehearse",
+			user:             "outsider",
+			expectedComments: []string{"openshift/release#123:@outsider: only [openshift](https://github.com/orgs/openshift/people) org members may request rehearsals"},
+		},
+		{
+			name:              "specific jobs are rehearsed",
+			body:              "/pj-rehearse pull-ci-org-repo-master-e2e",
+			user:              "member",
+			expectedRehearsal: &rehearse.Command{Jobs: []string{"pull-ci-org-repo-master-e2e"}},
+		},
+		{
+			name:              "more jobs are rehearsed",
+			body:              "/pj-rehearse more",
+			user:              "member",
+			expectedRehearsal: &rehearse.Command{More: true},
+		},
+		{
+			name:              "rehearsal failure is reported",
+			body:              "/pj-rehearse",
+			user:              "member",
+			rehearseErr:       errors.New("no jobs affected by this pull request were found"),
+			expectedRehearsal: &rehearse.Command{},
+			expectedComments:  []string{"openshift/release#123:@member: could not rehearse jobs: no jobs affected by this pull request were found"},
+		},
+		{
+			name:             "skip marks the context as successful",
+			body:             "/pj-rehearse skip",
+			user:             "member",
+			expectedComments: []string{"openshift/release#123:@member: rehearsals were skipped and the `ci/prow/pj-rehearse` context was marked as successful."},
+			expectedStatuses: []github.Status{{State: github.StatusSuccess, Context: "ci/prow/pj-rehearse", Description: "Rehearsals skipped by member"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ghc := fakegithub.NewFakeClient()
+			ghc.OrgMembers = map[string][]string{"openshift": {"member"}}
+			ghc.PullRequests = map[int]*github.PullRequest{123: {Number: 123, Head: github.PullRequestBranch{SHA: "head"}}}
+			ghc.CreatedStatuses = map[string][]github.Status{}
+
+			var rehearsed *rehearse.Command
+			s := &server{
+				ghc:              ghc,
+				rehearsalContext: "ci/prow/pj-rehearse",
+				rehearse: func(request rehearsalRequest, _ *logrus.Entry) error {
+					rehearsed = request.command
+					return tc.rehearseErr
+				},
+			}
+			event := github.IssueCommentEvent{
+				Action:  github.IssueCommentActionCreated,
+				Repo:    github.Repo{Owner: github.User{Login: "openshift"}, Name: "release"},
+				Issue:   github.Issue{Number: 123},
+				Comment: github.IssueComment{Body: tc.body, User: github.User{Login: tc.user}},
+			}
+			if !tc.notPullRequest {
+				event.Issue.PullRequest = &struct{}{}
+			}
+			s.handleIssueComment(logrus.NewEntry(logrus.StandardLogger()), event)
+
+			if diff := cmp.Diff(tc.expectedRehearsal, rehearsed); diff != "" {
+				t.Errorf("unexpected rehearsal request: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedComments, ghc.IssueCommentsAdded); diff != "" {
+				t.Errorf("unexpected comments: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedStatuses, ghc.CreatedStatuses["head"]); diff != "" {
+				t.Errorf("unexpected statuses: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectRehearsals(t *testing.T) {
+	var candidates []*prowconfig.Presubmit
+	for _, name := range []string{"pull-ci-org-repo-master-e2e", "pull-ci-org-repo-master-unit", "pull-ci-org-repo-master-lint"} {
+		candidates = append(candidates, &prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: "rehearse-123-" + name, Labels: map[string]string{rehearse.Label: "123"}}})
+	}
+	s := &server{rehearsalLimit: 1, moreLimit: 2}
+	testCases := []struct {
+		name        string
+		command     *rehearse.Command
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "regular limit",
+			command:  &rehearse.Command{},
+			expected: []string{"rehearse-123-pull-ci-org-repo-master-e2e"},
+		},
+		{
+			name:     "more",
+			command:  &rehearse.Command{More: true},
+			expected: []string{"rehearse-123-pull-ci-org-repo-master-e2e", "rehearse-123-pull-ci-org-repo-master-lint"},
+		},
+		{
+			name:     "requested jobs are not limited",
+			command:  &rehearse.Command{Jobs: []string{"pull-ci-org-repo-master-unit", "pull-ci-org-repo-master-lint"}},
+			expected: []string{"rehearse-123-pull-ci-org-repo-master-lint", "rehearse-123-pull-ci-org-repo-master-unit"},
+		},
+		{
+			name:        "unaffected job is requested",
+			command:     &rehearse.Command{Jobs: []string{"pull-ci-org-repo-master-images"}},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := s.selectRehearsals(tc.command, candidates, nil)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			var names []string
+			for _, selection := range selected {
+				names = append(names, selection.Job.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected selection: %s", diff)
+			}
+		})
+	}
+}
@@ -6,38 +6,28 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sort"
-	"strings"
-	"sync"
-	"time"
 
+	"cloud.google.com/go/storage"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/option"
 
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	prowconfig "k8s.io/test-infra/prow/config"
+	"k8s.io/test-infra/prow/config/secret"
+	prowflagutil "k8s.io/test-infra/prow/flagutil"
 	prowgithub "k8s.io/test-infra/prow/github"
-	prowplugins "k8s.io/test-infra/prow/plugins"
 	pjdwapi "k8s.io/test-infra/prow/pod-utils/downwardapi"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	imagev1 "github.com/openshift/api/image/v1"
 
-	apihelper "github.com/openshift/ci-tools/pkg/api/helper"
-	testimagestreamtagimportv1 "github.com/openshift/ci-tools/pkg/api/testimagestreamtagimport/v1"
 	"github.com/openshift/ci-tools/pkg/config"
 	"github.com/openshift/ci-tools/pkg/diffs"
-	"github.com/openshift/ci-tools/pkg/load"
-	"github.com/openshift/ci-tools/pkg/registry"
 	"github.com/openshift/ci-tools/pkg/rehearse"
 	"github.com/openshift/ci-tools/pkg/util"
 )
@@ -53,8 +43,20 @@ type options struct {
 
 	releaseRepoPath string
 	rehearsalLimit  int
+
+	rehearsalSelection string
+	jobFlakinessPath   string
+
+	commentResults     bool
+	github             prowflagutil.GitHubOptions
+	gcsCredentialsFile string
 }
 
+const (
+	selectionCoverage   = "coverage"
+	selectionSourceType = "source-type"
+)
+
 func gatherOptions() (options, error) {
 	o := options{}
 	fs := flag.CommandLine
@@ -70,6 +72,12 @@ func gatherOptions() (options, error) {
 	fs.BoolVar(&o.noClusterProfiles, "no-cluster-profiles", false, "If true, do not attempt to compare cluster profiles")
 
 	fs.IntVar(&o.rehearsalLimit, "rehearsal-limit", 35, "Upper limit of jobs attempted to rehearse (if more jobs are being touched, only this many will be rehearsed)")
+	fs.StringVar(&o.rehearsalSelection, "rehearsal-selection", selectionCoverage, fmt.Sprintf("Strategy to select jobs when more than --rehearsal-limit are touched: %q picks the most diverse jobs, %q picks evenly from each reason the jobs were touched", selectionCoverage, selectionSourceType))
+	fs.StringVar(&o.jobFlakinessPath, "job-flakiness-file", "", "Optional YAML file mapping job names to their historical failure rate (0-1), used to prefer reliable jobs with --rehearsal-selection=coverage")
+
+	fs.BoolVar(&o.commentResults, "comment-results", false, "Whether to summarize the rehearsal results in a comment on the tested pull request")
+	fs.StringVar(&o.gcsCredentialsFile, "gcs-credentials-file", "", "File with GCS credentials used to read the artifacts of the rehearsals. If unset, artifacts are read anonymously.")
+	o.github.AddFlags(fs)
 
 	if err := fs.Parse(os.Args[1:]); err != nil {
 		return o, fmt.Errorf("failed to parse flags: %w", err)
@@ -81,6 +89,14 @@ func validateOptions(o options) error {
 	if len(o.releaseRepoPath) == 0 {
 		return fmt.Errorf("--candidate-path was not provided")
 	}
+	if o.rehearsalSelection != selectionCoverage && o.rehearsalSelection != selectionSourceType {
+		return fmt.Errorf("--rehearsal-selection must be one of %q or %q", selectionCoverage, selectionSourceType)
+	}
+	if o.commentResults {
+		if err := o.github.Validate(o.dryRun); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -108,14 +124,6 @@ pj-rehearse created invalid rehearsal jobs.This is either a pj-rehearse bug, or
 the rehearsed jobs themselves are invalid.`
 )
 
-func loadConfigUpdaterCfg(releaseRepoPath string) (ret prowplugins.ConfigUpdater, err error) {
-	agent := prowplugins.ConfigAgent{}
-	if err = agent.Load(filepath.Join(releaseRepoPath, config.PluginConfigInRepoPath), []string{filepath.Join(releaseRepoPath, filepath.Dir(config.PluginConfigInRepoPath))}, "_pluginconfig.yaml", true); err == nil {
-		ret = agent.Config().ConfigUpdater
-	}
-	return
-}
-
 func rehearseMain() error {
 	o, err := gatherOptions()
 	if err != nil {
@@ -128,6 +136,13 @@ func rehearseMain() error {
 		logrus.WithError(err).Fatal("failed to register imagev1 scheme")
 	}
 
+	secretAgent := &secret.Agent{}
+	if o.commentResults && o.github.TokenPath != "" {
+		if err := secretAgent.Start([]string{o.github.TokenPath}); err != nil {
+			logrus.WithError(err).Fatal("failed to start secrets agent")
+		}
+	}
+
 	var jobSpec *pjdwapi.JobSpec
 	if jobSpec, err = pjdwapi.ResolveSpecFromEnv(); err != nil {
 		logrus.WithError(err).Error("could not read JOB_SPEC")
@@ -165,104 +180,6 @@ func rehearseMain() error {
 		}
 	}
 
-	prConfig := config.GetAllConfigs(o.releaseRepoPath, logger)
-	configUpdaterCfg, err := loadConfigUpdaterCfg(o.releaseRepoPath)
-	if err != nil {
-		logger.WithError(err).Error("could not load plugin configuration from tested revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-	masterConfig, err := config.GetAllConfigsFromSHA(o.releaseRepoPath, jobSpec.Refs.BaseSHA, logger)
-	if err != nil {
-		logger.WithError(err).Error("could not load configuration from base revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-
-	// We always need both Prow config versions, otherwise we cannot compare them
-	if masterConfig.Prow == nil || prConfig.Prow == nil {
-		logger.WithError(err).Error("could not load Prow configs from base or tested revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-	// We always need PR versions of ciop config, otherwise we cannot provide them to rehearsed jobs
-	if prConfig.CiOperator == nil {
-		logger.WithError(err).Error("could not load ci-operator configs from tested revision of release repo")
-		return fmt.Errorf(misconfigurationOutput)
-	}
-
-	// We can only detect changes if we managed to load both ci-operator config versions
-	changedCiopConfigData := config.DataByFilename{}
-	affectedJobs := make(map[string]sets.String)
-	if masterConfig.CiOperator != nil && prConfig.CiOperator != nil {
-		data, jobs := diffs.GetChangedCiopConfigs(masterConfig.CiOperator, prConfig.CiOperator, logger)
-		changedCiopConfigData = data
-		affectedJobs = jobs
-	}
-
-	var changedRegistrySteps []registry.Node
-	var refs registry.ReferenceByName
-	var chains registry.ChainByName
-	var workflows registry.WorkflowByName
-	var observers registry.ObserverByName
-
-	if !o.noRegistry {
-		refs, chains, workflows, _, _, observers, err = load.Registry(filepath.Join(o.releaseRepoPath, config.RegistryPath), false)
-		if err != nil {
-			logger.WithError(err).Error("could not load step registry")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-		graph, err := registry.NewGraph(refs, chains, workflows)
-		if err != nil {
-			logger.WithError(err).Error("could not create step registry graph")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-		changedRegistrySteps, err = config.GetChangedRegistrySteps(o.releaseRepoPath, jobSpec.Refs.BaseSHA, graph)
-		if err != nil {
-			logger.WithError(err).Error("could not get step registry differences")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-	}
-	if len(changedRegistrySteps) != 0 {
-		var names []string
-		for _, step := range changedRegistrySteps {
-			names = append(names, step.Name())
-		}
-		logger.Infof("Found %d changed registry steps: %s", len(changedRegistrySteps), strings.Join(names, ", "))
-	}
-
-	var rehearsalTemplates rehearse.ConfigMaps
-	if !o.noTemplates {
-		changedTemplates, err := config.GetChangedTemplates(o.releaseRepoPath, jobSpec.Refs.BaseSHA)
-		if err != nil {
-			logger.WithError(err).Error("could not get template differences")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-		rehearsalTemplates, err = rehearse.NewConfigMaps(changedTemplates, "template", jobSpec.BuildID, prNumber, configUpdaterCfg)
-		if err != nil {
-			logger.WithError(err).Error("could not match changed templates with cluster configmaps")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-
-	}
-	if len(rehearsalTemplates.Paths) != 0 {
-		logger.WithField("templates", rehearsalTemplates.Paths).Info("templates changed")
-	}
-
-	var rehearsalClusterProfiles rehearse.ConfigMaps
-	if !o.noClusterProfiles {
-		changedClusterProfiles, err := config.GetChangedClusterProfiles(o.releaseRepoPath, jobSpec.Refs.BaseSHA)
-		if err != nil {
-			logger.WithError(err).Error("could not get cluster profile differences")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-		rehearsalClusterProfiles, err = rehearse.NewConfigMaps(changedClusterProfiles, "cluster-profile", jobSpec.BuildID, prNumber, configUpdaterCfg)
-		if err != nil {
-			logger.WithError(err).Error("could not match changed cluster profiles with cluster configmaps")
-			return fmt.Errorf(misconfigurationOutput)
-		}
-	}
-	if len(rehearsalClusterProfiles.Paths) != 0 {
-		logger.WithField("profiles", rehearsalClusterProfiles.Paths).Info("cluster profiles changed")
-	}
-
 	pjclient, err := rehearse.NewProwJobClient(prowJobConfig, o.dryRun)
 	if err != nil {
 		logger.WithError(err).Error("could not create a ProwJob client")
@@ -281,47 +198,17 @@ func rehearseMain() error {
 		}
 	}
 	loggers := rehearse.Loggers{Job: logger, Debug: debugLogger.WithField(prowgithub.PrLogField, prNumber)}
-	toRehearse := config.Presubmits{}
-	periodicsToRehearse := config.Periodics{}
-
-	changedPeriodics := diffs.GetChangedPeriodics(masterConfig.Prow, prConfig.Prow, logger)
-	periodicsToRehearse.AddAll(changedPeriodics, config.ChangedPeriodic)
-	changedPresubmits := diffs.GetChangedPresubmits(masterConfig.Prow, prConfig.Prow, logger)
-	toRehearse.AddAll(changedPresubmits, config.ChangedPresubmit)
-
-	presubmitsForCiopConfigs, periodicsForCiopConfigs := diffs.GetJobsForCiopConfigs(prConfig.Prow, changedCiopConfigData, affectedJobs, logger)
-	toRehearse.AddAll(presubmitsForCiopConfigs, config.ChangedCiopConfig)
-	periodicsToRehearse.AddAll(periodicsForCiopConfigs, config.ChangedCiopConfig)
-
-	presubmitsForClusterProfiles := diffs.GetPresubmitsForClusterProfiles(prConfig.Prow, rehearsalClusterProfiles.ProductionNames, logger)
-	toRehearse.AddAll(presubmitsForClusterProfiles, config.ChangedClusterProfile)
-
-	randomJobsForChangedTemplates := rehearse.AddRandomJobsForChangedTemplates(rehearsalTemplates.ProductionNames, toRehearse, prConfig.Prow.JobConfig.PresubmitsStatic, loggers)
-	toRehearse.AddAll(randomJobsForChangedTemplates, config.ChangedTemplate)
-
-	presubmitsForRegistry, periodicsForRegistry := rehearse.SelectJobsForChangedRegistry(changedRegistrySteps, prConfig.Prow.JobConfig.PresubmitsStatic, prConfig.Prow.JobConfig.Periodics, prConfig.CiOperator, loggers)
-	toRehearse.AddAll(presubmitsForRegistry, config.ChangedRegistryContent)
-	periodicsToRehearse.AddAll(periodicsForRegistry, config.ChangedRegistryContent)
-
-	resolver := registry.NewResolver(refs, chains, workflows, observers)
-	jobConfigurer := rehearse.NewJobConfigurer(prConfig.CiOperator, resolver, prNumber, loggers, rehearsalTemplates.Names, rehearsalClusterProfiles.Names, jobSpec.Refs)
-	imagestreamtags, presubmitsToRehearse, err := jobConfigurer.ConfigurePresubmitRehearsals(toRehearse)
-	if err != nil {
-		return err
-	}
 
-	periodicImageStreamTags, periodics, err := jobConfigurer.ConfigurePeriodicRehearsals(periodicsToRehearse)
+	candidateOptions := rehearse.CandidateOptions{NoTemplates: o.noTemplates, NoRegistry: o.noRegistry, NoClusterProfiles: o.noClusterProfiles}
+	candidates, err := rehearse.DetermineCandidates(o.releaseRepoPath, jobSpec.Refs, jobSpec.BuildID, candidateOptions, logger, loggers)
 	if err != nil {
-		return err
-	}
-	apihelper.MergeImageStreamTagMaps(imagestreamtags, periodicImageStreamTags)
-
-	periodicPresubmits, err := jobConfigurer.ConvertPeriodicsToPresubmits(periodics)
-	if err != nil {
-		return err
+		logger.WithError(err).Error("could not determine the jobs to rehearse")
+		return fmt.Errorf(misconfigurationOutput)
 	}
-	presubmitsToRehearse = append(presubmitsToRehearse, periodicPresubmits...)
+	prConfig := candidates.Config
+	presubmitsToRehearse := candidates.Presubmits
 
+	rationales := map[string]string{}
 	if rehearsals := len(presubmitsToRehearse); rehearsals == 0 {
 		logger.Info("no jobs to rehearse have been found")
 		return nil
@@ -331,33 +218,34 @@ func rehearseMain() error {
 			"rehearsal-jobs":      rehearsals,
 		}
 		logger.WithFields(jobCountFields).Info("Would rehearse too many jobs, selecting a subset")
-		presubmitsToRehearse = determineSubsetToRehearse(presubmitsToRehearse, o.rehearsalLimit)
-	}
-
-	if prConfig.Prow.JobConfig.PresubmitsStatic == nil {
-		prConfig.Prow.JobConfig.PresubmitsStatic = map[string][]prowconfig.Presubmit{}
-	}
-	for _, presubmit := range presubmitsToRehearse {
-
-		// We can only have a given repo once, so remove whats in Refs from ExtraRefs
-		var cleanExtraRefs []pjapi.Refs
-		for _, extraRef := range presubmit.ExtraRefs {
-			if extraRef.Org == org && extraRef.Repo == repo {
-				continue
+		switch o.rehearsalSelection {
+		case selectionSourceType:
+			presubmitsToRehearse = determineSubsetToRehearse(presubmitsToRehearse, o.rehearsalLimit)
+		case selectionCoverage:
+			flakiness := rehearse.Flakiness{}
+			if o.jobFlakinessPath != "" {
+				if flakiness, err = rehearse.LoadFlakiness(o.jobFlakinessPath); err != nil {
+					logger.WithError(err).Error("could not load job flakiness")
+					return fmt.Errorf(misconfigurationOutput)
+				}
+			}
+			selected := rehearse.SelectRehearsalsByCoverage(presubmitsToRehearse, prConfig.CiOperator, flakiness, o.rehearsalLimit)
+			presubmitsToRehearse = nil
+			for _, selection := range selected {
+				logger.WithFields(logrus.Fields{diffs.LogJobName: selection.Job.Name, diffs.LogReasons: selection.Rationale}).Info("Selected job for rehearsal")
+				presubmitsToRehearse = append(presubmitsToRehearse, selection.Job)
+				rationales[selection.Job.Name] = selection.Rationale
 			}
-			cleanExtraRefs = append(cleanExtraRefs, extraRef)
 		}
-		presubmit.ExtraRefs = cleanExtraRefs
-
-		prConfig.Prow.JobConfig.PresubmitsStatic[org+"/"+repo] = append(prConfig.Prow.JobConfig.PresubmitsStatic[org+"/"+repo], *presubmit)
 	}
-	if err := prConfig.Prow.ValidateJobConfig(); err != nil {
+
+	if err := candidates.PrepareRehearsals(presubmitsToRehearse, org, repo); err != nil {
 		logger.WithError(err).Error("jobconfig validation failed")
 		return fmt.Errorf(jobValidationOutput)
 	}
 
 	var errs []error
-	cleanup, err := setupDependencies(
+	cleanup, err := rehearse.SetupDependencies(
 		presubmitsToRehearse,
 		prNumber,
 		buildClusterConfigs,
@@ -365,15 +253,15 @@ func rehearseMain() error {
 		prConfig.Prow.ProwJobNamespace,
 		pjclient,
 		prConfig.Prow.PodNamespace,
-		configUpdaterCfg,
+		candidates.ConfigUpdater,
 		o.releaseRepoPath,
 		o.dryRun,
-		rehearsalTemplates,
-		rehearsalClusterProfiles,
-		imagestreamtags)
+		candidates.Templates,
+		candidates.ClusterProfiles,
+		candidates.ImageStreamTags)
 	if err != nil {
 		logger.WithError(err).Error("Failed to set up dependencies. This might cause subsequent failures.")
-		errs = append(errs, err)
+		errs = append(errs, errors.New(failedSetupOutput))
 	}
 	if cleanup != nil {
 		defer cleanup()
@@ -381,6 +269,11 @@ func rehearseMain() error {
 
 	executor := rehearse.NewExecutor(presubmitsToRehearse, prNumber, o.releaseRepoPath, jobSpec.Refs, o.dryRun, loggers, pjclient, prConfig.Prow.ProwJobNamespace)
 	success, err := executor.ExecuteJobs()
+	if o.commentResults && !o.dryRun {
+		if reportErr := reportResults(o, secretAgent, executor.ProwJobs(), pjclient, prConfig.Prow.ProwJobNamespace, jobSpec.Refs, rationales, logger); reportErr != nil {
+			logger.WithError(reportErr).Error("Failed to comment the rehearsal results on the pull request")
+		}
+	}
 	if err != nil {
 		logger.WithError(err).Error("Failed to rehearse jobs")
 		return fmt.Errorf(rehearseFailureOutput)
@@ -401,6 +294,24 @@ func main() {
 	}
 }
 
+func reportResults(o options, secretAgent *secret.Agent, pjs []pjapi.ProwJob, pjclient ctrlruntimeclient.Client, namespace string, refs *pjapi.Refs, rationales map[string]string, logger *logrus.Entry) error {
+	githubClient, err := o.github.GitHubClient(secretAgent, o.dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+	gcsOption := option.WithoutAuthentication()
+	if o.gcsCredentialsFile != "" {
+		gcsOption = option.WithCredentialsFile(o.gcsCredentialsFile)
+	}
+	gcsClient, err := storage.NewClient(context.Background(), gcsOption)
+	if err != nil {
+		return fmt.Errorf("failed to create a GCS client: %w", err)
+	}
+	defer gcsClient.Close()
+	reporter := rehearse.NewReporter(githubClient, pjclient, rehearse.NewGCSJUnitGetter(gcsClient), namespace, refs.Org, refs.Repo, refs.Pulls[0].Number, rationales, logger)
+	return reporter.Report(pjs)
+}
+
 func pjKubeconfig(path string, defaultKubeconfig *rest.Config) (*rest.Config, error) {
 	if path == "" {
 		return defaultKubeconfig, nil
@@ -411,156 +322,6 @@ func pjKubeconfig(path string, defaultKubeconfig *rest.Config) (*rest.Config, er
 	).ClientConfig()
 }
 
-type cleanup func()
-type cleanups []cleanup
-
-func (c cleanups) cleanup() {
-	for _, cleanup := range c {
-		cleanup()
-	}
-}
-
-func setupDependencies(
-	jobs []*prowconfig.Presubmit,
-	prNumber int,
-	configs map[string]*rest.Config,
-	log *logrus.Entry,
-	prowJobNamespace string,
-	prowJobClient ctrlruntimeclient.Client,
-	podNamespace string,
-	configUpdaterCfg prowplugins.ConfigUpdater,
-	releaseRepoPath string,
-	dryRun bool,
-	changedTemplates rehearse.ConfigMaps,
-	changedClusterProfiles rehearse.ConfigMaps,
-	requiredImageStreamTags apihelper.ImageStreamTagMap,
-) (cleanup, error) {
-	buildClusters := sets.String{}
-	for _, job := range jobs {
-		if _, ok := configs[job.Cluster]; !ok && !dryRun {
-			return nil, fmt.Errorf("no config for buildcluster %s provided", job.Cluster)
-		}
-		buildClusters.Insert(job.Cluster)
-	}
-
-	var cleanups cleanups
-	cleanupsLock := &sync.Mutex{}
-
-	// Otherwise we flake in integration tests because we just capture stdout. Its not
-	// really possible to sort this as we use a client per cluster. Furthermore the output
-	// doesn't even contain the info which cluster was used.
-	// TODO: Remove the whole dry-run concept and write tests that just pass in a fakeclient.
-	if dryRun {
-		if len(buildClusters) > 1 {
-			buildClusters = sets.NewString("default")
-		}
-	}
-
-	g, ctx := errgroup.WithContext(context.Background())
-	for _, cluster := range buildClusters.UnsortedList() {
-		buildCluster := cluster
-		g.Go(func() error {
-			log := log.WithField("buildCluster", buildCluster)
-			cmClient, err := rehearse.NewCMClient(configs[buildCluster], podNamespace, dryRun)
-			if err != nil {
-				log.WithError(err).Error("could not create a configMap client")
-				return errors.New(misconfigurationOutput)
-			}
-
-			cmManager := rehearse.NewCMManager(buildCluster, prowJobNamespace, cmClient, configUpdaterCfg, prNumber, releaseRepoPath, log)
-
-			cleanupsLock.Lock()
-			cleanups = append(cleanups, func() {
-				if err := cmManager.Clean(); err != nil {
-					log.WithError(err).Error("failed to clean up temporary ConfigMaps")
-				}
-			})
-			cleanupsLock.Unlock()
-
-			if err := cmManager.Create(changedTemplates); err != nil {
-				log.WithError(err).Error("couldn't create temporary template ConfigMaps for rehearsals")
-				return errors.New(failedSetupOutput)
-			}
-			if err := cmManager.Create(changedClusterProfiles); err != nil {
-				log.WithError(err).Error("couldn't create temporary cluster profile ConfigMaps for rehearsals")
-				return errors.New(failedSetupOutput)
-			}
-
-			if dryRun {
-				return nil
-			}
-
-			client, err := ctrlruntimeclient.New(configs[buildCluster], ctrlruntimeclient.Options{})
-			if err != nil {
-				return fmt.Errorf("failed to construct client for cluster %s: %w", buildCluster, err)
-			}
-
-			if err := ensureImageStreamTags(ctx, client, requiredImageStreamTags, buildCluster, prowJobNamespace, prowJobClient, log); err != nil {
-				return fmt.Errorf("failed to ensure imagestreamtags in cluster %s: %w", buildCluster, err)
-			}
-
-			return nil
-		})
-	}
-
-	return cleanups.cleanup, g.Wait()
-}
-
-// Allow manipulating the speed of time for tests
-var second = time.Second
-
-func ensureImageStreamTags(ctx context.Context, client ctrlruntimeclient.Client, ists apihelper.ImageStreamTagMap, clusterName, namespace string, istImportClient ctrlruntimeclient.Client, log *logrus.Entry) error {
-	if clusterName == "app.ci" {
-		log.Info("Not creating imports on app.ci cluster as its authoritative source for all imagestreams")
-		return nil
-	}
-
-	g, ctx := errgroup.WithContext(ctx)
-
-	for _, ist := range ists {
-		requiredImageStreamTag := ist
-		g.Go(func() error {
-			istLog := log.WithFields(logrus.Fields{"ist-namespace": requiredImageStreamTag.Namespace, "ist-name": requiredImageStreamTag.Name})
-			err := client.Get(ctx, requiredImageStreamTag, &imagev1.ImageStreamTag{})
-			if err == nil {
-				istLog.Info("ImageStreamTag already exists in the build cluster")
-				return nil
-			}
-			if !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
-				return fmt.Errorf("failed to check if imagestreamtag %s exists: %w", requiredImageStreamTag, err)
-			}
-			istImport := &testimagestreamtagimportv1.TestImageStreamTagImport{
-				ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
-				Spec: testimagestreamtagimportv1.TestImageStreamTagImportSpec{
-					ClusterName: clusterName,
-					Namespace:   requiredImageStreamTag.Namespace,
-					Name:        requiredImageStreamTag.Name,
-				},
-			}
-			istImport.SetDeterministicName()
-			istLog.Info("Creating ImageStreamTagImport in the build cluster")
-			if err := istImportClient.Create(ctx, istImport); err != nil && !apierrors.IsAlreadyExists(err) {
-				return fmt.Errorf("failed to create imagestreamtag %s: %w", requiredImageStreamTag, err)
-			}
-			if err := wait.Poll(5*second, 30*second, func() (bool, error) {
-				if err := client.Get(ctx, requiredImageStreamTag, &imagev1.ImageStreamTag{}); err != nil {
-					if apierrors.IsNotFound(err) {
-						return false, nil
-					}
-					return false, fmt.Errorf("get failed: %w", err)
-				}
-				return true, nil
-			}); err != nil {
-				return fmt.Errorf("failed waiting for imagestreamtag %s to appear: %w", requiredImageStreamTag, err)
-			}
-
-			return nil
-		})
-	}
-
-	return g.Wait()
-}
-
 // determineSubsetToRehearse determines in a sophisticated way which subset of jobs should be chosen to be rehearsed.
 // First, it will create a list of the jobs mapped by the source type and calculates the maximum allowed jobs for each
 // source type. If there are jobs from a specific source type that are under the max allowed number, it will fill the gap
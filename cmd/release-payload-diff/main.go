@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/logrusutil"
+	"sigs.k8s.io/yaml"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/release/diff"
+)
+
+type options struct {
+	from           string
+	to             string
+	registryConfig string
+	outputDir      string
+}
+
+func gatherOptions() options {
+	o := options{}
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.StringVar(&o.from, "from", "", "Release payload to compare against: a pull spec or the path to its extracted image-references file")
+	fs.StringVar(&o.to, "to", "", "Release payload to compare: a pull spec or the path to its extracted image-references file")
+	fs.StringVar(&o.registryConfig, "registry-config", "", "Path to the registry credentials used to extract image-references from pull specs")
+	fs.StringVar(&o.outputDir, "output-dir", "", "Directory to write the JSON report and jUnit into, if set")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		logrus.WithError(err).Fatal("could not parse input")
+	}
+	return o
+}
+
+func (o *options) validate() error {
+	if o.from == "" {
+		return errors.New("--from is required")
+	}
+	if o.to == "" {
+		return errors.New("--to is required")
+	}
+	return nil
+}
+
+func main() {
+	logrusutil.ComponentInit()
+	o := gatherOptions()
+	if err := o.validate(); err != nil {
+		logrus.WithError(err).Fatal("invalid options")
+	}
+
+	ctx := context.Background()
+	components := map[string]map[string]diff.Component{}
+	for _, payload := range []string{o.from, o.to} {
+		stream, err := loadPayload(ctx, payload, o.registryConfig)
+		if err != nil {
+			logrus.WithError(err).Fatalf("could not load release payload %s", payload)
+		}
+		components[payload] = diff.ComponentsFor(stream)
+	}
+	report := diff.Diff(o.from, o.to, components[o.from], components[o.to])
+	fmt.Println(report.String())
+
+	if o.outputDir != "" {
+		if err := writeReport(o.outputDir, report); err != nil {
+			logrus.WithError(err).Fatal("could not write report")
+		}
+	}
+}
+
+// loadPayload reads the image-references of a payload from a file, or
+// extracts them from the payload when given a pull spec
+func loadPayload(ctx context.Context, payload, registryConfig string) (*imagev1.ImageStream, error) {
+	var raw []byte
+	if _, err := os.Stat(payload); err == nil {
+		if raw, err = ioutil.ReadFile(payload); err != nil {
+			return nil, fmt.Errorf("could not read image-references: %w", err)
+		}
+	} else {
+		args := []string{"adm", "release", "extract", "--file=image-references"}
+		if registryConfig != "" {
+			args = append(args, fmt.Sprintf("--registry-config=%s", registryConfig))
+		}
+		cmd := exec.CommandContext(ctx, "oc", append(args, payload)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if raw, err = cmd.Output(); err != nil {
+			return nil, fmt.Errorf("could not extract image-references: %w: %s", err, stderr.String())
+		}
+	}
+	stream := &imagev1.ImageStream{}
+	if err := yaml.Unmarshal(raw, stream); err != nil {
+		return nil, fmt.Errorf("could not parse image-references: %w", err)
+	}
+	return stream, nil
+}
+
+func writeReport(dir string, report diff.Report) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize report: %w", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "release-diff.json"), raw, 0644); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	testCases := report.TestCases()
+	suites := &junit.TestSuites{Suites: []*junit.TestSuite{{
+		Name:      "release-payload-diff",
+		NumTests:  uint(len(testCases)),
+		TestCases: testCases,
+	}}}
+	out, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal jUnit XML: %w", err)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "junit_release_diff.xml"), out, 0644)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/release/diff"
+)
+
+const imageReferences = `{
+  "kind": "ImageStream",
+  "apiVersion": "image.openshift.io/v1",
+  "spec": {
+    "tags": [
+      {
+        "name": "cli",
+        "annotations": {
+          "io.openshift.build.commit.id": "abc",
+          "io.openshift.build.source-location": "https://github.com/openshift/oc"
+        },
+        "from": {
+          "kind": "DockerImage",
+          "name": "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:cli"
+        }
+      }
+    ]
+  }
+}`
+
+func TestLoadPayload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image-references")
+	if err := ioutil.WriteFile(path, []byte(imageReferences), 0644); err != nil {
+		t.Fatalf("could not write image-references: %v", err)
+	}
+	stream, err := loadPayload(context.Background(), path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]diff.Component{
+		"cli": {
+			Name:   "cli",
+			Image:  "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:cli",
+			Digest: "sha256:cli",
+			Commit: "abc",
+			Source: "https://github.com/openshift/oc",
+		},
+	}
+	if diff := cmp.Diff(expected, diff.ComponentsFor(stream)); diff != "" {
+		t.Errorf("unexpected components: %s", diff)
+	}
+}
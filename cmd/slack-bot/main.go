@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -28,12 +29,18 @@ import (
 	"k8s.io/test-infra/prow/pjutil"
 	"k8s.io/test-infra/prow/pjutil/pprof"
 	"k8s.io/test-infra/prow/simplifypath"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift/ci-tools/pkg/jira"
+	"github.com/openshift/ci-tools/pkg/load/agents"
 	eventhandler "github.com/openshift/ci-tools/pkg/slack/events"
 	eventrouter "github.com/openshift/ci-tools/pkg/slack/events/router"
 	interactionhandler "github.com/openshift/ci-tools/pkg/slack/interactions"
 	interactionrouter "github.com/openshift/ci-tools/pkg/slack/interactions/router"
+	"github.com/openshift/ci-tools/pkg/slack/modals"
+	"github.com/openshift/ci-tools/pkg/slack/modals/declarative"
+	"github.com/openshift/ci-tools/pkg/slack/subscriptions"
+	"github.com/openshift/ci-tools/pkg/util"
 )
 
 type options struct {
@@ -48,6 +55,15 @@ type options struct {
 
 	slackTokenPath         string
 	slackSigningSecretPath string
+
+	subscriptionsNamespace    string
+	subscriptionsConfigMap    string
+	subscriptionsPollInterval time.Duration
+	resultsBucket             string
+	ciOperatorConfigPath      string
+	registryPath              string
+
+	modalsConfigPath string
 }
 
 func (o *options) Validate() error {
@@ -64,6 +80,10 @@ func (o *options) Validate() error {
 		return fmt.Errorf("--slack-signing-secret-path is required")
 	}
 
+	if o.subscriptionsConfigMap != "" && o.subscriptionsNamespace == "" {
+		return fmt.Errorf("--subscriptions-namespace is required with --subscriptions-configmap")
+	}
+
 	for _, group := range []flagutil.OptionGroup{&o.instrumentationOptions, &o.jiraOptions, &o.prowconfig} {
 		if err := group.Validate(false); err != nil {
 			return err
@@ -89,6 +109,14 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	fs.StringVar(&o.slackTokenPath, "slack-token-path", "", "Path to the file containing the Slack token to use.")
 	fs.StringVar(&o.slackSigningSecretPath, "slack-signing-secret-path", "", "Path to the file containing the Slack signing secret to use.")
 
+	fs.StringVar(&o.subscriptionsNamespace, "subscriptions-namespace", "ci", "Namespace of the ConfigMap holding channel subscriptions.")
+	fs.StringVar(&o.subscriptionsConfigMap, "subscriptions-configmap", "", "Name of the ConfigMap holding channel subscriptions. Subscriptions are disabled when unset.")
+	fs.DurationVar(&o.subscriptionsPollInterval, "subscriptions-poll-interval", 5*time.Minute, "How often to check periodic job results and the registry for subscribed events.")
+	fs.StringVar(&o.resultsBucket, "results-bucket", "origin-ci-test", "GCS bucket periodic jobs upload their results to.")
+	fs.StringVar(&o.ciOperatorConfigPath, "ci-operator-config-path", "", "Path to the ci-operator configs, to notify subscribers of changes to them.")
+	fs.StringVar(&o.registryPath, "registry-path", "", "Path to the step registry, to notify subscribers of changes to it.")
+	fs.StringVar(&o.modalsConfigPath, "modals-config-path", "", "Path to a file declaring additional modals, which replace built-in modals with the same ID.")
+
 	if err := fs.Parse(args); err != nil {
 		logrus.WithError(err).Fatal("Could not parse args.")
 	}
@@ -135,11 +163,54 @@ func main() {
 		logrus.WithError(err).Fatal("Could not initialize Jira issue filer.")
 	}
 
+	var declaredModals []*modals.FlowWithViewAndFollowUps
+	if o.modalsConfigPath != "" {
+		modalsConfig, err := declarative.Load(o.modalsConfigPath)
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not load declared modals.")
+		}
+		if declaredModals, err = modalsConfig.Register(issueFiler, slackClient); err != nil {
+			logrus.WithError(err).Fatal("Could not register declared modals.")
+		}
+	}
+
 	gcsClient, err := storage.NewClient(interrupts.Context(), option.WithoutAuthentication())
 	if err != nil {
 		logrus.WithError(err).Fatal("Could not initialize GCS client.")
 	}
 
+	var subscriptionStore subscriptions.Store
+	var registryAgent agents.RegistryAgent
+	if o.subscriptionsConfigMap != "" {
+		clusterConfig, err := util.LoadClusterConfig()
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not load cluster config.")
+		}
+		client, err := ctrlruntimeclient.New(clusterConfig, ctrlruntimeclient.Options{})
+		if err != nil {
+			logrus.WithError(err).Fatal("Could not create a Kubernetes client.")
+		}
+		subscriptionStore = subscriptions.NewConfigMapStore(client, o.subscriptionsNamespace, o.subscriptionsConfigMap)
+
+		var configs agents.ConfigAgent
+		if o.ciOperatorConfigPath != "" {
+			if configs, err = agents.NewConfigAgent(o.ciOperatorConfigPath); err != nil {
+				logrus.WithError(err).Fatal("Could not start the ci-operator config agent.")
+			}
+		}
+		if o.registryPath != "" {
+			if registryAgent, err = agents.NewRegistryAgent(o.registryPath); err != nil {
+				logrus.WithError(err).Fatal("Could not start the registry agent.")
+			}
+		}
+		notifier := subscriptions.NewNotifier(subscriptionStore, slackClient, gcsClient, o.resultsBucket, configs, registryAgent)
+		interrupts.Run(func(ctx context.Context) {
+			if err := notifier.Run(ctx, o.subscriptionsPollInterval); err != nil {
+				logrus.WithError(err).Fatal("Could not notify subscribers.")
+			}
+		})
+	}
+
 	metrics.ExposeMetrics("slack-bot", config.PushGateway{}, o.instrumentationOptions.MetricsPort)
 	simplifier := simplifypath.NewSimplifier(l("", // shadow element mimicing the root
 		l(""), // for black-box health checks
@@ -156,8 +227,8 @@ func main() {
 	mux := http.NewServeMux()
 	// handle the root to allow for a simple uptime probe
 	mux.Handle("/", handler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) { writer.WriteHeader(http.StatusOK) })))
-	mux.Handle("/slack/interactive-endpoint", handler(handleInteraction(secretAgent.GetTokenGenerator(o.slackSigningSecretPath), interactionrouter.ForModals(issueFiler, slackClient))))
-	mux.Handle("/slack/events-endpoint", handler(handleEvent(secretAgent.GetTokenGenerator(o.slackSigningSecretPath), eventrouter.ForEvents(slackClient, configAgent.Config, gcsClient))))
+	mux.Handle("/slack/interactive-endpoint", handler(handleInteraction(secretAgent.GetTokenGenerator(o.slackSigningSecretPath), interactionrouter.ForModals(issueFiler, slackClient, declaredModals...))))
+	mux.Handle("/slack/events-endpoint", handler(handleEvent(secretAgent.GetTokenGenerator(o.slackSigningSecretPath), eventrouter.ForEvents(slackClient, configAgent.Config, gcsClient, subscriptionStore, registryAgent))))
 	server := &http.Server{Addr: ":" + strconv.Itoa(o.port), Handler: mux}
 
 	health.ServeReady()
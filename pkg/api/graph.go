@@ -472,6 +472,9 @@ func mergeSteps(into, from CIOperatorStepDetails) CIOperatorStepDetails {
 	if into.Failed == nil {
 		into.Failed = from.Failed
 	}
+	if into.Reason == "" {
+		into.Reason = from.Reason
+	}
 	if into.Substeps == nil {
 		into.Substeps = from.Substeps
 	}
@@ -484,6 +487,23 @@ type CIOperatorStepDetails struct {
 	Substeps                 []CIOperatorStepDetailInfo `json:"substeps,omitempty"`
 }
 
+// UnmarshalJSON keeps the substeps, which the promoted method of
+// the embedded CIOperatorStepDetailInfo would otherwise drop
+func (c *CIOperatorStepDetails) UnmarshalJSON(data []byte) error {
+	var info CIOperatorStepDetailInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return err
+	}
+	var substeps struct {
+		Substeps []CIOperatorStepDetailInfo `json:"substeps,omitempty"`
+	}
+	if err := json.Unmarshal(data, &substeps); err != nil {
+		return err
+	}
+	*c = CIOperatorStepDetails{CIOperatorStepDetailInfo: info, Substeps: substeps.Substeps}
+	return nil
+}
+
 type CIOperatorStepDetailInfo struct {
 	StepName     string                     `json:"name"`
 	Description  string                     `json:"description"`
@@ -494,6 +514,7 @@ type CIOperatorStepDetailInfo struct {
 	Manifests    []ctrlruntimeclient.Object `json:"manifests,omitempty"`
 	LogURL       string                     `json:"log_url,omitempty"`
 	Failed       *bool                      `json:"failed,omitempty"`
+	Reason       string                     `json:"reason,omitempty"`
 }
 
 func (c *CIOperatorStepDetailInfo) UnmarshalJSON(data []byte) error {
@@ -487,3 +487,22 @@ func TestCIOperatorStepWithDependenciesSerializationRoundTrips(t *testing.T) {
 		})
 	}
 }
+
+func TestCIOperatorStepDetailsUnmarshalKeepsSubsteps(t *testing.T) {
+	failed := true
+	expected := CIOperatorStepDetails{
+		CIOperatorStepDetailInfo: CIOperatorStepDetailInfo{
+			StepName: "e2e",
+			Failed:   &failed,
+			Reason:   "step_failed",
+		},
+		Substeps: []CIOperatorStepDetailInfo{{StepName: "e2e-test", Failed: &failed}},
+	}
+	var actual CIOperatorStepDetails
+	if err := json.Unmarshal([]byte(`{"name":"e2e","failed":true,"reason":"step_failed","substeps":[{"name":"e2e-test","failed":true}]}`), &actual); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unmarshalled step differs from expected: %s", diff)
+	}
+}
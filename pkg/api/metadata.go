@@ -5,6 +5,7 @@ import (
 	"path"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -100,6 +101,51 @@ func FlavorForBranch(branch string) string {
 	return flavor
 }
 
+// VersionForBranch determines the minor version a branch tracks, like 4.9
+// for the release-4.9 branch
+func VersionForBranch(branch string) (string, bool) {
+	matches := fourXBranches.FindStringSubmatch(branch)
+	if matches == nil {
+		return "", false
+	}
+	return matches[2], true
+}
+
+// CandidateVersion determines the minor version a candidate release resolves
+// to: the configured version, or the version the branch tracks when none is
+// configured, made older by the relative minor
+func CandidateVersion(candidate Candidate, branch string) (string, error) {
+	version := candidate.Version
+	if version == "" {
+		var ok bool
+		if version, ok = VersionForBranch(branch); !ok {
+			return "", fmt.Errorf("no version is set and branch %q does not determine one", branch)
+		}
+	}
+	return relativeMinorVersion(version, candidate.RelativeMinor)
+}
+
+var minorVersion = regexp.MustCompile(`^([0-9]+)\.([0-9]+)$`)
+
+// relativeMinorVersion determines the minor version that is relative minors older than the version
+func relativeMinorVersion(version string, relative int) (string, error) {
+	if relative == 0 {
+		return version, nil
+	}
+	matches := minorVersion.FindStringSubmatch(version)
+	if matches == nil {
+		return "", fmt.Errorf("cannot determine a relative minor version for %q", version)
+	}
+	minor, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid minor version %q: %w", version, err)
+	}
+	if minor-relative < 0 {
+		return "", fmt.Errorf("there is no minor version %d before %s", relative, version)
+	}
+	return fmt.Sprintf("%s.%d", matches[1], minor-relative), nil
+}
+
 func LogFieldsFor(metadata Metadata) logrus.Fields {
 	return logrus.Fields{
 		"org":     metadata.Org,
@@ -348,3 +348,115 @@ func TestFlavorForBranch(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionForBranch(t *testing.T) {
+	testCases := []struct {
+		branch          string
+		expected        string
+		expectedVersion bool
+	}{
+		{branch: "release-4.9", expected: "4.9", expectedVersion: true},
+		{branch: "openshift-4.10", expected: "4.10", expectedVersion: true},
+		{branch: "master"},
+		{branch: "release-3.11"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.branch, func(t *testing.T) {
+			actual, ok := VersionForBranch(testCase.branch)
+			if actual != testCase.expected || ok != testCase.expectedVersion {
+				t.Errorf("expected (%q, %t), got (%q, %t)", testCase.expected, testCase.expectedVersion, actual, ok)
+			}
+		})
+	}
+}
+
+func TestCandidateVersion(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		candidate   Candidate
+		branch      string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:      "configured version",
+			candidate: Candidate{Version: "4.8"},
+			branch:    "release-4.9",
+			expected:  "4.8",
+		},
+		{
+			name:      "version of the branch",
+			candidate: Candidate{},
+			branch:    "release-4.9",
+			expected:  "4.9",
+		},
+		{
+			name:      "relative minor of the version of the branch",
+			candidate: Candidate{RelativeMinor: 1},
+			branch:    "release-4.9",
+			expected:  "4.8",
+		},
+		{
+			name:        "no version and the branch does not track one",
+			candidate:   Candidate{},
+			branch:      "master",
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := CandidateVersion(testCase.candidate, testCase.branch)
+			if (err != nil) != testCase.expectedErr {
+				t.Errorf("expected error %t, got %v", testCase.expectedErr, err)
+			}
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRelativeMinorVersion(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		version     string
+		relative    int
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "no relative minor",
+			version:  "4.9",
+			expected: "4.9",
+		},
+		{
+			name:     "previous minor",
+			version:  "4.9",
+			relative: 1,
+			expected: "4.8",
+		},
+		{
+			name:     "two-digit minor",
+			version:  "4.10",
+			relative: 2,
+			expected: "4.8",
+		},
+		{
+			name:        "no such minor",
+			version:     "4.1",
+			relative:    2,
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := relativeMinorVersion(testCase.version, testCase.relative)
+			if (err != nil) != testCase.expectedErr {
+				t.Errorf("expected error %t, got %v", testCase.expectedErr, err)
+			}
+			if actual != testCase.expected {
+				t.Errorf("expected %q, got %q", testCase.expected, actual)
+			}
+		})
+	}
+}
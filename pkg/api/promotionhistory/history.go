@@ -0,0 +1,148 @@
+package promotionhistory
+
+// This package contains the annotations ci-operator records on the image streams
+// it promotes to, so that promotions can be audited and rolled back.
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+const (
+	// HistoryAnnotation holds the JSON-encoded promotion history of every tag
+	// of the image stream, newest record first
+	HistoryAnnotation = "ci.openshift.io/promotion-history"
+	// PinAnnotation holds the JSON-encoded rollback pins of the image stream.
+	// A pinned tag was rolled back on purpose and must not be re-promoted
+	// automatically until the pin is removed.
+	PinAnnotation = "ci.openshift.io/promotion-rollback-pins"
+
+	// MaxRecords is the number of records kept in the history of each tag
+	MaxRecords = 10
+)
+
+// Record describes one change of a promoted tag
+type Record struct {
+	// Digest is the digest of the image the tag was set to
+	Digest string `json:"digest"`
+	// PreviousDigest is the digest of the image the tag pointed to before, if any
+	PreviousDigest string `json:"previous_digest,omitempty"`
+	// JobURL links to the job that promoted the image
+	JobURL string `json:"job_url,omitempty"`
+	// Commit is the commit of the repository the image was built from
+	Commit string `json:"commit,omitempty"`
+	// Time is when the tag was changed
+	Time time.Time `json:"time"`
+	// RolledBack is set when the record was created by a rollback
+	RolledBack bool `json:"rolled_back,omitempty"`
+}
+
+// Pin prevents re-promotion of a tag that was rolled back
+type Pin struct {
+	Digest string    `json:"digest"`
+	Reason string    `json:"reason,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// History returns the promotion history of all tags of the stream
+func History(stream *imagev1.ImageStream) (map[string][]Record, error) {
+	history := map[string][]Record{}
+	if err := unmarshalAnnotation(stream, HistoryAnnotation, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// AddRecord prepends the record to the history of the tag, dropping the oldest
+// records over MaxRecords. A record not created by a rollback removes the pin of
+// the tag, as the tag was promoted to deliberately.
+func AddRecord(stream *imagev1.ImageStream, tag string, record Record) error {
+	history, err := History(stream)
+	if err != nil {
+		return err
+	}
+	records := append([]Record{record}, history[tag]...)
+	if len(records) > MaxRecords {
+		records = records[:MaxRecords]
+	}
+	history[tag] = records
+	if err := marshalAnnotation(stream, HistoryAnnotation, history); err != nil {
+		return err
+	}
+	if !record.RolledBack {
+		return Unpin(stream, tag)
+	}
+	return nil
+}
+
+// Pins returns the rollback pins of the stream by tag
+func Pins(stream *imagev1.ImageStream) (map[string]Pin, error) {
+	pins := map[string]Pin{}
+	if err := unmarshalAnnotation(stream, PinAnnotation, &pins); err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+// SetPin pins the tag of the stream
+func SetPin(stream *imagev1.ImageStream, tag string, pin Pin) error {
+	pins, err := Pins(stream)
+	if err != nil {
+		return err
+	}
+	pins[tag] = pin
+	return marshalAnnotation(stream, PinAnnotation, pins)
+}
+
+// Unpin removes the pin of the tag of the stream, if any
+func Unpin(stream *imagev1.ImageStream, tag string) error {
+	pins, err := Pins(stream)
+	if err != nil {
+		return err
+	}
+	if _, pinned := pins[tag]; !pinned {
+		return nil
+	}
+	delete(pins, tag)
+	if len(pins) == 0 {
+		delete(stream.Annotations, PinAnnotation)
+		return nil
+	}
+	return marshalAnnotation(stream, PinAnnotation, pins)
+}
+
+// CurrentDigest returns the digest of the image the tag of the stream points to
+func CurrentDigest(stream *imagev1.ImageStream, tag string) string {
+	for _, t := range stream.Status.Tags {
+		if t.Tag == tag && len(t.Items) > 0 {
+			return t.Items[0].Image
+		}
+	}
+	return ""
+}
+
+func unmarshalAnnotation(stream *imagev1.ImageStream, annotation string, into interface{}) error {
+	raw, ok := stream.Annotations[annotation]
+	if !ok || raw == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), into); err != nil {
+		return fmt.Errorf("could not parse %s annotation of imagestream %s/%s: %w", annotation, stream.Namespace, stream.Name, err)
+	}
+	return nil
+}
+
+func marshalAnnotation(stream *imagev1.ImageStream, annotation string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("could not serialize %s annotation: %w", annotation, err)
+	}
+	if stream.Annotations == nil {
+		stream.Annotations = map[string]string{}
+	}
+	stream.Annotations[annotation] = string(raw)
+	return nil
+}
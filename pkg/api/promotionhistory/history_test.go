@@ -0,0 +1,69 @@
+package promotionhistory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func TestAddRecord(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	stream := &imagev1.ImageStream{}
+	if err := SetPin(stream, "foo", Pin{Digest: "sha256:old", Time: now}); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := SetPin(stream, "bar", Pin{Digest: "sha256:bar", Time: now}); err != nil {
+		t.Fatalf("failed to pin: %v", err)
+	}
+	if err := AddRecord(stream, "foo", Record{Digest: "sha256:old", Time: now, RolledBack: true}); err != nil {
+		t.Fatalf("failed to add record: %v", err)
+	}
+	pins, err := Pins(stream)
+	if err != nil {
+		t.Fatalf("failed to get pins: %v", err)
+	}
+	if _, pinned := pins["foo"]; !pinned {
+		t.Error("expected a rollback record to keep the pin")
+	}
+	for i := 0; i < MaxRecords+2; i++ {
+		if err := AddRecord(stream, "foo", Record{Digest: fmt.Sprintf("sha256:%d", i), Time: now}); err != nil {
+			t.Fatalf("failed to add record: %v", err)
+		}
+	}
+	history, err := History(stream)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	if len(history["foo"]) != MaxRecords {
+		t.Errorf("expected %d records, got %d", MaxRecords, len(history["foo"]))
+	}
+	if digest := history["foo"][0].Digest; digest != fmt.Sprintf("sha256:%d", MaxRecords+1) {
+		t.Errorf("expected the newest record first, got %s", digest)
+	}
+	pins, err = Pins(stream)
+	if err != nil {
+		t.Fatalf("failed to get pins: %v", err)
+	}
+	if diff := cmp.Diff(map[string]Pin{"bar": {Digest: "sha256:bar", Time: now}}, pins); diff != "" {
+		t.Errorf("expected a promotion to remove the pin of the tag: %s", diff)
+	}
+	if err := Unpin(stream, "bar"); err != nil {
+		t.Fatalf("failed to unpin: %v", err)
+	}
+	if _, ok := stream.Annotations[PinAnnotation]; ok {
+		t.Error("expected the pin annotation to be removed with the last pin")
+	}
+}
+
+func TestInvalidAnnotation(t *testing.T) {
+	stream := &imagev1.ImageStream{}
+	stream.Namespace, stream.Name = "ocp", "4.9"
+	stream.Annotations = map[string]string{HistoryAnnotation: "{"}
+	if _, err := History(stream); err == nil || err.Error() != "could not parse ci.openshift.io/promotion-history annotation of imagestream ocp/4.9: unexpected end of JSON input" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -222,6 +222,20 @@ type InputConfiguration struct {
 	// and cannot co-exist with 'tag_specification', as
 	// they result in the same output.
 	Releases map[string]UnresolvedRelease `json:"releases,omitempty"`
+
+	// ReleaseDiffs lists pairs of release payloads, like
+	// 'initial' and 'latest', to compare at the component
+	// level. The changed components are reported in the
+	// artifacts and in jUnit.
+	ReleaseDiffs []ReleaseDiff `json:"release_diffs,omitempty"`
+}
+
+// ReleaseDiff describes two release payloads to compare
+type ReleaseDiff struct {
+	// From is the name of the release payload compared against
+	From string `json:"from"`
+	// To is the name of the release payload compared
+	To string `json:"to"`
 }
 
 // UnresolvedRelease describes a semantic release payload
@@ -244,13 +258,23 @@ type Candidate struct {
 	Architecture ReleaseArchitecture `json:"architecture,omitempty"`
 	// ReleaseStream is the stream from which we pick the latest candidate
 	Stream ReleaseStream `json:"stream"`
-	// Version is the minor version to search for
-	Version string `json:"version"`
+	// Version is the minor version to search for. Defaults
+	// to the version of the tested branch, like 4.9 for the
+	// release-4.9 branch.
+	Version string `json:"version,omitempty"`
+	// RelativeMinor optionally requests an older minor version
+	// than Version. For instance, a value of 1 will resolve to
+	// a 4.8 release when testing the release-4.9 branch.
+	RelativeMinor int `json:"relative_minor,omitempty"`
 	// Relative optionally specifies how old of a release
 	// is requested from this stream. For instance, a value
 	// of 1 will resolve to the previous validated release
 	// for this stream.
 	Relative int `json:"relative,omitempty"`
+	// MaxAgeDays optionally excludes releases created more
+	// than this many days ago, failing the resolution when
+	// the requested release is older.
+	MaxAgeDays int `json:"max_age_days,omitempty"`
 }
 
 // Prerelease describes a validated release payload before it is exposed
@@ -264,13 +288,20 @@ type Prerelease struct {
 	VersionBounds VersionBounds `json:"version_bounds"`
 }
 
-// VersionBounds describe the upper and lower bounds on a version search
+// VersionBounds describe the upper and lower bounds on a version search,
+// either as exclusive lower and upper versions or as a semantic version range
 type VersionBounds struct {
-	Lower string `json:"lower"`
-	Upper string `json:"upper"`
+	Lower string `json:"lower,omitempty"`
+	Upper string `json:"upper,omitempty"`
+	// Range is a semantic version range expression, like
+	// ">=4.8.0 <4.9.0" or ">=4.8.0 <4.8.10 || >4.8.12 <4.9.0"
+	Range string `json:"range,omitempty"`
 }
 
 func (b *VersionBounds) Query() string {
+	if b.Range != "" {
+		return b.Range
+	}
 	return fmt.Sprintf(">%s <%s", b.Lower, b.Upper)
 }
 
@@ -287,6 +318,7 @@ type ReleaseArchitecture string
 
 const (
 	ReleaseArchitectureAMD64   ReleaseArchitecture = "amd64"
+	ReleaseArchitectureARM64   ReleaseArchitecture = "arm64"
 	ReleaseArchitecturePPC64le ReleaseArchitecture = "ppc64le"
 	ReleaseArchitectureS390x   ReleaseArchitecture = "s390x"
 )
@@ -299,6 +331,17 @@ const (
 	ReleaseStreamOKD     ReleaseStream = "okd"
 )
 
+// HasTimestampedNames determines if the names of releases in the stream end
+// with the time they were created at, like 4.8.0-0.nightly-2021-06-01-123456,
+// which is how the age of a release is determined
+func (s ReleaseStream) HasTimestampedNames() bool {
+	switch s {
+	case ReleaseStreamCI, ReleaseStreamNightly, ReleaseStreamOKD:
+		return true
+	}
+	return false
+}
+
 // Release describes a generally available release payload
 type Release struct {
 	// Version is the minor version to search for
@@ -426,6 +469,46 @@ type PromotionConfiguration struct {
 	// promotion does not imply output artifacts are being created
 	// for posterity.
 	DisableBuildCache bool `json:"disable_build_cache,omitempty"`
+
+	// ExternalDestinations are registries outside of the CI
+	// cluster that the promoted images are additionally pushed to
+	// once they were promoted to the integration image streams.
+	ExternalDestinations []ExternalPromotionDestination `json:"external_destinations,omitempty"`
+
+	// Signing configures signatures for the images pushed to the
+	// external destinations.
+	Signing *PromotionSigningConfiguration `json:"signing,omitempty"`
+}
+
+// ExternalPromotionDestination is an external repository that
+// promoted images are pushed to.
+type ExternalPromotionDestination struct {
+	// Repository is the prefix of the repositories the images are
+	// pushed to, for example quay.io/organization. Images are pushed
+	// as <repository>/<name>:<tag> with the name and tag of the image
+	// stream tag they are promoted to.
+	Repository string `json:"repository"`
+
+	// Credentials is the name of a secret in the test-credentials
+	// namespace holding a .dockerconfigjson that allows pushing to
+	// the repository.
+	Credentials string `json:"credentials"`
+}
+
+// PromotionSigningConfiguration configures cosign signatures for the
+// images pushed to external destinations. Signatures are verified
+// after they are pushed.
+type PromotionSigningConfiguration struct {
+	// KeySecret is the name of a secret in the test-credentials
+	// namespace holding the cosign.key and cosign.pub key pair and,
+	// if the private key is encrypted, its cosign.password.
+	KeySecret string `json:"key_secret"`
+
+	// SBOMPath is the path of a software bill of materials written
+	// into the images during their build. When set, the SBOM is
+	// extracted from every image, attached to it in the external
+	// destinations and signed.
+	SBOMPath string `json:"sbom_path,omitempty"`
 }
 
 // StepConfiguration holds one step configuration.
@@ -603,6 +686,31 @@ type TestStepConfiguration struct {
 	OpenshiftInstallerCustomTestImageClusterTestConfiguration *OpenshiftInstallerCustomTestImageClusterTestConfiguration `json:"openshift_installer_custom_test_image,omitempty"`
 }
 
+// GetClusterProfile returns the cluster profile used by the test, if any
+func (config TestStepConfiguration) GetClusterProfile() ClusterProfile {
+	switch {
+	case config.MultiStageTestConfiguration != nil:
+		return config.MultiStageTestConfiguration.ClusterProfile
+	case config.MultiStageTestConfigurationLiteral != nil:
+		return config.MultiStageTestConfigurationLiteral.ClusterProfile
+	case config.OpenshiftAnsibleClusterTestConfiguration != nil:
+		return config.OpenshiftAnsibleClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftAnsibleSrcClusterTestConfiguration != nil:
+		return config.OpenshiftAnsibleSrcClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftAnsibleCustomClusterTestConfiguration != nil:
+		return config.OpenshiftAnsibleCustomClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerUPIClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerUPIClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerUPISrcClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerUPISrcClusterTestConfiguration.ClusterProfile
+	case config.OpenshiftInstallerCustomTestImageClusterTestConfiguration != nil:
+		return config.OpenshiftInstallerCustomTestImageClusterTestConfiguration.ClusterProfile
+	}
+	return ""
+}
+
 // Cloud is the name of a cloud provider, e.g., aws cluster topology, etc.
 type Cloud string
 
@@ -1364,6 +1472,20 @@ type Bundle struct {
 	// UpdateGraph defines the update mode to use when adding the bundle to the base index.
 	// Can be: semver (default), semver-skippatch, or replaces
 	UpdateGraph IndexUpdate `json:"update_graph,omitempty"`
+	// Validation configures checks of the bundle and of the upgrade graph of the
+	// index the bundle is added to. The results are reported in jUnit.
+	Validation *BundleValidation `json:"validation,omitempty"`
+}
+
+// BundleValidation configures the validation of an operator bundle
+type BundleValidation struct {
+	// SelectOptional selects optional validators of `operator-sdk bundle validate`,
+	// like `suite=operatorframework`. By default, only the CSV schema and the
+	// required annotations of the bundle are validated.
+	SelectOptional string `json:"select_optional,omitempty"`
+	// Scorecard runs the `operator-sdk scorecard` tests configured in the bundle
+	// in the namespace of the job.
+	Scorecard bool `json:"scorecard,omitempty"`
 }
 
 // IndexGeneratorStepConfiguration describes a step that creates an index database and
@@ -1448,6 +1570,19 @@ type ProjectDirectoryImageBuildStepConfiguration struct {
 	// promoted unless explicitly targeted. Use for builds which
 	// are invoked only when testing certain parts of the repo.
 	Optional bool `json:"optional,omitempty"`
+
+	// Architectures the image is built for. When set, the image is
+	// built once for every architecture on nodes of that architecture
+	// and the results are assembled into a manifest list, which is
+	// what is tagged into the pipeline and promoted. When unset, the
+	// image is built for the architecture of the build cluster only.
+	Architectures []ReleaseArchitecture `json:"architectures,omitempty"`
+}
+
+// ArchitectureTag is the tag in the pipeline image stream holding
+// the image built for one architecture of a multi-architecture image
+func ArchitectureTag(tag PipelineImageStreamTagReference, architecture ReleaseArchitecture) PipelineImageStreamTagReference {
+	return PipelineImageStreamTagReference(fmt.Sprintf("%s-%s", tag, architecture))
 }
 
 // ProjectDirectoryImageBuildInputs holds inputs for an image build from the repo under test
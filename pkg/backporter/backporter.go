@@ -6,7 +6,6 @@ import (
 	"html/template"
 	"net/http"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -14,7 +13,6 @@ import (
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/test-infra/prow/bugzilla"
 	"k8s.io/test-infra/prow/metrics"
 )
 
@@ -192,12 +190,12 @@ const clonesTemplateConstructor = `
 						---
 					{{ end }}
 					</td>
-					<td style="vertical-align: middle;"><a href = "https://bugzilla.redhat.com/show_bug.cgi?id={{$clone.ID}}" target="_blank">{{ $clone.ID }}</a></td>
+					<td style="vertical-align: middle;"><a href = "{{ $clone.URL }}" target="_blank">{{ $clone.ID }}</a></td>
 					<td style="vertical-align: middle;">{{ $clone.Status }}</td>
 					<td style="vertical-align: middle;">
 						{{range $index, $pr := $clone.PRs }}
 							{{ if $index}},{{end}}
-							<a href = "{{ $pr.Type.URL }}/{{$pr.Org}}/{{$pr.Repo}}/pull/{{$pr.Num}}" target="_blank"> {{$pr.Org}}/{{$pr.Repo}}#{{$pr.Num}}</a>
+							<a href = "{{ $pr.URL }}" target="_blank"> {{$pr.Org}}/{{$pr.Repo}}#{{$pr.Num}}</a>
 						{{end}}
 					</td>
 				</tr>
@@ -217,6 +215,19 @@ const clonesTemplateConstructor = `
 		</select>
 		<button class="btn btn-outline-success my-2 my-sm-0" type="submit">Create Clone</button>
 	</form>
+	{{ if .CloneTargets }}
+	<br>
+	<form class="form-inline my-2 my-lg-0" action="/clones/bulk" method="get">
+		<input type="hidden" name="ID" value="{{.Bug.ID}}">
+		{{ range $release := .CloneTargets }}
+			<div class="form-check form-check-inline">
+				<input class="form-check-input" type="checkbox" name="release" value="{{$release}}" id="bulk_{{$release}}">
+				<label class="form-check-label" for="bulk_{{$release}}">{{$release}}</label>
+			</div>
+		{{end}}
+		<button class="btn btn-outline-success my-2 my-sm-0" type="submit">Preview Clones</button>
+	</form>
+	{{ end }}
 	<br>
 	<div class="col-sm-4">
 	<h4 id="clones"> <a href ="#clones"> Dependence Tree</a> </h4>
@@ -257,6 +268,17 @@ otherwise you will be redirected to an error page.
 Please note - Do not refresh the page once the clone has been created since this would cause another clone to be created.
 </p>
 
+<h2 id="title"><a href="#title">How to backport to several releases at once?</a></h2>
+
+<p>
+Tick every target release the fix needs to be backported to and click the "Preview Clones" button
+which can be found after the clones table.
+The preview lists every clone which will be created, in order, and the bug each one is cloned from.
+Releases between a selected one and the closest higher release with a clone get a clone as well,
+so that every clone is blocked by the clone targeting the next higher release.
+Click "Create Clones" to create them all.
+</p>
+
 <h2 id="title"><a href="#title">Getting the latest changes</a></h2>
 
 <p>
@@ -277,7 +299,7 @@ func renderTree(node *dependenceNode, height int) string {
 	for i := 0; i < height; i++ {
 		resultList += `<span class="indent"></span>`
 	}
-	resultList += fmt.Sprintf(`<span> %d (%s)</span></li>`, node.BugID, node.TargetRelease)
+	resultList += fmt.Sprintf(`<span> %s (%s)</span></li>`, node.BugID, node.TargetRelease)
 	for _, childNode := range node.Children {
 		resultList += renderTree(childNode, height+1)
 	}
@@ -295,14 +317,14 @@ var (
 	helpTemplate  = template.Must(template.New("help").Parse(helpTemplateConstructor))
 )
 
-func logFieldsFor(endpoint string, bugID int) logrus.Fields {
+func logFieldsFor(endpoint string, bugID string) logrus.Fields {
 	return logrus.Fields{
 		"endpoint": endpoint,
 		"bugID":    bugID,
 	}
 }
 
-func handleError(w http.ResponseWriter, err error, shortErrorMessage string, statusCode int, endpoint string, bugID int, m *metrics.Metrics) {
+func handleError(w http.ResponseWriter, err error, shortErrorMessage string, statusCode int, endpoint string, bugID string, m *metrics.Metrics) {
 	var fprintfErr error
 	w.WriteHeader(statusCode)
 	wpErr := writePage(w, http.StatusText(statusCode), errorTemplate, shortErrorMessage)
@@ -318,10 +340,10 @@ type HandlerFuncWithErrorReturn func(http.ResponseWriter, *http.Request) error
 
 // ClonesTemplateData holds the UI data for the clones page
 type ClonesTemplateData struct {
-	Bug             *bugzilla.Bug          // bug details
-	Clones          []*bugzilla.Bug        // List of clones for the bug
-	Parent          *bugzilla.Bug          // Root bug if it is a a bug, otherwise holds itself
-	PRs             []bugzilla.ExternalBug // Details of linked PR
+	Bug             *Issue        // bug details
+	Clones          []*Issue      // List of clones for the bug
+	Parent          *Issue        // Root bug if it is a a bug, otherwise holds itself
+	PRs             []PullRequest // Details of linked PR
 	CloneTargets    []string
 	NewCloneIDs     []string
 	MissingReleases []string
@@ -329,7 +351,7 @@ type ClonesTemplateData struct {
 }
 
 type dependenceNode struct {
-	BugID         int
+	BugID         string
 	TargetRelease string
 	Children      []*dependenceNode
 }
@@ -350,7 +372,7 @@ func writePage(w http.ResponseWriter, title string, body *template.Template, dat
 	return nil
 }
 
-func sortByTargetRelease(clones []*bugzilla.Bug) {
+func sortByTargetRelease(clones []*Issue) {
 	sort.SliceStable(clones, func(i, j int) bool {
 		if len(clones[i].TargetRelease) == 0 && len(clones[j].TargetRelease) == 0 {
 			return false
@@ -368,41 +390,34 @@ func GetLandingHandler(metrics *metrics.Metrics) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		err := writePage(w, "Home", helpTemplate, nil)
 		if err != nil {
-			handleError(w, err, "failed to build Landing page", http.StatusInternalServerError, req.URL.Path, 0, metrics)
+			handleError(w, err, "failed to build Landing page", http.StatusInternalServerError, req.URL.Path, "", metrics)
 		}
 	}
 }
 
 // GetBugHandler returns a function with bug details  in JSON format
-func GetBugHandler(client bugzilla.Client, m *metrics.Metrics) http.HandlerFunc {
+func GetBugHandler(tracker Tracker, m *metrics.Metrics) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		endpoint := r.URL.Path
 		if r.Method != "GET" {
 			http.Error(w, "not a valid request method: expected GET", http.StatusBadRequest)
 			metrics.RecordError("not a valid request method: expected GET", m.ErrorRate)
-			logrus.WithFields(logFieldsFor(endpoint, 0)).WithError(fmt.Errorf("not a valid request method: expected GET"))
+			logrus.WithFields(logFieldsFor(endpoint, "")).WithError(fmt.Errorf("not a valid request method: expected GET"))
 			return
 		}
-		bugIDStr := r.URL.Query().Get(BugIDQuery)
-		if bugIDStr == "" {
+		bugID := r.URL.Query().Get(BugIDQuery)
+		if bugID == "" {
 			http.Error(w, "missing mandatory query arg: \"ID\"", http.StatusBadRequest)
 			metrics.RecordError("missing mandatory query arg: \"ID\"", m.ErrorRate)
-			logrus.WithFields(logFieldsFor(endpoint, 0)).WithError(fmt.Errorf("missing mandatory query arg: \"ID\""))
-			return
-		}
-		bugID, err := strconv.Atoi(bugIDStr)
-		if err != nil {
-			http.Error(w, "unable to convert \"ID\" from string to int", http.StatusBadRequest)
-			metrics.RecordError("unable to convert \"ID\" from string to int", m.ErrorRate)
-			logrus.WithFields(logFieldsFor(endpoint, 0)).WithError(fmt.Errorf("unable to convert \"ID\" from string to int"))
+			logrus.WithFields(logFieldsFor(endpoint, "")).WithError(fmt.Errorf("missing mandatory query arg: \"ID\""))
 			return
 		}
 
-		bugInfo, err := client.GetBug(bugID)
+		bugInfo, err := tracker.GetIssue(bugID)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Bug#%d not found", bugID), http.StatusNotFound)
+			http.Error(w, fmt.Sprintf("Bug#%s not found", bugID), http.StatusNotFound)
 			metrics.RecordError("BugID not found", m.ErrorRate)
-			logrus.WithFields(logFieldsFor(endpoint, bugID)).WithError(fmt.Errorf("Bug#%d not found: %w", bugID, err))
+			logrus.WithFields(logFieldsFor(endpoint, bugID)).WithError(fmt.Errorf("Bug#%s not found: %w", bugID, err))
 			return
 		}
 
@@ -425,7 +440,7 @@ func GetBugHandler(client bugzilla.Client, m *metrics.Metrics) http.HandlerFunc
 	}
 }
 
-func isTargetReleaseSet(bug *bugzilla.Bug) bool {
+func isTargetReleaseSet(bug *Issue) bool {
 	return len(bug.TargetRelease) > 0 && bug.TargetRelease[0] != "---"
 }
 
@@ -437,15 +452,15 @@ func getMajorMinorRelease(release string) (string, error) {
 	return release[:periodIndex], nil
 }
 
-func buildDependenceTree(root *bugzilla.Bug, client bugzilla.Client) (*dependenceNode, error) {
+func buildDependenceTree(root *Issue, tracker Tracker) (*dependenceNode, error) {
 	// build the dependence tree
-	traversalStack := []*bugzilla.Bug{root}
+	traversalStack := []*Issue{root}
 	rootNode := &dependenceNode{BugID: root.ID, TargetRelease: root.TargetRelease[0]}
 	dependenceNodeStack := []*dependenceNode{rootNode}
 	for len(traversalStack) > 0 {
 		currBug := traversalStack[0]
 		traversalStack = traversalStack[1:]
-		children, err := client.GetClones(currBug)
+		children, err := tracker.GetClones(currBug)
 		if err != nil {
 			return nil, err
 		}
@@ -455,7 +470,7 @@ func buildDependenceTree(root *bugzilla.Bug, client bugzilla.Client) (*dependenc
 			traversalStack = append(traversalStack, children...)
 			for _, child := range children {
 				if len(child.TargetRelease) == 0 {
-					return nil, fmt.Errorf("TargetRelease not populated, BugID: %d", child.ID)
+					return nil, fmt.Errorf("TargetRelease not populated, BugID: %s", child.ID)
 				}
 				childNode := &dependenceNode{BugID: child.ID, TargetRelease: child.TargetRelease[0]}
 				currentNode.Children = append(currentNode.Children, childNode)
@@ -466,12 +481,12 @@ func buildDependenceTree(root *bugzilla.Bug, client bugzilla.Client) (*dependenc
 	return rootNode, nil
 }
 
-func getClonesTemplateData(bugID int, client bugzilla.Client, allTargetVersions []string) (*ClonesTemplateData, int, error) {
-	bug, err := client.GetBug(bugID)
+func getClonesTemplateData(bugID string, tracker Tracker, allTargetVersions []string) (*ClonesTemplateData, int, error) {
+	bug, err := tracker.GetIssue(bugID)
 	if err != nil {
-		return nil, http.StatusNotFound, fmt.Errorf("Bug#%d not found: %w", bugID, err)
+		return nil, http.StatusNotFound, fmt.Errorf("Bug#%s not found: %w", bugID, err)
 	}
-	clones, err := client.GetAllClones(bug)
+	clones, err := tracker.GetAllClones(bug)
 	if err != nil {
 		return nil, http.StatusInternalServerError, fmt.Errorf("unable to get clones: %w", err)
 	}
@@ -484,10 +499,10 @@ func getClonesTemplateData(bugID int, client bugzilla.Client, allTargetVersions
 	// Remove target versions of the original bug
 	targetVersions.Delete(bug.TargetRelease...)
 	g := new(errgroup.Group)
-	var prs []bugzilla.ExternalBug
+	var prs []PullRequest
 
 	g.Go(func() error {
-		prs, err = client.GetExternalBugPRsOnBug(bugID)
+		prs, err = tracker.GetPRs(bugID)
 		return err
 	})
 	clonedReleases := sets.NewString()
@@ -504,9 +519,9 @@ func getClonesTemplateData(bugID int, client bugzilla.Client, allTargetVersions
 		}
 
 		g.Go(func() error {
-			clonePRs, err := client.GetExternalBugPRsOnBug(clone.ID)
+			clonePRs, err := tracker.GetPRs(clone.ID)
 			if err != nil {
-				return fmt.Errorf("Bug#%d - error occurred while retreiving list of PRs : %w", clone.ID, err)
+				return fmt.Errorf("Bug#%s - error occurred while retreiving list of PRs : %w", clone.ID, err)
 			}
 			clone.PRs = clonePRs
 			return nil
@@ -572,7 +587,7 @@ func getClonesTemplateData(bugID int, client bugzilla.Client, allTargetVersions
 			targetVersions.Delete(allTargetVersions[i])
 		}
 	}
-	rootNode, err := buildDependenceTree(root, client)
+	rootNode, err := buildDependenceTree(root, tracker)
 	if err != nil {
 		return nil, http.StatusInternalServerError, fmt.Errorf("error building dependence tree: %w", err)
 	}
@@ -589,25 +604,20 @@ func getClonesTemplateData(bugID int, client bugzilla.Client, allTargetVersions
 }
 
 // GetClonesHandler returns an HTML page with detais about the bug and its clones
-func GetClonesHandler(client bugzilla.Client, allTargetVersions []string, m *metrics.Metrics) http.HandlerFunc {
+func GetClonesHandler(tracker Tracker, allTargetVersions []string, m *metrics.Metrics) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != "GET" {
-			handleError(w, fmt.Errorf("invalid request method, expected GET got %s", req.Method), "invalid request method", http.StatusBadRequest, req.URL.Path, 0, m)
+			handleError(w, fmt.Errorf("invalid request method, expected GET got %s", req.Method), "invalid request method", http.StatusBadRequest, req.URL.Path, "", m)
 			return
 		}
 
-		bugIDStr := req.URL.Query().Get(BugIDQuery)
-		if bugIDStr == "" {
-			handleError(w, fmt.Errorf("missing mandatory query arg: \"ID\""), "missing mandatory query arg: \"ID\"", http.StatusBadRequest, req.URL.Path, 0, m)
-			return
-		}
-		bugID, err := strconv.Atoi(bugIDStr)
-		if err != nil {
-			handleError(w, err, "unable to convert \"ID\" from string to int", http.StatusBadRequest, req.URL.Path, 0, m)
+		bugID := req.URL.Query().Get(BugIDQuery)
+		if bugID == "" {
+			handleError(w, fmt.Errorf("missing mandatory query arg: \"ID\""), "missing mandatory query arg: \"ID\"", http.StatusBadRequest, req.URL.Path, "", m)
 			return
 		}
 
-		wrpr, statusCode, err := getClonesTemplateData(bugID, client, allTargetVersions)
+		wrpr, statusCode, err := getClonesTemplateData(bugID, tracker, allTargetVersions)
 		if err != nil {
 			handleError(w, err, "unable to get get bug details", statusCode, req.URL.Path, bugID, m)
 			return
@@ -624,32 +634,28 @@ func releaseInvalidErrorMsg(release string) string {
 }
 
 // CreateCloneHandler will create a clone of the specified ID and return success/error
-func CreateCloneHandler(client bugzilla.Client, sortedTargetReleases []string, m *metrics.Metrics) http.HandlerFunc {
+func CreateCloneHandler(tracker Tracker, sortedTargetReleases []string, m *metrics.Metrics) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		endpoint := req.URL.Path
 		if req.Method != "POST" {
-			handleError(w, fmt.Errorf("invalid request method, expected POST got %s", req.Method), "invalid request method", http.StatusBadRequest, req.URL.Path, 0, m)
+			handleError(w, fmt.Errorf("invalid request method, expected POST got %s", req.Method), "invalid request method", http.StatusBadRequest, req.URL.Path, "", m)
 			return
 		}
 		// Parse the parameters passed in the POST request
 		err := req.ParseForm()
 		if err != nil {
-			handleError(w, err, "unable to parse request", http.StatusBadRequest, req.URL.Path, 0, m)
+			handleError(w, err, "unable to parse request", http.StatusBadRequest, req.URL.Path, "", m)
 			return
 		}
-		if req.FormValue("ID") == "" {
-			handleError(w, fmt.Errorf("missing mandatory query arg: \"ID\""), "missing mandatory query arg: \"ID\"", http.StatusBadRequest, req.URL.Path, 0, m)
-			return
-		}
-		bugID, err := strconv.Atoi(req.FormValue("ID"))
-		if err != nil {
-			handleError(w, err, fmt.Sprintf("unable to convert \"ID\" parameter from string to int: %s", req.FormValue("ID")), http.StatusBadRequest, req.URL.Path, 0, m)
+		bugID := req.FormValue("ID")
+		if bugID == "" {
+			handleError(w, fmt.Errorf("missing mandatory query arg: \"ID\""), "missing mandatory query arg: \"ID\"", http.StatusBadRequest, req.URL.Path, "", m)
 			return
 		}
 		// Get the details of the bug
-		bug, err := client.GetBug(bugID)
+		bug, err := tracker.GetIssue(bugID)
 		if err != nil {
-			handleError(w, err, fmt.Sprintf("unable to fetch bug details- Bug#%d", bugID), http.StatusNotFound, endpoint, bugID, m)
+			handleError(w, err, fmt.Sprintf("unable to fetch bug details- Bug#%s", bugID), http.StatusNotFound, endpoint, bugID, m)
 			return
 		}
 		allTargetVersions := sets.NewString(sortedTargetReleases...)
@@ -660,9 +666,9 @@ func CreateCloneHandler(client bugzilla.Client, sortedTargetReleases []string, m
 		}
 
 		// Get clones and sort them
-		clones, err := client.GetAllClones(bug)
+		clones, err := tracker.GetAllClones(bug)
 		if err != nil {
-			handleError(w, err, fmt.Sprintf("unable to convert \"ID\" parameter from string to int: %s", req.FormValue("ID")), http.StatusBadRequest, req.URL.Path, 0, m)
+			handleError(w, err, fmt.Sprintf("unable to get clones- Bug#%s", bugID), http.StatusInternalServerError, endpoint, bugID, m)
 			return
 		}
 		sortByTargetRelease(clones)
@@ -671,7 +677,7 @@ func CreateCloneHandler(client bugzilla.Client, sortedTargetReleases []string, m
 			handleError(w, err, releaseInvalidErrorMsg(req.FormValue("release")), http.StatusBadRequest, endpoint, bugID, m)
 			return
 		}
-		var sourceBug *bugzilla.Bug
+		var sourceBug *Issue
 		var sourceBugMajorMinorRel string
 		for _, clone := range clones {
 			if !isTargetReleaseSet(clone) {
@@ -722,31 +728,26 @@ func CreateCloneHandler(client bugzilla.Client, sortedTargetReleases []string, m
 		// Find source bug and keep iterating till we hit the target release
 		for i := targetRelease; descMajorMinorRelease[i] >= toCloneMajorMinorRelease; i++ {
 			// Create a clone of the bug
-			cloneID, err := client.CloneBug(sourceBug)
+			cloneID, err := tracker.CloneIssue(sourceBug)
 			if err != nil {
 				handleError(w, err, "clone creation failed", http.StatusInternalServerError, endpoint, bugID, m)
 				return
 			}
-			updateTargetRelease := bugzilla.BugUpdate{
-				TargetRelease: []string{
-					descMajorMinorRelease[i] + ".z",
-				},
-			}
 			// Updating the cloned bug with the right target version
-			if err = client.UpdateBug(cloneID, updateTargetRelease); err != nil {
-				handleError(w, err, fmt.Sprintf("failed to update version for bug %d after creating it", cloneID), http.StatusInternalServerError, endpoint, bugID, m)
+			if err = tracker.SetTargetRelease(cloneID, descMajorMinorRelease[i]+".z"); err != nil {
+				handleError(w, err, fmt.Sprintf("failed to update version for bug %s after creating it", cloneID), http.StatusInternalServerError, endpoint, bugID, m)
 				return
 			}
-			sourceBug, err = client.GetBug(cloneID)
+			sourceBug, err = tracker.GetIssue(cloneID)
 			if err != nil {
-				handleError(w, err, fmt.Sprintf("failed to get bug details: %d", cloneID), http.StatusInternalServerError, endpoint, bugID, m)
+				handleError(w, err, fmt.Sprintf("failed to get bug details: %s", cloneID), http.StatusInternalServerError, endpoint, bugID, m)
 				return
 			}
-			newClones = append(newClones, strconv.Itoa(cloneID))
+			newClones = append(newClones, cloneID)
 		}
 
 		// Repopulate the fields of the page with the right data
-		data, statusCode, err := getClonesTemplateData(bugID, client, sortedTargetReleases)
+		data, statusCode, err := getClonesTemplateData(bugID, tracker, sortedTargetReleases)
 		if err != nil {
 			handleError(w, err, "unable to get get bug details", statusCode, endpoint, bugID, m)
 			return
@@ -764,12 +765,12 @@ func CreateCloneHandler(client bugzilla.Client, sortedTargetReleases []string, m
 func GetHelpHandler(m *metrics.Metrics) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != "GET" {
-			handleError(w, fmt.Errorf("invalid request method, expected GET got %s", req.Method), "invalid request method", http.StatusBadRequest, req.URL.Path, 0, m)
+			handleError(w, fmt.Errorf("invalid request method, expected GET got %s", req.Method), "invalid request method", http.StatusBadRequest, req.URL.Path, "", m)
 			return
 		}
 		err := writePage(w, "Help", helpTemplate, nil)
 		if err != nil {
-			handleError(w, err, "failed to build response page", http.StatusInternalServerError, req.URL.Path, 0, m)
+			handleError(w, err, "failed to build response page", http.StatusInternalServerError, req.URL.Path, "", m)
 		}
 	}
 }
@@ -21,6 +21,11 @@ var (
 
 var fakebzbpMetrics = metrics.NewMetrics("fakebzbp")
 
+// issueFor converts a bug the way the Bugzilla tracker does for the fake
+func issueFor(bug *bugzilla.Bug) *Issue {
+	return (&bugzillaTracker{client: &bugzilla.Fake{}}).issueFor(bug)
+}
+
 func TestGetLandingHandler(t *testing.T) {
 	req, err := http.NewRequest("GET", "/", nil)
 	if err != nil {
@@ -87,7 +92,7 @@ func TestGetBugHandler(t *testing.T) {
 			}
 			req.URL.RawQuery = q.Encode()
 			rr := httptest.NewRecorder()
-			handler := GetBugHandler(fake, fakebzbpMetrics)
+			handler := GetBugHandler(NewBugzillaTracker(fake), fakebzbpMetrics)
 			handler.ServeHTTP(rr, req)
 			if status := rr.Code; status != tc.statusCode {
 				t.Errorf("testcase '%v' failed: getbug returned wrong status code - got %v, want %v", tc.name, status, tc.statusCode)
@@ -147,17 +152,17 @@ func TestGetClonesHandler(t *testing.T) {
 			},
 			statusCode: http.StatusOK,
 			data: ClonesTemplateData{
-				clone,
-				[]*bugzilla.Bug{
-					clone,
-					toBeCloned,
+				issueFor(clone),
+				[]*Issue{
+					issueFor(clone),
+					issueFor(toBeCloned),
 				},
-				toBeCloned,
+				issueFor(toBeCloned),
 				nil,
 				[]string{"4.2.z"},
 				[]string{},
 				[]string{},
-				&dependenceNode{toBeClonedID, "4.4.0", []*dependenceNode{{cloneID, "4.3.z", nil}}},
+				&dependenceNode{strconv.Itoa(toBeClonedID), "4.4.0", []*dependenceNode{{strconv.Itoa(cloneID), "4.3.z", nil}}},
 			},
 			tmplt:             clonesTemplate,
 			allTargetVersions: []string{"4.2.z", "4.3.z", "4.4.z", "4.5.z", "4.6.0"},
@@ -185,7 +190,7 @@ func TestGetClonesHandler(t *testing.T) {
 			}
 			req.URL.RawQuery = q.Encode()
 			rr := httptest.NewRecorder()
-			handler := GetClonesHandler(fake, tc.allTargetVersions, fakebzbpMetrics)
+			handler := GetClonesHandler(NewBugzillaTracker(fake), tc.allTargetVersions, fakebzbpMetrics)
 			handler.ServeHTTP(rr, req)
 			if status := rr.Code; status != tc.statusCode {
 				t.Errorf("testcase '%v' failed: getbug returned wrong status code - got %v, want %v", tc, status, tc.statusCode)
@@ -261,13 +266,13 @@ func TestCreateCloneHandler(t *testing.T) {
 			},
 			statusCode: http.StatusOK,
 			data: ClonesTemplateData{
-				Bug:            toBeCloned,
-				Clones:         []*bugzilla.Bug{&intermediateClone, toBeCloned},
-				Parent:         toBeCloned,
+				Bug:            issueFor(toBeCloned),
+				Clones:         []*Issue{issueFor(&intermediateClone), issueFor(toBeCloned)},
+				Parent:         issueFor(toBeCloned),
 				PRs:            nil,
 				CloneTargets:   []string{"4.2.z", "4.3.z"},
 				NewCloneIDs:    []string{strconv.Itoa(toBeClonedID + 1)},
-				DependenceTree: &dependenceNode{toBeClonedID, originalTargetRelease, []*dependenceNode{{intermediateClone.ID, intermediateRelease, nil}}},
+				DependenceTree: &dependenceNode{strconv.Itoa(toBeClonedID), originalTargetRelease, []*dependenceNode{{strconv.Itoa(intermediateClone.ID), intermediateRelease, nil}}},
 			},
 			tmplt:     clonesTemplate,
 			pageTitle: "Clones",
@@ -296,13 +301,13 @@ func TestCreateCloneHandler(t *testing.T) {
 			},
 			statusCode: http.StatusOK,
 			data: ClonesTemplateData{
-				Bug:            toBeCloned,
-				Clones:         []*bugzilla.Bug{&expectedClone, &intermediateClone, toBeCloned},
-				Parent:         toBeCloned,
+				Bug:            issueFor(toBeCloned),
+				Clones:         []*Issue{issueFor(&expectedClone), issueFor(&intermediateClone), issueFor(toBeCloned)},
+				Parent:         issueFor(toBeCloned),
 				PRs:            nil,
 				CloneTargets:   []string{"4.2.z"},
 				NewCloneIDs:    []string{strconv.Itoa(toBeClonedID + 1), strconv.Itoa(toBeClonedID + 2)},
-				DependenceTree: &dependenceNode{toBeClonedID, originalTargetRelease, []*dependenceNode{{intermediateClone.ID, intermediateRelease, []*dependenceNode{{expectedClone.ID, clonedRelease, nil}}}}},
+				DependenceTree: &dependenceNode{strconv.Itoa(toBeClonedID), originalTargetRelease, []*dependenceNode{{strconv.Itoa(intermediateClone.ID), intermediateRelease, []*dependenceNode{{strconv.Itoa(expectedClone.ID), clonedRelease, nil}}}}},
 			},
 			tmplt:     clonesTemplate,
 			pageTitle: "Clones",
@@ -324,7 +329,7 @@ func TestCreateCloneHandler(t *testing.T) {
 			}
 			rr := httptest.NewRecorder()
 			fake.Bugs = tc.existingBugs
-			handler := CreateCloneHandler(fake, tc.allTargetVersions, fakebzbpMetrics)
+			handler := CreateCloneHandler(NewBugzillaTracker(fake), tc.allTargetVersions, fakebzbpMetrics)
 			handler.ServeHTTP(rr, req)
 			if status := rr.Code; status != tc.statusCode {
 				t.Errorf("testcase '%v' failed: clonebug returned wrong status code - got %v, want %v", tc, status, tc.statusCode)
@@ -0,0 +1,130 @@
+package backporter
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/test-infra/prow/bugzilla"
+)
+
+// bugzillaTracker serves the backporter from Bugzilla, where clones
+// are bugs with the same summary which depend on the bug they clone
+type bugzillaTracker struct {
+	client bugzilla.Client
+}
+
+var _ Tracker = &bugzillaTracker{}
+
+// NewBugzillaTracker returns a Tracker backed by the Bugzilla client
+func NewBugzillaTracker(client bugzilla.Client) Tracker {
+	return &bugzillaTracker{client: client}
+}
+
+func (t *bugzillaTracker) issueFor(bug *bugzilla.Bug) *Issue {
+	return &Issue{
+		ID:            strconv.Itoa(bug.ID),
+		Summary:       bug.Summary,
+		Status:        bug.Status,
+		TargetRelease: bug.TargetRelease,
+		URL:           fmt.Sprintf("%s/show_bug.cgi?id=%d", t.client.Endpoint(), bug.ID),
+	}
+}
+
+func (t *bugzillaTracker) issuesFor(bugs []*bugzilla.Bug) []*Issue {
+	var issues []*Issue
+	for _, bug := range bugs {
+		issues = append(issues, t.issueFor(bug))
+	}
+	return issues
+}
+
+func parseBugID(id string) (int, error) {
+	bugID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bug ID %q: %w", id, err)
+	}
+	return bugID, nil
+}
+
+func (t *bugzillaTracker) getBug(id string) (*bugzilla.Bug, error) {
+	bugID, err := parseBugID(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.client.GetBug(bugID)
+}
+
+func (t *bugzillaTracker) GetIssue(id string) (*Issue, error) {
+	bug, err := t.getBug(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.issueFor(bug), nil
+}
+
+func (t *bugzillaTracker) GetPRs(id string) ([]PullRequest, error) {
+	bugID, err := parseBugID(id)
+	if err != nil {
+		return nil, err
+	}
+	externalBugs, err := t.client.GetExternalBugPRsOnBug(bugID)
+	if err != nil {
+		return nil, err
+	}
+	var prs []PullRequest
+	for _, pr := range externalBugs {
+		prs = append(prs, PullRequest{
+			Org:  pr.Org,
+			Repo: pr.Repo,
+			Num:  pr.Num,
+			URL:  fmt.Sprintf("%s/%s/%s/pull/%d", pr.Type.URL, pr.Org, pr.Repo, pr.Num),
+		})
+	}
+	return prs, nil
+}
+
+func (t *bugzillaTracker) GetClones(issue *Issue) ([]*Issue, error) {
+	bug, err := t.getBug(issue.ID)
+	if err != nil {
+		return nil, err
+	}
+	clones, err := t.client.GetClones(bug)
+	if err != nil {
+		return nil, err
+	}
+	return t.issuesFor(clones), nil
+}
+
+func (t *bugzillaTracker) GetAllClones(issue *Issue) ([]*Issue, error) {
+	bug, err := t.getBug(issue.ID)
+	if err != nil {
+		return nil, err
+	}
+	// clones are sorted by ID, so the original bug comes first
+	clones, err := t.client.GetAllClones(bug)
+	if err != nil {
+		return nil, err
+	}
+	return t.issuesFor(clones), nil
+}
+
+func (t *bugzillaTracker) CloneIssue(issue *Issue) (string, error) {
+	bug, err := t.getBug(issue.ID)
+	if err != nil {
+		return "", err
+	}
+	// the clone depends on the original bug, so the original blocks it
+	cloneID, err := t.client.CloneBug(bug)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(cloneID), nil
+}
+
+func (t *bugzillaTracker) SetTargetRelease(id, release string) error {
+	bugID, err := parseBugID(id)
+	if err != nil {
+		return err
+	}
+	return t.client.UpdateBug(bugID, bugzilla.BugUpdate{TargetRelease: []string{release}})
+}
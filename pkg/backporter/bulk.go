@@ -0,0 +1,262 @@
+package backporter
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/test-infra/prow/metrics"
+)
+
+const bulkTemplateConstructor = `
+<div class="container">
+	<h2> {{.Bug.Summary}} </h2>
+	<h4 id="plan"> <a href ="#plan"> Clones to create</a> </h4>
+	<p>
+	The following clones will be created in this order. Each clone depends on the bug it is cloned from,
+	which in turn blocks it, and targets the release listed below.
+	</p>
+	<table class="table">
+		<thead>
+			<tr>
+				<th title="Targeted version to release fix" class="info">Target Release</th>
+				<th title="Bug the clone is created from and which blocks it" class="info">Cloned From</th>
+				<th title="Why the clone is created" class="info">Reason</th>
+			</tr>
+		</thead>
+		<tbody>
+		{{ range $clone := .Plan }}
+			<tr>
+				<td style="vertical-align: middle;">{{ $clone.TargetRelease }}</td>
+				<td style="vertical-align: middle;">
+				{{ if $clone.Parent }}
+					<a href = "/clones?ID={{ $clone.Parent.ID }}">Bug {{ $clone.Parent.ID }}</a> ({{ $clone.ParentRelease }})
+				{{ else }}
+					New clone ({{ $clone.ParentRelease }})
+				{{ end }}
+				</td>
+				<td style="vertical-align: middle;">{{ if $clone.Requested }}Requested{{ else }}Needed to complete the dependency chain{{ end }}</td>
+			</tr>
+		{{ end }}
+		</tbody>
+	</table>
+	<form class="form-inline my-2 my-lg-0" action="/clones/bulk" method="post">
+		<input type="hidden" name="ID" value="{{.Bug.ID}}">
+		{{ range $release := .Releases }}
+			<input type="hidden" name="release" value="{{$release}}">
+		{{ end }}
+		<button class="btn btn-outline-success my-2 my-sm-0" type="submit">Create Clones</button>
+		<a class="btn btn-outline-secondary my-2 my-sm-0 ml-2" href="/clones?ID={{.Bug.ID}}">Cancel</a>
+	</form>
+</div>`
+
+var bulkTemplate = template.Must(template.New("bulk").Parse(bulkTemplateConstructor))
+
+// BulkTemplateData holds the UI data for the bulk clone preview page
+type BulkTemplateData struct {
+	Bug      *Issue         // bug details
+	Plan     []plannedClone // Clones which will be created, in order
+	Releases []string       // Target releases which were requested
+}
+
+// plannedClone is a clone which needs to be created to backport a bug
+type plannedClone struct {
+	// TargetRelease is the release the clone will target
+	TargetRelease string
+	// Parent is the existing bug the clone is created from, or nil
+	// when it is cloned from a clone created earlier in the plan
+	Parent *Issue
+	// ParentRelease is the target release of the bug the clone is created from
+	ParentRelease string
+	// Requested is false for clones which were not asked for but are
+	// needed so that the dependency chain has no gaps
+	Requested bool
+}
+
+// planClones determines which clones need to be created, and in which order,
+// to backport a bug to the requested releases. Every release between a
+// requested one and the closest higher release with a clone gets a clone,
+// so each clone is created from the clone targeting the next higher release.
+func planClones(clones []*Issue, sortedTargetReleases []string, requested []string) ([]plannedClone, error) {
+	existing := map[string]*Issue{}
+	for _, clone := range clones {
+		if !isTargetReleaseSet(clone) {
+			continue
+		}
+		majorMinorRelease, err := getMajorMinorRelease(clone.TargetRelease[0])
+		if err != nil {
+			return nil, errors.New(releaseInvalidErrorMsg(clone.TargetRelease[0]))
+		}
+		if _, exists := existing[majorMinorRelease]; !exists {
+			existing[majorMinorRelease] = clone
+		}
+	}
+
+	var ascMajorMinorRelease []string
+	seen := sets.NewString()
+	for _, release := range sortedTargetReleases {
+		majorMinorRelease, err := getMajorMinorRelease(release)
+		if err != nil {
+			return nil, errors.New(releaseInvalidErrorMsg(release))
+		}
+		if !seen.Has(majorMinorRelease) {
+			seen.Insert(majorMinorRelease)
+			ascMajorMinorRelease = append(ascMajorMinorRelease, majorMinorRelease)
+		}
+	}
+
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("at least one target release must be selected")
+	}
+	allTargetVersions := sets.NewString(sortedTargetReleases...)
+	requestedMajorMinor := sets.NewString()
+	for _, release := range requested {
+		if !allTargetVersions.Has(release) {
+			return nil, fmt.Errorf("invalid argument - %s is not a valid TargetRelease, must be one of %v", release, allTargetVersions.List())
+		}
+		majorMinorRelease, err := getMajorMinorRelease(release)
+		if err != nil {
+			return nil, errors.New(releaseInvalidErrorMsg(release))
+		}
+		if clone, exists := existing[majorMinorRelease]; exists {
+			return nil, fmt.Errorf("clone for major release %s already exists: Bug#%s", clone.TargetRelease[0], clone.ID)
+		}
+		requestedMajorMinor.Insert(majorMinorRelease)
+	}
+
+	// walk up from every requested release to the closest one with a clone
+	needed := sets.NewString()
+	for i, majorMinorRelease := range ascMajorMinorRelease {
+		if !requestedMajorMinor.Has(majorMinorRelease) {
+			continue
+		}
+		j := i
+		for ; j < len(ascMajorMinorRelease) && existing[ascMajorMinorRelease[j]] == nil; j++ {
+			needed.Insert(ascMajorMinorRelease[j])
+		}
+		if j == len(ascMajorMinorRelease) {
+			return nil, fmt.Errorf("one bug with greater release than %s needs to be present to clone from", majorMinorRelease)
+		}
+	}
+
+	// walk down the releases so that every parent exists before its clones
+	var plan []plannedClone
+	var parent *Issue
+	var parentRelease string
+	for i := len(ascMajorMinorRelease) - 1; i >= 0; i-- {
+		majorMinorRelease := ascMajorMinorRelease[i]
+		if clone, exists := existing[majorMinorRelease]; exists {
+			parent, parentRelease = clone, clone.TargetRelease[0]
+			continue
+		}
+		if !needed.Has(majorMinorRelease) {
+			continue
+		}
+		targetRelease := majorMinorRelease + ".z"
+		plan = append(plan, plannedClone{
+			TargetRelease: targetRelease,
+			Parent:        parent,
+			ParentRelease: parentRelease,
+			Requested:     requestedMajorMinor.Has(majorMinorRelease),
+		})
+		parent, parentRelease = nil, targetRelease
+	}
+	return plan, nil
+}
+
+// createClones creates the planned clones in order, returning the IDs
+// of the clones created, even if a later step of the plan failed
+func createClones(tracker Tracker, plan []plannedClone) ([]string, error) {
+	var created []string
+	var parent *Issue
+	for _, clone := range plan {
+		if clone.Parent != nil {
+			var err error
+			if parent, err = tracker.GetIssue(clone.Parent.ID); err != nil {
+				return created, fmt.Errorf("failed to get bug details: %s: %w", clone.Parent.ID, err)
+			}
+		}
+		cloneID, err := tracker.CloneIssue(parent)
+		if err != nil {
+			return created, fmt.Errorf("clone creation for %s failed: %w", clone.TargetRelease, err)
+		}
+		created = append(created, cloneID)
+		if err := tracker.SetTargetRelease(cloneID, clone.TargetRelease); err != nil {
+			return created, fmt.Errorf("failed to update version for bug %s after creating it: %w", cloneID, err)
+		}
+		if parent, err = tracker.GetIssue(cloneID); err != nil {
+			return created, fmt.Errorf("failed to get bug details: %s: %w", cloneID, err)
+		}
+	}
+	return created, nil
+}
+
+// BulkCloneHandler previews (GET) or creates (POST) the whole chain of clones
+// needed to backport a bug to the selected target releases
+func BulkCloneHandler(tracker Tracker, sortedTargetReleases []string, m *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		endpoint := req.URL.Path
+		if req.Method != "GET" && req.Method != "POST" {
+			handleError(w, fmt.Errorf("invalid request method, expected GET or POST got %s", req.Method), "invalid request method", http.StatusBadRequest, endpoint, "", m)
+			return
+		}
+		if err := req.ParseForm(); err != nil {
+			handleError(w, err, "unable to parse request", http.StatusBadRequest, endpoint, "", m)
+			return
+		}
+		bugID := req.FormValue(BugIDQuery)
+		if bugID == "" {
+			handleError(w, fmt.Errorf("missing mandatory query arg: \"ID\""), "missing mandatory query arg: \"ID\"", http.StatusBadRequest, endpoint, "", m)
+			return
+		}
+		bug, err := tracker.GetIssue(bugID)
+		if err != nil {
+			handleError(w, err, fmt.Sprintf("unable to fetch bug details- Bug#%s", bugID), http.StatusNotFound, endpoint, bugID, m)
+			return
+		}
+		clones, err := tracker.GetAllClones(bug)
+		if err != nil {
+			handleError(w, err, fmt.Sprintf("unable to get clones- Bug#%s", bugID), http.StatusInternalServerError, endpoint, bugID, m)
+			return
+		}
+		releases := req.Form["release"]
+		// the plan is recomputed on submission, in case clones were created since the preview
+		plan, err := planClones(clones, sortedTargetReleases, releases)
+		if err != nil {
+			handleError(w, err, err.Error(), http.StatusBadRequest, endpoint, bugID, m)
+			return
+		}
+
+		if req.Method == "GET" {
+			if err := writePage(w, "Create Clones", bulkTemplate, BulkTemplateData{Bug: bug, Plan: plan, Releases: releases}); err != nil {
+				handleError(w, err, "failed to build Create Clones page", http.StatusInternalServerError, endpoint, bugID, m)
+			}
+			return
+		}
+
+		newClones, err := createClones(tracker, plan)
+		if err != nil {
+			msg := "clone creation failed"
+			if len(newClones) > 0 {
+				msg = fmt.Sprintf("clone creation failed after creating %s", strings.Join(newClones, ", "))
+			}
+			handleError(w, err, msg, http.StatusInternalServerError, endpoint, bugID, m)
+			return
+		}
+
+		// Repopulate the fields of the page with the right data
+		data, statusCode, err := getClonesTemplateData(bugID, tracker, sortedTargetReleases)
+		if err != nil {
+			handleError(w, err, "unable to get get bug details", statusCode, endpoint, bugID, m)
+			return
+		}
+		// Populating the NewCloneId which is used to show the success info banner
+		data.NewCloneIDs = newClones
+		if err := writePage(w, "Clones", clonesTemplate, *data); err != nil {
+			handleError(w, err, "failed to build CreateClones response page", http.StatusInternalServerError, endpoint, bugID, m)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package backporter
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/test-infra/prow/bugzilla"
+	"k8s.io/utils/diff"
+)
+
+func TestPlanClones(t *testing.T) {
+	allTargetVersions := []string{"4.2.z", "4.3.z", "4.4.z", "4.5.z", "4.6.0", "4.6.z"}
+	original := &Issue{ID: "1", TargetRelease: []string{"4.6.0"}}
+	clone := &Issue{ID: "2", TargetRelease: []string{"4.4.z"}}
+	testCases := []struct {
+		name          string
+		clones        []*Issue
+		requested     []string
+		expected      []plannedClone
+		expectedError string
+	}{
+		{
+			name:      "single release right below the original",
+			clones:    []*Issue{original},
+			requested: []string{"4.5.z"},
+			expected: []plannedClone{
+				{TargetRelease: "4.5.z", Parent: original, ParentRelease: "4.6.0", Requested: true},
+			},
+		},
+		{
+			name:      "intermediate releases are filled in",
+			clones:    []*Issue{original},
+			requested: []string{"4.3.z"},
+			expected: []plannedClone{
+				{TargetRelease: "4.5.z", Parent: original, ParentRelease: "4.6.0"},
+				{TargetRelease: "4.4.z", ParentRelease: "4.5.z"},
+				{TargetRelease: "4.3.z", ParentRelease: "4.4.z", Requested: true},
+			},
+		},
+		{
+			name:      "several releases are created highest first",
+			clones:    []*Issue{original},
+			requested: []string{"4.2.z", "4.5.z", "4.4.z", "4.3.z"},
+			expected: []plannedClone{
+				{TargetRelease: "4.5.z", Parent: original, ParentRelease: "4.6.0", Requested: true},
+				{TargetRelease: "4.4.z", ParentRelease: "4.5.z", Requested: true},
+				{TargetRelease: "4.3.z", ParentRelease: "4.4.z", Requested: true},
+				{TargetRelease: "4.2.z", ParentRelease: "4.3.z", Requested: true},
+			},
+		},
+		{
+			name:      "existing clones are cloned from and gaps above them are left alone",
+			clones:    []*Issue{clone, original},
+			requested: []string{"4.2.z"},
+			expected: []plannedClone{
+				{TargetRelease: "4.3.z", Parent: clone, ParentRelease: "4.4.z"},
+				{TargetRelease: "4.2.z", ParentRelease: "4.3.z", Requested: true},
+			},
+		},
+		{
+			name:          "nothing requested",
+			clones:        []*Issue{original},
+			expectedError: "at least one target release must be selected",
+		},
+		{
+			name:          "unknown release",
+			clones:        []*Issue{original},
+			requested:     []string{"3.11.z"},
+			expectedError: "invalid argument - 3.11.z is not a valid TargetRelease, must be one of [4.2.z 4.3.z 4.4.z 4.5.z 4.6.0 4.6.z]",
+		},
+		{
+			name:          "release which already has a clone",
+			clones:        []*Issue{clone, original},
+			requested:     []string{"4.3.z", "4.4.z"},
+			expectedError: "clone for major release 4.4.z already exists: Bug#2",
+		},
+		{
+			name:          "no higher release to clone from",
+			clones:        []*Issue{clone},
+			requested:     []string{"4.5.z"},
+			expectedError: "one bug with greater release than 4.5 needs to be present to clone from",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := planClones(tc.clones, allTargetVersions, tc.requested)
+			var actualError string
+			if err != nil {
+				actualError = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedError, actualError); diff != "" {
+				t.Errorf("got incorrect error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, plan); diff != "" {
+				t.Errorf("got incorrect plan: %s", diff)
+			}
+		})
+	}
+}
+
+func TestBulkCloneHandler(t *testing.T) {
+	allTargetVersions := []string{"4.2.z", "4.3.z", "4.4.z", "4.5.z", "4.6.0"}
+	newFake := func(t *testing.T) (*bugzilla.Fake, int) {
+		fake := &bugzilla.Fake{Bugs: map[int]bugzilla.Bug{}, BugComments: map[int][]bugzilla.Comment{}}
+		id, err := fake.CreateBug(&bugzilla.BugCreate{Summary: "Sample bug to test bulk clone creation"})
+		if err != nil {
+			t.Fatalf("error creating bug: %v", err)
+		}
+		if err := fake.UpdateBug(id, bugzilla.BugUpdate{TargetRelease: []string{"4.6.0"}}); err != nil {
+			t.Fatalf("error while updating bug: %v", err)
+		}
+		return fake, id
+	}
+	request := func(t *testing.T, method string, id int, releases ...string) *http.Request {
+		formData := url.Values{"ID": []string{strconv.Itoa(id)}, "release": releases}
+		var req *http.Request
+		var err error
+		if method == "GET" {
+			req, err = http.NewRequest(method, "/clones/bulk?"+formData.Encode(), nil)
+		} else {
+			req, err = http.NewRequest(method, "/clones/bulk", bytes.NewBufferString(formData.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	t.Run("preview lists the clones without creating them", func(t *testing.T) {
+		fake, id := newFake(t)
+		rr := httptest.NewRecorder()
+		BulkCloneHandler(NewBugzillaTracker(fake), allTargetVersions, fakebzbpMetrics).ServeHTTP(rr, request(t, "GET", id, "4.4.z", "4.2.z"))
+		if rr.Code != http.StatusOK {
+			t.Errorf("got wrong status code - got %v, want %v", rr.Code, http.StatusOK)
+		}
+		bug, err := fake.GetBug(id)
+		if err != nil {
+			t.Fatalf("error getting bug: %v", err)
+		}
+		issue := issueFor(bug)
+		var buf bytes.Buffer
+		if err := bulkTemplate.Execute(&buf, BulkTemplateData{
+			Bug: issue,
+			Plan: []plannedClone{
+				{TargetRelease: "4.5.z", Parent: issue, ParentRelease: "4.6.0"},
+				{TargetRelease: "4.4.z", ParentRelease: "4.5.z", Requested: true},
+				{TargetRelease: "4.3.z", ParentRelease: "4.4.z"},
+				{TargetRelease: "4.2.z", ParentRelease: "4.3.z", Requested: true},
+			},
+			Releases: []string{"4.4.z", "4.2.z"},
+		}); err != nil {
+			t.Fatalf("unable to render template: %v", err)
+		}
+		expected := fmt.Sprintf(htmlPageStart, "Create Clones") + buf.String() + htmlPageEnd
+		if resp := rr.Body.String(); resp != expected {
+			t.Errorf("response differs from expected by: %s", diff.StringDiff(resp, expected))
+		}
+		if len(fake.Bugs) != 1 {
+			t.Errorf("expected no clones to be created, got %d bugs", len(fake.Bugs))
+		}
+	})
+
+	t.Run("submission creates the chain of clones", func(t *testing.T) {
+		fake, id := newFake(t)
+		rr := httptest.NewRecorder()
+		BulkCloneHandler(NewBugzillaTracker(fake), allTargetVersions, fakebzbpMetrics).ServeHTTP(rr, request(t, "POST", id, "4.4.z", "4.3.z"))
+		if rr.Code != http.StatusOK {
+			t.Errorf("got wrong status code - got %v, want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		type link struct {
+			TargetRelease []string
+			DependsOn     []int
+			Blocks        []int
+		}
+		expected := map[int]link{
+			id:     {TargetRelease: []string{"4.6.0"}, Blocks: []int{id + 1}},
+			id + 1: {TargetRelease: []string{"4.5.z"}, DependsOn: []int{id}, Blocks: []int{id + 2}},
+			id + 2: {TargetRelease: []string{"4.4.z"}, DependsOn: []int{id + 1}, Blocks: []int{id + 3}},
+			id + 3: {TargetRelease: []string{"4.3.z"}, DependsOn: []int{id + 2}},
+		}
+		actual := map[int]link{}
+		for bugID, bug := range fake.Bugs {
+			actual[bugID] = link{TargetRelease: bug.TargetRelease, DependsOn: bug.DependsOn, Blocks: bug.Blocks}
+		}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Errorf("got incorrect clones: %s", diff)
+		}
+	})
+
+	t.Run("submission for a release which already has a clone fails", func(t *testing.T) {
+		fake, id := newFake(t)
+		rr := httptest.NewRecorder()
+		BulkCloneHandler(NewBugzillaTracker(fake), allTargetVersions, fakebzbpMetrics).ServeHTTP(rr, request(t, "POST", id, "4.6.0"))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("got wrong status code - got %v, want %v", rr.Code, http.StatusBadRequest)
+		}
+		if len(fake.Bugs) != 1 {
+			t.Errorf("expected no clones to be created, got %d bugs", len(fake.Bugs))
+		}
+	})
+}
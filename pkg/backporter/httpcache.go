@@ -24,6 +24,8 @@ const (
 type bugzillaCache struct {
 	lock  sync.Mutex
 	cache map[string][]byte
+	// version is bumped every time the cache is invalidated
+	version int
 }
 
 func (bc *bugzillaCache) get(key string) ([]byte, bool) {
@@ -39,6 +41,40 @@ func (bc *bugzillaCache) set(key string, respBytes []byte) {
 	bc.cache[key] = respBytes
 }
 
+func (bc *bugzillaCache) generation() int {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	return bc.version
+}
+
+// setSince stores the response unless the cache was invalidated after the
+// generation, as the response may then predate a write
+func (bc *bugzillaCache) setSince(generation int, key string, respBytes []byte) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	if bc.version == generation {
+		bc.cache[key] = respBytes
+	}
+}
+
+// invalidate drops all cached responses
+func (bc *bugzillaCache) invalidate() {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	bc.cache = map[string][]byte{}
+	bc.version++
+}
+
+func (bc *bugzillaCache) keys() []string {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	var keys []string
+	for key := range bc.cache {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 var _ cache = &bugzillaCache{}
 
 func newBugzillaCache() *bugzillaCache {
@@ -48,6 +84,9 @@ func newBugzillaCache() *bugzillaCache {
 type cache interface {
 	get(string) ([]byte, bool)
 	set(string, []byte)
+	generation() int
+	setSince(int, string, []byte)
+	invalidate()
 }
 
 // cachingTransport is an implementation http.RoundTripper
@@ -58,11 +97,15 @@ type cachingTransport struct {
 }
 
 // RoundTrip will first check if there are any cached responses and return that
-// if not it will make an HTTP call using the DefaultTransport
+// if not it will make an HTTP call using the upstream transport. Any other
+// request than a GET may write to the issue tracker, so it invalidates the
+// cache and the reads that follow it go to the issue tracker.
 func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	if req.Method != "GET" {
+		defer t.cache.invalidate()
 		return t.transport.RoundTrip(req)
 	}
+	generation := t.cache.generation()
 	var resp *http.Response
 	g := errgroup.Group{}
 	g.Go(func() error {
@@ -79,7 +122,7 @@ func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 			if err != nil {
 				return fmt.Errorf("err while serializing response to cache: %w", err)
 			}
-			t.cache.set(req.URL.String(), body)
+			t.cache.setSince(generation, req.URL.String(), body)
 		}
 
 		return nil
@@ -100,19 +143,20 @@ func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	return resp, nil
 }
 
-func refreshCache(bc *bugzillaCache, m prometheus.Gauge) {
+func refreshCache(bc *bugzillaCache, client *http.Client, m prometheus.Gauge) {
 	var sem = semaphore.NewWeighted(int64(10))
 	ctx := context.Background()
-	logrus.WithField("cache_entries", len(bc.cache)).Info("Refreshing cache")
-	m.Set(float64(len(bc.cache)))
-	for url := range bc.cache {
+	generation, urls := bc.generation(), bc.keys()
+	logrus.WithField("cache_entries", len(urls)).Info("Refreshing cache")
+	m.Set(float64(len(urls)))
+	for _, url := range urls {
 		if err := sem.Acquire(ctx, 1); err != nil {
 			logrus.WithError(fmt.Errorf("failed to acquire semaphore for key %s: %w", url, err))
 		}
 		url := url
 		go func() {
 			defer sem.Release(1)
-			resp, err := http.Get(url)
+			resp, err := client.Get(url)
 			if err != nil {
 				logrus.WithError(fmt.Errorf("cache refresh error - failed to fetch %s: %w", url, err))
 				return
@@ -124,22 +168,23 @@ func refreshCache(bc *bugzillaCache, m prometheus.Gauge) {
 					logrus.WithError(fmt.Errorf("cache refresh error - DumpResponse failed %s: %w", url, err))
 					return
 				}
-				bc.set(url, body)
+				bc.setSince(generation, url, body)
 			}
 		}()
 	}
 
 }
 
-// NewCachingTransport is a constructor for cachingTransport
+// NewCachingTransport is a constructor for cachingTransport in front of the upstream transport
 // If an entry is present in the cache, it is immediately returned
-// while also generating an async HTTP call to the bugzilla server to get the latest value
+// while also generating an async HTTP call to the issue tracker to get the latest value
 // which is stored in the cache.
 // Therefore this cache does *NOT* reduce the HTTP traffic, and is only used to speed up the response.
-func NewCachingTransport() http.RoundTripper {
+// Refreshing the cache goes through the upstream transport too, so it may authenticate requests.
+func NewCachingTransport(upstream http.RoundTripper) http.RoundTripper {
 	t := cachingTransport{
 		cache:     newBugzillaCache(),
-		transport: http.DefaultTransport,
+		transport: upstream,
 	}
 	cacheRefreshMetrics := prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -153,7 +198,7 @@ func NewCachingTransport() http.RoundTripper {
 		defer ticker.Stop()
 		for {
 			<-ticker.C
-			refreshCache(t.cache.(*bugzillaCache), cacheRefreshMetrics)
+			refreshCache(t.cache.(*bugzillaCache), &http.Client{Transport: upstream}, cacheRefreshMetrics)
 		}
 	}()
 	return &t
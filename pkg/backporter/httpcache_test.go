@@ -207,3 +207,43 @@ func (c *accessTrackingCache) get(key string) ([]byte, bool) {
 	c.accessCounter++
 	return c.cache.get(key)
 }
+
+func TestRoundTripInvalidatesOnWrite(t *testing.T) {
+	resp := &http.Response{
+		Status:     http.StatusText(http.StatusOK),
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBuffer([]byte("body text"))),
+	}
+	body, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		t.Fatalf("failed to serialize dummy response: %v", err)
+	}
+	cache := &bugzillaCache{cache: map[string][]byte{"http://somewhere.com/": body}}
+	before := cache.generation()
+	tp := cachingTransport{
+		cache:     cache,
+		transport: fakeTransport{response: &http.Response{StatusCode: http.StatusCreated, Body: ioutil.NopCloser(&bytes.Buffer{})}},
+	}
+	r, err := http.NewRequest("POST", "http://somewhere.com/", nil)
+	if err != nil {
+		t.Fatalf("failed to make request to fake server: %v", err)
+	}
+	resp, err = tp.RoundTrip(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if _, isCached := cache.get("http://somewhere.com/"); isCached {
+		t.Error("expected the write to invalidate the cached response")
+	}
+
+	// a response to a read that started before the write must not be cached
+	cache.setSince(before, "http://somewhere.com/", body)
+	if _, isCached := cache.get("http://somewhere.com/"); isCached {
+		t.Error("expected a response from before the write not to be cached")
+	}
+	cache.setSince(cache.generation(), "http://somewhere.com/", body)
+	if _, isCached := cache.get("http://somewhere.com/"); !isCached {
+		t.Error("expected a response from after the write to be cached")
+	}
+}
@@ -0,0 +1,298 @@
+package backporter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+
+	jirautil "k8s.io/test-infra/prow/jira"
+)
+
+const (
+	// linkTypeCloners is the Jira issue link type between a clone and the
+	// issue it was cloned from: the clone "clones" the original issue
+	linkTypeCloners = "Cloners"
+	// linkTypeBlocks is the Jira issue link type between an issue and the
+	// issues it blocks: the original issue "blocks" its clones
+	linkTypeBlocks = "Blocks"
+)
+
+var pullRequestURL = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/pull/(\d+)$`)
+
+// this adapter is needed since none of the upstream types
+// are interfaces and they hold mutually ambiguous methods
+type jiraAdapter struct {
+	delegate *jira.Client
+}
+
+func (a *jiraAdapter) GetIssue(id string) (*jira.Issue, *jira.Response, error) {
+	return a.delegate.Issue.Get(id, nil)
+}
+
+func (a *jiraAdapter) GetRemoteLinks(id string) (*[]jira.RemoteLink, *jira.Response, error) {
+	return a.delegate.Issue.GetRemoteLinks(id)
+}
+
+func (a *jiraAdapter) CreateIssue(issue *jira.Issue) (*jira.Issue, *jira.Response, error) {
+	return a.delegate.Issue.Create(issue)
+}
+
+func (a *jiraAdapter) UpdateIssue(id string, data map[string]interface{}) (*jira.Response, error) {
+	return a.delegate.Issue.UpdateIssue(id, data)
+}
+
+func (a *jiraAdapter) AddLink(link *jira.IssueLink) (*jira.Response, error) {
+	return a.delegate.Issue.AddLink(link)
+}
+
+type jiraClient interface {
+	GetIssue(id string) (*jira.Issue, *jira.Response, error)
+	GetRemoteLinks(id string) (*[]jira.RemoteLink, *jira.Response, error)
+	CreateIssue(issue *jira.Issue) (*jira.Issue, *jira.Response, error)
+	UpdateIssue(id string, data map[string]interface{}) (*jira.Response, error)
+	AddLink(link *jira.IssueLink) (*jira.Response, error)
+}
+
+// jiraTracker serves the backporter from Jira, where clones are linked
+// to the issue they clone with a "Cloners" link and blocked by it with
+// a "Blocks" link, and target releases are held in a version field
+type jiraTracker struct {
+	client jiraClient
+	// endpoint is the address of the Jira server, used to link to issues
+	endpoint string
+	// targetVersionField is the ID of the (custom) field holding the
+	// release an issue targets, e.g. customfield_12319940
+	targetVersionField string
+}
+
+var _ Tracker = &jiraTracker{}
+
+// NewJiraTracker returns a Tracker backed by the Jira client
+func NewJiraTracker(client *jira.Client, targetVersionField string) Tracker {
+	endpoint := client.GetBaseURL()
+	return &jiraTracker{
+		client:             &jiraAdapter{delegate: client},
+		endpoint:           strings.TrimSuffix(endpoint.String(), "/"),
+		targetVersionField: targetVersionField,
+	}
+}
+
+func (t *jiraTracker) getIssue(id string) (*jira.Issue, error) {
+	issue, response, err := t.client.GetIssue(id)
+	if err != nil {
+		return nil, jirautil.JiraError(response, err)
+	}
+	if issue.Fields == nil {
+		return nil, fmt.Errorf("issue %s has no fields", id)
+	}
+	return issue, nil
+}
+
+func (t *jiraTracker) issueFor(issue *jira.Issue) *Issue {
+	converted := &Issue{
+		ID:            issue.Key,
+		Summary:       issue.Fields.Summary,
+		TargetRelease: t.targetReleaseFor(issue),
+		URL:           fmt.Sprintf("%s/browse/%s", t.endpoint, issue.Key),
+	}
+	if issue.Fields.Status != nil {
+		converted.Status = issue.Fields.Status.Name
+	}
+	return converted
+}
+
+// targetReleaseFor reads the names of the versions in the target version
+// field, which holds either one version or a list of versions
+func (t *jiraTracker) targetReleaseFor(issue *jira.Issue) []string {
+	value, set := issue.Fields.Unknowns[t.targetVersionField]
+	if !set {
+		return nil
+	}
+	versions, isList := value.([]interface{})
+	if !isList {
+		versions = []interface{}{value}
+	}
+	var releases []string
+	for _, version := range versions {
+		if fields, ok := version.(map[string]interface{}); ok {
+			if name, ok := fields["name"].(string); ok && name != "" {
+				releases = append(releases, name)
+			}
+		}
+	}
+	return releases
+}
+
+// linkedIssues returns the keys of issues linked to the issue with the type,
+// either on the inward side ("is cloned by") or on the outward side ("clones")
+func linkedIssues(issue *jira.Issue, linkType string, inward bool) []string {
+	var keys []string
+	for _, link := range issue.Fields.IssueLinks {
+		if link == nil || link.Type.Name != linkType {
+			continue
+		}
+		if inward && link.InwardIssue != nil {
+			keys = append(keys, link.InwardIssue.Key)
+		}
+		if !inward && link.OutwardIssue != nil {
+			keys = append(keys, link.OutwardIssue.Key)
+		}
+	}
+	return keys
+}
+
+func (t *jiraTracker) GetIssue(id string) (*Issue, error) {
+	issue, err := t.getIssue(id)
+	if err != nil {
+		return nil, err
+	}
+	return t.issueFor(issue), nil
+}
+
+func (t *jiraTracker) GetPRs(id string) ([]PullRequest, error) {
+	links, response, err := t.client.GetRemoteLinks(id)
+	if err != nil {
+		return nil, jirautil.JiraError(response, err)
+	}
+	var prs []PullRequest
+	for _, link := range *links {
+		if link.Object == nil {
+			continue
+		}
+		match := pullRequestURL.FindStringSubmatch(link.Object.URL)
+		if match == nil {
+			continue
+		}
+		num, err := strconv.Atoi(match[3])
+		if err != nil {
+			continue
+		}
+		prs = append(prs, PullRequest{Org: match[1], Repo: match[2], Num: num, URL: link.Object.URL})
+	}
+	return prs, nil
+}
+
+// clonesOf fetches the issues cloned from the already fetched issue with get
+func clonesOf(issue *jira.Issue, get func(string) (*jira.Issue, error)) ([]*jira.Issue, error) {
+	var clones []*jira.Issue
+	for _, cloneKey := range linkedIssues(issue, linkTypeCloners, true) {
+		clone, err := get(cloneKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get clone %s of %s: %w", cloneKey, issue.Key, err)
+		}
+		clones = append(clones, clone)
+	}
+	return clones, nil
+}
+
+func (t *jiraTracker) GetClones(issue *Issue) ([]*Issue, error) {
+	original, err := t.getIssue(issue.ID)
+	if err != nil {
+		return nil, err
+	}
+	clones, err := clonesOf(original, t.getIssue)
+	if err != nil {
+		return nil, err
+	}
+	var converted []*Issue
+	for _, clone := range clones {
+		converted = append(converted, t.issueFor(clone))
+	}
+	return converted, nil
+}
+
+func (t *jiraTracker) GetAllClones(issue *Issue) ([]*Issue, error) {
+	// issues on the way up to the original are seen again on the way down
+	fetched := map[string]*jira.Issue{}
+	get := func(key string) (*jira.Issue, error) {
+		if issue, ok := fetched[key]; ok {
+			return issue, nil
+		}
+		issue, err := t.getIssue(key)
+		if err != nil {
+			return nil, err
+		}
+		fetched[key] = issue
+		return issue, nil
+	}
+
+	// find the original issue by following the issues each one clones
+	root, err := get(issue.ID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{root.Key: true}
+	for {
+		parents := linkedIssues(root, linkTypeCloners, false)
+		if len(parents) == 0 || seen[parents[0]] {
+			break
+		}
+		if root, err = get(parents[0]); err != nil {
+			return nil, fmt.Errorf("failed to get the issue %s was cloned from: %w", parents[0], err)
+		}
+		seen[root.Key] = true
+	}
+
+	// then walk down the clones, breadth first, so that the original comes first
+	all := []*Issue{t.issueFor(root)}
+	queue := []*jira.Issue{root}
+	seen = map[string]bool{root.Key: true}
+	for len(queue) > 0 {
+		clones, err := clonesOf(queue[0], get)
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		for _, clone := range clones {
+			if seen[clone.Key] {
+				continue
+			}
+			seen[clone.Key] = true
+			all = append(all, t.issueFor(clone))
+			queue = append(queue, clone)
+		}
+	}
+	return all, nil
+}
+
+func (t *jiraTracker) CloneIssue(issue *Issue) (string, error) {
+	original, err := t.getIssue(issue.ID)
+	if err != nil {
+		return "", err
+	}
+	clone, response, err := t.client.CreateIssue(&jira.Issue{Fields: &jira.IssueFields{
+		Project:     original.Fields.Project,
+		Type:        original.Fields.Type,
+		Summary:     original.Fields.Summary,
+		Description: original.Fields.Description,
+		Components:  original.Fields.Components,
+		Priority:    original.Fields.Priority,
+		Assignee:    original.Fields.Assignee,
+	}})
+	if err != nil {
+		return "", jirautil.JiraError(response, err)
+	}
+	// the inward issue of a link is the one the outward description applies
+	// to, so these read "clone clones original" and "original blocks clone"
+	for _, link := range []*jira.IssueLink{
+		{Type: jira.IssueLinkType{Name: linkTypeCloners}, InwardIssue: &jira.Issue{Key: clone.Key}, OutwardIssue: &jira.Issue{Key: original.Key}},
+		{Type: jira.IssueLinkType{Name: linkTypeBlocks}, InwardIssue: &jira.Issue{Key: original.Key}, OutwardIssue: &jira.Issue{Key: clone.Key}},
+	} {
+		if response, err := t.client.AddLink(link); err != nil {
+			return "", fmt.Errorf("failed to link clone %s to %s: %w", clone.Key, original.Key, jirautil.JiraError(response, err))
+		}
+	}
+	return clone.Key, nil
+}
+
+func (t *jiraTracker) SetTargetRelease(id, release string) error {
+	response, err := t.client.UpdateIssue(id, map[string]interface{}{
+		"fields": map[string]interface{}{
+			t.targetVersionField: []map[string]string{{"name": release}},
+		},
+	})
+	return jirautil.JiraError(response, err)
+}
@@ -0,0 +1,225 @@
+package backporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/google/go-cmp/cmp"
+)
+
+const fakeTargetVersionField = "customfield_12319940"
+
+// fakeJiraClient is an in-memory Jira holding issues with their links
+type fakeJiraClient struct {
+	issues      map[string]*jira.Issue
+	remoteLinks map[string][]jira.RemoteLink
+	created     int
+	// fetched counts how often each issue was read
+	fetched map[string]int
+}
+
+func newFakeJiraClient(issues ...*jira.Issue) *fakeJiraClient {
+	f := &fakeJiraClient{issues: map[string]*jira.Issue{}, remoteLinks: map[string][]jira.RemoteLink{}, fetched: map[string]int{}}
+	for _, issue := range issues {
+		f.issues[issue.Key] = issue
+	}
+	return f
+}
+
+func (f *fakeJiraClient) GetIssue(id string) (*jira.Issue, *jira.Response, error) {
+	f.fetched[id]++
+	issue, exists := f.issues[id]
+	if !exists {
+		return nil, nil, fmt.Errorf("issue %s not found", id)
+	}
+	fields := *issue.Fields
+	fields.IssueLinks = append([]*jira.IssueLink{}, issue.Fields.IssueLinks...)
+	return &jira.Issue{Key: issue.Key, Fields: &fields}, nil, nil
+}
+
+func (f *fakeJiraClient) GetRemoteLinks(id string) (*[]jira.RemoteLink, *jira.Response, error) {
+	links := f.remoteLinks[id]
+	return &links, nil, nil
+}
+
+func (f *fakeJiraClient) CreateIssue(issue *jira.Issue) (*jira.Issue, *jira.Response, error) {
+	f.created++
+	key := fmt.Sprintf("%s-%d", issue.Fields.Project.Key, 100+f.created)
+	fields := *issue.Fields
+	f.issues[key] = &jira.Issue{Key: key, Fields: &fields}
+	return &jira.Issue{Key: key}, nil, nil
+}
+
+func (f *fakeJiraClient) UpdateIssue(id string, data map[string]interface{}) (*jira.Response, error) {
+	issue, exists := f.issues[id]
+	if !exists {
+		return nil, fmt.Errorf("issue %s not found", id)
+	}
+	// round-trip the fields through JSON so they look like they came from the server
+	raw, err := json.Marshal(data["fields"])
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	if issue.Fields.Unknowns == nil {
+		issue.Fields.Unknowns = map[string]interface{}{}
+	}
+	for field, value := range fields {
+		issue.Fields.Unknowns[field] = value
+	}
+	return nil, nil
+}
+
+// AddLink records the link on both issues the way Jira returns them: the inward
+// issue of the link sees the other issue as outward and the other way around
+func (f *fakeJiraClient) AddLink(link *jira.IssueLink) (*jira.Response, error) {
+	inward, outward := f.issues[link.InwardIssue.Key], f.issues[link.OutwardIssue.Key]
+	if inward == nil || outward == nil {
+		return nil, fmt.Errorf("cannot link %s to %s: issue not found", link.InwardIssue.Key, link.OutwardIssue.Key)
+	}
+	inward.Fields.IssueLinks = append(inward.Fields.IssueLinks, &jira.IssueLink{Type: link.Type, OutwardIssue: &jira.Issue{Key: outward.Key}})
+	outward.Fields.IssueLinks = append(outward.Fields.IssueLinks, &jira.IssueLink{Type: link.Type, InwardIssue: &jira.Issue{Key: inward.Key}})
+	return nil, nil
+}
+
+func newFakeJiraTracker(client *fakeJiraClient) *jiraTracker {
+	return &jiraTracker{client: client, endpoint: "https://issues.redhat.com", targetVersionField: fakeTargetVersionField}
+}
+
+func originalJiraIssue() *jira.Issue {
+	return &jira.Issue{Key: "OCPBUGS-1", Fields: &jira.IssueFields{
+		Project:  jira.Project{Key: "OCPBUGS"},
+		Type:     jira.IssueType{Name: "Bug"},
+		Summary:  "Sample issue to test the Jira tracker",
+		Status:   &jira.Status{Name: "POST"},
+		Unknowns: map[string]interface{}{fakeTargetVersionField: []interface{}{map[string]interface{}{"name": "4.12.0"}}},
+	}}
+}
+
+func TestJiraTracker(t *testing.T) {
+	client := newFakeJiraClient(originalJiraIssue())
+	client.remoteLinks["OCPBUGS-1"] = []jira.RemoteLink{
+		{Object: &jira.RemoteLinkObject{URL: "https://github.com/openshift/ci-tools/pull/1234"}},
+		{Object: &jira.RemoteLinkObject{URL: "https://access.redhat.com/solutions/1"}},
+	}
+	tracker := newFakeJiraTracker(client)
+
+	original, err := tracker.GetIssue("OCPBUGS-1")
+	if err != nil {
+		t.Fatalf("failed to get issue: %v", err)
+	}
+	expectedOriginal := &Issue{
+		ID:            "OCPBUGS-1",
+		Summary:       "Sample issue to test the Jira tracker",
+		Status:        "POST",
+		TargetRelease: []string{"4.12.0"},
+		URL:           "https://issues.redhat.com/browse/OCPBUGS-1",
+	}
+	if diff := cmp.Diff(expectedOriginal, original); diff != "" {
+		t.Errorf("got incorrect issue: %s", diff)
+	}
+
+	prs, err := tracker.GetPRs("OCPBUGS-1")
+	if err != nil {
+		t.Fatalf("failed to get PRs: %v", err)
+	}
+	expectedPRs := []PullRequest{{Org: "openshift", Repo: "ci-tools", Num: 1234, URL: "https://github.com/openshift/ci-tools/pull/1234"}}
+	if diff := cmp.Diff(expectedPRs, prs); diff != "" {
+		t.Errorf("got incorrect PRs: %s", diff)
+	}
+
+	cloneID, err := tracker.CloneIssue(original)
+	if err != nil {
+		t.Fatalf("failed to clone issue: %v", err)
+	}
+	if err := tracker.SetTargetRelease(cloneID, "4.11.z"); err != nil {
+		t.Fatalf("failed to set target release: %v", err)
+	}
+	clone, err := tracker.GetIssue(cloneID)
+	if err != nil {
+		t.Fatalf("failed to get clone: %v", err)
+	}
+	grandchildID, err := tracker.CloneIssue(clone)
+	if err != nil {
+		t.Fatalf("failed to clone issue: %v", err)
+	}
+	if err := tracker.SetTargetRelease(grandchildID, "4.10.z"); err != nil {
+		t.Fatalf("failed to set target release: %v", err)
+	}
+
+	clones, err := tracker.GetClones(original)
+	if err != nil {
+		t.Fatalf("failed to get clones: %v", err)
+	}
+	if diff := cmp.Diff([]*Issue{{ID: "OCPBUGS-101", Summary: original.Summary, TargetRelease: []string{"4.11.z"}, URL: "https://issues.redhat.com/browse/OCPBUGS-101"}}, clones); diff != "" {
+		t.Errorf("got incorrect clones: %s", diff)
+	}
+
+	grandchild := &Issue{ID: grandchildID}
+	client.fetched = map[string]int{}
+	all, err := tracker.GetAllClones(grandchild)
+	if err != nil {
+		t.Fatalf("failed to get all clones: %v", err)
+	}
+	var releases []string
+	for _, issue := range all {
+		releases = append(releases, fmt.Sprintf("%s@%v", issue.ID, issue.TargetRelease))
+	}
+	if diff := cmp.Diff([]string{"OCPBUGS-1@[4.12.0]", "OCPBUGS-101@[4.11.z]", "OCPBUGS-102@[4.10.z]"}, releases); diff != "" {
+		t.Errorf("got incorrect clones, original should come first: %s", diff)
+	}
+	if diff := cmp.Diff(map[string]int{"OCPBUGS-1": 1, "OCPBUGS-101": 1, "OCPBUGS-102": 1}, client.fetched); diff != "" {
+		t.Errorf("every issue should be fetched once when walking the clones: %s", diff)
+	}
+
+	// clones are blocked by the issue they were cloned from
+	blocked, _, err := client.GetIssue("OCPBUGS-101")
+	if err != nil {
+		t.Fatalf("failed to get issue: %v", err)
+	}
+	if diff := cmp.Diff([]string{"OCPBUGS-1"}, linkedIssues(blocked, linkTypeBlocks, true)); diff != "" {
+		t.Errorf("clone is not blocked by the original: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"OCPBUGS-102"}, linkedIssues(blocked, linkTypeBlocks, false)); diff != "" {
+		t.Errorf("clone does not block its own clone: %s", diff)
+	}
+}
+
+func TestBulkCloneHandlerWithJira(t *testing.T) {
+	client := newFakeJiraClient(originalJiraIssue())
+	tracker := newFakeJiraTracker(client)
+	allTargetVersions := []string{"4.10.z", "4.11.z", "4.12.0"}
+
+	formData := url.Values{"ID": []string{"OCPBUGS-1"}, "release": []string{"4.10.z"}}
+	req, err := http.NewRequest("POST", "/clones/bulk", bytes.NewBufferString(formData.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+	rr := httptest.NewRecorder()
+	BulkCloneHandler(tracker, allTargetVersions, fakebzbpMetrics).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got wrong status code - got %v, want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	all, err := tracker.GetAllClones(&Issue{ID: "OCPBUGS-1"})
+	if err != nil {
+		t.Fatalf("failed to get all clones: %v", err)
+	}
+	var releases []string
+	for _, issue := range all {
+		releases = append(releases, fmt.Sprintf("%s@%v", issue.ID, issue.TargetRelease))
+	}
+	if diff := cmp.Diff([]string{"OCPBUGS-1@[4.12.0]", "OCPBUGS-101@[4.11.z]", "OCPBUGS-102@[4.10.z]"}, releases); diff != "" {
+		t.Errorf("got incorrect clones: %s", diff)
+	}
+}
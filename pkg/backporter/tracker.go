@@ -0,0 +1,42 @@
+package backporter
+
+// Issue is a bug in an issue tracker with the details the backporter shows
+// and needs in order to find and create clones, regardless of the tracker
+type Issue struct {
+	// ID identifies the issue in its tracker, e.g. 1234 or OCPBUGS-1234
+	ID            string
+	Summary       string
+	Status        string
+	TargetRelease []string
+	// URL is the address of the issue in the tracker's UI
+	URL string
+	// PRs are the pull requests linked to the issue, only populated for the clones page
+	PRs []PullRequest
+}
+
+// PullRequest is a pull request linked to an issue
+type PullRequest struct {
+	Org  string
+	Repo string
+	Num  int
+	URL  string
+}
+
+// Tracker is an issue tracker which holds the bugs and clones the backporter
+// works with. Clones of an issue are blocked by the issue they are cloned from.
+type Tracker interface {
+	// GetIssue returns the issue with the ID
+	GetIssue(id string) (*Issue, error)
+	// GetPRs returns the pull requests linked to the issue with the ID
+	GetPRs(id string) ([]PullRequest, error)
+	// GetClones returns the clones created directly from the issue
+	GetClones(issue *Issue) ([]*Issue, error)
+	// GetAllClones returns all the clones of the issue across all levels,
+	// including the issue itself, with the original issue first
+	GetAllClones(issue *Issue) ([]*Issue, error)
+	// CloneIssue creates a clone of the issue which is blocked by it
+	// and returns the ID of the clone
+	CloneIssue(issue *Issue) (string, error)
+	// SetTargetRelease updates the release the issue with the ID targets
+	SetTargetRelease(id, release string) error
+}
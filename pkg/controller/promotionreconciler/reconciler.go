@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/test-infra/prow/config"
 	"k8s.io/test-infra/prow/github"
 	"sigs.k8s.io/controller-runtime"
@@ -20,8 +21,10 @@ import (
 
 	"github.com/openshift/api/image/docker10"
 	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
 
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/promotionhistory"
 	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler/prowjobreconciler"
 	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 	"github.com/openshift/ci-tools/pkg/load/agents"
@@ -150,6 +153,16 @@ func (r *reconciler) reconcile(ctx context.Context, req controllerruntime.Reques
 	}
 	log = log.WithField("org", ciOPConfig.Metadata.Org).WithField("repo", ciOPConfig.Metadata.Repo).WithField("branch", ciOPConfig.Metadata.Branch)
 
+	pin, pinned, err := r.rollbackPin(ctx, req.NamespacedName)
+	if err != nil {
+		return controllerutil.TerminalError(fmt.Errorf("failed to get rollback pin: %w", err))
+	}
+	if pinned {
+		// The tag was rolled back on purpose, promoting it again would revert that
+		log.WithField("pinnedDigest", pin.Digest).WithField("reason", pin.Reason).Debug("ImageStreamTag is pinned by a rollback, not requesting promotion")
+		return nil
+	}
+
 	istCommit, err := commitForIST(ist)
 	if err != nil {
 		return controllerutil.TerminalError(fmt.Errorf("failed to get commit for imageStreamTag: %w", err))
@@ -195,6 +208,27 @@ func (r *reconciler) promotionConfig(ist *imagev1.ImageStreamTag) (*cioperatorap
 	}
 }
 
+// rollbackPin returns the rollback pin of the imageStreamTag, if any
+func (r *reconciler) rollbackPin(ctx context.Context, name types.NamespacedName) (promotionhistory.Pin, bool, error) {
+	streamName, tag, err := imageutil.ParseImageStreamTagName(name.Name)
+	if err != nil {
+		return promotionhistory.Pin{}, false, err
+	}
+	stream := &imagev1.ImageStream{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: name.Namespace, Name: streamName}, stream); err != nil {
+		if apierrors.IsNotFound(err) {
+			return promotionhistory.Pin{}, false, nil
+		}
+		return promotionhistory.Pin{}, false, fmt.Errorf("failed to get imageStream: %w", err)
+	}
+	pins, err := promotionhistory.Pins(stream)
+	if err != nil {
+		return promotionhistory.Pin{}, false, err
+	}
+	pin, pinned := pins[tag]
+	return pin, pinned, nil
+}
+
 func commitForIST(ist *imagev1.ImageStreamTag) (string, error) {
 	metadata := &docker10.DockerImage{}
 	if err := json.Unmarshal(ist.Image.DockerImageMetadata.Raw, metadata); err != nil {
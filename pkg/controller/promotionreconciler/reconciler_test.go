@@ -22,6 +22,7 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 
 	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/promotionhistory"
 	"github.com/openshift/ci-tools/pkg/controller/promotionreconciler/prowjobreconciler"
 	controllerutil "github.com/openshift/ci-tools/pkg/controller/util"
 )
@@ -94,6 +95,7 @@ func TestReconcile(t *testing.T) {
 		name              string
 		githubClient      func(owner, repo, ref string) (string, error)
 		promotionDisabled bool
+		pinned            bool
 		verify            func(error, *prowjobreconciler.OrgRepoBranchCommit) error
 	}{
 		{
@@ -145,6 +147,20 @@ func TestReconcile(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name:         "Ist outdated, tag pinned by rollback, no prowjob created",
+			githubClient: func(_, _, _ string) (string, error) { return "newer", nil },
+			pinned:       true,
+			verify: func(e error, req *prowjobreconciler.OrgRepoBranchCommit) error {
+				if e != nil {
+					return fmt.Errorf("expected error to be nil, was %w", e)
+				}
+				if req != nil {
+					return fmt.Errorf("expected no request, got %v", req)
+				}
+				return nil
+			},
+		},
 		{
 			name:         "Ist outdated, prowjob created",
 			githubClient: func(_, _, _ string) (string, error) { return "newer", nil },
@@ -287,11 +303,20 @@ func TestReconcile(t *testing.T) {
 				},
 			}
 
+			objects := []runtime.Object{imageStreamTag}
+			if tc.pinned {
+				imageStream := &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "namespace", Name: "name"}}
+				if err := promotionhistory.SetPin(imageStream, "tag", promotionhistory.Pin{Digest: "sha256:old"}); err != nil {
+					t.Fatalf("failed to pin tag: %v", err)
+				}
+				objects = append(objects, imageStream)
+			}
+
 			var req *prowjobreconciler.OrgRepoBranchCommit
 
 			r := &reconciler{
 				log:    logrus.NewEntry(logrus.New()),
-				client: fakectrlruntimeclient.NewFakeClient(imageStreamTag),
+				client: fakectrlruntimeclient.NewFakeClient(objects...),
 				releaseBuildConfigs: func(_ string) ([]*cioperatorapi.ReleaseBuildConfiguration, error) {
 					return []*cioperatorapi.ReleaseBuildConfiguration{{
 						Metadata: cioperatorapi.Metadata{
@@ -20,6 +20,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreclientset "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/test-infra/prow/secretutil"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
@@ -34,9 +35,7 @@ import (
 	testimagestreamtagimportv1 "github.com/openshift/ci-tools/pkg/api/testimagestreamtagimport/v1"
 	"github.com/openshift/ci-tools/pkg/lease"
 	"github.com/openshift/ci-tools/pkg/release"
-	"github.com/openshift/ci-tools/pkg/release/candidate"
-	"github.com/openshift/ci-tools/pkg/release/official"
-	"github.com/openshift/ci-tools/pkg/release/prerelease"
+	"github.com/openshift/ci-tools/pkg/release/resolve"
 	"github.com/openshift/ci-tools/pkg/results"
 	"github.com/openshift/ci-tools/pkg/secrets"
 	"github.com/openshift/ci-tools/pkg/steps"
@@ -68,6 +67,9 @@ func FromConfig(
 	pullSecret, pushSecret *coreapi.Secret,
 	censor *secrets.DynamicCensor,
 	hiveKubeconfig *rest.Config,
+	registryKubeconfig *rest.Config,
+	pipelineImageCache *steps.PipelineImageCache,
+	releaseHTTPClient release.HTTPClient,
 ) ([]api.Step, []api.Step, error) {
 	crclient, err := ctrlruntimeclient.NewWithWatch(clusterConfig, ctrlruntimeclient.Options{})
 	crclient = secretrecordingclient.Wrap(crclient, censor)
@@ -102,7 +104,19 @@ func FromConfig(
 		}
 	}
 
-	return fromConfig(ctx, config, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, &http.Client{}, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, api.NewDeferredParameters(nil))
+	var registryClient ctrlruntimeclient.Client
+	if registryKubeconfig != nil {
+		registryClient, err = ctrlruntimeclient.New(registryKubeconfig, ctrlruntimeclient.Options{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not get registry client for registry kube config: %w", err)
+		}
+	}
+
+	if releaseHTTPClient == nil {
+		releaseHTTPClient = &http.Client{}
+	}
+
+	return fromConfig(ctx, config, jobSpec, templates, paramFile, promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, registryClient, releaseHTTPClient, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, pipelineImageCache, censor, api.NewDeferredParameters(nil))
 }
 
 func fromConfig(
@@ -118,10 +132,13 @@ func fromConfig(
 	podClient steps.PodClient,
 	leaseClient *lease.Client,
 	hiveClient ctrlruntimeclient.WithWatch,
+	registryClient ctrlruntimeclient.Client,
 	httpClient release.HTTPClient,
 	requiredTargets []string,
 	cloneAuthConfig *steps.CloneAuthConfig,
 	pullSecret, pushSecret *coreapi.Secret,
+	pipelineImageCache *steps.PipelineImageCache,
+	censor secretutil.Censorer,
 	params *api.DeferredParameters,
 ) ([]api.Step, []api.Step, error) {
 	requiredNames := sets.NewString()
@@ -173,14 +190,7 @@ func fromConfig(
 				}
 				logrus.Infof("Using explicitly provided pull-spec for release %s (%s)", resolveConfig.Name, value)
 			} else {
-				switch {
-				case resolveConfig.Candidate != nil:
-					value, err = candidate.ResolvePullSpec(httpClient, *resolveConfig.Candidate)
-				case resolveConfig.Release != nil:
-					value, _, err = official.ResolvePullSpecAndVersion(httpClient, *resolveConfig.Release)
-				case resolveConfig.Prerelease != nil:
-					value, err = prerelease.ResolvePullSpec(httpClient, *resolveConfig.Prerelease)
-				}
+				value, err = resolve.PullSpec(httpClient, resolveConfig.UnresolvedRelease, config.Metadata.Branch)
 				if err != nil {
 					return nil, nil, results.ForReason("resolving_release").ForError(fmt.Errorf("failed to resolve release %s: %w", resolveConfig.Name, err))
 				}
@@ -202,15 +212,23 @@ func fromConfig(
 			step = steps.InputImageTagStep(&conf, client, jobSpec)
 			inputImages[conf.InputImage] = struct{}{}
 		} else if rawStep.PipelineImageCacheStepConfiguration != nil {
-			step = steps.PipelineImageCacheStep(*rawStep.PipelineImageCacheStepConfiguration, config.Resources, buildClient, jobSpec, pullSecret)
+			conf := *rawStep.PipelineImageCacheStepConfiguration
+			step = steps.PipelineImageCacheStep(conf, config.Resources, buildClient, jobSpec, pullSecret)
+			if pipelineImageCache != nil {
+				step = steps.CachedImageStep(step, conf.To, conf, []api.PipelineImageStreamTagReference{conf.From}, *pipelineImageCache, buildClient, jobSpec)
+			}
 		} else if rawStep.SourceStepConfiguration != nil {
-			step = steps.SourceStep(*rawStep.SourceStepConfiguration, config.Resources, buildClient, jobSpec, cloneAuthConfig, pullSecret)
+			conf := *rawStep.SourceStepConfiguration
+			step = steps.SourceStep(conf, config.Resources, buildClient, jobSpec, cloneAuthConfig, pullSecret)
+			if pipelineImageCache != nil {
+				step = steps.CachedImageStep(step, conf.To, conf, []api.PipelineImageStreamTagReference{conf.From}, *pipelineImageCache, buildClient, jobSpec)
+			}
 		} else if rawStep.BundleSourceStepConfiguration != nil {
 			step = steps.BundleSourceStep(*rawStep.BundleSourceStepConfiguration, config, config.Resources, buildClient, jobSpec, pullSecret)
 		} else if rawStep.IndexGeneratorStepConfiguration != nil {
 			step = steps.IndexGeneratorStep(*rawStep.IndexGeneratorStepConfiguration, config, config.Resources, buildClient, jobSpec, pullSecret)
 		} else if rawStep.ProjectDirectoryImageBuildStepConfiguration != nil {
-			step = steps.ProjectDirectoryImageBuildStep(*rawStep.ProjectDirectoryImageBuildStepConfiguration, config, config.Resources, buildClient, jobSpec, pullSecret)
+			step = steps.ProjectDirectoryImageBuildStep(*rawStep.ProjectDirectoryImageBuildStepConfiguration, config, config.Resources, buildClient, podClient, jobSpec, pullSecret)
 		} else if rawStep.ProjectDirectoryImageBuildInputs != nil {
 			step = steps.GitSourceStep(*rawStep.ProjectDirectoryImageBuildInputs, config.Resources, buildClient, jobSpec, cloneAuthConfig, pullSecret)
 		} else if rawStep.RPMImageInjectionStepConfiguration != nil {
@@ -289,6 +307,23 @@ func fromConfig(
 		addProvidesForStep(step, params)
 	}
 
+	for _, releaseDiff := range config.ReleaseDiffs {
+		buildSteps = append(buildSteps, releasesteps.DiffReleaseStep(releaseDiff, client, jobSpec, censor))
+	}
+
+	if config.Operator != nil {
+		for i, bundle := range config.Operator.Bundles {
+			if bundle.Validation == nil {
+				continue
+			}
+			bundleName, indexGenerator := api.BundleName(i), api.PipelineImageStreamTagReferenceIndexImageGenerator
+			if bundle.As != "" {
+				bundleName, indexGenerator = bundle.As, api.IndexGeneratorName(api.PipelineImageStreamTagReference(api.IndexName(bundle.As)))
+			}
+			buildSteps = append(buildSteps, steps.BundleValidationStep(*bundle.Validation, bundleName, indexGenerator, podClient, jobSpec))
+		}
+	}
+
 	if len(paramFile) > 0 {
 		step := steps.WriteParametersStep(params, paramFile)
 		buildSteps = append(buildSteps, step)
@@ -312,7 +347,7 @@ func fromConfig(
 		if config.PromotionConfiguration == nil {
 			return nil, nil, fmt.Errorf("cannot promote images, no promotion configuration defined")
 		}
-		postSteps = append(postSteps, releasesteps.PromotionStep(config, requiredNames, jobSpec, podClient, pushSecret))
+		postSteps = append(postSteps, releasesteps.PromotionStep(config, requiredNames, jobSpec, podClient, pushSecret, registryClient))
 	}
 
 	return append(overridableSteps, buildSteps...), postSteps, nil
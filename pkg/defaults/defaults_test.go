@@ -18,6 +18,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
 	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+	"k8s.io/test-infra/prow/secretutil"
 	"k8s.io/utils/diff"
 	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -1006,6 +1007,30 @@ func TestFromConfig(t *testing.T) {
 			"LOCAL_IMAGE_CI_BUNDLE0": "public_docker_image_repository:ci-bundle0",
 			"LOCAL_IMAGE_CI_INDEX":   "public_docker_image_repository:ci-index",
 		},
+	}, {
+		name: "bundle validation",
+		config: api.ReleaseBuildConfiguration{
+			Operator: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					DockerfilePath: "dockerfile_path",
+					ContextDir:     "context_dir",
+					Validation:     &api.BundleValidation{Scorecard: true},
+				}},
+			},
+		},
+		expectedSteps: []string{
+			"src-bundle",
+			"ci-bundle0",
+			"ci-index-gen",
+			"ci-index",
+			"[validate-bundle:ci-bundle0]",
+			"[output-images]",
+			"[images]",
+		},
+		expectedParams: map[string]string{
+			"LOCAL_IMAGE_CI_BUNDLE0": "public_docker_image_repository:ci-bundle0",
+			"LOCAL_IMAGE_CI_INDEX":   "public_docker_image_repository:ci-index",
+		},
 	}, {
 		name: "image build",
 		config: api.ReleaseBuildConfiguration{
@@ -1344,7 +1369,7 @@ func TestFromConfig(t *testing.T) {
 			for k, v := range tc.params {
 				params.Add(k, func() (string, error) { return v, nil })
 			}
-			configSteps, post, err := fromConfig(context.Background(), &tc.config, &jobSpec, tc.templates, tc.paramFiles, tc.promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, httpClient, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, params)
+			configSteps, post, err := fromConfig(context.Background(), &tc.config, &jobSpec, tc.templates, tc.paramFiles, tc.promote, client, buildClient, templateClient, podClient, leaseClient, hiveClient, nil, httpClient, requiredTargets, cloneAuthConfig, pullSecret, pushSecret, nil, secretutil.NewCensorer(), params)
 			if diff := cmp.Diff(tc.expectedErr, err); diff != "" {
 				t.Errorf("unexpected error: %v", diff)
 			}
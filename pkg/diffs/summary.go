@@ -0,0 +1,405 @@
+package diffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "k8s.io/test-infra/prow/config"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/prowgen"
+)
+
+// ConfigSummary is a human-readable description of the semantic changes
+// made to a single ci-operator configuration file
+type ConfigSummary struct {
+	Filename string `json:"filename"`
+	// Added is set when the configuration file is new
+	Added bool `json:"added,omitempty"`
+	// Removed is set when the configuration file was deleted
+	Removed bool `json:"removed,omitempty"`
+
+	TestsAdded   []string `json:"tests_added,omitempty"`
+	TestsRemoved []string `json:"tests_removed,omitempty"`
+	// Changes describes all other semantic changes, one per line
+	Changes []string `json:"changes,omitempty"`
+
+	// Jobs is the impact on the generated Prow jobs
+	Jobs JobImpact `json:"jobs"`
+}
+
+// JobImpact lists the generated Prow jobs that are affected by a change
+type JobImpact struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// SummarizeCiopConfigChanges describes the differences between the ci-operator
+// configurations in the two revisions of the release repository. Unchanged
+// configurations are omitted and the summaries are sorted by filename.
+func SummarizeCiopConfigChanges(masterConfig, prConfig config.DataByFilename) []ConfigSummary {
+	filenames := sets.NewString()
+	for filename := range masterConfig {
+		filenames.Insert(filename)
+	}
+	for filename := range prConfig {
+		filenames.Insert(filename)
+	}
+
+	var summaries []ConfigSummary
+	for _, filename := range filenames.List() {
+		oldConfig, inMaster := masterConfig[filename]
+		newConfig, inPR := prConfig[filename]
+		summary := ConfigSummary{Filename: filename}
+		switch {
+		case !inMaster:
+			summary.Added = true
+			summary.TestsAdded = testNames(newConfig.Configuration.Tests)
+			summary.Jobs = jobImpact(nil, &newConfig)
+		case !inPR:
+			summary.Removed = true
+			summary.TestsRemoved = testNames(oldConfig.Configuration.Tests)
+			summary.Jobs = jobImpact(&oldConfig, nil)
+		default:
+			old, new := oldConfig.Configuration, newConfig.Configuration
+			if equality.Semantic.DeepEqual(old, new) {
+				continue
+			}
+			summary.TestsAdded, summary.TestsRemoved, summary.Changes = describeTestChanges(old.Tests, new.Tests)
+			summary.Changes = append(describeConfigChanges(old, new), summary.Changes...)
+			summary.Jobs = jobImpact(&oldConfig, &newConfig)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func testNames(tests []cioperatorapi.TestStepConfiguration) []string {
+	var names []string
+	for _, test := range tests {
+		names = append(names, test.As)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func describeConfigChanges(old, new cioperatorapi.ReleaseBuildConfiguration) []string {
+	var changes []string
+	changes = append(changes, describeImageChanges(old.Images, new.Images)...)
+	changes = append(changes, describePromotionChanges(old.PromotionConfiguration, new.PromotionConfiguration)...)
+	changes = append(changes, describeMapChanges("base image", old.BaseImages, new.BaseImages)...)
+	changes = append(changes, describeMapChanges("base RPM image", old.BaseRPMImages, new.BaseRPMImages)...)
+	changes = append(changes, describeMapChanges("release", old.Releases, new.Releases)...)
+	if !equality.Semantic.DeepEqual(old.ReleaseTagConfiguration, new.ReleaseTagConfiguration) {
+		changes = append(changes, "tag_specification changed")
+	}
+	if !equality.Semantic.DeepEqual(old.BuildRootImage, new.BuildRootImage) {
+		changes = append(changes, "build_root changed")
+	}
+	if !equality.Semantic.DeepEqual(old.Resources, new.Resources) {
+		changes = append(changes, "resources changed")
+	}
+
+	// catch everything not described above so that no change goes unnoticed
+	withoutDescribed := func(in cioperatorapi.ReleaseBuildConfiguration) cioperatorapi.ReleaseBuildConfiguration {
+		in.Tests = nil
+		in.Images = nil
+		in.PromotionConfiguration = nil
+		in.BaseImages = nil
+		in.BaseRPMImages = nil
+		in.Releases = nil
+		in.ReleaseTagConfiguration = nil
+		in.BuildRootImage = nil
+		in.Resources = nil
+		return in
+	}
+	if !equality.Semantic.DeepEqual(withoutDescribed(old), withoutDescribed(new)) {
+		changes = append(changes, "other configuration changed")
+	}
+	return changes
+}
+
+func describeImageChanges(old, new []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration) []string {
+	byName := func(images []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration) map[string]cioperatorapi.ProjectDirectoryImageBuildStepConfiguration {
+		ret := map[string]cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{}
+		for _, image := range images {
+			ret[string(image.To)] = image
+		}
+		return ret
+	}
+	return describeMapChanges("image", byName(old), byName(new))
+}
+
+// describeMapChanges describes added, removed and changed keys of two maps
+// with string keys. Maps that cannot be compared key by key are described as
+// a whole, so that no change goes unnoticed.
+func describeMapChanges(kind string, old, new interface{}) []string {
+	oldValues, oldErr := toValues(old)
+	newValues, newErr := toValues(new)
+	if oldErr != nil || newErr != nil {
+		if !equality.Semantic.DeepEqual(old, new) {
+			return []string{fmt.Sprintf("%ss changed", kind)}
+		}
+		return nil
+	}
+	keys := sets.StringKeySet(oldValues).Union(sets.StringKeySet(newValues))
+	var changes []string
+	for _, key := range keys.List() {
+		oldValue, inOld := oldValues[key]
+		newValue, inNew := newValues[key]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("%s %s added", kind, key))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("%s %s removed", kind, key))
+		case !equality.Semantic.DeepEqual(oldValue, newValue):
+			changes = append(changes, fmt.Sprintf("%s %s changed", kind, key))
+		}
+	}
+	return changes
+}
+
+// toValues converts a map with string keys of any value type to a generic
+// map by round-tripping it through JSON
+func toValues(in interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func promotionTarget(promotion *cioperatorapi.PromotionConfiguration) string {
+	if promotion.Name != "" {
+		return fmt.Sprintf("%s/%s:${component}", promotion.Namespace, promotion.Name)
+	}
+	return fmt.Sprintf("%s/${component}:%s", promotion.Namespace, promotion.Tag)
+}
+
+func describePromotionChanges(old, new *cioperatorapi.PromotionConfiguration) []string {
+	switch {
+	case old == nil && new == nil:
+		return nil
+	case old == nil:
+		return []string{fmt.Sprintf("promotion to %s added", promotionTarget(new))}
+	case new == nil:
+		return []string{fmt.Sprintf("promotion to %s removed", promotionTarget(old))}
+	}
+	var changes []string
+	if oldTarget, newTarget := promotionTarget(old), promotionTarget(new); oldTarget != newTarget {
+		changes = append(changes, fmt.Sprintf("promotion target changed from %s to %s", oldTarget, newTarget))
+	}
+	if old.Disabled != new.Disabled {
+		if new.Disabled {
+			changes = append(changes, "promotion disabled")
+		} else {
+			changes = append(changes, "promotion enabled")
+		}
+	}
+	oldExcluded, newExcluded := sets.NewString(old.ExcludedImages...), sets.NewString(new.ExcludedImages...)
+	for _, image := range newExcluded.Difference(oldExcluded).List() {
+		changes = append(changes, fmt.Sprintf("image %s excluded from promotion", image))
+	}
+	for _, image := range oldExcluded.Difference(newExcluded).List() {
+		changes = append(changes, fmt.Sprintf("image %s no longer excluded from promotion", image))
+	}
+	changes = append(changes, describeMapChanges("additional promoted image", old.AdditionalImages, new.AdditionalImages)...)
+	if old.RegistryOverride != new.RegistryOverride {
+		changes = append(changes, fmt.Sprintf("promotion registry override changed from %q to %q", old.RegistryOverride, new.RegistryOverride))
+	}
+	if old.DisableBuildCache != new.DisableBuildCache {
+		changes = append(changes, fmt.Sprintf("promotion build cache upload disabled changed to %t", new.DisableBuildCache))
+	}
+	return changes
+}
+
+func describeTestChanges(old, new []cioperatorapi.TestStepConfiguration) (added, removed, changes []string) {
+	oldTests, newTests := getTestsByName(old), getTestsByName(new)
+	for _, name := range testNames(new) {
+		if _, ok := oldTests[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	for _, name := range testNames(old) {
+		oldTest := oldTests[name]
+		newTest, ok := newTests[name]
+		if !ok {
+			removed = append(removed, name)
+			continue
+		}
+		for _, change := range describeTestChange(oldTest, newTest) {
+			changes = append(changes, fmt.Sprintf("test %s: %s", name, change))
+		}
+	}
+	return added, removed, changes
+}
+
+func describeTestChange(old, new cioperatorapi.TestStepConfiguration) []string {
+	if equality.Semantic.DeepEqual(old, new) {
+		return nil
+	}
+	var changes []string
+	if oldType, newType := testType(old), testType(new); oldType != newType {
+		changes = append(changes, fmt.Sprintf("type changed from %s to %s", oldType, newType))
+	}
+	if oldProfile, newProfile := old.GetClusterProfile(), new.GetClusterProfile(); oldProfile != newProfile {
+		changes = append(changes, fmt.Sprintf("cluster profile changed from %q to %q", oldProfile, newProfile))
+	}
+	if old.Commands != new.Commands {
+		changes = append(changes, "commands changed")
+	}
+	if !equality.Semantic.DeepEqual(old.Cron, new.Cron) || !equality.Semantic.DeepEqual(old.Interval, new.Interval) {
+		changes = append(changes, "schedule changed")
+	}
+	if old.Postsubmit != new.Postsubmit {
+		changes = append(changes, fmt.Sprintf("postsubmit changed to %t", new.Postsubmit))
+	}
+	if !equality.Semantic.DeepEqual(old.ClusterClaim, new.ClusterClaim) {
+		changes = append(changes, "cluster claim changed")
+	}
+	if old.MultiStageTestConfiguration != nil && new.MultiStageTestConfiguration != nil {
+		changes = append(changes, describeMultiStageChanges(*old.MultiStageTestConfiguration, *new.MultiStageTestConfiguration)...)
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "configuration changed")
+	}
+	return changes
+}
+
+func describeMultiStageChanges(old, new cioperatorapi.MultiStageTestConfiguration) []string {
+	var changes []string
+	workflow := func(w *string) string {
+		if w == nil {
+			return ""
+		}
+		return *w
+	}
+	if oldWorkflow, newWorkflow := workflow(old.Workflow), workflow(new.Workflow); oldWorkflow != newWorkflow {
+		changes = append(changes, fmt.Sprintf("workflow changed from %q to %q", oldWorkflow, newWorkflow))
+	}
+	changes = append(changes, describeMapChanges("env", old.Environment, new.Environment)...)
+	if !equality.Semantic.DeepEqual(old.Pre, new.Pre) {
+		changes = append(changes, "pre steps changed")
+	}
+	if !equality.Semantic.DeepEqual(old.Test, new.Test) {
+		changes = append(changes, "test steps changed")
+	}
+	if !equality.Semantic.DeepEqual(old.Post, new.Post) {
+		changes = append(changes, "post steps changed")
+	}
+	if !equality.Semantic.DeepEqual(old.Dependencies, new.Dependencies) {
+		changes = append(changes, "dependencies changed")
+	}
+	if !equality.Semantic.DeepEqual(old.Leases, new.Leases) {
+		changes = append(changes, "leases changed")
+	}
+	return changes
+}
+
+func testType(test cioperatorapi.TestStepConfiguration) string {
+	switch {
+	case test.ContainerTestConfiguration != nil:
+		return "container"
+	case test.MultiStageTestConfiguration != nil, test.MultiStageTestConfigurationLiteral != nil:
+		return "multi-stage"
+	default:
+		return "template"
+	}
+}
+
+// jobImpact generates Prow jobs for both versions of the configuration and
+// compares them. Either of the versions may be nil when a file is added or
+// removed.
+func jobImpact(old, new *config.DataWithInfo) JobImpact {
+	oldJobs, newJobs := generatedJobsByName(old), generatedJobsByName(new)
+	var impact JobImpact
+	for _, name := range sets.StringKeySet(newJobs).List() {
+		oldJob, existed := oldJobs[name]
+		switch {
+		case !existed:
+			impact.Added = append(impact.Added, name)
+		case !equality.Semantic.DeepEqual(oldJob, newJobs[name]):
+			impact.Changed = append(impact.Changed, name)
+		}
+	}
+	for _, name := range sets.StringKeySet(oldJobs).List() {
+		if _, exists := newJobs[name]; !exists {
+			impact.Removed = append(impact.Removed, name)
+		}
+	}
+	return impact
+}
+
+func generatedJobsByName(data *config.DataWithInfo) map[string]interface{} {
+	ret := map[string]interface{}{}
+	if data == nil {
+		return ret
+	}
+	jobConfig := prowgen.GenerateJobs(&data.Configuration, &prowgen.ProwgenInfo{Metadata: data.Info.Metadata})
+	addJob := func(job prowconfig.JobBase, spec interface{}) {
+		ret[job.Name] = spec
+	}
+	for _, jobs := range jobConfig.PresubmitsStatic {
+		for _, job := range jobs {
+			addJob(job.JobBase, job)
+		}
+	}
+	for _, jobs := range jobConfig.PostsubmitsStatic {
+		for _, job := range jobs {
+			addJob(job.JobBase, job)
+		}
+	}
+	for _, job := range jobConfig.Periodics {
+		addJob(job.JobBase, job)
+	}
+	return ret
+}
+
+// FormatConfigSummaries renders the summaries as Markdown, suitable to be
+// posted as a comment on a pull request
+func FormatConfigSummaries(summaries []ConfigSummary) string {
+	if len(summaries) == 0 {
+		return "No ci-operator configuration changes detected.\n"
+	}
+	var b strings.Builder
+	b.WriteString("## ci-operator configuration changes\n\n")
+	for _, summary := range summaries {
+		b.WriteString(fmt.Sprintf("### `%s`\n\n", summary.Filename))
+		switch {
+		case summary.Added:
+			b.WriteString("Configuration file added.\n\n")
+		case summary.Removed:
+			b.WriteString("Configuration file removed.\n\n")
+		}
+		writeList := func(title string, items []string, code bool) {
+			if len(items) == 0 {
+				return
+			}
+			b.WriteString(fmt.Sprintf("%s:\n", title))
+			for _, item := range items {
+				if code {
+					item = fmt.Sprintf("`%s`", item)
+				}
+				b.WriteString(fmt.Sprintf("- %s\n", item))
+			}
+			b.WriteString("\n")
+		}
+		writeList("Tests added", summary.TestsAdded, true)
+		writeList("Tests removed", summary.TestsRemoved, true)
+		writeList("Changes", summary.Changes, false)
+		writeList("Generated jobs added", summary.Jobs.Added, true)
+		writeList("Generated jobs removed", summary.Jobs.Removed, true)
+		writeList("Generated jobs changed", summary.Jobs.Changed, true)
+	}
+	return b.String()
+}
@@ -0,0 +1,248 @@
+package diffs
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	utilpointer "k8s.io/utils/pointer"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func summaryTestConfig(mutate func(*cioperatorapi.ReleaseBuildConfiguration)) config.DataWithInfo {
+	data := config.DataWithInfo{
+		Configuration: cioperatorapi.ReleaseBuildConfiguration{
+			InputConfiguration: cioperatorapi.InputConfiguration{
+				BuildRootImage: &cioperatorapi.BuildRootImageConfiguration{
+					ImageStreamTagReference: &cioperatorapi.ImageStreamTagReference{Namespace: "ci", Name: "root", Tag: "latest"},
+				},
+			},
+			Images: []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{{To: "component"}},
+			PromotionConfiguration: &cioperatorapi.PromotionConfiguration{
+				Namespace: "ocp",
+				Name:      "4.9",
+			},
+			Tests: []cioperatorapi.TestStepConfiguration{
+				{As: "unit", ContainerTestConfiguration: &cioperatorapi.ContainerTestConfiguration{From: "src"}, Commands: "make unit"},
+				{As: "e2e", MultiStageTestConfiguration: &cioperatorapi.MultiStageTestConfiguration{
+					ClusterProfile: cioperatorapi.ClusterProfileAWS,
+					Workflow:       utilpointer.StringPtr("openshift-e2e-aws"),
+					Environment:    cioperatorapi.TestEnvironment{"TEST_SUITE": "openshift/conformance"},
+				}},
+			},
+			Resources: cioperatorapi.ResourceConfiguration{"*": {Requests: cioperatorapi.ResourceList{"cpu": "100m"}}},
+		},
+		Info: config.Info{
+			Metadata: cioperatorapi.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+			Filename: "org-repo-master.yaml",
+		},
+	}
+	if mutate != nil {
+		mutate(&data.Configuration)
+	}
+	return data
+}
+
+func TestSummarizeCiopConfigChanges(t *testing.T) {
+	testCases := []struct {
+		name     string
+		master   config.DataByFilename
+		pr       config.DataByFilename
+		expected []ConfigSummary
+	}{
+		{
+			name:   "no changes",
+			master: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+			pr:     config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+		},
+		{
+			name: "new config",
+			pr:   config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+			expected: []ConfigSummary{{
+				Filename:   "org-repo-master.yaml",
+				Added:      true,
+				TestsAdded: []string{"e2e", "unit"},
+				Jobs: JobImpact{Added: []string{
+					"branch-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-e2e",
+					"pull-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-unit",
+				}},
+			}},
+		},
+		{
+			name:   "removed config",
+			master: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+			expected: []ConfigSummary{{
+				Filename:     "org-repo-master.yaml",
+				Removed:      true,
+				TestsRemoved: []string{"e2e", "unit"},
+				Jobs: JobImpact{Removed: []string{
+					"branch-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-e2e",
+					"pull-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-unit",
+				}},
+			}},
+		},
+		{
+			name:   "test added and removed",
+			master: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+			pr: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(func(c *cioperatorapi.ReleaseBuildConfiguration) {
+				c.Tests[0].As = "unit-new"
+			})},
+			expected: []ConfigSummary{{
+				Filename:     "org-repo-master.yaml",
+				TestsAdded:   []string{"unit-new"},
+				TestsRemoved: []string{"unit"},
+				Jobs: JobImpact{
+					Added:   []string{"pull-ci-org-repo-master-unit-new"},
+					Removed: []string{"pull-ci-org-repo-master-unit"},
+				},
+			}},
+		},
+		{
+			name:   "multi-stage test changed",
+			master: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+			pr: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(func(c *cioperatorapi.ReleaseBuildConfiguration) {
+				c.Tests[1].MultiStageTestConfiguration = &cioperatorapi.MultiStageTestConfiguration{
+					ClusterProfile: cioperatorapi.ClusterProfileGCP,
+					Workflow:       utilpointer.StringPtr("openshift-e2e-gcp"),
+					Environment:    cioperatorapi.TestEnvironment{"TEST_SUITE": "openshift/conformance/serial", "TEST_TYPE": "upgrade"},
+				}
+			})},
+			expected: []ConfigSummary{{
+				Filename: "org-repo-master.yaml",
+				Changes: []string{
+					`test e2e: cluster profile changed from "aws" to "gcp"`,
+					`test e2e: workflow changed from "openshift-e2e-aws" to "openshift-e2e-gcp"`,
+					"test e2e: env TEST_SUITE changed",
+					"test e2e: env TEST_TYPE added",
+				},
+				Jobs: JobImpact{Changed: []string{"pull-ci-org-repo-master-e2e"}},
+			}},
+		},
+		{
+			name:   "images and promotion changed",
+			master: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(nil)},
+			pr: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(func(c *cioperatorapi.ReleaseBuildConfiguration) {
+				c.Images = append(c.Images, cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{To: "other"})
+				c.PromotionConfiguration = &cioperatorapi.PromotionConfiguration{
+					Namespace:      "ocp",
+					Name:           "4.10",
+					ExcludedImages: []string{"other"},
+					Disabled:       true,
+				}
+				c.BuildRootImage = &cioperatorapi.BuildRootImageConfiguration{FromRepository: true}
+				c.BinaryBuildCommands = "make"
+			})},
+			expected: []ConfigSummary{{
+				Filename: "org-repo-master.yaml",
+				Changes: []string{
+					"image other added",
+					"promotion target changed from ocp/4.9:${component} to ocp/4.10:${component}",
+					"promotion disabled",
+					"image other excluded from promotion",
+					"build_root changed",
+					"other configuration changed",
+				},
+				Jobs: JobImpact{Changed: []string{
+					"branch-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-e2e",
+					"pull-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-unit",
+				}},
+			}},
+		},
+		{
+			name: "base images, releases and additional images changed",
+			master: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(func(c *cioperatorapi.ReleaseBuildConfiguration) {
+				c.BaseImages = map[string]cioperatorapi.ImageStreamTagReference{"base": {Namespace: "ocp", Name: "4.9", Tag: "base"}}
+				c.Releases = map[string]cioperatorapi.UnresolvedRelease{"latest": {Release: &cioperatorapi.Release{Version: "4.9", Channel: cioperatorapi.ReleaseChannelStable}}}
+			})},
+			pr: config.DataByFilename{"org-repo-master.yaml": summaryTestConfig(func(c *cioperatorapi.ReleaseBuildConfiguration) {
+				c.BaseImages = map[string]cioperatorapi.ImageStreamTagReference{"base": {Namespace: "ocp", Name: "4.10", Tag: "base"}}
+				c.BaseRPMImages = map[string]cioperatorapi.ImageStreamTagReference{"rpms": {Namespace: "ocp", Name: "4.10", Tag: "rpms"}}
+				c.PromotionConfiguration.AdditionalImages = map[string]string{"extra": "src"}
+			})},
+			expected: []ConfigSummary{{
+				Filename: "org-repo-master.yaml",
+				Changes: []string{
+					"additional promoted image extra added",
+					"base image base changed",
+					"base RPM image rpms added",
+					"release latest removed",
+				},
+				Jobs: JobImpact{Changed: []string{
+					"branch-ci-org-repo-master-images",
+					"pull-ci-org-repo-master-e2e",
+					"pull-ci-org-repo-master-images",
+				}},
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := SummarizeCiopConfigChanges(tc.master, tc.pr)
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected summary: %s", diff)
+			}
+		})
+	}
+}
+
+func TestDescribeMapChanges(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old, new interface{}
+		expected []string
+	}{
+		{
+			name:     "typed values compared key by key",
+			old:      map[string]cioperatorapi.ImageStreamTagReference{"base": {Name: "4.9"}, "gone": {Name: "4.9"}},
+			new:      map[string]cioperatorapi.ImageStreamTagReference{"base": {Name: "4.10"}, "new": {Name: "4.10"}},
+			expected: []string{"thing base changed", "thing gone removed", "thing new added"},
+		},
+		{
+			name: "nil and empty maps are the same",
+			old:  map[string]string(nil),
+			new:  map[string]string{},
+		},
+		{
+			name:     "values that are not maps are described as a whole",
+			old:      []string{"a"},
+			new:      []string{"b"},
+			expected: []string{"things changed"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, describeMapChanges("thing", tc.old, tc.new)); diff != "" {
+				t.Errorf("unexpected changes: %s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatConfigSummaries(t *testing.T) {
+	summaries := []ConfigSummary{
+		{
+			Filename:   "org-repo-master.yaml",
+			Added:      true,
+			TestsAdded: []string{"e2e", "unit"},
+			Jobs:       JobImpact{Added: []string{"pull-ci-org-repo-master-e2e", "pull-ci-org-repo-master-unit"}},
+		},
+		{
+			Filename:     "org-other-master.yaml",
+			TestsRemoved: []string{"lint"},
+			Changes:      []string{`test e2e: workflow changed from "openshift-e2e-aws" to "openshift-e2e-gcp"`},
+			Jobs: JobImpact{
+				Removed: []string{"pull-ci-org-other-master-lint"},
+				Changed: []string{"pull-ci-org-other-master-e2e"},
+			},
+		},
+	}
+	testhelper.CompareWithFixture(t, FormatConfigSummaries(summaries))
+}
@@ -19,10 +19,38 @@ type IssueResponse struct {
 	Error error
 }
 
+// SearchRequest describes a client call to search for similar issues
+type SearchRequest struct {
+	Title, Description string
+}
+
+// SearchResponse describes a client response for searching for similar issues
+type SearchResponse struct {
+	Issues []jira.Issue
+	Error  error
+}
+
+// CommentRequest describes a client call to comment on an issue
+type CommentRequest struct {
+	Key, Comment, Reporter string
+}
+
+// LinkRequest describes a client call to link an issue to others
+type LinkRequest struct {
+	Key     string
+	Related string
+}
+
 // Fake is an injectable IssueFiler
 type Fake struct {
 	behavior map[IssueRequest]IssueResponse
 	unwanted []IssueRequest
+
+	searches         map[SearchRequest]SearchResponse
+	comments         map[CommentRequest]error
+	unwantedComments []CommentRequest
+	links            map[LinkRequest]error
+	unwantedLinks    []LinkRequest
 }
 
 // FileIssue files the issue using injected behavior
@@ -42,6 +70,47 @@ func (f *Fake) FileIssue(issueType, title, description, reporter string, logger
 	return response.Issue, response.Error
 }
 
+// FindSimilarIssues searches using injected behavior, finding
+// nothing for searches that were not registered
+func (f *Fake) FindSimilarIssues(title, description string, logger *logrus.Entry) ([]jira.Issue, error) {
+	request := SearchRequest{Title: title, Description: description}
+	response, registered := f.searches[request]
+	if !registered {
+		return nil, nil
+	}
+	delete(f.searches, request)
+	return response.Issues, response.Error
+}
+
+// CommentOnIssue comments using injected behavior
+func (f *Fake) CommentOnIssue(key, comment, reporter string, logger *logrus.Entry) error {
+	request := CommentRequest{Key: key, Comment: comment, Reporter: reporter}
+	err, registered := f.comments[request]
+	if !registered {
+		f.unwantedComments = append(f.unwantedComments, request)
+		return errors.New("no such comment request behavior in fake")
+	}
+	delete(f.comments, request)
+	return err
+}
+
+// LinkIssues links using injected behavior
+func (f *Fake) LinkIssues(key string, related []string, logger *logrus.Entry) error {
+	for _, other := range related {
+		request := LinkRequest{Key: key, Related: other}
+		err, registered := f.links[request]
+		if !registered {
+			f.unwantedLinks = append(f.unwantedLinks, request)
+			return errors.New("no such link request behavior in fake")
+		}
+		delete(f.links, request)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Validate ensures that all expected client calls happened
 func (f *Fake) Validate(t *testing.T) {
 	for request := range f.behavior {
@@ -50,6 +119,21 @@ func (f *Fake) Validate(t *testing.T) {
 	for _, request := range f.unwanted {
 		t.Errorf("fake issue filer got unwanted request: %v", request)
 	}
+	for request := range f.searches {
+		t.Errorf("fake issue filer did not get search: %v", request)
+	}
+	for request := range f.comments {
+		t.Errorf("fake issue filer did not get comment: %v", request)
+	}
+	for _, request := range f.unwantedComments {
+		t.Errorf("fake issue filer got unwanted comment: %v", request)
+	}
+	for request := range f.links {
+		t.Errorf("fake issue filer did not get link: %v", request)
+	}
+	for _, request := range f.unwantedLinks {
+		t.Errorf("fake issue filer got unwanted link: %v", request)
+	}
 }
 
 var _ IssueFiler = &Fake{}
@@ -58,3 +142,21 @@ var _ IssueFiler = &Fake{}
 func NewFake(calls map[IssueRequest]IssueResponse) *Fake {
 	return &Fake{behavior: calls}
 }
+
+// WithSearches injects behavior for searches for similar issues
+func (f *Fake) WithSearches(searches map[SearchRequest]SearchResponse) *Fake {
+	f.searches = searches
+	return f
+}
+
+// WithComments injects behavior for comments on issues
+func (f *Fake) WithComments(comments map[CommentRequest]error) *Fake {
+	f.comments = comments
+	return f
+}
+
+// WithLinks injects behavior for links between issues
+func (f *Fake) WithLinks(links map[LinkRequest]error) *Fake {
+	f.links = links
+	return f
+}
@@ -3,6 +3,8 @@ package jira
 import (
 	"fmt"
 	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/andygrunwald/go-jira"
 	"github.com/sirupsen/logrus"
@@ -21,11 +23,27 @@ const (
 	IssueTypeRequest  = "Request"
 	IssueTypeStory    = "Story"
 	IssueTypeTask     = "Task"
+
+	// maxSimilarIssues is the most candidates we show to a
+	// user that is about to file a possible duplicate
+	maxSimilarIssues = 5
+	// minSummaryWords is the fewest words a title needs to have
+	// to be distinctive enough to search for similar summaries
+	minSummaryWords = 3
+	// linkTypeRelates is the Jira issue link type for related issues
+	linkTypeRelates = "Relates"
 )
 
 // IssueFiler knows how to file an issue in Jira
 type IssueFiler interface {
 	FileIssue(issueType, title, description, reporter string, logger *logrus.Entry) (*jira.Issue, error)
+	// FindSimilarIssues searches for open issues that look like
+	// duplicates of the issue that would be filed with the data
+	FindSimilarIssues(title, description string, logger *logrus.Entry) ([]jira.Issue, error)
+	// CommentOnIssue adds a comment to an existing issue instead of filing a new one
+	CommentOnIssue(key, comment, reporter string, logger *logrus.Entry) error
+	// LinkIssues records that the issue relates to the other issues
+	LinkIssues(key string, related []string, logger *logrus.Entry) error
 }
 
 type slackClient interface {
@@ -53,9 +71,24 @@ func (a *jiraAdapter) CreateIssue(issue *jira.Issue) (*jira.Issue, *jira.Respons
 	return a.delegate.Issue.Create(issue)
 }
 
+func (a *jiraAdapter) SearchIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	return a.delegate.Issue.Search(jql, options)
+}
+
+func (a *jiraAdapter) AddComment(issueID string, comment *jira.Comment) (*jira.Comment, *jira.Response, error) {
+	return a.delegate.Issue.AddComment(issueID, comment)
+}
+
+func (a *jiraAdapter) AddLink(link *jira.IssueLink) (*jira.Response, error) {
+	return a.delegate.Issue.AddLink(link)
+}
+
 type jiraClient interface {
 	FindUser(property string) ([]jira.User, *jira.Response, error)
 	CreateIssue(issue *jira.Issue) (*jira.Issue, *jira.Response, error)
+	SearchIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error)
+	AddComment(issueID string, comment *jira.Comment) (*jira.Comment, *jira.Response, error)
+	AddLink(link *jira.IssueLink) (*jira.Response, error)
 }
 
 // filer caches information from Jira to make filing issues easier
@@ -98,6 +131,101 @@ func (f *filer) FileIssue(issueType, title, description, reporter string, logger
 	return issue, jirautil.JiraError(response, err)
 }
 
+// FindSimilarIssues searches for open issues in the project that share
+// a title, or a job name and failure reason, with the issue we would file
+func (f *filer) FindSimilarIssues(title, description string, logger *logrus.Entry) ([]jira.Issue, error) {
+	query := similarIssuesQuery(f.project.Key, title, description)
+	if query == "" {
+		return nil, nil
+	}
+	logger.WithField("query", query).Debug("Searching for similar Jira issues.")
+	issues, response, err := f.jiraClient.SearchIssues(query, &jira.SearchOptions{MaxResults: maxSimilarIssues, Fields: []string{"summary", "status"}})
+	return issues, jirautil.JiraError(response, err)
+}
+
+var (
+	// jobNamePattern matches the names of jobs generated for ci-operator configs
+	jobNamePattern = regexp.MustCompile(`\b(?:rehearse-\d+-)?(?:pull|branch|periodic)-ci-[\w.-]+`)
+	// reasonPattern matches the failure reason ci-operator records, as we add it to descriptions
+	reasonPattern = regexp.MustCompile(`with reason ([\w:,.-]+)`)
+	// querySpecialCharacters are characters that have meaning in Jira text searches
+	querySpecialCharacters = regexp.MustCompile(`[+\-&|!(){}\[\]^"~*?:\\/]+`)
+)
+
+// similarIssuesQuery builds a JQL query for open issues in the project whose
+// summary contains the title as a phrase, or which mention one of the job names
+// in the description together with one of the failure reasons, when there are
+// any. Fuzzy matches on single words match almost any issue, so titles that are
+// too short to be distinctive and reasons without a job name are not searched
+// for. Returns nothing if there is nothing meaningful to search for.
+func similarIssuesQuery(project, title, description string) string {
+	var clauses []string
+	if words := strings.Fields(querySpecialCharacters.ReplaceAllString(title, " ")); len(words) >= minSummaryWords {
+		clauses = append(clauses, fmt.Sprintf(`summary ~ "\"%s\""`, strings.Join(words, " ")))
+	}
+	var reasons []string
+	for _, match := range reasonPattern.FindAllStringSubmatch(description, -1) {
+		// ci-operator joins multiple reasons with commas
+		for _, reason := range strings.Split(strings.Trim(match[1], ",."), ",") {
+			if reason != "" {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+	if mentions := textPhrasesClause(jobNamePattern.FindAllString(description, -1)); mentions != "" {
+		if reasonMentions := textPhrasesClause(reasons); reasonMentions != "" {
+			mentions = fmt.Sprintf("%s AND %s", mentions, reasonMentions)
+		}
+		clauses = append(clauses, mentions)
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`project = %s AND statusCategory != Done AND (%s) ORDER BY updated DESC`, project, strings.Join(clauses, " OR "))
+}
+
+// textPhrasesClause builds a JQL clause matching issues that mention any of
+// the phrases verbatim, or nothing if there are no phrases
+func textPhrasesClause(phrases []string) string {
+	var clauses []string
+	seen := map[string]bool{}
+	for _, phrase := range phrases {
+		if seen[phrase] {
+			continue
+		}
+		seen[phrase] = true
+		clauses = append(clauses, fmt.Sprintf(`text ~ "\"%s\""`, phrase))
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s)", strings.Join(clauses, " OR "))
+}
+
+// CommentOnIssue adds the comment to the issue, crediting the Slack reporter
+func (f *filer) CommentOnIssue(key, comment, reporter string, logger *logrus.Entry) error {
+	suffix, _ := f.resolveRequester(reporter, logger)
+	logger.WithField("issue", key).Debug("Commenting on Jira issue.")
+	_, response, err := f.jiraClient.AddComment(key, &jira.Comment{Body: fmt.Sprintf("%s\n\nThis comment was added by %s", comment, suffix)})
+	return jirautil.JiraError(response, err)
+}
+
+// LinkIssues links the issue to each of the related issues
+func (f *filer) LinkIssues(key string, related []string, logger *logrus.Entry) error {
+	for _, other := range related {
+		logger.WithFields(logrus.Fields{"issue": key, "related": other}).Debug("Linking Jira issues.")
+		response, err := f.jiraClient.AddLink(&jira.IssueLink{
+			Type:         jira.IssueLinkType{Name: linkTypeRelates},
+			InwardIssue:  &jira.Issue{Key: key},
+			OutwardIssue: &jira.Issue{Key: other},
+		})
+		if err := jirautil.JiraError(response, err); err != nil {
+			return fmt.Errorf("could not link %s to %s: %w", key, other, err)
+		}
+	}
+	return nil
+}
+
 // resolveRequester attempts to get more information about the Slack
 // user that requested the Jira issue, doing everything best-effort
 func (f *filer) resolveRequester(reporter string, logger *logrus.Entry) (string, *jira.User) {
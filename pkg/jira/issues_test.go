@@ -63,6 +63,18 @@ func (f *fakeJiraClient) CreateIssue(issue *jira.Issue) (*jira.Issue, *jira.Resp
 	return nil, nil, errors.New("not implemented")
 }
 
+func (f *fakeJiraClient) SearchIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (f *fakeJiraClient) AddComment(issueID string, comment *jira.Comment) (*jira.Comment, *jira.Response, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func (f *fakeJiraClient) AddLink(link *jira.IssueLink) (*jira.Response, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (f *fakeJiraClient) Validate(t *testing.T) {
 	for user := range f.searchBehavior {
 		t.Errorf("fake did not get search: %v", user)
@@ -171,3 +183,46 @@ func TestResolveRequester(t *testing.T) {
 		})
 	}
 }
+
+func TestSimilarIssuesQuery(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		title       string
+		description string
+		expected    string
+	}{
+		{
+			name:     "only a title",
+			title:    "Image builds fail: (again!)",
+			expected: `project = DPTP AND statusCategory != Done AND (summary ~ "\"Image builds fail again\"") ORDER BY updated DESC`,
+		},
+		{
+			name:        "job names and reasons in the description",
+			title:       "Job failed",
+			description: "The test step of periodic-ci-openshift-release-master-nightly-4.8-e2e-aws failed with reason executing_graph:step_failed,utilizing_lease:acquiring_lease. See also periodic-ci-openshift-release-master-nightly-4.8-e2e-aws",
+			expected:    `project = DPTP AND statusCategory != Done AND ((text ~ "\"periodic-ci-openshift-release-master-nightly-4.8-e2e-aws\"") AND (text ~ "\"executing_graph:step_failed\"" OR text ~ "\"utilizing_lease:acquiring_lease\"")) ORDER BY updated DESC`,
+		},
+		{
+			name:        "title and job name without a reason",
+			title:       "Nightly AWS jobs fail",
+			description: "See periodic-ci-openshift-release-master-nightly-4.8-e2e-aws",
+			expected:    `project = DPTP AND statusCategory != Done AND (summary ~ "\"Nightly AWS jobs fail\"" OR (text ~ "\"periodic-ci-openshift-release-master-nightly-4.8-e2e-aws\"")) ORDER BY updated DESC`,
+		},
+		{
+			name:        "reasons without a job name are too generic",
+			title:       "Job failed",
+			description: "It failed with reason executing_graph:step_failed",
+		},
+		{
+			name:  "nothing to search for",
+			title: "?!",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, similarIssuesQuery("DPTP", testCase.title, testCase.description)); diff != "" {
+				t.Errorf("%s: got incorrect query: %v", testCase.name, diff)
+			}
+		})
+	}
+}
@@ -26,8 +26,14 @@ type ConfigAgent interface {
 	// GetMatchingConfig loads a configuration that matches the metadata,
 	// allowing for regex matching on branch names.
 	GetMatchingConfig(metadata api.Metadata) (api.ReleaseBuildConfiguration, error)
+	// GetMatchingConfigAt loads a configuration that matches the metadata as of
+	// a retained generation, identified by its number or git SHA. An empty
+	// revision identifies the current generation.
+	GetMatchingConfigAt(metadata api.Metadata, revision string) (api.ReleaseBuildConfiguration, load.Generation, error)
 	GetAll() load.ByOrgRepo
 	GetGeneration() int
+	// GetGenerations returns the retained generations, newest first
+	GetGenerations() []load.Generation
 	AddIndex(indexName string, indexFunc IndexFn) error
 	GetFromIndex(indexName string, indexKey string) ([]*api.ReleaseBuildConfiguration, error)
 	SubscribeToIndexChanges(indexName string) (<-chan IndexDelta, error)
@@ -41,6 +47,9 @@ type configAgent struct {
 	configs          load.ByOrgRepo
 	configPath       string
 	generation       int
+	sha              string
+	historyLength    int
+	history          []configSnapshot
 	errorMetrics     *prometheus.CounterVec
 	indexFuncs       map[string]IndexFn
 	indexes          map[string]configIndex
@@ -50,6 +59,13 @@ type configAgent struct {
 
 type configIndex map[string][]*api.ReleaseBuildConfiguration
 
+// configSnapshot is a past generation of the configs, retained
+// to serve requests for configs as of that generation
+type configSnapshot struct {
+	generation load.Generation
+	configs    load.ByOrgRepo
+}
+
 var configReloadTimeMetric = prometheus.NewHistogram(
 	prometheus.HistogramOpts{
 		Name:    "configresolver_config_reload_duration_seconds",
@@ -84,6 +100,9 @@ type ConfigAgentOptions struct {
 	// ErrorMetric holds the CounterVec to count errors on. It must include a `error` label
 	// or the agent panics on the first error.
 	ErrorMetric *prometheus.CounterVec
+	// History is the number of generations, including the current one, that are retained
+	// in memory to serve requests for past generations. Defaults to 1.
+	History int
 }
 
 type ConfigAgentOption func(*ConfigAgentOptions)
@@ -94,6 +113,12 @@ func WithConfigMetrics(m *prometheus.CounterVec) ConfigAgentOption {
 	}
 }
 
+func WithConfigHistory(n int) ConfigAgentOption {
+	return func(o *ConfigAgentOptions) {
+		o.History = n
+	}
+}
+
 // NewConfigAgent returns a ConfigAgent interface that automatically reloads when
 // configs are changed on disk.
 func NewConfigAgent(configPath string, opts ...ConfigAgentOption) (ConfigAgent, error) {
@@ -104,7 +129,7 @@ func NewConfigAgent(configPath string, opts ...ConfigAgentOption) (ConfigAgent,
 	if opt.ErrorMetric == nil {
 		opt.ErrorMetric = prometheus.NewCounterVec(prometheus.CounterOpts{Name: "config_agent_errors_total"}, []string{"error"})
 	}
-	a := &configAgent{configPath: configPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric}
+	a := &configAgent{configPath: configPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, historyLength: opt.History}
 	a.reloadConfig = a.loadFilenameToConfig
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.reloadConfig(); err != nil {
@@ -124,7 +149,28 @@ func (a *configAgent) recordError(label string) {
 func (a *configAgent) GetMatchingConfig(metadata api.Metadata) (api.ReleaseBuildConfiguration, error) {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
-	orgConfigs, exist := a.configs[metadata.Org]
+	return matchingConfig(a.configs, metadata)
+}
+
+func (a *configAgent) GetMatchingConfigAt(metadata api.Metadata, revision string) (api.ReleaseBuildConfiguration, load.Generation, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	current := load.Generation{Generation: a.generation, SHA: a.sha}
+	if revision == "" || current.Matches(revision) {
+		config, err := matchingConfig(a.configs, metadata)
+		return config, current, err
+	}
+	for i := len(a.history) - 1; i >= 0; i-- {
+		if a.history[i].generation.Matches(revision) {
+			config, err := matchingConfig(a.history[i].configs, metadata)
+			return config, a.history[i].generation, err
+		}
+	}
+	return api.ReleaseBuildConfiguration{}, load.Generation{}, fmt.Errorf("configs at %s: %w", revision, ErrGenerationNotRetained)
+}
+
+func matchingConfig(configs load.ByOrgRepo, metadata api.Metadata) (api.ReleaseBuildConfiguration, error) {
+	orgConfigs, exist := configs[metadata.Org]
 	if !exist {
 		return api.ReleaseBuildConfiguration{}, fmt.Errorf("could not find any config for org %s", metadata.Org)
 	}
@@ -164,6 +210,16 @@ func (a *configAgent) GetGeneration() int {
 	return a.generation
 }
 
+func (a *configAgent) GetGenerations() []load.Generation {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	generations := []load.Generation{{Generation: a.generation, SHA: a.sha}}
+	for i := len(a.history) - 1; i >= 0; i-- {
+		generations = append(generations, a.history[i].generation)
+	}
+	return generations
+}
+
 func (a *configAgent) GetFromIndex(indexName string, indexKey string) ([]*api.ReleaseBuildConfiguration, error) {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
@@ -224,9 +280,7 @@ func (a *configAgent) loadFilenameToConfig() error {
 		if err != nil {
 			return time.Duration(0), fmt.Errorf("loading config failed: %w", err)
 		}
-		a.configs = configs
-		a.buildIndexes()
-		a.generation++
+		a.setConfigs(configs, gitRevision(a.configPath))
 		return time.Since(startTime), nil
 	}()
 	if err != nil {
@@ -237,6 +291,21 @@ func (a *configAgent) loadFilenameToConfig() error {
 	return nil
 }
 
+// setConfigs makes the configs loaded from the revision the current generation, retaining
+// the previous generations up to the history length. Must be called with the lock held.
+func (a *configAgent) setConfigs(configs load.ByOrgRepo, sha string) {
+	if a.historyLength > 1 && a.configs != nil {
+		a.history = append(a.history, configSnapshot{generation: load.Generation{Generation: a.generation, SHA: a.sha}, configs: a.configs})
+		if excess := len(a.history) - (a.historyLength - 1); excess > 0 {
+			a.history = a.history[excess:]
+		}
+	}
+	a.configs = configs
+	a.buildIndexes()
+	a.generation++
+	a.sha = sha
+}
+
 func (a *configAgent) buildIndexes() {
 	oldIndexes := a.indexes
 
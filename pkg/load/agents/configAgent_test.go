@@ -2,6 +2,7 @@ package agents
 
 import (
 	"encoding/json"
+	"errors"
 	"sort"
 	"sync"
 	"testing"
@@ -359,6 +360,83 @@ func TestConfigAgent_GetMatchingConfig(t *testing.T) {
 	}
 }
 
+func TestConfigAgent_GetMatchingConfigAt(t *testing.T) {
+	metadata := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	configsWith := func(commands string) load.ByOrgRepo {
+		return load.ByOrgRepo{"org": {"repo": {{Metadata: metadata, TestBinaryBuildCommands: commands}}}}
+	}
+	agent := &configAgent{lock: &sync.RWMutex{}, historyLength: 3}
+	agent.setConfigs(configsWith("first"), "1111111111111111111111111111111111111111")
+	agent.setConfigs(configsWith("second"), "2222222222222222222222222222222222222222")
+	agent.setConfigs(configsWith("third"), "")
+	agent.setConfigs(configsWith("fourth"), "4444444444444444444444444444444444444444")
+
+	expectedGenerations := []load.Generation{
+		{Generation: 4, SHA: "4444444444444444444444444444444444444444"},
+		{Generation: 3},
+		{Generation: 2, SHA: "2222222222222222222222222222222222222222"},
+	}
+	if diff := cmp.Diff(expectedGenerations, agent.GetGenerations()); diff != "" {
+		t.Errorf("unexpected retained generations: %s", diff)
+	}
+
+	var testCases = []struct {
+		name               string
+		revision           string
+		expected           string
+		expectedGeneration load.Generation
+		expectedErr        error
+	}{
+		{
+			name:               "no revision serves the current generation",
+			expected:           "fourth",
+			expectedGeneration: expectedGenerations[0],
+		},
+		{
+			name:               "generation number",
+			revision:           "3",
+			expected:           "third",
+			expectedGeneration: expectedGenerations[1],
+		},
+		{
+			name:               "full SHA",
+			revision:           "2222222222222222222222222222222222222222",
+			expected:           "second",
+			expectedGeneration: expectedGenerations[2],
+		},
+		{
+			name:               "short SHA",
+			revision:           "4444444",
+			expected:           "fourth",
+			expectedGeneration: expectedGenerations[0],
+		},
+		{
+			name:        "too short SHA",
+			revision:    "444",
+			expectedErr: ErrGenerationNotRetained,
+		},
+		{
+			name:        "generation no longer retained",
+			revision:    "1111111",
+			expectedErr: ErrGenerationNotRetained,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config, generation, err := agent.GetMatchingConfigAt(metadata, testCase.revision)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("expected error %v, got %v", testCase.expectedErr, err)
+			}
+			if diff := cmp.Diff(testCase.expected, config.TestBinaryBuildCommands); diff != "" {
+				t.Errorf("got incorrect config: %s", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedGeneration, generation); diff != "" {
+				t.Errorf("got incorrect generation: %s", diff)
+			}
+		})
+	}
+}
+
 func TestBuildIndexDelta(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -19,8 +19,13 @@ import (
 // memory and resolve ReleaseBuildConfigurations using the registry
 type RegistryAgent interface {
 	ResolveConfig(config api.ReleaseBuildConfiguration) (api.ReleaseBuildConfiguration, error)
+	// ResolveConfigAt resolves the config with the registry as of a retained generation,
+	// identified by its number or git SHA. An empty revision identifies the current generation.
+	ResolveConfigAt(config api.ReleaseBuildConfiguration, revision string) (api.ReleaseBuildConfiguration, load.Generation, error)
 	GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata)
 	GetGeneration() int
+	// GetGenerations returns the retained generations, newest first
+	GetGenerations() []load.Generation
 	registry.Resolver
 }
 
@@ -29,6 +34,9 @@ type registryAgent struct {
 	resolver      registry.Resolver
 	registryPath  string
 	generation    int
+	sha           string
+	historyLength int
+	history       []registrySnapshot
 	errorMetrics  *prometheus.CounterVec
 	flatRegistry  bool
 	references    registry.ReferenceByName
@@ -38,6 +46,13 @@ type registryAgent struct {
 	metadata      api.RegistryMetadata
 }
 
+// registrySnapshot is a past generation of the registry, retained
+// to serve requests for configs resolved as of that generation
+type registrySnapshot struct {
+	generation load.Generation
+	resolver   registry.Resolver
+}
+
 var registryReloadTimeMetric = prometheus.NewHistogram(
 	prometheus.HistogramOpts{
 		Name:    "configresolver_registry_reload_duration_seconds",
@@ -57,6 +72,9 @@ type RegistryAgentOptions struct {
 	// FlatRegistry describes if the registry is flat, which means org/repo/branch info can not be inferred
 	// from the filepath. Defaults to true.
 	FlatRegistry *bool
+	// History is the number of generations, including the current one, that are retained
+	// in memory to serve requests for past generations. Defaults to 1.
+	History int
 }
 
 type RegistryAgentOption func(*RegistryAgentOptions)
@@ -73,6 +91,27 @@ func WithRegistryFlat(v bool) RegistryAgentOption {
 	}
 }
 
+func WithRegistryHistory(n int) RegistryAgentOption {
+	return func(o *RegistryAgentOptions) {
+		o.History = n
+	}
+}
+
+// NewFakeRegistryAgent returns a new static registry agent
+// that can be used for tests
+func NewFakeRegistryAgent(references registry.ReferenceByName, chains registry.ChainByName, workflows registry.WorkflowByName, documentation map[string]string, metadata api.RegistryMetadata) RegistryAgent {
+	return &registryAgent{
+		lock:          &sync.RWMutex{},
+		resolver:      registry.NewResolver(references, chains, workflows, nil),
+		errorMetrics:  prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"error"}),
+		references:    references,
+		chains:        chains,
+		workflows:     workflows,
+		documentation: documentation,
+		metadata:      metadata,
+	}
+}
+
 // NewRegistryAgent returns a RegistryAgent interface that automatically reloads when
 // the registry is changed on disk.
 func NewRegistryAgent(registryPath string, opts ...RegistryAgentOption) (RegistryAgent, error) {
@@ -87,7 +126,7 @@ func NewRegistryAgent(registryPath string, opts ...RegistryAgentOption) (Registr
 		opt.FlatRegistry = utilpointer.BoolPtr(true)
 	}
 
-	a := &registryAgent{registryPath: registryPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, flatRegistry: *opt.FlatRegistry}
+	a := &registryAgent{registryPath: registryPath, lock: &sync.RWMutex{}, errorMetrics: opt.ErrorMetric, flatRegistry: *opt.FlatRegistry, historyLength: opt.History}
 	// Load config once so we fail early if that doesn't work and are ready as soon as we return
 	if err := a.loadRegistry(); err != nil {
 		return nil, fmt.Errorf("failed to load registry: %w", err)
@@ -108,12 +147,39 @@ func (a *registryAgent) ResolveConfig(config api.ReleaseBuildConfiguration) (api
 	return registry.ResolveConfig(a.resolver, config)
 }
 
+func (a *registryAgent) ResolveConfigAt(config api.ReleaseBuildConfiguration, revision string) (api.ReleaseBuildConfiguration, load.Generation, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	current := load.Generation{Generation: a.generation, SHA: a.sha}
+	if revision == "" || current.Matches(revision) {
+		resolved, err := registry.ResolveConfig(a.resolver, config)
+		return resolved, current, err
+	}
+	for i := len(a.history) - 1; i >= 0; i-- {
+		if a.history[i].generation.Matches(revision) {
+			resolved, err := registry.ResolveConfig(a.history[i].resolver, config)
+			return resolved, a.history[i].generation, err
+		}
+	}
+	return api.ReleaseBuildConfiguration{}, load.Generation{}, fmt.Errorf("registry at %s: %w", revision, ErrGenerationNotRetained)
+}
+
 func (a *registryAgent) GetGeneration() int {
 	a.lock.RLock()
 	defer a.lock.RUnlock()
 	return a.generation
 }
 
+func (a *registryAgent) GetGenerations() []load.Generation {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	generations := []load.Generation{{Generation: a.generation, SHA: a.sha}}
+	for i := len(a.history) - 1; i >= 0; i-- {
+		generations = append(generations, a.history[i].generation)
+	}
+	return generations
+}
+
 func (a *registryAgent) GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata) {
 	return a.references, a.chains, a.workflows, a.documentation, a.metadata
 }
@@ -129,6 +195,7 @@ func (a *registryAgent) loadRegistry() error {
 			a.recordError("failed to load ci-operator registry")
 			return time.Duration(0), fmt.Errorf("failed to load ci-operator registry (%w)", err)
 		}
+		a.retainCurrentGeneration()
 		a.references = references
 		a.chains = chains
 		a.workflows = workflows
@@ -136,6 +203,7 @@ func (a *registryAgent) loadRegistry() error {
 		a.metadata = metadata
 		a.resolver = registry.NewResolver(references, chains, workflows, observers)
 		a.generation++
+		a.sha = gitRevision(a.registryPath)
 		return time.Since(startTime), nil
 	}()
 	if err != nil {
@@ -146,6 +214,18 @@ func (a *registryAgent) loadRegistry() error {
 	return nil
 }
 
+// retainCurrentGeneration moves the current resolver into the history before it is
+// replaced, dropping the oldest retained generations. Must be called with the lock held.
+func (a *registryAgent) retainCurrentGeneration() {
+	if a.historyLength <= 1 || a.resolver == nil {
+		return
+	}
+	a.history = append(a.history, registrySnapshot{generation: load.Generation{Generation: a.generation, SHA: a.sha}, resolver: a.resolver})
+	if excess := len(a.history) - (a.historyLength - 1); excess > 0 {
+		a.history = a.history[excess:]
+	}
+}
+
 func (a *registryAgent) Resolve(name string, config api.MultiStageTestConfiguration) (api.MultiStageTestConfigurationLiteral, error) {
 	return a.resolver.Resolve(name, config)
 }
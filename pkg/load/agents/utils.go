@@ -2,7 +2,10 @@ package agents
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os/exec"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
@@ -12,6 +15,21 @@ import (
 	"k8s.io/test-infra/prow/interrupts"
 )
 
+// ErrGenerationNotRetained is returned when a generation that is not (or no longer)
+// retained by an agent is requested
+var ErrGenerationNotRetained = errors.New("generation is not retained")
+
+// gitRevision returns the revision checked out in the git repository containing
+// the path, or an empty string if it cannot be determined
+func gitRevision(path string) string {
+	out, err := exec.Command("git", "-C", path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		logrus.WithError(err).WithField("path", path).Debug("Could not determine the git revision")
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
 func startWatchers(path string, callback func() error, recordError func(string)) error {
 	cms, dirs, err := config.ListCMsAndDirs(path)
 	if err != nil {
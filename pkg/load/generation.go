@@ -0,0 +1,95 @@
+package load
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ConfigGenerationQuery requests a config as of a generation or git SHA of the configs
+	ConfigGenerationQuery = "configGeneration"
+	// RegistryGenerationQuery requests a config resolved as of a generation or git SHA of the registry
+	RegistryGenerationQuery = "registryGeneration"
+	// SHAQuery requests a config as of a git SHA of both the configs and the registry
+	SHAQuery = "sha"
+
+	// ConfigGenerationHeader holds the generation of the configs a response was served from
+	ConfigGenerationHeader = "X-Config-Generation"
+	// ConfigSHAHeader holds the git SHA of the configs a response was served from
+	ConfigSHAHeader = "X-Config-SHA"
+	// RegistryGenerationHeader holds the generation of the registry a response was resolved with
+	RegistryGenerationHeader = "X-Registry-Generation"
+	// RegistrySHAHeader holds the git SHA of the registry a response was resolved with
+	RegistrySHAHeader = "X-Registry-SHA"
+
+	// minimumSHAPrefix is the shortest prefix of a git SHA that identifies a generation
+	minimumSHAPrefix = 7
+)
+
+// Generation identifies a state of the configs or the registry loaded by the configresolver
+type Generation struct {
+	// Generation is incremented every time the configresolver reloads from disk
+	Generation int `json:"generation"`
+	// SHA is the git revision of the repository the state was loaded from, if known
+	SHA string `json:"sha,omitempty"`
+}
+
+// Matches determines whether the generation is identified by the revision, which
+// is either a generation number or a (prefix of a) git SHA
+func (g Generation) Matches(revision string) bool {
+	if revision == strconv.Itoa(g.Generation) {
+		return true
+	}
+	return g.SHA != "" && len(revision) >= minimumSHAPrefix && strings.HasPrefix(g.SHA, revision)
+}
+
+// ResolvedGenerations records the state of the configresolver a config was loaded with,
+// so that the same config can be requested again later
+type ResolvedGenerations struct {
+	Config   *Generation `json:"config,omitempty"`
+	Registry *Generation `json:"registry,omitempty"`
+}
+
+// SetGenerationHeaders records the generation in the response headers
+func SetGenerationHeaders(header http.Header, generationHeader, shaHeader string, generation Generation) {
+	header.Set(generationHeader, strconv.Itoa(generation.Generation))
+	if generation.SHA != "" {
+		header.Set(shaHeader, generation.SHA)
+	}
+}
+
+func generationFromHeaders(header http.Header, generationHeader, shaHeader string) *Generation {
+	generation, err := strconv.Atoi(header.Get(generationHeader))
+	if err != nil {
+		return nil
+	}
+	return &Generation{Generation: generation, SHA: header.Get(shaHeader)}
+}
+
+func resolvedGenerationsFromHeaders(header http.Header) *ResolvedGenerations {
+	generations := &ResolvedGenerations{
+		Config:   generationFromHeaders(header, ConfigGenerationHeader, ConfigSHAHeader),
+		Registry: generationFromHeaders(header, RegistryGenerationHeader, RegistrySHAHeader),
+	}
+	if generations.Config == nil && generations.Registry == nil {
+		return nil
+	}
+	return generations
+}
+
+// RevisionsFromQuery returns the revisions of the configs and the registry requested
+// in the query, where the SHA shortcut applies to both as they share a repository
+func RevisionsFromQuery(query url.Values) (configRevision, registryRevision string) {
+	configRevision, registryRevision = query.Get(ConfigGenerationQuery), query.Get(RegistryGenerationQuery)
+	if sha := query.Get(SHAQuery); sha != "" {
+		if configRevision == "" {
+			configRevision = sha
+		}
+		if registryRevision == "" {
+			registryRevision = sha
+		}
+	}
+	return configRevision, registryRevision
+}
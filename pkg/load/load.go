@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -36,6 +37,24 @@ type ResolverInfo struct {
 	Branch  string
 	// Variant is optional
 	Variant string
+	// ConfigGeneration optionally requests the config as of a generation
+	// or git SHA of the configs retained by the configresolver
+	ConfigGeneration string
+	// RegistryGeneration optionally requests the config resolved as of a
+	// generation or git SHA of the registry retained by the configresolver
+	RegistryGeneration string
+}
+
+func (info *ResolverInfo) addGenerationQueries(query url.Values) {
+	if info == nil {
+		return
+	}
+	if info.ConfigGeneration != "" {
+		query.Add(ConfigGenerationQuery, info.ConfigGeneration)
+	}
+	if info.RegistryGeneration != "" {
+		query.Add(RegistryGenerationQuery, info.RegistryGeneration)
+	}
 }
 
 const (
@@ -100,7 +119,7 @@ func fromPath(path string) (filenameToConfig, error) {
 		errGroup.Go(func() error {
 			ext := filepath.Ext(path)
 			if !info.IsDir() && (ext == ".yml" || ext == ".yaml") {
-				configSpec, err := Config(path, "", "", nil)
+				configSpec, _, err := Config(path, "", "", nil)
 				if err != nil {
 					return fmt.Errorf("failed to load ci-operator config (%w)", err)
 				}
@@ -121,7 +140,7 @@ func fromPath(path string) (filenameToConfig, error) {
 	return configs, utilerrors.NewAggregate([]error{err, errGroup.Wait()})
 }
 
-func Config(path, unresolvedPath, registryPath string, info *ResolverInfo) (*api.ReleaseBuildConfiguration, error) {
+func Config(path, unresolvedPath, registryPath string, info *ResolverInfo) (*api.ReleaseBuildConfiguration, *ResolvedGenerations, error) {
 	// Load the standard configuration path, env, or configresolver (in that order of priority)
 	var raw string
 
@@ -132,12 +151,12 @@ func Config(path, unresolvedPath, registryPath string, info *ResolverInfo) (*api
 	case len(path) > 0:
 		data, err := gzip.ReadFileMaybeGZIP(path)
 		if err != nil {
-			return nil, fmt.Errorf("--config error: %w", err)
+			return nil, nil, fmt.Errorf("--config error: %w", err)
 		}
 		raw = string(data)
 	case configSpecSet:
 		if len(configSpecEnv) == 0 {
-			return nil, errors.New("CONFIG_SPEC environment variable cannot be set to an empty string")
+			return nil, nil, errors.New("CONFIG_SPEC environment variable cannot be set to an empty string")
 		}
 		// if being run by pj-rehearse, config spec may be base64 and gzipped
 		if decoded, err := base64.StdEncoding.DecodeString(configSpecEnv); err != nil {
@@ -145,48 +164,48 @@ func Config(path, unresolvedPath, registryPath string, info *ResolverInfo) (*api
 		} else {
 			data, err := gzip.ReadBytesMaybeGZIP(decoded)
 			if err != nil {
-				return nil, fmt.Errorf("--config error: %w", err)
+				return nil, nil, fmt.Errorf("--config error: %w", err)
 			}
 			raw = string(data)
 		}
 	case len(unresolvedPath) > 0:
 		data, err := gzip.ReadFileMaybeGZIP(unresolvedPath)
 		if err != nil {
-			return nil, fmt.Errorf("--unresolved-config error: %w", err)
+			return nil, nil, fmt.Errorf("--unresolved-config error: %w", err)
 		}
-		configSpec, err := literalConfigFromResolver(data, info.Address)
+		configSpec, generations, err := literalConfigFromResolver(data, info)
 		err = results.ForReason("config_resolver_literal").ForError(err)
-		return configSpec, err
+		return configSpec, generations, err
 	case unresolvedConfigSet:
-		configSpec, err := literalConfigFromResolver([]byte(unresolvedConfigEnv), info.Address)
+		configSpec, generations, err := literalConfigFromResolver([]byte(unresolvedConfigEnv), info)
 		err = results.ForReason("config_resolver_literal").ForError(err)
-		return configSpec, err
+		return configSpec, generations, err
 	default:
-		configSpec, err := configFromResolver(info)
+		configSpec, generations, err := configFromResolver(info)
 		err = results.ForReason("config_resolver").ForError(err)
-		return configSpec, err
+		return configSpec, generations, err
 	}
 	configSpec := api.ReleaseBuildConfiguration{}
 	if err := yaml.UnmarshalStrict([]byte(raw), &configSpec); err != nil {
 		if len(path) > 0 {
-			return nil, fmt.Errorf("invalid configuration in file %s: %w\nvalue:\n%s", path, err, raw)
+			return nil, nil, fmt.Errorf("invalid configuration in file %s: %w\nvalue:\n%s", path, err, raw)
 		}
-		return nil, fmt.Errorf("invalid configuration: %w\nvalue:\n%s", err, raw)
+		return nil, nil, fmt.Errorf("invalid configuration: %w\nvalue:\n%s", err, raw)
 	}
 	if registryPath != "" {
 		refs, chains, workflows, _, _, observers, err := Registry(registryPath, false)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load registry: %w", err)
+			return nil, nil, fmt.Errorf("failed to load registry: %w", err)
 		}
 		configSpec, err = registry.ResolveConfig(registry.NewResolver(refs, chains, workflows, observers), configSpec)
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve configuration: %w", err)
+			return nil, nil, fmt.Errorf("failed to resolve configuration: %w", err)
 		}
 	}
-	return &configSpec, nil
+	return &configSpec, nil, nil
 }
 
-func configFromResolver(info *ResolverInfo) (*api.ReleaseBuildConfiguration, error) {
+func configFromResolver(info *ResolverInfo) (*api.ReleaseBuildConfiguration, *ResolvedGenerations, error) {
 	identifier := fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch)
 	if info.Variant != "" {
 		identifier = fmt.Sprintf("%s [%s]", identifier, info.Variant)
@@ -194,7 +213,7 @@ func configFromResolver(info *ResolverInfo) (*api.ReleaseBuildConfiguration, err
 	logrus.Infof("Loading configuration from %s for %s", info.Address, identifier)
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/config", info.Address), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for configresolver: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request for configresolver: %w", err)
 	}
 	query := req.URL.Query()
 	query.Add("org", info.Org)
@@ -203,11 +222,12 @@ func configFromResolver(info *ResolverInfo) (*api.ReleaseBuildConfiguration, err
 	if len(info.Variant) > 0 {
 		query.Add("variant", info.Variant)
 	}
+	info.addGenerationQueries(query)
 	req.URL.RawQuery = query.Encode()
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request to configresolver: %w", err)
+		return nil, nil, fmt.Errorf("failed to make request to configresolver: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
@@ -217,33 +237,41 @@ func configFromResolver(info *ResolverInfo) (*api.ReleaseBuildConfiguration, err
 		} else {
 			responseBody = string(data)
 		}
-		return nil, fmt.Errorf("got unexpected http %d status code from configresolver: %s", resp.StatusCode, responseBody)
+		return nil, nil, fmt.Errorf("got unexpected http %d status code from configresolver: %s", resp.StatusCode, responseBody)
 	}
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read configresolver response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read configresolver response body: %w", err)
 	}
 	configSpecHTTP := &api.ReleaseBuildConfiguration{}
 	err = json.Unmarshal(data, configSpecHTTP)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config from configresolver: invalid configuration: %w\nvalue:\n%s", err, string(data))
+		return nil, nil, fmt.Errorf("failed to unmarshal config from configresolver: invalid configuration: %w\nvalue:\n%s", err, string(data))
 	}
-	return configSpecHTTP, nil
+	return configSpecHTTP, resolvedGenerationsFromHeaders(resp.Header), nil
 }
 
-func literalConfigFromResolver(raw []byte, address string) (*api.ReleaseBuildConfiguration, error) {
+func literalConfigFromResolver(raw []byte, info *ResolverInfo) (*api.ReleaseBuildConfiguration, *ResolvedGenerations, error) {
 	// check that the user has sent us something reasonable
 	unresolvedConfig := &api.ReleaseBuildConfiguration{}
 	if err := yaml.UnmarshalStrict(raw, unresolvedConfig); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal unresolved config: invalid configuration: %w, raw: %v", err, string(raw))
+		return nil, nil, fmt.Errorf("failed to unmarshal unresolved config: invalid configuration: %w, raw: %v", err, string(raw))
 	}
 	encoded, err := json.Marshal(unresolvedConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal unresolved config: invalid configuration: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal unresolved config: invalid configuration: %w", err)
 	}
-	resp, err := http.Post(fmt.Sprintf("%s/resolve", address), "application/json", bytes.NewReader(encoded))
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/resolve", info.Address), bytes.NewReader(encoded))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request for configresolver: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	query := req.URL.Query()
+	info.addGenerationQueries(query)
+	req.URL.RawQuery = query.Encode()
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to request resolved config: %w", err)
+		return nil, nil, fmt.Errorf("failed to request resolved config: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
@@ -253,17 +281,17 @@ func literalConfigFromResolver(raw []byte, address string) (*api.ReleaseBuildCon
 		} else {
 			responseBody = string(data)
 		}
-		return nil, fmt.Errorf("got unexpected http %d status code from configresolver: %s", resp.StatusCode, responseBody)
+		return nil, nil, fmt.Errorf("got unexpected http %d status code from configresolver: %s", resp.StatusCode, responseBody)
 	}
 	resolved, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read configresolver response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read configresolver response body: %w", err)
 	}
 	resolvedConfig := &api.ReleaseBuildConfiguration{}
 	if err = json.Unmarshal(resolved, resolvedConfig); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal resolved config: invalid configuration: %w\n", err)
+		return nil, nil, fmt.Errorf("failed to unmarshal resolved config: invalid configuration: %w\n", err)
 	}
-	return resolvedConfig, nil
+	return resolvedConfig, resolvedGenerationsFromHeaders(resp.Header), nil
 }
 
 // Registry takes the path to a registry config directory and returns the full set of references, chains,
@@ -614,7 +614,7 @@ func TestConfig(t *testing.T) {
 					t.Fatalf("%s: failed to populate env var: %v", testCase.name, err)
 				}
 			}
-			config, err := Config(path, "", "", nil)
+			config, _, err := Config(path, "", "", nil)
 			if err == nil && testCase.expectedError {
 				t.Errorf("%s: expected an error, but got none", testCase.name)
 			}
@@ -647,6 +647,19 @@ func TestConfigFromResolver(t *testing.T) {
 			}
 		})
 	}
+	generationHandler := func(t *testing.T, jsonConfig []byte) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get(ConfigGenerationQuery) != "4b825dc" {
+				t.Errorf("%s: configGeneration should equal 4b825dc, but was %s", t.Name(), r.URL.Query().Get(ConfigGenerationQuery))
+			}
+			SetGenerationHeaders(w.Header(), ConfigGenerationHeader, ConfigSHAHeader, Generation{Generation: 3, SHA: "4b825dc642cb6eb9a060e54bf8d69288fbee4904"})
+			SetGenerationHeaders(w.Header(), RegistryGenerationHeader, RegistrySHAHeader, Generation{Generation: 5})
+			w.WriteHeader(http.StatusOK)
+			if _, err := w.Write(jsonConfig); err != nil {
+				t.Errorf("failed to write data: %v", err)
+			}
+		})
+	}
 	failingHandler := func(t *testing.T, jsonConfig []byte) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusBadRequest)
@@ -656,10 +669,12 @@ func TestConfigFromResolver(t *testing.T) {
 		})
 	}
 	var testCases = []struct {
-		name           string
-		handlerWrapper func(t *testing.T, jsonConfig []byte) http.Handler
-		expected       *api.ReleaseBuildConfiguration
-		expectedError  bool
+		name                string
+		handlerWrapper      func(t *testing.T, jsonConfig []byte) http.Handler
+		configGeneration    string
+		expected            *api.ReleaseBuildConfiguration
+		expectedGenerations *ResolvedGenerations
+		expectedError       bool
 	}{
 		{
 			name:           "getting config works",
@@ -667,6 +682,16 @@ func TestConfigFromResolver(t *testing.T) {
 			expected:       parsedConfig,
 			expectedError:  false,
 		},
+		{
+			name:             "getting config as of a generation works",
+			handlerWrapper:   generationHandler,
+			configGeneration: "4b825dc",
+			expected:         parsedConfig,
+			expectedGenerations: &ResolvedGenerations{
+				Config:   &Generation{Generation: 3, SHA: "4b825dc642cb6eb9a060e54bf8d69288fbee4904"},
+				Registry: &Generation{Generation: 5},
+			},
+		},
 		{
 			name:           "function errors on non OK status",
 			handlerWrapper: failingHandler,
@@ -684,7 +709,8 @@ func TestConfigFromResolver(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			server := httptest.NewServer(testCase.handlerWrapper(t, jsonConfig))
 			info.Address = server.URL
-			config, err := configFromResolver(&info)
+			info.ConfigGeneration = testCase.configGeneration
+			config, generations, err := configFromResolver(&info)
 			if err == nil && testCase.expectedError {
 				t.Errorf("%s: expected an error, but got none", testCase.name)
 			}
@@ -694,6 +720,9 @@ func TestConfigFromResolver(t *testing.T) {
 			if !reflect.DeepEqual(config, testCase.expected) {
 				t.Errorf("%s: didn't get correct config: %v", testCase.name, diff.ObjectReflectDiff(config, testCase.expected))
 			}
+			if !reflect.DeepEqual(generations, testCase.expectedGenerations) {
+				t.Errorf("%s: didn't get correct generations: %v", testCase.name, diff.ObjectReflectDiff(generations, testCase.expectedGenerations))
+			}
 			server.Close()
 		})
 	}
@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+// Func mutates the configuration in place and returns the number of changes
+// it made. Migrations must be idempotent: running a migration on its own
+// output must not change anything and return zero.
+type Func func(configuration *api.ReleaseBuildConfiguration, info *config.Info) (int, error)
+
+// Migration is a named transformation of a ci-operator configuration
+type Migration struct {
+	Name    string
+	Migrate Func
+}
+
+// Select returns the migrations from all whose names are in enabled, keeping
+// the order in which they are declared in all.
+func Select(all []Migration, enabled sets.String) ([]Migration, error) {
+	known := sets.NewString()
+	var selected []Migration
+	for _, migration := range all {
+		known.Insert(migration.Name)
+		if enabled.Has(migration.Name) {
+			selected = append(selected, migration)
+		}
+	}
+	if unknown := enabled.Difference(known); len(unknown) != 0 {
+		return nil, fmt.Errorf("invalid migrations %v, valid values: %v", unknown.List(), known.List())
+	}
+	return selected, nil
+}
+
+// AllowList limits the configurations that migrations operate on. An empty
+// set allows all values for the respective field.
+type AllowList struct {
+	Orgs     sets.String
+	Repos    sets.String
+	Branches sets.String
+}
+
+// Allows determines if the configuration described by the info may be migrated
+func (a AllowList) Allows(info config.Info) bool {
+	allowed := func(allowed sets.String, value string) bool {
+		return len(allowed) == 0 || allowed.Has(value)
+	}
+	return allowed(a.Orgs, info.Org) && allowed(a.Repos, info.Repo) && allowed(a.Branches, info.Branch)
+}
+
+// Result describes what happened when migrating a single configuration
+type Result struct {
+	// Changes holds the number of changes made, by migration name
+	Changes map[string]int
+	// Diff is a unified diff between the serialized configuration before
+	// and after the migrations ran
+	Diff string
+}
+
+// Changed determines if any migration changed the configuration
+func (r Result) Changed() bool {
+	return len(r.Changes) != 0
+}
+
+// Migrator applies an ordered list of migrations to configurations,
+// touching at most a limited number of files.
+type Migrator struct {
+	migrations []Migration
+	allowList  AllowList
+	// ceiling is the maximum number of files to change, zero means unlimited
+	ceiling int
+	touched int
+}
+
+// NewMigrator returns a migrator that runs the migrations in the given order
+// on allowed configurations until the ceiling of files touched is reached.
+// A ceiling of zero means no limit.
+func NewMigrator(migrations []Migration, allowList AllowList, ceiling int) *Migrator {
+	return &Migrator{migrations: migrations, allowList: allowList, ceiling: ceiling}
+}
+
+// Touched returns the number of files changed by the migrator so far
+func (m *Migrator) Touched() int {
+	return m.touched
+}
+
+// Migrate runs all migrations on the configuration, mutating it in place.
+// Callers running in dry-run mode may use the returned diff and discard the
+// data instead of committing it.
+func (m *Migrator) Migrate(data *config.DataWithInfo) (Result, error) {
+	result := Result{Changes: map[string]int{}}
+	if len(m.migrations) == 0 || !m.allowList.Allows(data.Info) || m.ceilingReached() {
+		return Result{}, nil
+	}
+
+	before, err := yaml.Marshal(data.Configuration)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	for _, migration := range m.migrations {
+		changes, err := migration.Migrate(&data.Configuration, &data.Info)
+		if err != nil {
+			return Result{}, fmt.Errorf("migration %s failed: %w", migration.Name, err)
+		}
+		if changes == 0 {
+			continue
+		}
+		if again, err := migration.Migrate(&data.Configuration, &data.Info); err != nil || again != 0 {
+			return Result{}, fmt.Errorf("migration %s is not idempotent: made %d changes on its own output (error: %v)", migration.Name, again, err)
+		}
+		result.Changes[migration.Name] = changes
+	}
+	if !result.Changed() {
+		return Result{}, nil
+	}
+	m.touched++
+
+	after, err := yaml.Marshal(data.Configuration)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to marshal migrated configuration: %w", err)
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: data.Info.RelativePath(),
+		ToFile:   data.Info.RelativePath(),
+		Context:  3,
+	})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to construct diff: %w", err)
+	}
+	result.Diff = diff
+	return result, nil
+}
+
+func (m *Migrator) ceilingReached() bool {
+	return m.ceiling > 0 && m.touched >= m.ceiling
+}
@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestSelect(t *testing.T) {
+	all := []Migration{{Name: "first"}, {Name: "second"}, {Name: "third"}}
+	testCases := []struct {
+		name          string
+		enabled       sets.String
+		expected      []string
+		expectedError error
+	}{
+		{
+			name: "nothing enabled",
+		},
+		{
+			name:     "declared order is kept",
+			enabled:  sets.NewString("third", "first"),
+			expected: []string{"first", "third"},
+		},
+		{
+			name:          "unknown migration",
+			enabled:       sets.NewString("first", "fourth"),
+			expectedError: errors.New("invalid migrations [fourth], valid values: [first second third]"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := Select(all, tc.enabled)
+			if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			var names []string
+			for _, migration := range selected {
+				names = append(names, migration.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected migrations: %s", diff)
+			}
+		})
+	}
+}
+
+// renameTests appends a suffix to all tests that don't have it yet
+func renameTests(configuration *api.ReleaseBuildConfiguration, _ *config.Info) (int, error) {
+	var changes int
+	for i, test := range configuration.Tests {
+		if test.As == "unit" {
+			configuration.Tests[i].As = "unit-migrated"
+			changes++
+		}
+	}
+	return changes, nil
+}
+
+// appendTest is not idempotent, as it always adds a new test
+func appendTest(configuration *api.ReleaseBuildConfiguration, _ *config.Info) (int, error) {
+	configuration.Tests = append(configuration.Tests, api.TestStepConfiguration{As: "new"})
+	return 1, nil
+}
+
+func TestMigrator(t *testing.T) {
+	data := func(branch string) *config.DataWithInfo {
+		return &config.DataWithInfo{
+			Configuration: api.ReleaseBuildConfiguration{
+				Tests: []api.TestStepConfiguration{{As: "unit", Commands: "make test"}},
+			},
+			Info: config.Info{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: branch}},
+		}
+	}
+	testCases := []struct {
+		name          string
+		migrations    []Migration
+		allowList     AllowList
+		ceiling       int
+		input         []*config.DataWithInfo
+		expected      []map[string]int
+		expectedError error
+	}{
+		{
+			name:       "migration applied to all files",
+			migrations: []Migration{{Name: "rename", Migrate: renameTests}},
+			input:      []*config.DataWithInfo{data("master"), data("release-4.8")},
+			expected:   []map[string]int{{"rename": 1}, {"rename": 1}},
+		},
+		{
+			name:       "ceiling limits touched files",
+			migrations: []Migration{{Name: "rename", Migrate: renameTests}},
+			ceiling:    1,
+			input:      []*config.DataWithInfo{data("master"), data("release-4.8")},
+			expected:   []map[string]int{{"rename": 1}, nil},
+		},
+		{
+			name:       "allow list excludes branches",
+			migrations: []Migration{{Name: "rename", Migrate: renameTests}},
+			allowList:  AllowList{Branches: sets.NewString("release-4.8")},
+			input:      []*config.DataWithInfo{data("master"), data("release-4.8")},
+			expected:   []map[string]int{nil, {"rename": 1}},
+		},
+		{
+			name:          "non-idempotent migration is rejected",
+			migrations:    []Migration{{Name: "rename", Migrate: renameTests}, {Name: "append", Migrate: appendTest}},
+			input:         []*config.DataWithInfo{data("master")},
+			expectedError: errors.New("migration append is not idempotent: made 1 changes on its own output (error: <nil>)"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			migrator := NewMigrator(tc.migrations, tc.allowList, tc.ceiling)
+			var changes []map[string]int
+			for _, input := range tc.input {
+				result, err := migrator.Migrate(input)
+				if diff := cmp.Diff(tc.expectedError, err, testhelper.EquateErrorMessage); diff != "" {
+					t.Fatalf("unexpected error: %s", diff)
+				}
+				if err != nil {
+					return
+				}
+				changes = append(changes, result.Changes)
+			}
+			if diff := cmp.Diff(tc.expected, changes); diff != "" {
+				t.Errorf("unexpected changes: %s", diff)
+			}
+		})
+	}
+}
+
+func TestMigratorDiff(t *testing.T) {
+	input := &config.DataWithInfo{
+		Configuration: api.ReleaseBuildConfiguration{
+			Tests: []api.TestStepConfiguration{{As: "unit", Commands: "make test"}},
+		},
+		Info: config.Info{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}},
+	}
+	result, err := NewMigrator([]Migration{{Name: "rename", Migrate: renameTests}}, AllowList{}, 0).Migrate(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testhelper.CompareWithFixture(t, result.Diff)
+}
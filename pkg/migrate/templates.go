@@ -0,0 +1,186 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilpointer "k8s.io/utils/pointer"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+const (
+	OpenshiftInstallerCustomTestImageTemplateName = "openshift_installer_custom_test_image"
+	OpenshiftInstallerUPITemplateName             = "openshift_installer_upi"
+	OpenShiftInstallerTemplateName                = "openshift_installer"
+)
+
+// TemplateMigrations returns the migrations of deprecated template tests to
+// multi-stage workflows, in the order in which they should be run. Only tests
+// using one of the allowed cluster profiles are migrated, all profiles are
+// allowed when the set is empty.
+func TemplateMigrations(allowedClusterProfiles sets.String) []Migration {
+	return []Migration{
+		{
+			Name:    OpenshiftInstallerCustomTestImageTemplateName,
+			Migrate: migrateOpenshiftInstallerCustomTestImageTemplates(allowedClusterProfiles),
+		},
+		{
+			Name:    OpenshiftInstallerUPITemplateName,
+			Migrate: migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(allowedClusterProfiles),
+		},
+		{
+			Name:    OpenShiftInstallerTemplateName,
+			Migrate: migrateOpenShiftInstallerTemplates(allowedClusterProfiles),
+		},
+	}
+}
+
+func upgradeWorkflowForClusterProfile(clusterProfile api.ClusterProfile) string {
+	return fmt.Sprintf("openshift-upgrade-%s", clusterProfile)
+}
+
+func e2eWorkflowForClusterProfile(clusterProfile api.ClusterProfile) string {
+	return fmt.Sprintf("openshift-e2e-%s", clusterProfile)
+}
+
+func migrateOpenShiftInstallerTemplates(allowedCloudproviders sets.String) Func {
+	return func(configuration *api.ReleaseBuildConfiguration, _ *config.Info) (migratedCount int, _ error) {
+		for idx, test := range configuration.Tests {
+			if test.OpenshiftInstallerClusterTestConfiguration == nil ||
+				(len(allowedCloudproviders) != 0 && !allowedCloudproviders.Has(string(test.OpenshiftInstallerClusterTestConfiguration.ClusterProfile))) {
+				continue
+			}
+
+			clusterProfile := test.OpenshiftInstallerClusterTestConfiguration.ClusterProfile
+			switch {
+			case test.OpenshiftInstallerClusterTestConfiguration.Upgrade:
+				test.OpenshiftInstallerClusterTestConfiguration = nil
+				test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
+					ClusterProfile: clusterProfile,
+					Workflow:       utilpointer.StringPtr(upgradeWorkflowForClusterProfile(clusterProfile)),
+				}
+			case test.Commands == "setup_ssh_bastion; TEST_SUITE=openshift/disruptive run-tests; TEST_SUITE=openshift/conformance/parallel run-tests":
+				// TODO(muller): Unfortunately there is no easy way to express this ("run same step twice")
+				continue
+			default:
+				test.OpenshiftInstallerClusterTestConfiguration = nil
+				test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
+					ClusterProfile: clusterProfile,
+					Workflow:       utilpointer.StringPtr(e2eWorkflowForClusterProfile(clusterProfile)),
+				}
+			}
+			test.Commands = ""
+			configuration.Tests[idx] = test
+			migratedCount++
+		}
+
+		return migratedCount, nil
+	}
+}
+
+func migrateOpenshiftInstallerCustomTestImageTemplates(allowedCloudproviders sets.String) Func {
+	return func(configuration *api.ReleaseBuildConfiguration, _ *config.Info) (migratedCount int, _ error) {
+		for idx, test := range configuration.Tests {
+			if test.OpenshiftInstallerCustomTestImageClusterTestConfiguration == nil ||
+				(len(allowedCloudproviders) != 0 && !allowedCloudproviders.Has(string(test.OpenshiftInstallerCustomTestImageClusterTestConfiguration.ClusterProfile))) {
+				continue
+			}
+
+			clusterProfile := test.OpenshiftInstallerCustomTestImageClusterTestConfiguration.ClusterProfile
+			fromImage := test.OpenshiftInstallerCustomTestImageClusterTestConfiguration.From
+			test.OpenshiftInstallerCustomTestImageClusterTestConfiguration = nil
+			test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
+				ClusterProfile: clusterProfile,
+				Test: []api.TestStep{{LiteralTestStep: &api.LiteralTestStep{
+					As:       "test",
+					From:     fromImage,
+					Commands: test.Commands,
+					Cli:      api.LatestReleaseName,
+					Resources: api.ResourceRequirements{
+						Requests: api.ResourceList{"cpu": "100m"},
+					},
+				}}},
+				Workflow: utilpointer.StringPtr(ipiWorkflowForClusterProfile(clusterProfile)),
+			}
+			test.Commands = ""
+
+			configuration.Tests[idx] = test
+			migratedCount++
+		}
+
+		return migratedCount, nil
+	}
+}
+
+func providerNameForProfile(clusterProfile api.ClusterProfile) string {
+	if clusterProfile == api.ClusterProfileAzure4 {
+		return "azure"
+	}
+	return string(clusterProfile)
+}
+
+func ipiWorkflowForClusterProfile(clusterProfile api.ClusterProfile) string {
+	return fmt.Sprintf("ipi-%s", providerNameForProfile(clusterProfile))
+}
+
+func migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(allowedCloudproviders sets.String) Func {
+	return func(configuration *api.ReleaseBuildConfiguration, info *config.Info) (migratedCount int, _ error) {
+		log := logrus.WithField("file", info.Filename)
+
+		for idx, test := range configuration.Tests {
+			if test.OpenshiftInstallerUPIClusterTestConfiguration == nil ||
+				(len(allowedCloudproviders) != 0 && !allowedCloudproviders.Has(string(test.OpenshiftInstallerUPIClusterTestConfiguration.ClusterProfile))) {
+				continue
+			}
+			log := log.WithField("field", fmt.Sprintf("tests.%d", idx))
+
+			commandFields := strings.Fields(test.Commands)
+			if n := len(commandFields); n != 2 {
+				log.Warnf("command %q didn't have exactly two fields, skipping migration of openshift_installer_upi template", test.Commands)
+				continue
+			}
+			equalSignSplit := strings.Split(commandFields[0], "=")
+			if n := len(equalSignSplit); n != 2 {
+				log.Warnf("splitting first field of command %q by = didn't yield exactly two results, skipping migration of openshift_installer_upi template", test.Commands)
+				continue
+			}
+
+			var testTypeEnv string
+			switch commandFields[1] {
+			case "run-tests":
+				testTypeEnv = ""
+			case "run-upgrade":
+				testTypeEnv = "upgrade"
+			default:
+				log.Warnf("command %q has unrecognized command element %q, known elements: ['run-tests', 'run-upgrade'], skipping migration of openshift_installer_upi template", test.Commands, commandFields[1])
+				continue
+			}
+
+			clusterProfile := test.OpenshiftInstallerUPIClusterTestConfiguration.ClusterProfile
+			test.OpenshiftInstallerUPIClusterTestConfiguration = nil
+			test.MultiStageTestConfiguration = &api.MultiStageTestConfiguration{
+				ClusterProfile: clusterProfile,
+				Environment: api.TestEnvironment{
+					// https://github.com/openshift/release/blob/ea3cc4842843c941e9fa1e71ce8a4dc3ce841184/ci-operator/step-registry/openshift/e2e/test/openshift-e2e-test-ref.yaml#L10
+					"TEST_SUITE": equalSignSplit[1],
+				},
+				Workflow: utilpointer.StringPtr(fmt.Sprintf("openshift-e2e-%s-upi", providerNameForProfile(clusterProfile))),
+			}
+			if testTypeEnv != "" {
+				// https://github.com/openshift/release/blob/ea3cc4842843c941e9fa1e71ce8a4dc3ce841184/ci-operator/step-registry/openshift/e2e/test/openshift-e2e-test-ref.yaml#L7
+				test.MultiStageTestConfiguration.Environment["TEST_TYPE"] = testTypeEnv
+			}
+			test.Commands = ""
+
+			configuration.Tests[idx] = test
+			migratedCount++
+		}
+
+		return migratedCount, nil
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package migrate
 
 import (
 	"testing"
@@ -121,7 +121,12 @@ func TestMigrateOpenshiftInstallerCustomTestImageTemplates(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actualMigrationCount := migrateOpenshiftInstallerCustomTestImageTemplates(tc.configuration, tc.allowedOrgs, tc.allowedBranches, tc.allowedCloudproviders)
+			migrator := NewMigrator([]Migration{{Name: "test", Migrate: migrateOpenshiftInstallerCustomTestImageTemplates(tc.allowedCloudproviders)}}, AllowList{Orgs: tc.allowedOrgs, Branches: tc.allowedBranches}, 0)
+			result, err := migrator.Migrate(tc.configuration)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actualMigrationCount := result.Changes["test"]
 			if actualMigrationCount != tc.expectedMigrationCount {
 				t.Errorf("expected %d migrated tests, got %d", tc.expectedMigrationCount, actualMigrationCount)
 			}
@@ -253,7 +258,12 @@ func TestMigrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(t *testin
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actualMigrationCount := migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(tc.configuration, tc.allowedOrgs, tc.allowedBranches, tc.allowedCloudproviders)
+			migrator := NewMigrator([]Migration{{Name: "test", Migrate: migrateOpenshiftOpenshiftInstallerUPIClusterTestConfiguration(tc.allowedCloudproviders)}}, AllowList{Orgs: tc.allowedOrgs, Branches: tc.allowedBranches}, 0)
+			result, err := migrator.Migrate(tc.configuration)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actualMigrationCount := result.Changes["test"]
 			if actualMigrationCount != tc.expectedMigrationCount {
 				t.Errorf("expected %d migrated tests, got %d", tc.expectedMigrationCount, actualMigrationCount)
 			}
@@ -396,7 +406,12 @@ func TestMigrateOpenshiftInstallerTemplates(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			actualMigrationCount := migrateOpenShiftInstallerTemplates(tc.configuration, tc.allowedOrgs, tc.allowedBranches, tc.allowedCloudproviders)
+			migrator := NewMigrator([]Migration{{Name: "test", Migrate: migrateOpenShiftInstallerTemplates(tc.allowedCloudproviders)}}, AllowList{Orgs: tc.allowedOrgs, Branches: tc.allowedBranches}, 0)
+			result, err := migrator.Migrate(tc.configuration)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actualMigrationCount := result.Changes["test"]
 			if actualMigrationCount != tc.expectedMigrationCount {
 				t.Errorf("expected %d migrated tests, got %d", tc.expectedMigrationCount, actualMigrationCount)
 			}
@@ -0,0 +1,64 @@
+package promotion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/steps/release"
+)
+
+// Targets collects the locations a set of configurations promote to, allowing
+// to detect configurations that would overwrite each other's images
+type Targets map[string][]*config.Info
+
+// Record adds the targets the configuration promotes to, both in the image
+// streams of the CI cluster and in external destinations
+func (t Targets) Record(configuration *cioperatorapi.ReleaseBuildConfiguration, info *config.Info) {
+	for _, destinations := range release.PromotionPlan(configuration, nil) {
+		for _, destination := range destinations {
+			t[destination] = append(t[destination], info)
+		}
+	}
+}
+
+// Conflict is a promotion target more than one configuration promotes to
+type Conflict struct {
+	Target  string
+	Configs []*config.Info
+}
+
+func (c Conflict) Error() string {
+	var formatted []string
+	for _, info := range c.Configs {
+		identifier := fmt.Sprintf("%s/%s@%s", info.Org, info.Repo, info.Branch)
+		if info.Variant != "" {
+			identifier = fmt.Sprintf("%s [%s]", identifier, info.Variant)
+		}
+		formatted = append(formatted, identifier)
+	}
+	return fmt.Sprintf("images are promoted to %s from more than one place: %s", c.Target, strings.Join(formatted, ", "))
+}
+
+// Conflicts returns all targets promoted to by more than one configuration,
+// sorted by the target
+func (t Targets) Conflicts() []Conflict {
+	var conflicts []Conflict
+	for target, infos := range t {
+		if len(infos) <= 1 {
+			continue
+		}
+		configs := make([]*config.Info, len(infos))
+		copy(configs, infos)
+		sort.Slice(configs, func(i, j int) bool {
+			return configs[i].Basename() < configs[j].Basename()
+		})
+		conflicts = append(conflicts, Conflict{Target: target, Configs: configs})
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Target < conflicts[j].Target
+	})
+	return conflicts
+}
@@ -0,0 +1,102 @@
+package promotion
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	cioperatorapi "github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+func TestConflicts(t *testing.T) {
+	info := func(branch, variant string) *config.Info {
+		return &config.Info{Metadata: cioperatorapi.Metadata{Org: "org", Repo: "repo", Branch: branch, Variant: variant}}
+	}
+	promoting := func(promotion cioperatorapi.PromotionConfiguration, images ...string) *cioperatorapi.ReleaseBuildConfiguration {
+		configuration := &cioperatorapi.ReleaseBuildConfiguration{PromotionConfiguration: &promotion}
+		for _, image := range images {
+			configuration.Images = append(configuration.Images, cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{To: cioperatorapi.PipelineImageStreamTagReference(image)})
+		}
+		return configuration
+	}
+	type recorded struct {
+		configuration *cioperatorapi.ReleaseBuildConfiguration
+		info          *config.Info
+	}
+	testCases := []struct {
+		name     string
+		configs  []recorded
+		expected []string
+	}{
+		{
+			name: "distinct targets do not conflict",
+			configs: []recorded{
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.8"}, "foo"), info: info("release-4.8", "")},
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9"}, "foo"), info: info("master", "")},
+			},
+		},
+		{
+			name: "disabled promotion does not conflict",
+			configs: []recorded{
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9"}, "foo"), info: info("master", "")},
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9", Disabled: true}, "foo"), info: info("release-4.9", "")},
+			},
+		},
+		{
+			name: "excluded image does not conflict",
+			configs: []recorded{
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9"}, "foo", "bar"), info: info("master", "")},
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9", ExcludedImages: []string{"foo"}}, "foo", "baz"), info: info("master", "variant")},
+			},
+		},
+		{
+			name: "same targets conflict",
+			configs: []recorded{
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9"}, "foo", "bar"), info: info("master", "")},
+				{configuration: promoting(cioperatorapi.PromotionConfiguration{Namespace: "ocp", Name: "4.9", AdditionalImages: map[string]string{"bar": "src"}}, "foo"), info: info("release-4.9", "")},
+			},
+			expected: []string{
+				"images are promoted to registry.ci.openshift.org/ocp/4.9:bar from more than one place: org/repo@master, org/repo@release-4.9",
+				"images are promoted to registry.ci.openshift.org/ocp/4.9:foo from more than one place: org/repo@master, org/repo@release-4.9",
+			},
+		},
+		{
+			name: "same external destinations conflict",
+			configs: []recorded{
+				{
+					configuration: promoting(cioperatorapi.PromotionConfiguration{
+						Namespace:            "ocp",
+						Name:                 "4.9",
+						ExternalDestinations: []cioperatorapi.ExternalPromotionDestination{{Repository: "quay.io/org", Credentials: "quay"}},
+					}, "foo"),
+					info: info("master", ""),
+				},
+				{
+					configuration: promoting(cioperatorapi.PromotionConfiguration{
+						Namespace:            "origin",
+						Name:                 "4.9",
+						ExternalDestinations: []cioperatorapi.ExternalPromotionDestination{{Repository: "quay.io/org", Credentials: "quay"}},
+					}, "foo"),
+					info: info("master", "okd"),
+				},
+			},
+			expected: []string{"images are promoted to quay.io/org/4.9:foo from more than one place: org/repo@master, org/repo@master [okd]"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets := Targets{}
+			for _, c := range tc.configs {
+				targets.Record(c.configuration, c.info)
+			}
+			var actual []string
+			for _, conflict := range targets.Conflicts() {
+				actual = append(actual, conflict.Error())
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected conflicts: %s", diff)
+			}
+		})
+	}
+}
@@ -45,6 +45,11 @@ func AllPromotionImageStreamTags(configSpec *cioperatorapi.ReleaseBuildConfigura
 		result.Insert(fmt.Sprintf("%s/%s:%s", namespace, name, image.To))
 	}
 
+	// exclusions are made before additional images are included
+	for _, excluded := range configSpec.PromotionConfiguration.ExcludedImages {
+		result.Delete(fmt.Sprintf("%s/%s:%s", namespace, name, excluded))
+	}
+
 	for additionalTagToPromote := range configSpec.PromotionConfiguration.AdditionalImages {
 		result.Insert(fmt.Sprintf("%s/%s:%s", namespace, name, additionalTagToPromote))
 	}
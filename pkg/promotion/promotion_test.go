@@ -150,6 +150,19 @@ func TestAllPromotionImageStreamTags(t *testing.T) {
 			},
 			expected: sets.NewString("some-namespace/some-stream:expected", "some-namespace/some-stream:src"),
 		},
+		{
+			name: "excluded image",
+			config: &cioperatorapi.ReleaseBuildConfiguration{
+				PromotionConfiguration: &cioperatorapi.PromotionConfiguration{
+					Namespace:        "some-namespace",
+					Name:             "some-stream",
+					ExcludedImages:   []string{"excluded", "replaced"},
+					AdditionalImages: map[string]string{"replaced": "src"},
+				},
+				Images: []cioperatorapi.ProjectDirectoryImageBuildStepConfiguration{{To: "excluded"}, {To: "replaced"}, {To: cioperatorapi.PipelineImageStreamTagReferenceSource}},
+			},
+			expected: sets.NewString("some-namespace/some-stream:replaced", "some-namespace/some-stream:src"),
+		},
 	}
 
 	for _, tc := range testCases {
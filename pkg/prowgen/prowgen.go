@@ -141,7 +141,11 @@ func GenerateJobs(configSpec *cioperatorapi.ReleaseBuildConfiguration, info *Pro
 	var periodics []prowconfig.Periodic
 	var jobRelease string
 	if release, found := configSpec.Releases[cioperatorapi.LatestReleaseName]; found && release.Candidate != nil {
-		jobRelease = release.Candidate.Version
+		// label jobs with the version the release resolves to, invalid
+		// candidates are caught by validation so we leave those unlabeled
+		if version, err := cioperatorapi.CandidateVersion(*release.Candidate, info.Branch); err == nil {
+			jobRelease = version
+		}
 	}
 
 	skipCloning := true
@@ -556,6 +556,24 @@ func TestGenerateJobs(t *testing.T) {
 				Branch: "branch",
 			}},
 		},
+		{
+			id: "job release label from the version of the branch and the relative minor",
+			config: &ciop.ReleaseBuildConfiguration{
+				InputConfiguration: ciop.InputConfiguration{
+					Releases: map[string]ciop.UnresolvedRelease{
+						ciop.LatestReleaseName: {Candidate: &ciop.Candidate{Product: ciop.ReleaseProductOCP, Stream: ciop.ReleaseStreamNightly, RelativeMinor: 1}},
+					},
+				},
+				Tests: []ciop.TestStepConfiguration{
+					{As: "unit", ContainerTestConfiguration: &ciop.ContainerTestConfiguration{From: "bin"}},
+				},
+			},
+			repoInfo: &ProwgenInfo{Metadata: ciop.Metadata{
+				Org:    "organization",
+				Repo:   "repository",
+				Branch: "release-4.9",
+			}},
+		},
 		{
 			id: "cluster label for postsubmit",
 			config: &ciop.ReleaseBuildConfiguration{
@@ -19,6 +19,10 @@ const (
 
 var nodeTypes = [3]string{Workflow: "workflow", Reference: "reference", Chain: "chain"}
 
+func (t Type) String() string {
+	return nodeTypes[t]
+}
+
 // Node is an interface that allows a user to identify ancestors and descendants of a step registry element
 type Node interface {
 	// Name returns the name of the registry element a Node refers to
@@ -0,0 +1,218 @@
+package rehearse
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowconfig "k8s.io/test-infra/prow/config"
+	prowplugins "k8s.io/test-infra/prow/plugins"
+
+	apihelper "github.com/openshift/ci-tools/pkg/api/helper"
+	"github.com/openshift/ci-tools/pkg/config"
+	"github.com/openshift/ci-tools/pkg/diffs"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// CandidateOptions determine which kinds of changes are considered when
+// looking for jobs to rehearse
+type CandidateOptions struct {
+	NoTemplates       bool
+	NoRegistry        bool
+	NoClusterProfiles bool
+}
+
+// Candidates are the rehearsals of all jobs affected by a change to the
+// release repository, along with everything needed to run them
+type Candidates struct {
+	// Presubmits are the configured rehearsal jobs
+	Presubmits []*prowconfig.Presubmit
+	// ImageStreamTags are required to be present on the build clusters
+	ImageStreamTags apihelper.ImageStreamTagMap
+	// Templates and ClusterProfiles are the changed content that needs
+	// to be made available to the rehearsals in temporary ConfigMaps
+	Templates       ConfigMaps
+	ClusterProfiles ConfigMaps
+	// ConfigUpdater is the config-updater configuration of the tested revision
+	ConfigUpdater prowplugins.ConfigUpdater
+	// Config is the configuration of the tested revision
+	Config *config.ReleaseRepoConfig
+}
+
+func loadConfigUpdaterCfg(releaseRepoPath string) (ret prowplugins.ConfigUpdater, err error) {
+	agent := prowplugins.ConfigAgent{}
+	if err = agent.Load(filepath.Join(releaseRepoPath, config.PluginConfigInRepoPath), []string{filepath.Join(releaseRepoPath, filepath.Dir(config.PluginConfigInRepoPath))}, "_pluginconfig.yaml", true); err == nil {
+		ret = agent.Config().ConfigUpdater
+	}
+	return
+}
+
+// DetermineCandidates compares the working copy of the release repository
+// with its base revision and configures rehearsals for all affected jobs
+func DetermineCandidates(releaseRepoPath string, refs *pjapi.Refs, buildID string, options CandidateOptions, logger *logrus.Entry, loggers Loggers) (*Candidates, error) {
+	prNumber := refs.Pulls[0].Number
+
+	prConfig := config.GetAllConfigs(releaseRepoPath, logger)
+	configUpdaterCfg, err := loadConfigUpdaterCfg(releaseRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load plugin configuration from tested revision of release repo: %w", err)
+	}
+	masterConfig, err := config.GetAllConfigsFromSHA(releaseRepoPath, refs.BaseSHA, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not load configuration from base revision of release repo: %w", err)
+	}
+
+	// We always need both Prow config versions, otherwise we cannot compare them
+	if masterConfig.Prow == nil || prConfig.Prow == nil {
+		return nil, fmt.Errorf("could not load Prow configs from base or tested revision of release repo")
+	}
+	// We always need PR versions of ciop config, otherwise we cannot provide them to rehearsed jobs
+	if prConfig.CiOperator == nil {
+		return nil, fmt.Errorf("could not load ci-operator configs from tested revision of release repo")
+	}
+
+	// We can only detect changes if we managed to load both ci-operator config versions
+	changedCiopConfigData := config.DataByFilename{}
+	affectedJobs := make(map[string]sets.String)
+	if masterConfig.CiOperator != nil && prConfig.CiOperator != nil {
+		data, jobs := diffs.GetChangedCiopConfigs(masterConfig.CiOperator, prConfig.CiOperator, logger)
+		changedCiopConfigData = data
+		affectedJobs = jobs
+	}
+
+	var changedRegistrySteps []registry.Node
+	var references registry.ReferenceByName
+	var chains registry.ChainByName
+	var workflows registry.WorkflowByName
+	var observers registry.ObserverByName
+
+	if !options.NoRegistry {
+		references, chains, workflows, _, _, observers, err = load.Registry(filepath.Join(releaseRepoPath, config.RegistryPath), false)
+		if err != nil {
+			return nil, fmt.Errorf("could not load step registry: %w", err)
+		}
+		graph, err := registry.NewGraph(references, chains, workflows)
+		if err != nil {
+			return nil, fmt.Errorf("could not create step registry graph: %w", err)
+		}
+		changedRegistrySteps, err = config.GetChangedRegistrySteps(releaseRepoPath, refs.BaseSHA, graph)
+		if err != nil {
+			return nil, fmt.Errorf("could not get step registry differences: %w", err)
+		}
+	}
+	if len(changedRegistrySteps) != 0 {
+		var names []string
+		for _, step := range changedRegistrySteps {
+			names = append(names, step.Name())
+		}
+		logger.Infof("Found %d changed registry steps: %s", len(changedRegistrySteps), strings.Join(names, ", "))
+	}
+
+	var rehearsalTemplates ConfigMaps
+	if !options.NoTemplates {
+		changedTemplates, err := config.GetChangedTemplates(releaseRepoPath, refs.BaseSHA)
+		if err != nil {
+			return nil, fmt.Errorf("could not get template differences: %w", err)
+		}
+		rehearsalTemplates, err = NewConfigMaps(changedTemplates, "template", buildID, prNumber, configUpdaterCfg)
+		if err != nil {
+			return nil, fmt.Errorf("could not match changed templates with cluster configmaps: %w", err)
+		}
+	}
+	if len(rehearsalTemplates.Paths) != 0 {
+		logger.WithField("templates", rehearsalTemplates.Paths).Info("templates changed")
+	}
+
+	var rehearsalClusterProfiles ConfigMaps
+	if !options.NoClusterProfiles {
+		changedClusterProfiles, err := config.GetChangedClusterProfiles(releaseRepoPath, refs.BaseSHA)
+		if err != nil {
+			return nil, fmt.Errorf("could not get cluster profile differences: %w", err)
+		}
+		rehearsalClusterProfiles, err = NewConfigMaps(changedClusterProfiles, "cluster-profile", buildID, prNumber, configUpdaterCfg)
+		if err != nil {
+			return nil, fmt.Errorf("could not match changed cluster profiles with cluster configmaps: %w", err)
+		}
+	}
+	if len(rehearsalClusterProfiles.Paths) != 0 {
+		logger.WithField("profiles", rehearsalClusterProfiles.Paths).Info("cluster profiles changed")
+	}
+
+	toRehearse := config.Presubmits{}
+	periodicsToRehearse := config.Periodics{}
+
+	changedPeriodics := diffs.GetChangedPeriodics(masterConfig.Prow, prConfig.Prow, logger)
+	periodicsToRehearse.AddAll(changedPeriodics, config.ChangedPeriodic)
+	changedPresubmits := diffs.GetChangedPresubmits(masterConfig.Prow, prConfig.Prow, logger)
+	toRehearse.AddAll(changedPresubmits, config.ChangedPresubmit)
+
+	presubmitsForCiopConfigs, periodicsForCiopConfigs := diffs.GetJobsForCiopConfigs(prConfig.Prow, changedCiopConfigData, affectedJobs, logger)
+	toRehearse.AddAll(presubmitsForCiopConfigs, config.ChangedCiopConfig)
+	periodicsToRehearse.AddAll(periodicsForCiopConfigs, config.ChangedCiopConfig)
+
+	presubmitsForClusterProfiles := diffs.GetPresubmitsForClusterProfiles(prConfig.Prow, rehearsalClusterProfiles.ProductionNames, logger)
+	toRehearse.AddAll(presubmitsForClusterProfiles, config.ChangedClusterProfile)
+
+	randomJobsForChangedTemplates := AddRandomJobsForChangedTemplates(rehearsalTemplates.ProductionNames, toRehearse, prConfig.Prow.JobConfig.PresubmitsStatic, loggers)
+	toRehearse.AddAll(randomJobsForChangedTemplates, config.ChangedTemplate)
+
+	presubmitsForRegistry, periodicsForRegistry := SelectJobsForChangedRegistry(changedRegistrySteps, prConfig.Prow.JobConfig.PresubmitsStatic, prConfig.Prow.JobConfig.Periodics, prConfig.CiOperator, loggers)
+	toRehearse.AddAll(presubmitsForRegistry, config.ChangedRegistryContent)
+	periodicsToRehearse.AddAll(periodicsForRegistry, config.ChangedRegistryContent)
+
+	resolver := registry.NewResolver(references, chains, workflows, observers)
+	jobConfigurer := NewJobConfigurer(prConfig.CiOperator, resolver, prNumber, loggers, rehearsalTemplates.Names, rehearsalClusterProfiles.Names, refs)
+	imagestreamtags, presubmitsToRehearse, err := jobConfigurer.ConfigurePresubmitRehearsals(toRehearse)
+	if err != nil {
+		return nil, err
+	}
+
+	periodicImageStreamTags, periodics, err := jobConfigurer.ConfigurePeriodicRehearsals(periodicsToRehearse)
+	if err != nil {
+		return nil, err
+	}
+	apihelper.MergeImageStreamTagMaps(imagestreamtags, periodicImageStreamTags)
+
+	periodicPresubmits, err := jobConfigurer.ConvertPeriodicsToPresubmits(periodics)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Candidates{
+		Presubmits:      append(presubmitsToRehearse, periodicPresubmits...),
+		ImageStreamTags: imagestreamtags,
+		Templates:       rehearsalTemplates,
+		ClusterProfiles: rehearsalClusterProfiles,
+		ConfigUpdater:   configUpdaterCfg,
+		Config:          prConfig,
+	}, nil
+}
+
+// PrepareRehearsals adds the selected rehearsals to the Prow configuration of
+// the tested revision and validates them. The rehearsals run against the
+// tested repository, so it is removed from their extra refs.
+func (c *Candidates) PrepareRehearsals(selected []*prowconfig.Presubmit, org, repo string) error {
+	if c.Config.Prow.JobConfig.PresubmitsStatic == nil {
+		c.Config.Prow.JobConfig.PresubmitsStatic = map[string][]prowconfig.Presubmit{}
+	}
+	for _, presubmit := range selected {
+
+		// We can only have a given repo once, so remove whats in Refs from ExtraRefs
+		var cleanExtraRefs []pjapi.Refs
+		for _, extraRef := range presubmit.ExtraRefs {
+			if extraRef.Org == org && extraRef.Repo == repo {
+				continue
+			}
+			cleanExtraRefs = append(cleanExtraRefs, extraRef)
+		}
+		presubmit.ExtraRefs = cleanExtraRefs
+
+		c.Config.Prow.JobConfig.PresubmitsStatic[org+"/"+repo] = append(c.Config.Prow.JobConfig.PresubmitsStatic[org+"/"+repo], *presubmit)
+	}
+	return c.Config.Prow.ValidateJobConfig()
+}
@@ -0,0 +1,71 @@
+package rehearse
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "k8s.io/test-infra/prow/config"
+)
+
+const (
+	// CommandSkip acknowledges that the pull request does not need rehearsals
+	CommandSkip = "skip"
+	// CommandMore requests rehearsals beyond the regular rehearsal limit
+	CommandMore = "more"
+)
+
+var commandRe = regexp.MustCompile(`(?m)^/pj-rehearse(?:[ \t]+(.*?))?[ \t]*$`)
+
+// Command is a request for rehearsals posted as a pull request comment:
+// `/pj-rehearse` rehearses the regular selection of affected jobs,
+// `/pj-rehearse job-name [job-name...]` rehearses the named jobs,
+// `/pj-rehearse more` extends the selection beyond the rehearsal limit and
+// `/pj-rehearse skip` acknowledges that no rehearsals are needed.
+type Command struct {
+	Skip bool
+	More bool
+	// Jobs are the names of the jobs to rehearse, either the original job
+	// names or the names of the rehearsals
+	Jobs []string
+}
+
+// ParseCommand finds the first rehearsal command in the comment body
+func ParseCommand(body string) (*Command, bool) {
+	match := commandRe.FindStringSubmatch(body)
+	if match == nil {
+		return nil, false
+	}
+	command := &Command{}
+	for _, arg := range strings.Fields(match[1]) {
+		switch arg {
+		case CommandSkip:
+			command.Skip = true
+		case CommandMore:
+			command.More = true
+		default:
+			command.Jobs = append(command.Jobs, arg)
+		}
+	}
+	return command, true
+}
+
+// SelectRequestedRehearsals picks the rehearsals of the requested jobs out of
+// the candidates and returns the names of requested jobs that are not among
+// the candidates
+func SelectRequestedRehearsals(candidates []*prowconfig.Presubmit, requested []string) ([]SelectedRehearsal, []string) {
+	remaining := sets.NewString(requested...)
+	var selected []SelectedRehearsal
+	for _, candidate := range candidates {
+		source := sourceJobName(candidate.Name, candidate.Labels)
+		if remaining.HasAny(source, candidate.Name) {
+			remaining.Delete(source, candidate.Name)
+			selected = append(selected, SelectedRehearsal{Job: candidate, Rationale: "requested by a /pj-rehearse comment"})
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Job.Name < selected[j].Job.Name
+	})
+	return selected, remaining.List()
+}
@@ -0,0 +1,93 @@
+package rehearse
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	prowconfig "k8s.io/test-infra/prow/config"
+)
+
+func TestParseCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		expected      *Command
+		expectedFound bool
+	}{
+		{
+			name: "no command",
+			body: "LGTM, but can we rehearse this?",
+		},
+		{
+			name: "command not at the start of a line",
+			body: "please run /pj-rehearse",
+		},
+		{
+			name:          "regular rehearsals",
+			body:          "/pj-rehearse",
+			expected:      &Command{},
+			expectedFound: true,
+		},
+		{
+			name:          "skip",
+			body:          "Only docs changed.\n/pj-rehearse skip\n",
+			expected:      &Command{Skip: true},
+			expectedFound: true,
+		},
+		{
+			name:          "more",
+			body:          "/pj-rehearse more",
+			expected:      &Command{More: true},
+			expectedFound: true,
+		},
+		{
+			name:          "specific jobs",
+			body:          "/pj-rehearse pull-ci-org-repo-master-e2e   periodic-ci-org-repo-master-nightly ",
+			expected:      &Command{Jobs: []string{"pull-ci-org-repo-master-e2e", "periodic-ci-org-repo-master-nightly"}},
+			expectedFound: true,
+		},
+		{
+			name: "other command with the same prefix",
+			body: "/pj-rehearse-all",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			command, found := ParseCommand(tc.body)
+			if found != tc.expectedFound {
+				t.Errorf("expected found=%t, got %t", tc.expectedFound, found)
+			}
+			if diff := cmp.Diff(tc.expected, command); diff != "" {
+				t.Errorf("unexpected command: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectRequestedRehearsals(t *testing.T) {
+	rehearsal := func(name string) *prowconfig.Presubmit {
+		return &prowconfig.Presubmit{JobBase: prowconfig.JobBase{Name: "rehearse-123-" + name, Labels: map[string]string{Label: "123"}}}
+	}
+	candidates := []*prowconfig.Presubmit{
+		rehearsal("pull-ci-org-repo-master-unit"),
+		rehearsal("pull-ci-org-repo-master-e2e"),
+		rehearsal("periodic-ci-org-repo-master-nightly"),
+	}
+	selected, unknown := SelectRequestedRehearsals(candidates, []string{
+		"pull-ci-org-repo-master-e2e",
+		"rehearse-123-periodic-ci-org-repo-master-nightly",
+		"periodic-ci-org-repo-master-nightly",
+		"pull-ci-org-repo-master-images",
+	})
+	var names []string
+	for _, selection := range selected {
+		names = append(names, selection.Job.Name)
+	}
+	if diff := cmp.Diff([]string{"rehearse-123-periodic-ci-org-repo-master-nightly", "rehearse-123-pull-ci-org-repo-master-e2e"}, names); diff != "" {
+		t.Errorf("unexpected selection: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"pull-ci-org-repo-master-images"}, unknown); diff != "" {
+		t.Errorf("unexpected unknown jobs: %s", diff)
+	}
+}
@@ -0,0 +1,176 @@
+package rehearse
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	prowconfig "k8s.io/test-infra/prow/config"
+	prowplugins "k8s.io/test-infra/prow/plugins"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	apihelper "github.com/openshift/ci-tools/pkg/api/helper"
+	testimagestreamtagimportv1 "github.com/openshift/ci-tools/pkg/api/testimagestreamtagimport/v1"
+)
+
+type cleanup func()
+type cleanups []cleanup
+
+func (c cleanups) cleanup() {
+	for _, cleanup := range c {
+		cleanup()
+	}
+}
+
+// SetupDependencies makes sure that everything the rehearsals need is present on
+// the build clusters they run on: the temporary ConfigMaps with the changed
+// templates and cluster profiles, and the imported ImageStreamTags. The returned
+// function removes the temporary ConfigMaps again.
+func SetupDependencies(
+	jobs []*prowconfig.Presubmit,
+	prNumber int,
+	configs map[string]*rest.Config,
+	log *logrus.Entry,
+	prowJobNamespace string,
+	prowJobClient ctrlruntimeclient.Client,
+	podNamespace string,
+	configUpdaterCfg prowplugins.ConfigUpdater,
+	releaseRepoPath string,
+	dryRun bool,
+	changedTemplates ConfigMaps,
+	changedClusterProfiles ConfigMaps,
+	requiredImageStreamTags apihelper.ImageStreamTagMap,
+) (func(), error) {
+	buildClusters := sets.String{}
+	for _, job := range jobs {
+		if _, ok := configs[job.Cluster]; !ok && !dryRun {
+			return nil, fmt.Errorf("no config for buildcluster %s provided", job.Cluster)
+		}
+		buildClusters.Insert(job.Cluster)
+	}
+
+	var cleanups cleanups
+	cleanupsLock := &sync.Mutex{}
+
+	// Otherwise we flake in integration tests because we just capture stdout. Its not
+	// really possible to sort this as we use a client per cluster. Furthermore the output
+	// doesn't even contain the info which cluster was used.
+	// TODO: Remove the whole dry-run concept and write tests that just pass in a fakeclient.
+	if dryRun {
+		if len(buildClusters) > 1 {
+			buildClusters = sets.NewString("default")
+		}
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	for _, cluster := range buildClusters.UnsortedList() {
+		buildCluster := cluster
+		g.Go(func() error {
+			log := log.WithField("buildCluster", buildCluster)
+			cmClient, err := NewCMClient(configs[buildCluster], podNamespace, dryRun)
+			if err != nil {
+				return fmt.Errorf("could not create a configMap client for cluster %s: %w", buildCluster, err)
+			}
+
+			cmManager := NewCMManager(buildCluster, prowJobNamespace, cmClient, configUpdaterCfg, prNumber, releaseRepoPath, log)
+
+			cleanupsLock.Lock()
+			cleanups = append(cleanups, func() {
+				if err := cmManager.Clean(); err != nil {
+					log.WithError(err).Error("failed to clean up temporary ConfigMaps")
+				}
+			})
+			cleanupsLock.Unlock()
+
+			if err := cmManager.Create(changedTemplates); err != nil {
+				return fmt.Errorf("couldn't create temporary template ConfigMaps for rehearsals in cluster %s: %w", buildCluster, err)
+			}
+			if err := cmManager.Create(changedClusterProfiles); err != nil {
+				return fmt.Errorf("couldn't create temporary cluster profile ConfigMaps for rehearsals in cluster %s: %w", buildCluster, err)
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			client, err := ctrlruntimeclient.New(configs[buildCluster], ctrlruntimeclient.Options{})
+			if err != nil {
+				return fmt.Errorf("failed to construct client for cluster %s: %w", buildCluster, err)
+			}
+
+			if err := ensureImageStreamTags(ctx, client, requiredImageStreamTags, buildCluster, prowJobNamespace, prowJobClient, log); err != nil {
+				return fmt.Errorf("failed to ensure imagestreamtags in cluster %s: %w", buildCluster, err)
+			}
+
+			return nil
+		})
+	}
+
+	return cleanups.cleanup, g.Wait()
+}
+
+// Allow manipulating the speed of time for tests
+var second = time.Second
+
+func ensureImageStreamTags(ctx context.Context, client ctrlruntimeclient.Client, ists apihelper.ImageStreamTagMap, clusterName, namespace string, istImportClient ctrlruntimeclient.Client, log *logrus.Entry) error {
+	if clusterName == "app.ci" {
+		log.Info("Not creating imports on app.ci cluster as its authoritative source for all imagestreams")
+		return nil
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	for _, ist := range ists {
+		requiredImageStreamTag := ist
+		g.Go(func() error {
+			istLog := log.WithFields(logrus.Fields{"ist-namespace": requiredImageStreamTag.Namespace, "ist-name": requiredImageStreamTag.Name})
+			err := client.Get(ctx, requiredImageStreamTag, &imagev1.ImageStreamTag{})
+			if err == nil {
+				istLog.Info("ImageStreamTag already exists in the build cluster")
+				return nil
+			}
+			if !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) {
+				return fmt.Errorf("failed to check if imagestreamtag %s exists: %w", requiredImageStreamTag, err)
+			}
+			istImport := &testimagestreamtagimportv1.TestImageStreamTagImport{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+				Spec: testimagestreamtagimportv1.TestImageStreamTagImportSpec{
+					ClusterName: clusterName,
+					Namespace:   requiredImageStreamTag.Namespace,
+					Name:        requiredImageStreamTag.Name,
+				},
+			}
+			istImport.SetDeterministicName()
+			istLog.Info("Creating ImageStreamTagImport in the build cluster")
+			if err := istImportClient.Create(ctx, istImport); err != nil && !apierrors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create imagestreamtag %s: %w", requiredImageStreamTag, err)
+			}
+			if err := wait.Poll(5*second, 30*second, func() (bool, error) {
+				if err := client.Get(ctx, requiredImageStreamTag, &imagev1.ImageStreamTag{}); err != nil {
+					if apierrors.IsNotFound(err) {
+						return false, nil
+					}
+					return false, fmt.Errorf("get failed: %w", err)
+				}
+				return true, nil
+			}); err != nil {
+				return fmt.Errorf("failed waiting for imagestreamtag %s to appear: %w", requiredImageStreamTag, err)
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
@@ -808,6 +808,8 @@ type Executor struct {
 	loggers    Loggers
 	pjclient   ctrlruntimeclient.Client
 	namespace  string
+	// observed holds the last seen state of the submitted ProwJobs by name
+	observed map[string]pjapi.ProwJob
 	// Allow faking this in tests
 	pollFunc func(interval, timeout time.Duration, condition wait.ConditionFunc) error
 }
@@ -824,10 +826,21 @@ func NewExecutor(presubmits []*prowconfig.Presubmit, prNumber int, prRepo string
 		loggers:    loggers,
 		pjclient:   pjclient,
 		namespace:  namespace,
+		observed:   map[string]pjapi.ProwJob{},
 		pollFunc:   wait.Poll,
 	}
 }
 
+// ProwJobs returns the last observed state of all submitted rehearsal ProwJobs
+func (e *Executor) ProwJobs() []pjapi.ProwJob {
+	var pjs []pjapi.ProwJob
+	for _, pj := range e.observed {
+		pjs = append(pjs, pj)
+	}
+	sort.Slice(pjs, func(i, j int) bool { return pjs[i].Spec.Job < pjs[j].Spec.Job })
+	return pjs
+}
+
 func printAsYaml(pjs []*pjapi.ProwJob) error {
 	sort.Slice(pjs, func(a, b int) bool { return pjs[a].Spec.Job < pjs[b].Spec.Job })
 	jobAsYAML, err := yaml.Marshal(pjs)
@@ -866,6 +879,7 @@ func (e *Executor) ExecuteJobs() (bool, error) {
 	names := sets.NewString()
 	for _, job := range pjs {
 		names.Insert(job.Name)
+		e.observed[job.Name] = *job
 	}
 	waitSuccess, err := e.waitForJobs(names, selector)
 	if !submitSuccess {
@@ -900,6 +914,7 @@ func (e *Executor) waitForJobs(jobs sets.String, selector ctrlruntimeclient.List
 			if !jobs.Has(pj.Name) {
 				continue
 			}
+			e.observed[pj.Name] = pj
 
 			switch pj.Status.State {
 			case pjapi.FailureState, pjapi.AbortedState, pjapi.ErrorState:
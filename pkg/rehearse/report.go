@@ -0,0 +1,343 @@
+package rehearse
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/kube"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	// summaryMarker identifies the rehearsal summary comment so that it can
+	// be updated in place instead of posting a new comment on every run
+	summaryMarker = "<!-- pj-rehearse: rehearsal summary -->"
+
+	// operatorJUnitPath is the location of the ci-operator jUnit in the job artifacts
+	operatorJUnitPath = "artifacts/junit_operator.xml"
+
+	// maxReasonLength caps the failure reason so the table stays readable
+	maxReasonLength = 200
+)
+
+// JUnitGetter fetches the ci-operator jUnit report of a finished ProwJob
+type JUnitGetter interface {
+	JUnit(pj *pjapi.ProwJob) (*junit.TestSuites, error)
+}
+
+// NewGCSJUnitGetter returns a JUnitGetter that reads the job artifacts from GCS
+func NewGCSJUnitGetter(client *storage.Client) JUnitGetter {
+	return &gcsJUnitGetter{client: client}
+}
+
+type gcsJUnitGetter struct {
+	client *storage.Client
+}
+
+func (g *gcsJUnitGetter) JUnit(pj *pjapi.ProwJob) (*junit.TestSuites, error) {
+	bucket, path, err := artifactsLocation(pj.Status.URL)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := g.client.Bucket(bucket).Object(path + "/" + operatorJUnitPath).NewReader(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("could not open jUnit from gs://%s/%s: %w", bucket, path, err)
+	}
+	defer reader.Close()
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not read jUnit from gs://%s/%s: %w", bucket, path, err)
+	}
+	suites := &junit.TestSuites{}
+	if err := xml.Unmarshal(raw, suites); err != nil {
+		return nil, fmt.Errorf("could not parse jUnit: %w", err)
+	}
+	return suites, nil
+}
+
+// artifactsLocation determines the GCS bucket and path of the job artifacts
+// from the Deck URL of the job
+func artifactsLocation(jobURL string) (string, string, error) {
+	parts := strings.SplitN(jobURL, "/view/gs/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("job URL %q does not point to GCS artifacts", jobURL)
+	}
+	location := strings.SplitN(strings.Trim(parts[1], "/"), "/", 2)
+	if len(location) != 2 || location[0] == "" || location[1] == "" {
+		return "", "", fmt.Errorf("job URL %q does not contain a GCS bucket and path", jobURL)
+	}
+	return location[0], location[1], nil
+}
+
+// failureReason returns a short description of the first failed test case
+func failureReason(suites *junit.TestSuites) string {
+	var fromSuite func(suite *junit.TestSuite) string
+	fromSuite = func(suite *junit.TestSuite) string {
+		for _, testCase := range suite.TestCases {
+			if testCase.FailureOutput == nil {
+				continue
+			}
+			message := testCase.FailureOutput.Message
+			if message == "" {
+				message = testCase.FailureOutput.Output
+			}
+			message = strings.TrimSpace(strings.SplitN(strings.TrimSpace(message), "\n", 2)[0])
+			if message == "" {
+				return testCase.Name
+			}
+			return fmt.Sprintf("%s: %s", testCase.Name, message)
+		}
+		for _, child := range suite.Children {
+			if reason := fromSuite(child); reason != "" {
+				return reason
+			}
+		}
+		return ""
+	}
+	for _, suite := range suites.Suites {
+		if reason := fromSuite(suite); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// BaselineRun is the most recent run of a job outside of a rehearsal
+type BaselineRun struct {
+	State pjapi.ProwJobState
+	URL   string
+}
+
+// RehearsalResult is the outcome of a single rehearsal job
+type RehearsalResult struct {
+	// Job is the name of the rehearsed job
+	Job string
+	// Context is the status context the job would have when run normally
+	Context       string
+	State         pjapi.ProwJobState
+	URL           string
+	Duration      time.Duration
+	FailureReason string
+	// Rationale explains why the job was selected for rehearsal, if known
+	Rationale string
+	// Baseline is the last run of the job that was not a rehearsal, if any
+	Baseline *BaselineRun
+}
+
+func (r RehearsalResult) failed() bool {
+	switch r.State {
+	case pjapi.FailureState, pjapi.AbortedState, pjapi.ErrorState:
+		return true
+	}
+	return false
+}
+
+// summaryCommentClient is the subset of the GitHub client needed to
+// maintain the summary comment
+type summaryCommentClient interface {
+	BotUserChecker() (func(candidate string) bool, error)
+	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	CreateComment(org, repo string, number int, comment string) error
+	EditComment(org, repo string, id int, comment string) error
+}
+
+// Reporter summarizes the outcome of rehearsals in a single comment
+// on the tested pull request
+type Reporter struct {
+	ghc        summaryCommentClient
+	pjclient   ctrlruntimeclient.Reader
+	junit      JUnitGetter
+	namespace  string
+	org, repo  string
+	prNumber   int
+	rationales map[string]string
+	logger     logrus.FieldLogger
+}
+
+// NewReporter creates a Reporter. The rationales map rehearsal job names to
+// the reason why they were selected and can be nil.
+func NewReporter(ghc summaryCommentClient, pjclient ctrlruntimeclient.Reader, junit JUnitGetter, namespace, org, repo string, prNumber int, rationales map[string]string, logger logrus.FieldLogger) *Reporter {
+	return &Reporter{
+		ghc:        ghc,
+		pjclient:   pjclient,
+		junit:      junit,
+		namespace:  namespace,
+		org:        org,
+		repo:       repo,
+		prNumber:   prNumber,
+		rationales: rationales,
+		logger:     logger,
+	}
+}
+
+// Results aggregates the observed ProwJobs into rehearsal results, looking up
+// the failure reasons and the most recent non-rehearsal runs of the same jobs
+func (r *Reporter) Results(pjs []pjapi.ProwJob) []RehearsalResult {
+	var results []RehearsalResult
+	for i := range pjs {
+		pj := &pjs[i]
+		result := RehearsalResult{
+			Job:       pj.Spec.Job,
+			Context:   pj.Labels[LabelContext],
+			State:     pj.Status.State,
+			URL:       pj.Status.URL,
+			Rationale: r.rationales[pj.Spec.Job],
+		}
+		if pj.Status.CompletionTime != nil {
+			result.Duration = pj.Status.CompletionTime.Sub(pj.Status.StartTime.Time).Round(time.Second)
+		}
+		logger := r.logger.WithField(logRehearsalJob, pj.Spec.Job)
+		if result.failed() && r.junit != nil {
+			if suites, err := r.junit.JUnit(pj); err != nil {
+				logger.WithError(err).Debug("Could not fetch the jUnit of the rehearsal")
+			} else {
+				result.FailureReason = failureReason(suites)
+			}
+		}
+		baseline, err := r.baseline(sourceJobName(pj.Spec.Job, pj.Labels))
+		if err != nil {
+			logger.WithError(err).Warn("Could not determine the last non-rehearsal run of the job")
+		}
+		result.Baseline = baseline
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Job < results[j].Job
+	})
+	return results
+}
+
+// baseline finds the most recently completed run of the job that was not a rehearsal
+func (r *Reporter) baseline(job string) (*BaselineRun, error) {
+	// Prow truncates the job name when it is used as a label value
+	labelValue := job
+	if len(labelValue) > validation.LabelValueMaxLength {
+		labelValue = strings.TrimRight(labelValue[:validation.LabelValueMaxLength], "._-")
+	}
+	list := &pjapi.ProwJobList{}
+	if err := r.pjclient.List(context.Background(), list, ctrlruntimeclient.MatchingLabels{kube.ProwJobAnnotation: labelValue}, ctrlruntimeclient.InNamespace(r.namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ProwJobs: %w", err)
+	}
+	var latest *pjapi.ProwJob
+	for i := range list.Items {
+		pj := &list.Items[i]
+		if _, rehearsal := pj.Labels[Label]; rehearsal || pj.Spec.Job != job || pj.Status.CompletionTime == nil {
+			continue
+		}
+		if latest == nil || pj.Status.CompletionTime.After(latest.Status.CompletionTime.Time) {
+			latest = pj
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return &BaselineRun{State: latest.Status.State, URL: latest.Status.URL}, nil
+}
+
+// Report posts the summary of the rehearsals to the pull request, updating
+// the previous summary if there is one
+func (r *Reporter) Report(pjs []pjapi.ProwJob) error {
+	body := FormatResults(r.Results(pjs))
+	isBot, err := r.ghc.BotUserChecker()
+	if err != nil {
+		return fmt.Errorf("failed to determine the bot user: %w", err)
+	}
+	comments, err := r.ghc.ListIssueComments(r.org, r.repo, r.prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to list comments: %w", err)
+	}
+	for _, comment := range comments {
+		if isBot(comment.User.Login) && strings.Contains(comment.Body, summaryMarker) {
+			if err := r.ghc.EditComment(r.org, r.repo, comment.ID, body); err != nil {
+				return fmt.Errorf("failed to update the rehearsal summary: %w", err)
+			}
+			return nil
+		}
+	}
+	if err := r.ghc.CreateComment(r.org, r.repo, r.prNumber, body); err != nil {
+		return fmt.Errorf("failed to create the rehearsal summary: %w", err)
+	}
+	return nil
+}
+
+// FormatResults renders the rehearsal results as the Markdown body of the summary comment
+func FormatResults(results []RehearsalResult) string {
+	var failed int
+	for _, result := range results {
+		if result.failed() {
+			failed++
+		}
+	}
+	b := &strings.Builder{}
+	b.WriteString(summaryMarker + "\n")
+	fmt.Fprintf(b, "**Rehearsal summary**: %d/%d rehearsals succeeded\n\n", len(results)-failed, len(results))
+	if len(results) == 0 {
+		return b.String()
+	}
+	b.WriteString("| Job | Status | Duration | Failure reason | Last non-rehearsal run |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, result := range results {
+		job := fmt.Sprintf("`%s`", result.Job)
+		if result.Rationale != "" {
+			job = fmt.Sprintf("%s<br>%s", job, tableCell(result.Rationale))
+		}
+		status := string(result.State)
+		if result.URL != "" {
+			status = fmt.Sprintf("[%s](%s)", status, result.URL)
+		}
+		duration := "-"
+		if result.Duration > 0 {
+			duration = result.Duration.String()
+		}
+		reason := "-"
+		if result.failed() {
+			reason = "unknown"
+			if result.FailureReason != "" {
+				reason = tableCell(result.FailureReason)
+			}
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", job, status, duration, reason, formatBaseline(result))
+	}
+	return b.String()
+}
+
+func formatBaseline(result RehearsalResult) string {
+	if result.Baseline == nil {
+		return "no recent run"
+	}
+	baseline := string(result.Baseline.State)
+	if result.Baseline.URL != "" {
+		baseline = fmt.Sprintf("[%s](%s)", baseline, result.Baseline.URL)
+	}
+	switch {
+	case result.failed() && result.Baseline.State == pjapi.SuccessState:
+		baseline += ", **newly failing**"
+	case result.failed() && (result.Baseline.State == pjapi.FailureState || result.Baseline.State == pjapi.ErrorState):
+		baseline += ", also failing without this PR"
+	case result.State == pjapi.SuccessState && (result.Baseline.State == pjapi.FailureState || result.Baseline.State == pjapi.ErrorState):
+		baseline += ", fixed"
+	}
+	return baseline
+}
+
+// tableCell makes text safe to use in a single Markdown table cell
+func tableCell(text string) string {
+	text = strings.NewReplacer("\n", " ", "|", "\\|").Replace(text)
+	if len(text) > maxReasonLength {
+		text = text[:maxReasonLength] + "..."
+	}
+	return text
+}
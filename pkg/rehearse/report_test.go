@@ -0,0 +1,233 @@
+package rehearse
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/github"
+	"k8s.io/test-infra/prow/github/fakegithub"
+	"k8s.io/test-infra/prow/kube"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestArtifactsLocation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		url            string
+		expectedBucket string
+		expectedPath   string
+		expectedErr    bool
+	}{
+		{
+			name:           "deck URL",
+			url:            "https://prow.ci.openshift.org/view/gs/origin-ci-test/pr-logs/pull/openshift_release/123/rehearse-123-job/456",
+			expectedBucket: "origin-ci-test",
+			expectedPath:   "pr-logs/pull/openshift_release/123/rehearse-123-job/456",
+		},
+		{
+			name:        "not a GCS URL",
+			url:         "https://prow.ci.openshift.org/view/s3/bucket/path",
+			expectedErr: true,
+		},
+		{
+			name:        "no path",
+			url:         "https://prow.ci.openshift.org/view/gs/origin-ci-test/",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bucket, path, err := artifactsLocation(tc.url)
+			if (err != nil) != tc.expectedErr {
+				t.Fatalf("expected error %t, got %v", tc.expectedErr, err)
+			}
+			if bucket != tc.expectedBucket || path != tc.expectedPath {
+				t.Errorf("expected %s/%s, got %s/%s", tc.expectedBucket, tc.expectedPath, bucket, path)
+			}
+		})
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	testCases := []struct {
+		name     string
+		suites   *junit.TestSuites
+		expected string
+	}{
+		{
+			name: "no failures",
+			suites: &junit.TestSuites{Suites: []*junit.TestSuite{{
+				TestCases: []*junit.TestCase{{Name: "Build image src"}},
+			}}},
+		},
+		{
+			name: "first line of the failure output",
+			suites: &junit.TestSuites{Suites: []*junit.TestSuite{{
+				TestCases: []*junit.TestCase{
+					{Name: "Build image src"},
+					{Name: "Run multi-stage test e2e - e2e-test container test", FailureOutput: &junit.FailureOutput{Output: "\nerror: tests failed\nmore output"}},
+					{Name: "Run multi-stage test e2e - e2e-gather container test", FailureOutput: &junit.FailureOutput{Output: "gather failed"}},
+				},
+			}}},
+			expected: "Run multi-stage test e2e - e2e-test container test: error: tests failed",
+		},
+		{
+			name: "failure message is preferred, nested suites are searched",
+			suites: &junit.TestSuites{Suites: []*junit.TestSuite{{
+				Children: []*junit.TestSuite{{
+					TestCases: []*junit.TestCase{{Name: "initialize", FailureOutput: &junit.FailureOutput{Message: "could not resolve inputs", Output: "output"}}},
+				}},
+			}}},
+			expected: "initialize: could not resolve inputs",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, failureReason(tc.suites)); diff != "" {
+				t.Errorf("unexpected failure reason: %s", diff)
+			}
+		})
+	}
+}
+
+type fakeJUnitGetter map[string]*junit.TestSuites
+
+func (f fakeJUnitGetter) JUnit(pj *pjapi.ProwJob) (*junit.TestSuites, error) {
+	if suites, ok := f[pj.Spec.Job]; ok {
+		return suites, nil
+	}
+	return nil, errors.New("not found")
+}
+
+// editingGitHubClient records comment edits, which the fake client ignores
+type editingGitHubClient struct {
+	*fakegithub.FakeClient
+}
+
+func (c editingGitHubClient) EditComment(org, repo string, id int, comment string) error {
+	for number, comments := range c.IssueComments {
+		for i := range comments {
+			if comments[i].ID == id {
+				c.IssueComments[number][i].Body = comment
+				return nil
+			}
+		}
+	}
+	return errors.New("comment not found")
+}
+
+func reportTestProwJob(name, job string, labels map[string]string, state pjapi.ProwJobState, start, completion time.Time) *pjapi.ProwJob {
+	pjLabels := map[string]string{kube.ProwJobAnnotation: job}
+	for k, v := range labels {
+		pjLabels[k] = v
+	}
+	pj := &pjapi.ProwJob{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ci", Labels: pjLabels},
+		Spec:       pjapi.ProwJobSpec{Job: job},
+		Status: pjapi.ProwJobStatus{
+			State:     state,
+			StartTime: metav1.NewTime(start),
+			URL:       "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/" + job + "/" + name,
+		},
+	}
+	if !completion.IsZero() {
+		pj.Status.CompletionTime = &metav1.Time{Time: completion}
+	}
+	return pj
+}
+
+func TestReporter(t *testing.T) {
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	rehearsalLabels := map[string]string{Label: "123"}
+	rehearsals := []pjapi.ProwJob{
+		*reportTestProwJob("r1", "rehearse-123-pull-ci-org-repo-master-e2e", rehearsalLabels, pjapi.FailureState, start, start.Add(90*time.Minute)),
+		*reportTestProwJob("r2", "rehearse-123-pull-ci-org-repo-master-unit", rehearsalLabels, pjapi.SuccessState, start, start.Add(5*time.Minute)),
+		*reportTestProwJob("r3", "rehearse-123-periodic-ci-org-repo-master-nightly", rehearsalLabels, pjapi.ErrorState, start, start.Add(time.Minute)),
+	}
+	history := []runtime.Object{
+		reportTestProwJob("b1", "pull-ci-org-repo-master-e2e", nil, pjapi.FailureState, start.Add(-3*time.Hour), start.Add(-2*time.Hour)),
+		reportTestProwJob("b2", "pull-ci-org-repo-master-e2e", nil, pjapi.SuccessState, start.Add(-2*time.Hour), start.Add(-1*time.Hour)),
+		reportTestProwJob("b3", "pull-ci-org-repo-master-e2e", nil, pjapi.PendingState, start.Add(-time.Hour), time.Time{}),
+		reportTestProwJob("b4", "pull-ci-org-repo-master-unit", nil, pjapi.FailureState, start.Add(-time.Hour), start.Add(-time.Minute)),
+		// rehearsals from an earlier run are not a baseline
+		reportTestProwJob("b5", "rehearse-123-pull-ci-org-repo-master-unit", rehearsalLabels, pjapi.SuccessState, start.Add(-time.Hour), start.Add(-time.Minute)),
+	}
+	for i := range rehearsals {
+		history = append(history, &rehearsals[i])
+	}
+	junitGetter := fakeJUnitGetter{
+		"rehearse-123-pull-ci-org-repo-master-e2e": &junit.TestSuites{Suites: []*junit.TestSuite{{
+			TestCases: []*junit.TestCase{{Name: "Run multi-stage test e2e - e2e-test container test", FailureOutput: &junit.FailureOutput{Output: "error: 3 tests | failed"}}},
+		}}},
+	}
+	rationales := map[string]string{"rehearse-123-pull-ci-org-repo-master-e2e": "covers cluster profile aws"}
+
+	ghc := editingGitHubClient{FakeClient: fakegithub.NewFakeClient()}
+	ghc.IssueComments[123] = []github.IssueComment{
+		{ID: 1, Body: summaryMarker + "\nsomeone else's summary", User: github.User{Login: "someone"}},
+	}
+	ghc.IssueCommentID = 1
+	reporter := NewReporter(ghc, fakectrlruntimeclient.NewFakeClient(history...), junitGetter, "ci", "openshift", "release", 123, rationales, logrus.NewEntry(logrus.StandardLogger()))
+
+	t.Run("results", func(t *testing.T) {
+		expected := []RehearsalResult{
+			{
+				Job:      "rehearse-123-periodic-ci-org-repo-master-nightly",
+				State:    pjapi.ErrorState,
+				URL:      "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/rehearse-123-periodic-ci-org-repo-master-nightly/r3",
+				Duration: time.Minute,
+			},
+			{
+				Job:           "rehearse-123-pull-ci-org-repo-master-e2e",
+				State:         pjapi.FailureState,
+				URL:           "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/rehearse-123-pull-ci-org-repo-master-e2e/r1",
+				Duration:      90 * time.Minute,
+				FailureReason: "Run multi-stage test e2e - e2e-test container test: error: 3 tests | failed",
+				Rationale:     "covers cluster profile aws",
+				Baseline:      &BaselineRun{State: pjapi.SuccessState, URL: "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/pull-ci-org-repo-master-e2e/b2"},
+			},
+			{
+				Job:      "rehearse-123-pull-ci-org-repo-master-unit",
+				State:    pjapi.SuccessState,
+				URL:      "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/rehearse-123-pull-ci-org-repo-master-unit/r2",
+				Duration: 5 * time.Minute,
+				Baseline: &BaselineRun{State: pjapi.FailureState, URL: "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/pull-ci-org-repo-master-unit/b4"},
+			},
+		}
+		if diff := cmp.Diff(expected, reporter.Results(rehearsals)); diff != "" {
+			t.Errorf("unexpected results: %s", diff)
+		}
+	})
+
+	t.Run("summary comment is created and then updated", func(t *testing.T) {
+		if err := reporter.Report(rehearsals); err != nil {
+			t.Fatalf("failed to report: %v", err)
+		}
+		if n := len(ghc.IssueComments[123]); n != 2 {
+			t.Fatalf("expected the summary to be added as a second comment, got %d comments", n)
+		}
+		testhelper.CompareWithFixture(t, ghc.IssueComments[123][1].Body)
+
+		rehearsals[0].Status.State = pjapi.SuccessState
+		if err := reporter.Report(rehearsals); err != nil {
+			t.Fatalf("failed to report: %v", err)
+		}
+		if n := len(ghc.IssueComments[123]); n != 2 {
+			t.Fatalf("expected the summary to be updated in place, got %d comments", n)
+		}
+		if body := ghc.IssueComments[123][1].Body; !strings.Contains(body, "2/3 rehearsals succeeded") {
+			t.Errorf("expected the summary to be updated, got:\n%s", body)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package rehearse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	prowconfig "k8s.io/test-infra/prow/config"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+// Flakiness maps names of the original (not rehearsal) jobs to their
+// historical failure rate, a number between 0 and 1
+type Flakiness map[string]float64
+
+// LoadFlakiness reads the historical failure rates of jobs from a YAML file
+func LoadFlakiness(path string) (Flakiness, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job flakiness file: %w", err)
+	}
+	var flakiness Flakiness
+	if err := yaml.Unmarshal(raw, &flakiness); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job flakiness file: %w", err)
+	}
+	for job, rate := range flakiness {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("flakiness of job %s must be between 0 and 1, got %v", job, rate)
+		}
+	}
+	return flakiness, nil
+}
+
+// coverage dimensions, in the order in which they are reported
+const (
+	dimensionClusterProfile = "cluster profile"
+	dimensionWorkflow       = "workflow"
+	dimensionBranch         = "branch"
+	dimensionVariant        = "variant"
+	dimensionSource         = "source"
+)
+
+var coverageDimensions = []string{dimensionClusterProfile, dimensionWorkflow, dimensionBranch, dimensionVariant, dimensionSource}
+
+// coverageFeatures returns the value of every coverage dimension the
+// rehearsal job exercises, determined from the job itself and the
+// ci-operator configuration of the test it runs
+func coverageFeatures(job *prowconfig.Presubmit, ciopConfigs config.DataByFilename) map[string]string {
+	features := map[string]string{
+		dimensionSource: string(config.GetSourceType(job.Labels)),
+	}
+	if variant := VariantFromLabels(job.Labels); variant != "" {
+		features[dimensionVariant] = variant
+	}
+
+	var metadata api.Metadata
+	switch {
+	case len(job.ExtraRefs) > 0:
+		metadata = api.Metadata{Org: job.ExtraRefs[0].Org, Repo: job.ExtraRefs[0].Repo, Branch: job.ExtraRefs[0].BaseRef, Variant: features[dimensionVariant]}
+	case len(job.Branches) > 0:
+		metadata.Branch = BranchFromRegexes(job.Branches)
+	}
+	if metadata.Branch != "" {
+		features[dimensionBranch] = metadata.Branch
+	}
+
+	if job.Spec == nil || len(job.Spec.Containers) == 0 {
+		return features
+	}
+	container := job.Spec.Containers[0]
+	for _, env := range container.Env {
+		if env.Name == clusterTypeEnvName && env.Value != "" {
+			features[dimensionClusterProfile] = env.Value
+		}
+	}
+	var target string
+	for _, arg := range container.Args {
+		if strings.HasPrefix(arg, "--target=") {
+			target = strings.TrimPrefix(arg, "--target=")
+		}
+	}
+	ciopConfig, ok := ciopConfigs[metadata.Basename()]
+	if target == "" || metadata.IsComplete() != nil || !ok {
+		return features
+	}
+	for _, test := range ciopConfig.Configuration.Tests {
+		if test.As != target {
+			continue
+		}
+		if profile := test.GetClusterProfile(); profile != "" {
+			features[dimensionClusterProfile] = string(profile)
+		}
+		if test.MultiStageTestConfiguration != nil && test.MultiStageTestConfiguration.Workflow != nil {
+			features[dimensionWorkflow] = *test.MultiStageTestConfiguration.Workflow
+		}
+	}
+	return features
+}
+
+// SelectedRehearsal is a rehearsal job chosen to be run, along with the
+// reason why it was chosen
+type SelectedRehearsal struct {
+	Job       *prowconfig.Presubmit
+	Rationale string
+}
+
+// sourceJobName returns the name of the job a rehearsal was created from
+func sourceJobName(rehearsal string, labels map[string]string) string {
+	if number, ok := labels[Label]; ok {
+		return strings.TrimPrefix(rehearsal, fmt.Sprintf("rehearse-%s-", number))
+	}
+	return rehearsal
+}
+
+// SelectRehearsalsByCoverage picks at most limit rehearsals so that the
+// selected jobs exercise as many distinct cluster profiles, workflows,
+// branches, variants and change sources as possible. Every candidate is
+// scored by the number of dimension values it would newly cover, discounted
+// by its historical flakiness, and the best candidate is picked greedily
+// until the limit is reached. Ties are broken by job name to keep the
+// selection deterministic.
+func SelectRehearsalsByCoverage(candidates []*prowconfig.Presubmit, ciopConfigs config.DataByFilename, flakiness Flakiness, limit int) []SelectedRehearsal {
+	type candidate struct {
+		job       *prowconfig.Presubmit
+		features  map[string]string
+		flakiness float64
+	}
+	var remaining []candidate
+	for _, job := range candidates {
+		remaining = append(remaining, candidate{
+			job:       job,
+			features:  coverageFeatures(job, ciopConfigs),
+			flakiness: flakiness[sourceJobName(job.Name, job.Labels)],
+		})
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].job.Name < remaining[j].job.Name
+	})
+
+	covered := map[string]sets.String{}
+	for _, dimension := range coverageDimensions {
+		covered[dimension] = sets.NewString()
+	}
+	uncovered := func(c candidate) []string {
+		var ret []string
+		for _, dimension := range coverageDimensions {
+			if value, ok := c.features[dimension]; ok && !covered[dimension].Has(value) {
+				ret = append(ret, fmt.Sprintf("%s %s", dimension, value))
+			}
+		}
+		return ret
+	}
+	score := func(c candidate) float64 {
+		return float64(len(uncovered(c))) - c.flakiness
+	}
+
+	var selected []SelectedRehearsal
+	for len(selected) < limit && len(remaining) > 0 {
+		best := 0
+		for i := range remaining {
+			if score(remaining[i]) > score(remaining[best]) {
+				best = i
+			}
+		}
+		chosen := remaining[best]
+		remaining = append(remaining[:best], remaining[best+1:]...)
+
+		rationale := "fills the remaining rehearsal budget"
+		if newlyCovered := uncovered(chosen); len(newlyCovered) > 0 {
+			rationale = fmt.Sprintf("covers %s", strings.Join(newlyCovered, ", "))
+		}
+		if chosen.flakiness > 0 {
+			rationale = fmt.Sprintf("%s (historical failure rate %.0f%%)", rationale, chosen.flakiness*100)
+		}
+		for dimension, value := range chosen.features {
+			covered[dimension].Insert(value)
+		}
+		selected = append(selected, SelectedRehearsal{Job: chosen.job, Rationale: rationale})
+	}
+	return selected
+}
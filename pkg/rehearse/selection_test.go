@@ -0,0 +1,105 @@
+package rehearse
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "k8s.io/api/core/v1"
+	pjapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	prowconfig "k8s.io/test-infra/prow/config"
+	utilpointer "k8s.io/utils/pointer"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/config"
+)
+
+func TestSelectRehearsalsByCoverage(t *testing.T) {
+	ciopConfigs := config.DataByFilename{}
+	for _, branch := range []string{"master", "release-4.8"} {
+		info := config.Info{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: branch}}
+		ciopConfigs[info.Basename()] = config.DataWithInfo{
+			Info: info,
+			Configuration: api.ReleaseBuildConfiguration{Tests: []api.TestStepConfiguration{
+				{As: "e2e-aws", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{ClusterProfile: api.ClusterProfileAWS, Workflow: utilpointer.StringPtr("ipi-aws")}},
+				{As: "e2e-aws-upgrade", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{ClusterProfile: api.ClusterProfileAWS, Workflow: utilpointer.StringPtr("openshift-upgrade-aws")}},
+				{As: "e2e-gcp", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{ClusterProfile: api.ClusterProfileGCP, Workflow: utilpointer.StringPtr("ipi-gcp")}},
+			}},
+		}
+	}
+	rehearsal := func(branch, test string) *prowconfig.Presubmit {
+		return &prowconfig.Presubmit{
+			JobBase: prowconfig.JobBase{
+				Name:   "rehearse-123-pull-ci-org-repo-" + branch + "-" + test,
+				Labels: map[string]string{Label: "123", config.SourceTypeLabel: string(config.ChangedRegistryContent)},
+				Spec: &v1.PodSpec{Containers: []v1.Container{{
+					Command: []string{"ci-operator"},
+					Args:    []string{"--target=" + test},
+				}}},
+				UtilityConfig: prowconfig.UtilityConfig{ExtraRefs: []pjapi.Refs{{Org: "org", Repo: "repo", BaseRef: branch}}},
+			},
+		}
+	}
+	candidates := []*prowconfig.Presubmit{
+		rehearsal("master", "e2e-aws"),
+		rehearsal("master", "e2e-aws-upgrade"),
+		rehearsal("master", "e2e-gcp"),
+		rehearsal("release-4.8", "e2e-aws"),
+		rehearsal("release-4.8", "e2e-aws-upgrade"),
+		rehearsal("release-4.8", "e2e-gcp"),
+	}
+
+	type selection struct {
+		Name, Rationale string
+	}
+	testCases := []struct {
+		name      string
+		flakiness Flakiness
+		limit     int
+		expected  []selection
+	}{
+		{
+			name:  "most diverse jobs are picked first",
+			limit: 3,
+			expected: []selection{
+				{Name: "rehearse-123-pull-ci-org-repo-master-e2e-aws", Rationale: "covers cluster profile aws, workflow ipi-aws, branch master, source changedRegistryContent"},
+				{Name: "rehearse-123-pull-ci-org-repo-release-4.8-e2e-gcp", Rationale: "covers cluster profile gcp, workflow ipi-gcp, branch release-4.8"},
+				{Name: "rehearse-123-pull-ci-org-repo-master-e2e-aws-upgrade", Rationale: "covers workflow openshift-upgrade-aws"},
+			},
+		},
+		{
+			name:  "flakiness discounts the coverage gain",
+			limit: 2,
+			flakiness: Flakiness{
+				"pull-ci-org-repo-master-e2e-aws":      0.9,
+				"pull-ci-org-repo-release-4.8-e2e-gcp": 0.5,
+			},
+			expected: []selection{
+				{Name: "rehearse-123-pull-ci-org-repo-master-e2e-aws-upgrade", Rationale: "covers cluster profile aws, workflow openshift-upgrade-aws, branch master, source changedRegistryContent"},
+				{Name: "rehearse-123-pull-ci-org-repo-release-4.8-e2e-gcp", Rationale: "covers cluster profile gcp, workflow ipi-gcp, branch release-4.8 (historical failure rate 50%)"},
+			},
+		},
+		{
+			name:  "budget is filled when there is nothing new to cover",
+			limit: 5,
+			expected: []selection{
+				{Name: "rehearse-123-pull-ci-org-repo-master-e2e-aws", Rationale: "covers cluster profile aws, workflow ipi-aws, branch master, source changedRegistryContent"},
+				{Name: "rehearse-123-pull-ci-org-repo-release-4.8-e2e-gcp", Rationale: "covers cluster profile gcp, workflow ipi-gcp, branch release-4.8"},
+				{Name: "rehearse-123-pull-ci-org-repo-master-e2e-aws-upgrade", Rationale: "covers workflow openshift-upgrade-aws"},
+				{Name: "rehearse-123-pull-ci-org-repo-master-e2e-gcp", Rationale: "fills the remaining rehearsal budget"},
+				{Name: "rehearse-123-pull-ci-org-repo-release-4.8-e2e-aws", Rationale: "fills the remaining rehearsal budget"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []selection
+			for _, selected := range SelectRehearsalsByCoverage(candidates, ciopConfigs, tc.flakiness, tc.limit) {
+				actual = append(actual, selection{Name: selected.Job.Name, Rationale: selected.Rationale})
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected selection: %s", diff)
+			}
+		})
+	}
+}
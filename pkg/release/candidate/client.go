@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -54,16 +56,72 @@ func defaultFields(candidate api.Candidate) api.Candidate {
 	return candidate
 }
 
-// ResolvePullSpec determines the pull spec for the candidate release
-func ResolvePullSpec(client release.HTTPClient, candidate api.Candidate) (string, error) {
-	return resolvePullSpec(client, endpoint(defaultFields(candidate)), candidate.Relative)
+// ResolvePullSpec determines the pull spec for the candidate release.
+// Candidates without a version resolve to the version of the tested branch.
+func ResolvePullSpec(client release.HTTPClient, candidate api.Candidate, branch string) (string, error) {
+	return resolvePullSpecAt(client, candidate, branch, time.Now())
+}
+
+func resolvePullSpecAt(client release.HTTPClient, candidate api.Candidate, branch string, now time.Time) (string, error) {
+	candidate = defaultFields(candidate)
+	version, err := api.CandidateVersion(candidate, branch)
+	if err != nil {
+		return "", err
+	}
+	candidate.Version = version
+	release, servedAt, err := resolveRelease(client, endpoint(candidate), candidate.Relative)
+	if err != nil {
+		return "", err
+	}
+	if candidate.MaxAgeDays > 0 {
+		// the age is relative to when the response was served, so that
+		// responses replayed from recorded fixtures do not grow older
+		if !servedAt.IsZero() {
+			now = servedAt
+		}
+		if err := checkAge(release, time.Duration(candidate.MaxAgeDays)*24*time.Hour, now); err != nil {
+			return "", err
+		}
+	}
+	return release.PullSpec, nil
+}
+
+// releaseTimestamp matches the time the release was created at, encoded in its name
+var releaseTimestamp = regexp.MustCompile(`([0-9]{4}-[0-9]{2}-[0-9]{2}-[0-9]{6})$`)
+
+// checkAge ensures the release was created within the maximum age. Validation
+// only allows a maximum age for streams with timestamped release names, so a
+// release without a timestamp means the stream did not serve what we expect.
+func checkAge(release Release, maxAge time.Duration, now time.Time) error {
+	matches := releaseTimestamp.FindStringSubmatch(release.Name)
+	if matches == nil {
+		return fmt.Errorf("cannot determine when release %s was created: its name does not end with a timestamp", release.Name)
+	}
+	created, err := time.Parse("2006-01-02-150405", matches[1])
+	if err != nil {
+		return fmt.Errorf("cannot determine when release %s was created: %w", release.Name, err)
+	}
+	if now.Sub(created) > maxAge {
+		return fmt.Errorf("release %s was created at %s, more than %s ago", release.Name, created.Format(time.RFC3339), maxAge)
+	}
+	return nil
 }
 
 func resolvePullSpec(client release.HTTPClient, endpoint string, relative int) (string, error) {
-	req, err := http.NewRequest("GET", endpoint, nil)
+	release, _, err := resolveRelease(client, endpoint, relative)
 	if err != nil {
 		return "", err
 	}
+	return release.PullSpec, nil
+}
+
+// resolveRelease requests the release from the endpoint, returning it with
+// the time the response was served at, if the server sent one
+func resolveRelease(client release.HTTPClient, endpoint string, relative int) (Release, time.Time, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return Release{}, time.Time{}, err
+	}
 	req.Header.Set("Accept", "application/json")
 	if relative != 0 {
 		q := req.URL.Query()
@@ -73,23 +131,25 @@ func resolvePullSpec(client release.HTTPClient, endpoint string, relative int) (
 	logrus.Debugf("Requesting a release from %s", req.URL.String())
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to request latest release: %w", err)
+		return Release{}, time.Time{}, fmt.Errorf("failed to request latest release: %w", err)
 	}
 	if resp == nil {
-		return "", errors.New("failed to request latest release: got a nil response")
+		return Release{}, time.Time{}, errors.New("failed to request latest release: got a nil response")
 	}
 	defer resp.Body.Close()
 	data, readErr := ioutil.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to request latest release: server responded with %d: %s", resp.StatusCode, data)
+		return Release{}, time.Time{}, fmt.Errorf("failed to request latest release: server responded with %d: %s", resp.StatusCode, data)
 	}
 	if readErr != nil {
-		return "", fmt.Errorf("failed to read response body: %w", readErr)
+		return Release{}, time.Time{}, fmt.Errorf("failed to read response body: %w", readErr)
 	}
 	release := Release{}
 	err = json.Unmarshal(data, &release)
 	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal release: %w (%s)", err, data)
+		return Release{}, time.Time{}, fmt.Errorf("failed to unmarshal release: %w (%s)", err, data)
 	}
-	return release.PullSpec, nil
+	// a missing or malformed Date header leaves the time unknown
+	servedAt, _ := http.ParseTime(resp.Header.Get("Date"))
+	return release, servedAt, nil
 }
@@ -1,14 +1,19 @@
 package candidate
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/release"
 )
 
 func TestServiceHost(t *testing.T) {
@@ -232,3 +237,60 @@ func TestResolvePullSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestResolvePullSpecMaxAge(t *testing.T) {
+	now := time.Date(2020, 5, 30, 0, 0, 0, 0, time.UTC)
+	var testCases = []struct {
+		name        string
+		candidate   api.Candidate
+		release     string
+		servedAt    string
+		expectedErr bool
+	}{
+		{
+			name:      "recent release",
+			candidate: api.Candidate{Product: api.ReleaseProductOCP, Stream: api.ReleaseStreamNightly, Version: "4.5", RelativeMinor: 1, MaxAgeDays: 10},
+			release:   "4.4.0-0.nightly-2020-05-22-121811",
+		},
+		{
+			name:        "release older than the maximum age",
+			candidate:   api.Candidate{Product: api.ReleaseProductOCP, Stream: api.ReleaseStreamNightly, Version: "4.5", RelativeMinor: 1, MaxAgeDays: 7},
+			release:     "4.4.0-0.nightly-2020-05-22-121811",
+			expectedErr: true,
+		},
+		{
+			name:      "release which was recent when the response was served",
+			candidate: api.Candidate{Product: api.ReleaseProductOCP, Stream: api.ReleaseStreamNightly, Version: "4.5", RelativeMinor: 1, MaxAgeDays: 7},
+			release:   "4.4.0-0.nightly-2020-05-22-121811",
+			servedAt:  "Sun, 24 May 2020 00:00:00 GMT",
+		},
+		{
+			name:        "release without a timestamp",
+			candidate:   api.Candidate{Product: api.ReleaseProductOCP, Stream: api.ReleaseStreamNightly, Version: "4.5", RelativeMinor: 1, MaxAgeDays: 7},
+			release:     "4.4.3",
+			expectedErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := release.NewFakeHTTPClient(func(req *http.Request) (*http.Response, error) {
+				if expected := "https://amd64.ocp.releases.ci.openshift.org/api/v1/releasestream/4.4.0-0.nightly/latest"; req.URL.String() != expected {
+					t.Errorf("expected request to %s, got %s", expected, req.URL.String())
+				}
+				body := fmt.Sprintf(`{"name": %q, "pullSpec": "registry.ci.openshift.org/ocp/release:%s"}`, testCase.release, testCase.release)
+				header := http.Header{}
+				if testCase.servedAt != "" {
+					header.Set("Date", testCase.servedAt)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(bytes.NewBufferString(body))}, nil
+			})
+			actual, err := resolvePullSpecAt(client, testCase.candidate, "", now)
+			if (err != nil) != testCase.expectedErr {
+				t.Fatalf("expected error %t, got %v", testCase.expectedErr, err)
+			}
+			if err == nil && actual != "registry.ci.openshift.org/ocp/release:"+testCase.release {
+				t.Errorf("got incorrect pullspec: %s", actual)
+			}
+		})
+	}
+}
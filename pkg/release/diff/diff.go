@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/junit"
+)
+
+const (
+	// CommitAnnotation records the commit the image of a release component was built from
+	CommitAnnotation = "io.openshift.build.commit.id"
+	// SourceAnnotation records the repository the image of a release component was built from
+	SourceAnnotation = "io.openshift.build.source-location"
+)
+
+// Component is an image in a release payload
+type Component struct {
+	Name   string `json:"name"`
+	Image  string `json:"image,omitempty"`
+	Digest string `json:"digest,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+// ChangeType describes how a component differs between two payloads
+type ChangeType string
+
+const (
+	ChangeTypeAdded   ChangeType = "added"
+	ChangeTypeRemoved ChangeType = "removed"
+	ChangeTypeChanged ChangeType = "changed"
+)
+
+// Change is a component that differs between two payloads
+type Change struct {
+	Name string     `json:"name"`
+	Type ChangeType `json:"type"`
+	From *Component `json:"from,omitempty"`
+	To   *Component `json:"to,omitempty"`
+	// Changelog links to the commits between the two sources, when known
+	Changelog string `json:"changelog,omitempty"`
+}
+
+// Report is the component-level difference between two payloads
+type Report struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Changes   []Change `json:"changes,omitempty"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// ComponentsFor determines the components of a payload from its image stream,
+// either the `image-references` of the payload or a stable stream it was imported to.
+func ComponentsFor(stream *imagev1.ImageStream) map[string]Component {
+	digests := map[string]string{}
+	for _, tag := range stream.Status.Tags {
+		if len(tag.Items) > 0 {
+			digests[tag.Tag] = tag.Items[0].Image
+		}
+	}
+	components := map[string]Component{}
+	for _, tag := range stream.Spec.Tags {
+		component := Component{
+			Name:   tag.Name,
+			Digest: digests[tag.Name],
+			Commit: tag.Annotations[CommitAnnotation],
+			Source: tag.Annotations[SourceAnnotation],
+		}
+		if tag.From != nil {
+			component.Image = tag.From.Name
+			if component.Digest == "" {
+				if parts := strings.SplitN(tag.From.Name, "@", 2); len(parts) == 2 {
+					component.Digest = parts[1]
+				}
+			}
+		}
+		components[tag.Name] = component
+	}
+	return components
+}
+
+// Diff compares the components of two payloads
+func Diff(from, to string, before, after map[string]Component) Report {
+	report := Report{From: from, To: to}
+	for name, old := range before {
+		old := old
+		current, ok := after[name]
+		if !ok {
+			report.Changes = append(report.Changes, Change{Name: name, Type: ChangeTypeRemoved, From: &old})
+			continue
+		}
+		if !changed(old, current) {
+			report.Unchanged++
+			continue
+		}
+		report.Changes = append(report.Changes, Change{
+			Name:      name,
+			Type:      ChangeTypeChanged,
+			From:      &old,
+			To:        &current,
+			Changelog: ChangelogURL(current.Source, old.Commit, current.Commit),
+		})
+	}
+	for name, current := range after {
+		current := current
+		if _, ok := before[name]; !ok {
+			report.Changes = append(report.Changes, Change{Name: name, Type: ChangeTypeAdded, To: &current})
+		}
+	}
+	sort.Slice(report.Changes, func(i, j int) bool {
+		return report.Changes[i].Name < report.Changes[j].Name
+	})
+	return report
+}
+
+func changed(old, current Component) bool {
+	if old.Digest != "" && current.Digest != "" {
+		return old.Digest != current.Digest
+	}
+	return old.Image != current.Image || old.Commit != current.Commit
+}
+
+// ChangelogURL links to the comparison of two commits of a GitHub repository,
+// or is empty when the comparison cannot be determined
+func ChangelogURL(source, from, to string) string {
+	source = strings.TrimSuffix(strings.TrimSuffix(source, "/"), ".git")
+	if !strings.HasPrefix(source, "https://github.com/") || from == "" || to == "" || from == to {
+		return ""
+	}
+	return fmt.Sprintf("%s/compare/%s...%s", source, from, to)
+}
+
+// TestCases reports every changed component as a test case
+func (r Report) TestCases() []*junit.TestCase {
+	var testCases []*junit.TestCase
+	for _, change := range r.Changes {
+		testCases = append(testCases, &junit.TestCase{
+			Name:      fmt.Sprintf("Release component %s %s between %s and %s", change.Name, change.Type, r.From, r.To),
+			SystemOut: change.String(),
+		})
+	}
+	return testCases
+}
+
+func (c Change) String() string {
+	var lines []string
+	for _, component := range []struct {
+		title     string
+		component *Component
+	}{{title: "from", component: c.From}, {title: "to", component: c.To}} {
+		if component.component == nil {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", component.title, component.component.Image)
+		if component.component.Commit != "" {
+			line = fmt.Sprintf("%s (%s@%s)", line, component.component.Source, component.component.Commit)
+		}
+		lines = append(lines, line)
+	}
+	if c.Changelog != "" {
+		lines = append(lines, fmt.Sprintf("changelog: %s", c.Changelog))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String summarizes the report for humans
+func (r Report) String() string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("%d components changed and %d unchanged between %s and %s", len(r.Changes), r.Unchanged, r.From, r.To))
+	for _, change := range r.Changes {
+		lines = append(lines, fmt.Sprintf("%s %s", change.Name, change.Type))
+		for _, line := range strings.Split(change.String(), "\n") {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
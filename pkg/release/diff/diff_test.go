@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func tag(name, image, commit string) imagev1.TagReference {
+	return imagev1.TagReference{
+		Name: name,
+		Annotations: map[string]string{
+			CommitAnnotation: commit,
+			SourceAnnotation: "https://github.com/openshift/" + name,
+		},
+		From: &coreapi.ObjectReference{Kind: "DockerImage", Name: image},
+	}
+}
+
+func TestComponentsFor(t *testing.T) {
+	stream := &imagev1.ImageStream{
+		Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+			tag("cli", "quay.io/ocp@sha256:cli", "abc"),
+			{Name: "tests", From: &coreapi.ObjectReference{Kind: "ImageStreamTag", Name: "pipeline:tests"}},
+		}},
+		Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+			{Tag: "tests", Items: []imagev1.TagEvent{{Image: "sha256:tests"}}},
+		}},
+	}
+	expected := map[string]Component{
+		"cli":   {Name: "cli", Image: "quay.io/ocp@sha256:cli", Digest: "sha256:cli", Commit: "abc", Source: "https://github.com/openshift/cli"},
+		"tests": {Name: "tests", Image: "pipeline:tests", Digest: "sha256:tests"},
+	}
+	if diff := cmp.Diff(expected, ComponentsFor(stream)); diff != "" {
+		t.Errorf("unexpected components: %s", diff)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]Component{
+		"cli":       {Name: "cli", Digest: "sha256:cli", Commit: "abc", Source: "https://github.com/openshift/oc"},
+		"installer": {Name: "installer", Digest: "sha256:installer", Commit: "def", Source: "https://github.com/openshift/installer"},
+		"removed":   {Name: "removed", Digest: "sha256:removed"},
+	}
+	after := map[string]Component{
+		"cli":       {Name: "cli", Digest: "sha256:cli", Commit: "abc", Source: "https://github.com/openshift/oc"},
+		"installer": {Name: "installer", Digest: "sha256:newer", Commit: "ghi", Source: "https://github.com/openshift/installer"},
+		"added":     {Name: "added", Digest: "sha256:added"},
+	}
+	installerFrom, installerTo, removed, added := before["installer"], after["installer"], before["removed"], after["added"]
+	expected := Report{
+		From: "initial",
+		To:   "latest",
+		Changes: []Change{
+			{Name: "added", Type: ChangeTypeAdded, To: &added},
+			{Name: "installer", Type: ChangeTypeChanged, From: &installerFrom, To: &installerTo, Changelog: "https://github.com/openshift/installer/compare/def...ghi"},
+			{Name: "removed", Type: ChangeTypeRemoved, From: &removed},
+		},
+		Unchanged: 1,
+	}
+	if diff := cmp.Diff(expected, Diff("initial", "latest", before, after)); diff != "" {
+		t.Errorf("unexpected report: %s", diff)
+	}
+}
+
+func TestChangelogURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		from, to string
+		expected string
+	}{
+		{
+			name:     "GitHub repository",
+			source:   "https://github.com/openshift/oc",
+			from:     "abc",
+			to:       "def",
+			expected: "https://github.com/openshift/oc/compare/abc...def",
+		},
+		{
+			name:     "GitHub repository with suffix",
+			source:   "https://github.com/openshift/oc.git",
+			from:     "abc",
+			to:       "def",
+			expected: "https://github.com/openshift/oc/compare/abc...def",
+		},
+		{
+			name:   "other repository",
+			source: "https://gitlab.com/openshift/oc",
+			from:   "abc",
+			to:     "def",
+		},
+		{
+			name:   "unknown commit",
+			source: "https://github.com/openshift/oc",
+			to:     "def",
+		},
+		{
+			name:   "same commit",
+			source: "https://github.com/openshift/oc",
+			from:   "abc",
+			to:     "abc",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := ChangelogURL(tc.source, tc.from, tc.to); actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
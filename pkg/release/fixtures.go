@@ -0,0 +1,110 @@
+package release
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Fixture is a recorded response of a release endpoint
+type Fixture struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Body       string `json:"body"`
+	// Date is when the response was served, replayed in the
+	// Date header so the age of releases is that of the snapshot
+	Date string `json:"date,omitempty"`
+}
+
+// FixturePath determines the file in the fixtures directory that holds
+// the recorded response to the request
+func FixturePath(dir string, req *http.Request) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s %s", req.Method, req.URL.String())))
+	return filepath.Join(dir, fmt.Sprintf("%s.json", hex.EncodeToString(hash[:8])))
+}
+
+// NewRecordingHTTPClient sends requests with the delegate and records
+// every response into the fixtures directory
+func NewRecordingHTTPClient(delegate HTTPClient, dir string) HTTPClient {
+	return &recordingHTTPClient{delegate: delegate, dir: dir}
+}
+
+type recordingHTTPClient struct {
+	delegate HTTPClient
+	dir      string
+	lock     sync.Mutex
+}
+
+func (c *recordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.delegate.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response to record it: %w", err)
+	}
+	date := resp.Header.Get("Date")
+	if date == "" {
+		date = time.Now().UTC().Format(http.TimeFormat)
+	}
+	raw, err := json.MarshalIndent(Fixture{Method: req.Method, URL: req.URL.String(), StatusCode: resp.StatusCode, Body: string(body), Date: date}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize fixture: %w", err)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create fixtures directory: %w", err)
+	}
+	if err := ioutil.WriteFile(FixturePath(c.dir, req), raw, 0644); err != nil {
+		return nil, fmt.Errorf("could not record fixture: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// NewReplayingHTTPClient answers requests with the responses recorded in
+// the fixtures directory and fails for requests that were not recorded
+func NewReplayingHTTPClient(dir string) HTTPClient {
+	return &replayingHTTPClient{dir: dir}
+}
+
+type replayingHTTPClient struct {
+	dir string
+}
+
+func (c *replayingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	raw, err := ioutil.ReadFile(FixturePath(c.dir, req))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no response recorded for %s %s", req.Method, req.URL.String())
+		}
+		return nil, fmt.Errorf("could not read fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(raw, &fixture); err != nil {
+		return nil, fmt.Errorf("could not parse fixture: %w", err)
+	}
+	header := http.Header{}
+	if fixture.Date != "" {
+		header.Set("Date", fixture.Date)
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", fixture.StatusCode, http.StatusText(fixture.StatusCode)),
+		StatusCode: fixture.StatusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(fixture.Body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,54 @@
+package release
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	var requests int
+	live := NewFakeHTTPClient(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Date": []string{"Sat, 30 May 2020 00:00:00 GMT"}}, Body: ioutil.NopCloser(bytes.NewBufferString(`{"pullSpec":"quay.io/openshift-release-dev/ocp-release:4.7.0"}`))}, nil
+	})
+	endpoint := "https://openshift-release.apps.ci.l2s4.p1.openshiftapps.com/api/v1/releasestream/4.7.0-0.ci/latest?rel=1"
+
+	for _, client := range []struct {
+		name string
+		HTTPClient
+	}{{name: "recording", HTTPClient: NewRecordingHTTPClient(live, dir)}, {name: "replaying", HTTPClient: NewReplayingHTTPClient(dir)}} {
+		name := client.name
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		if err != nil {
+			t.Fatalf("could not create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s: could not read body: %v", name, err)
+		}
+		if resp.StatusCode != http.StatusOK || string(body) != `{"pullSpec":"quay.io/openshift-release-dev/ocp-release:4.7.0"}` {
+			t.Errorf("%s: unexpected response %d: %s", name, resp.StatusCode, body)
+		}
+		if date := resp.Header.Get("Date"); date != "Sat, 30 May 2020 00:00:00 GMT" {
+			t.Errorf("%s: expected the response to be served at the recorded time, got %q", name, date)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected one request to the live endpoint, got %d", requests)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint+"&other", nil)
+	if err != nil {
+		t.Fatalf("could not create request: %v", err)
+	}
+	if _, err := NewReplayingHTTPClient(dir).Do(req); err == nil {
+		t.Error("expected an error for a request that was not recorded")
+	}
+}
@@ -123,6 +123,15 @@ func TestResolvePullSpec(t *testing.T) {
 			expected:    "registry.svc.ci.openshift.org/ocp/release:4.3.0-0.ci-2020-05-22-121811",
 			expectedErr: false,
 		},
+		{
+			name: "request with a version range",
+			versionBounds: api.VersionBounds{
+				Range: ">=4.8.0 <4.8.10 || >4.8.12 <4.9.0",
+			},
+			raw:         []byte(`{"name": "4.8.13","phase": "Accepted","pullSpec": "quay.io/openshift-release-dev/ocp-release:4.8.13-x86_64"}`),
+			expected:    "quay.io/openshift-release-dev/ocp-release:4.8.13-x86_64",
+			expectedErr: false,
+		},
 		{
 			name:        "malformed response errors",
 			raw:         []byte(`{"na1":}`),
@@ -0,0 +1,41 @@
+package resolve
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/release"
+	"github.com/openshift/ci-tools/pkg/release/candidate"
+	"github.com/openshift/ci-tools/pkg/release/official"
+	"github.com/openshift/ci-tools/pkg/release/prerelease"
+)
+
+// PullSpec resolves the pull spec of the release payload the release describes.
+// Candidates without a version resolve to the version of the tested branch.
+func PullSpec(client release.HTTPClient, unresolved api.UnresolvedRelease, branch string) (string, error) {
+	switch {
+	case unresolved.Candidate != nil:
+		return candidate.ResolvePullSpec(client, *unresolved.Candidate, branch)
+	case unresolved.Release != nil:
+		pullSpec, _, err := official.ResolvePullSpecAndVersion(client, *unresolved.Release)
+		return pullSpec, err
+	case unresolved.Prerelease != nil:
+		return prerelease.ResolvePullSpec(client, *unresolved.Prerelease)
+	}
+	return "", nil
+}
+
+// Releases resolves every release of the configuration, returning errors
+// for the ones that do not resolve. With a client replaying recorded fixtures,
+// this checks the releases resolve against that snapshot.
+func Releases(client release.HTTPClient, config *api.ReleaseBuildConfiguration) []error {
+	var errs []error
+	for _, name := range sets.StringKeySet(config.Releases).List() {
+		if _, err := PullSpec(client, config.Releases[name], config.Metadata.Branch); err != nil {
+			errs = append(errs, fmt.Errorf("releases.%s: could not resolve: %w", name, err))
+		}
+	}
+	return errs
+}
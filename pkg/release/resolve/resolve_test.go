@@ -0,0 +1,49 @@
+package resolve
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/release"
+)
+
+func TestReleases(t *testing.T) {
+	dir := t.TempDir()
+	live := release.NewFakeHTTPClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`{"name":"4.7.0-0.ci","pullSpec":"registry.ci.openshift.org/ocp/release:4.7.0-0.ci"}`))}, nil
+	})
+	recorded := api.UnresolvedRelease{Candidate: &api.Candidate{Product: api.ReleaseProductOCP, Architecture: api.ReleaseArchitectureAMD64, Stream: api.ReleaseStreamCI, Version: "4.7"}}
+	pullSpec, err := PullSpec(release.NewRecordingHTTPClient(live, dir), recorded, "master")
+	if err != nil {
+		t.Fatalf("could not record release: %v", err)
+	}
+	if expected := "registry.ci.openshift.org/ocp/release:4.7.0-0.ci"; pullSpec != expected {
+		t.Errorf("expected pull spec %s, got %s", expected, pullSpec)
+	}
+
+	branchVersion := api.UnresolvedRelease{Candidate: &api.Candidate{Product: api.ReleaseProductOCP, Stream: api.ReleaseStreamCI}}
+	if _, err := PullSpec(release.NewReplayingHTTPClient(dir), branchVersion, "release-4.7"); err != nil {
+		t.Errorf("expected the version of the branch to resolve from the recorded release: %v", err)
+	}
+	if _, err := PullSpec(release.NewReplayingHTTPClient(dir), branchVersion, "master"); err == nil {
+		t.Error("expected an error for a branch without a version")
+	}
+
+	config := &api.ReleaseBuildConfiguration{InputConfiguration: api.InputConfiguration{Releases: map[string]api.UnresolvedRelease{
+		"latest":  recorded,
+		"initial": {Candidate: &api.Candidate{Product: api.ReleaseProductOCP, Architecture: api.ReleaseArchitectureAMD64, Stream: api.ReleaseStreamCI, Version: "4.6"}},
+	}}}
+	var errs []string
+	for _, err := range Releases(release.NewReplayingHTTPClient(dir), config) {
+		errs = append(errs, err.Error())
+	}
+	expected := []string{"releases.initial: could not resolve: failed to request latest release: no response recorded for GET https://amd64.ocp.releases.ci.openshift.org/api/v1/releasestream/4.6.0-0.ci/latest"}
+	if diff := cmp.Diff(expected, errs); diff != "" {
+		t.Errorf("unexpected errors: %s", diff)
+	}
+}
@@ -0,0 +1,95 @@
+package artifacts
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// Getter knows how to read artifacts that jobs upload
+type Getter interface {
+	// Get returns the content of the object, or storage.ErrObjectNotExist
+	Get(ctx context.Context, bucket, object string) ([]byte, error)
+	// Tail returns at most the last size bytes of the object, or storage.ErrObjectNotExist
+	Tail(ctx context.Context, bucket, object string, size int64) ([]byte, error)
+}
+
+// Lister knows how to list the artifacts that jobs upload
+type Lister interface {
+	// ListDirectories returns the names of the directories directly under the prefix,
+	// like the build IDs for the logs/<job>/ prefix
+	ListDirectories(ctx context.Context, bucket, prefix string) ([]string, error)
+}
+
+// GCS reads the artifacts jobs upload to GCS
+type GCS struct {
+	client *storage.Client
+}
+
+var _ Getter = &GCS{}
+var _ Lister = &GCS{}
+
+// NewGCS creates a reader for artifacts in GCS
+func NewGCS(client *storage.Client) *GCS {
+	return &GCS{client: client}
+}
+
+func (g *GCS) Get(ctx context.Context, bucket, object string) ([]byte, error) {
+	reader, err := g.client.Bucket(bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+func (g *GCS) Tail(ctx context.Context, bucket, object string, size int64) ([]byte, error) {
+	reader, err := g.client.Bucket(bucket).Object(object).NewRangeReader(ctx, -size, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	// objects stored with gzip encoding are served whole regardless of
+	// the range, so only ever hold on to the end of what is read
+	var tail []byte
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := reader.Read(chunk)
+		tail = append(tail, chunk[:n]...)
+		if excess := int64(len(tail)) - size; excess > 0 {
+			tail = append(tail[:0], tail[excess:]...)
+		}
+		if err == io.EOF {
+			return tail, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (g *GCS) ListDirectories(ctx context.Context, bucket, prefix string) ([]string, error) {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	objects := g.client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	var directories []string
+	for {
+		attrs, err := objects.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// with a delimiter, directories are listed as prefixes
+		if attrs.Prefix != "" {
+			directories = append(directories, strings.TrimSuffix(strings.TrimPrefix(attrs.Prefix, prefix), "/"))
+		}
+	}
+	return directories, nil
+}
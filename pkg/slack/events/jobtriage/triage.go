@@ -0,0 +1,386 @@
+package jobtriage
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/slack/artifacts"
+	"github.com/openshift/ci-tools/pkg/slack/events"
+	"github.com/openshift/ci-tools/pkg/slack/modals/bug"
+)
+
+const (
+	// buildLog is the log of the job run, relative to its storage
+	buildLog = "build-log.txt"
+	// operatorJUnit holds the results of the steps ci-operator ran
+	operatorJUnit = "junit_operator.xml"
+
+	// logTailLines is the number of lines we show from the end of the build log
+	logTailLines = 20
+	// logTailBytes is how much we read from the end of the build log to find the
+	// lines to show, as build logs can be too large to read whole
+	logTailBytes = 64 * 1024
+	// triageTimeout bounds the time we spend reading artifacts for a request
+	triageTimeout = 30 * time.Second
+	// maxSnippetLength keeps snippets well under the 3000-character limit for section text
+	maxSnippetLength = 2500
+	// maxSymptomLength leaves space for the rest of a pre-filled button value
+	// when we add the failure output to the symptoms
+	maxSymptomLength = 1000
+)
+
+type messagePoster interface {
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
+}
+
+// triagePattern matches a request to triage a job run. Slack wraps links in
+// angle brackets and may add a label after a pipe.
+var triagePattern = regexp.MustCompile(`\btriage\s+<([^|>]+)(?:\|[^>]*)?>`)
+
+// Handler returns a handler that knows how to respond to mentions of the
+// robot asking to triage a job run, like `@bot triage <job-url>`, by
+// summarizing why the run failed from the artifacts it uploaded
+func Handler(client messagePoster, gcsClient *storage.Client) events.PartialHandler {
+	return handler(client, artifacts.NewGCS(gcsClient))
+}
+
+func handler(client messagePoster, getter artifacts.Getter) events.PartialHandler {
+	return events.PartialHandlerFunc("jobtriage", func(callback *slackevents.EventsAPIEvent, logger *logrus.Entry) (handled bool, err error) {
+		if callback.Type != slackevents.CallbackEvent {
+			return false, nil
+		}
+		event, ok := callback.InnerEvent.Data.(*slackevents.AppMentionEvent)
+		if !ok {
+			return false, nil
+		}
+		run := runFromMention(event.Text)
+		if run == nil {
+			return false, nil
+		}
+		logger = logger.WithFields(logrus.Fields{"job": run.name, "id": run.id})
+		logger.Info("Handling triage request...")
+		ctx, cancel := context.WithTimeout(context.Background(), triageTimeout)
+		defer cancel()
+		blocks := triageRun(ctx, *run, getter, logger).blocks()
+		timestamp := event.TimeStamp
+		if event.ThreadTimeStamp != "" {
+			timestamp = event.ThreadTimeStamp
+		}
+		responseChannel, responseTimestamp, err := client.PostMessage(event.Channel, slack.MsgOptionBlocks(blocks...), slack.MsgOptionTS(timestamp))
+		if err != nil {
+			logger.WithError(err).Warn("Failed to post response to triage request")
+		} else {
+			logger.Infof("Posted response to triage request in channel %s at %s", responseChannel, responseTimestamp)
+		}
+		return true, err
+	})
+}
+
+// jobRun identifies where a job run stored its artifacts
+type jobRun struct {
+	bucket string
+	// path is the storage prefix of the run, ending in its build ID
+	path     string
+	name, id string
+}
+
+// url is where the run can be viewed in Prow
+func (r jobRun) url() string {
+	return fmt.Sprintf("%s/view/gs/%s/%s", api.URLForService(api.ServiceProw), r.bucket, r.path)
+}
+
+func (r jobRun) object(elements ...string) string {
+	return path.Join(append([]string{r.path}, elements...)...)
+}
+
+func runFromMention(text string) *jobRun {
+	match := triagePattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	link, err := url.Parse(match[1])
+	if err != nil {
+		return nil
+	}
+	return runFromURL(link)
+}
+
+// runFromURL handles links that hold the storage location of the job run, like:
+// https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/periodic-openshift-library-import/1319699861964066816
+// https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/origin-ci-test/logs/periodic-openshift-library-import/1319699861964066816/build-log.txt
+// https://storage.googleapis.com/origin-ci-test/logs/periodic-openshift-library-import/1319699861964066816/build-log.txt
+func runFromURL(link *url.URL) *jobRun {
+	var prefix string
+	switch link.Host {
+	case api.DomainForService(api.ServiceProw):
+		prefix = "/view/gs/"
+	case api.DomainForService(api.ServiceGCSWeb):
+		prefix = "/gcs/"
+	case "storage.googleapis.com":
+		prefix = "/"
+	default:
+		return nil
+	}
+	if !strings.HasPrefix(link.Path, prefix) {
+		return nil
+	}
+	parts := strings.Split(strings.TrimPrefix(link.Path, prefix), "/")
+	// the last fully numeric path part before user-provided
+	// artifacts will be the job ID, preceded by the job name
+	index := -1
+	for i, part := range parts {
+		if part == "artifacts" {
+			break
+		}
+		if _, err := strconv.Atoi(part); err == nil {
+			index = i
+		}
+	}
+	if index < 2 {
+		return nil
+	}
+	return &jobRun{
+		bucket: parts[0],
+		path:   strings.Join(parts[1:index+1], "/"),
+		name:   parts[index-1],
+		id:     parts[index],
+	}
+}
+
+// triage summarizes why a job run failed
+type triage struct {
+	run jobRun
+	// step is the ci-operator step that failed, if we found one
+	step, description string
+	// substep is the failed step of a multi-stage test
+	substep string
+	// reason is the reason ci-operator recorded for the failure
+	reason string
+	// output is the failure recorded in the jUnit for the step
+	output string
+	// logTail is the end of the build log
+	logTail string
+	// missing lists the artifacts we could not read
+	missing []string
+}
+
+func triageRun(ctx context.Context, run jobRun, getter artifacts.Getter, logger *logrus.Entry) triage {
+	result := triage{run: run}
+	// read fetches the whole object, or only its end when tailSize is set
+	read := func(object string, tailSize int64) []byte {
+		var raw []byte
+		var err error
+		if tailSize > 0 {
+			raw, err = getter.Tail(ctx, run.bucket, object, tailSize)
+		} else {
+			raw, err = getter.Get(ctx, run.bucket, object)
+		}
+		if err != nil {
+			if !errors.Is(err, storage.ErrObjectNotExist) {
+				logger.WithError(err).Warnf("Failed to read artifact %s", object)
+			}
+			result.missing = append(result.missing, path.Base(object))
+			return nil
+		}
+		return raw
+	}
+
+	if raw := read(run.object("artifacts", api.CIOperatorStepGraphJSONFilename), 0); raw != nil {
+		var graph api.CIOperatorStepGraph
+		if err := json.Unmarshal(raw, &graph); err != nil {
+			logger.WithError(err).Warn("Failed to parse the step graph")
+		} else {
+			result.fromGraph(graph)
+		}
+	}
+	if raw := read(run.object("artifacts", operatorJUnit), 0); raw != nil {
+		var suites junit.TestSuites
+		if err := xml.Unmarshal(raw, &suites); err != nil {
+			logger.WithError(err).Warn("Failed to parse the jUnit")
+		} else {
+			result.fromJUnit(suites)
+		}
+	}
+	if raw := read(run.object(buildLog), logTailBytes); raw != nil {
+		text := string(raw)
+		if len(raw) == logTailBytes {
+			// the read most likely started in the middle of a line
+			if newline := strings.IndexByte(text, '\n'); newline != -1 {
+				text = text[newline+1:]
+			}
+		}
+		result.logTail = tail(text, logTailLines)
+	}
+	return result
+}
+
+// fromGraph records the first failed step in the graph
+func (t *triage) fromGraph(graph api.CIOperatorStepGraph) {
+	for _, step := range graph {
+		if step.Failed == nil || !*step.Failed {
+			continue
+		}
+		t.step, t.description, t.reason = step.StepName, step.Description, step.Reason
+		for _, substep := range step.Substeps {
+			if substep.Failed != nil && *substep.Failed {
+				t.substep = substep.StepName
+				break
+			}
+		}
+		return
+	}
+}
+
+// fromJUnit records the failure output for the failed step, or the first
+// failure when the step graph did not identify a step
+func (t *triage) fromJUnit(suites junit.TestSuites) {
+	var failures []*junit.TestCase
+	var collect func(suite *junit.TestSuite)
+	collect = func(suite *junit.TestSuite) {
+		for _, testCase := range suite.TestCases {
+			if testCase.FailureOutput != nil {
+				failures = append(failures, testCase)
+			}
+		}
+		for _, child := range suite.Children {
+			collect(child)
+		}
+	}
+	for _, suite := range suites.Suites {
+		collect(suite)
+	}
+	if len(failures) == 0 {
+		return
+	}
+	failure := failures[0]
+	if t.step != "" {
+		failure = nil
+		for _, testCase := range failures {
+			if testCase.Name == t.description {
+				failure = testCase
+				break
+			}
+		}
+		if failure == nil {
+			return
+		}
+	} else {
+		t.step = failure.Name
+	}
+	t.output = strings.TrimSpace(failure.FailureOutput.Output)
+	if t.output == "" {
+		t.output = strings.TrimSpace(failure.FailureOutput.Message)
+	}
+}
+
+// tail returns the last lines of the text
+func tail(text string, lines int) string {
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n")
+}
+
+// truncate shortens text to the limit, keeping the end as that is where
+// the relevant parts of failures usually are
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return "..." + text[len(text)-limit+3:]
+}
+
+func (t triage) blocks() []slack.Block {
+	markdown := func(text string) slack.Block {
+		return &slack.SectionBlock{
+			Type: slack.MBTSection,
+			Text: &slack.TextBlockObject{Type: slack.MarkdownType, Text: text},
+		}
+	}
+	blocks := []slack.Block{markdown(fmt.Sprintf("*Triage for <%s|%s #%s>*", t.run.url(), t.run.name, t.run.id))}
+	if t.step == "" {
+		blocks = append(blocks, markdown("I could not find a failed step in the artifacts of this job run."))
+	} else {
+		details := []string{fmt.Sprintf("*Failed step:* `%s`", t.step)}
+		if t.description != "" {
+			details = append(details, t.description)
+		}
+		if t.substep != "" {
+			details = append(details, fmt.Sprintf("*Failed sub-step:* `%s`", t.substep))
+		}
+		if t.reason != "" {
+			details = append(details, fmt.Sprintf("*Reason:* `%s`", t.reason))
+		}
+		blocks = append(blocks, markdown(strings.Join(details, "\n")))
+	}
+	if t.output != "" {
+		blocks = append(blocks, markdown(fmt.Sprintf("*Failure:*\n```%s```", truncate(t.output, maxSnippetLength))))
+	}
+	if t.logTail != "" {
+		blocks = append(blocks, markdown(fmt.Sprintf("*End of the build log:*\n```%s```", truncate(t.logTail, maxSnippetLength))))
+	}
+	if len(t.missing) > 0 {
+		blocks = append(blocks, &slack.ContextBlock{
+			Type: slack.MBTContext,
+			ContextElements: slack.ContextElements{Elements: []slack.MixedElement{
+				&slack.TextBlockObject{Type: slack.MarkdownType, Text: fmt.Sprintf("Could not read: %s", strings.Join(t.missing, ", "))},
+			}},
+		})
+	}
+	return append(blocks, &slack.DividerBlock{Type: slack.MBTDivider}, &slack.SectionBlock{
+		Type: slack.MBTSection,
+		Text: &slack.TextBlockObject{
+			Type: slack.PlainTextType,
+			Text: "If the test infrastructure caused this failure, file a bug with these details filled in.",
+		},
+		Accessory: &slack.Accessory{
+			ButtonElement: &slack.ButtonBlockElement{
+				Type:  slack.METButton,
+				Text:  &slack.TextBlockObject{Type: slack.PlainTextType, Text: "File a Bug"},
+				Value: t.bugButtonValue(),
+			},
+		},
+	})
+}
+
+// bugButtonValue opens the bug modal with a title and symptoms from the
+// triage, falling back to an empty modal if they do not fit in the button
+func (t triage) bugButtonValue() string {
+	title := fmt.Sprintf("%s failed", t.run.name)
+	symptom := fmt.Sprintf("Job run %s failed", t.run.url())
+	if t.step != "" {
+		title = fmt.Sprintf("%s failed in %s", t.run.name, t.step)
+		symptom = fmt.Sprintf("%s in step %s", symptom, t.step)
+		if t.substep != "" {
+			symptom = fmt.Sprintf("%s (sub-step %s)", symptom, t.substep)
+		}
+	}
+	if t.reason != "" {
+		symptom = fmt.Sprintf("%s with reason %s", symptom, t.reason)
+	}
+	if t.output != "" {
+		symptom = fmt.Sprintf("%s:\n\n%s", symptom, truncate(t.output, maxSymptomLength))
+	}
+	value, err := bug.PrefilledButtonValue(title, symptom)
+	if err != nil {
+		return string(bug.Identifier)
+	}
+	return value
+}
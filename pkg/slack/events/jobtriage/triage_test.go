@@ -0,0 +1,165 @@
+package jobtriage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestRunFromMention(t *testing.T) {
+	var testCases = []struct {
+		text     string
+		expected *jobRun
+	}{
+		{
+			text: "<@U01B31ARZDG> triage <https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/periodic-openshift-library-import/1319699861964066816>",
+			expected: &jobRun{
+				bucket: "origin-ci-test",
+				path:   "logs/periodic-openshift-library-import/1319699861964066816",
+				name:   "periodic-openshift-library-import",
+				id:     "1319699861964066816",
+			},
+		},
+		{
+			text: "<@U01B31ARZDG> triage <https://prow.ci.openshift.org/view/gs/origin-ci-test/pr-logs/pull/openshift_release/12371/rehearse-12371-periodic-ci-kubevirt-kubevirt-master-e2e-nested-virt/1318930182802771968|this job> please",
+			expected: &jobRun{
+				bucket: "origin-ci-test",
+				path:   "pr-logs/pull/openshift_release/12371/rehearse-12371-periodic-ci-kubevirt-kubevirt-master-e2e-nested-virt/1318930182802771968",
+				name:   "rehearse-12371-periodic-ci-kubevirt-kubevirt-master-e2e-nested-virt",
+				id:     "1318930182802771968",
+			},
+		},
+		{
+			text: "<@U01B31ARZDG> triage <https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/origin-ci-test/pr-logs/pull/25585/pull-ci-openshift-origin-master-e2e-aws-disruptive/1319310480841379840/artifacts/e2e-aws-disruptive/123/build-log.txt>",
+			expected: &jobRun{
+				bucket: "origin-ci-test",
+				path:   "pr-logs/pull/25585/pull-ci-openshift-origin-master-e2e-aws-disruptive/1319310480841379840",
+				name:   "pull-ci-openshift-origin-master-e2e-aws-disruptive",
+				id:     "1319310480841379840",
+			},
+		},
+		{
+			text: "<@U01B31ARZDG> triage <https://storage.googleapis.com/origin-ci-test/logs/periodic-openshift-library-import/1319699861964066816/build-log.txt>",
+			expected: &jobRun{
+				bucket: "origin-ci-test",
+				path:   "logs/periodic-openshift-library-import/1319699861964066816",
+				name:   "periodic-openshift-library-import",
+				id:     "1319699861964066816",
+			},
+		},
+		{
+			text: "<@U01B31ARZDG> triage <https://prow.ci.openshift.org/log?job=pull-ci-openshift-installer-release-4.6-e2e-metal-ipi&amp;id=1319125780608847872>",
+		},
+		{
+			text: "<@U01B31ARZDG> triage <https://github.com/openshift/release/pull/13221>",
+		},
+		{
+			text: "<@U01B31ARZDG> I need to report an outage, please triage",
+		},
+	}
+	for _, testCase := range testCases {
+		if diff := cmp.Diff(testCase.expected, runFromMention(testCase.text), cmp.AllowUnexported(jobRun{})); diff != "" {
+			t.Errorf("got incorrect job run for %q: %v", testCase.text, diff)
+		}
+	}
+}
+
+type fakeArtifactGetter map[string]string
+
+func (f fakeArtifactGetter) Get(_ context.Context, bucket, object string) ([]byte, error) {
+	content, ok := f[bucket+"/"+object]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	return []byte(content), nil
+}
+
+func (f fakeArtifactGetter) Tail(_ context.Context, bucket, object string, size int64) ([]byte, error) {
+	content, ok := f[bucket+"/"+object]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	if int64(len(content)) > size {
+		content = content[int64(len(content))-size:]
+	}
+	return []byte(content), nil
+}
+
+const stepGraph = `[
+  {"name": "src", "description": "Clone the correct source code into an image and tag it as src", "dependencies": null, "started_at": null, "finished_at": null, "failed": false},
+  {"name": "e2e-aws", "description": "Run multi-stage test e2e-aws", "dependencies": ["src"], "started_at": null, "finished_at": null, "failed": true, "reason": "step_failed:executing_graph:executing_test",
+   "substeps": [
+     {"name": "e2e-aws-ipi-install", "description": "", "dependencies": null, "started_at": null, "finished_at": null, "failed": false},
+     {"name": "e2e-aws-openshift-e2e-test", "description": "", "dependencies": null, "started_at": null, "finished_at": null, "failed": true},
+     {"name": "e2e-aws-ipi-deprovision", "description": "", "dependencies": null, "started_at": null, "finished_at": null, "failed": false}
+   ]}
+]`
+
+const operatorJUnitContent = `<testsuites>
+  <testsuite name="operator" tests="2" skipped="0" failures="1" time="120">
+    <testcase name="Clone the correct source code into an image and tag it as src" time="10"></testcase>
+    <testcase name="Run multi-stage test e2e-aws" time="110">
+      <failure message="">"e2e-aws" pod "e2e-aws-openshift-e2e-test" failed: the pod ci-op-1234/e2e-aws-openshift-e2e-test failed after 1h0m0s (failed containers: test)</failure>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+const buildLogContent = `INFO[2021-03-01T00:00:00Z] Running step e2e-aws-openshift-e2e-test.
+INFO[2021-03-01T01:00:00Z] Step e2e-aws-openshift-e2e-test failed after 1h0m0s.
+INFO[2021-03-01T01:00:01Z] Running step e2e-aws-ipi-deprovision.
+ERRO[2021-03-01T01:10:00Z] Some steps failed:
+ERRO[2021-03-01T01:10:00Z]   * could not run steps: step e2e-aws failed: "e2e-aws" test steps failed
+`
+
+func TestTriageRun(t *testing.T) {
+	run := jobRun{
+		bucket: "origin-ci-test",
+		path:   "logs/periodic-ci-openshift-release-master-nightly-4.8-e2e-aws/1366420036279488512",
+		name:   "periodic-ci-openshift-release-master-nightly-4.8-e2e-aws",
+		id:     "1366420036279488512",
+	}
+	prefix := run.bucket + "/" + run.path + "/"
+	var testCases = []struct {
+		name      string
+		artifacts fakeArtifactGetter
+	}{
+		{
+			name: "failed multi-stage test with all artifacts",
+			artifacts: fakeArtifactGetter{
+				prefix + "artifacts/ci-operator-step-graph.json": stepGraph,
+				prefix + "artifacts/junit_operator.xml":          operatorJUnitContent,
+				prefix + "build-log.txt":                         buildLogContent,
+			},
+		},
+		{
+			name: "build log larger than what is read from its end",
+			artifacts: fakeArtifactGetter{
+				prefix + "artifacts/ci-operator-step-graph.json": stepGraph,
+				prefix + "artifacts/junit_operator.xml":          operatorJUnitContent,
+				prefix + "build-log.txt":                         strings.Repeat("x", logTailBytes) + "\n" + buildLogContent,
+			},
+		},
+		{
+			name: "failure found only in jUnit",
+			artifacts: fakeArtifactGetter{
+				prefix + "artifacts/junit_operator.xml": operatorJUnitContent,
+			},
+		},
+		{
+			name:      "no artifacts",
+			artifacts: fakeArtifactGetter{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := triageRun(context.Background(), run, testCase.artifacts, logrus.WithField("test", testCase.name)).blocks()
+			testhelper.CompareWithFixture(t, actual)
+		})
+	}
+}
@@ -6,16 +6,27 @@ import (
 
 	"k8s.io/test-infra/prow/config"
 
+	"github.com/openshift/ci-tools/pkg/load/agents"
 	"github.com/openshift/ci-tools/pkg/slack/events"
 	"github.com/openshift/ci-tools/pkg/slack/events/joblink"
+	"github.com/openshift/ci-tools/pkg/slack/events/jobtriage"
 	"github.com/openshift/ci-tools/pkg/slack/events/mention"
+	"github.com/openshift/ci-tools/pkg/slack/events/subscribe"
+	"github.com/openshift/ci-tools/pkg/slack/subscriptions"
 )
 
 // ForEvents returns a Handler that appropriately routes
-// event callbacks for the handlers we know about
-func ForEvents(client *slack.Client, config config.Getter, gcsClient *storage.Client) events.Handler {
-	return events.MultiHandler(
+// event callbacks for the handlers we know about. Requests
+// to manage subscriptions are only handled with a store, and
+// their registry targets are checked when a registry is set.
+func ForEvents(client *slack.Client, config config.Getter, gcsClient *storage.Client, store subscriptions.Store, registryAgent agents.RegistryAgent) events.Handler {
+	// triage and subscription requests are mentions as well, so they need to be handled first
+	handlers := []events.PartialHandler{jobtriage.Handler(client, gcsClient)}
+	if store != nil {
+		handlers = append(handlers, subscribe.Handler(client, store, config, registryAgent))
+	}
+	return events.MultiHandler(append(handlers,
 		mention.Handler(client),
 		joblink.Handler(client, joblink.NewJobGetter(config), gcsClient),
-	)
+	)...)
 }
@@ -0,0 +1,171 @@
+package subscribe
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+
+	"k8s.io/test-infra/prow/config"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/slack/events"
+	"github.com/openshift/ci-tools/pkg/slack/subscriptions"
+)
+
+type messagePoster interface {
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
+}
+
+// registryGetter knows the components of the step registry
+type registryGetter interface {
+	GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata)
+}
+
+var (
+	// subscribePattern matches requests like `@bot subscribe periodic <job> 3` or `@bot unsubscribe config org/repo`
+	subscribePattern = regexp.MustCompile(`^\s*<@[^>]+>\s+(subscribe|unsubscribe)\s+(periodic|config|registry)\s+(\S+)(?:\s+(\d+))?\s*$`)
+	// listPattern matches requests to list the subscriptions of a channel, like `@bot subscriptions`
+	listPattern = regexp.MustCompile(`^\s*<@[^>]+>\s+(?:list\s+)?subscriptions\s*$`)
+)
+
+// request is what a user asked for in a mention
+type request struct {
+	unsubscribe  bool
+	list         bool
+	subscription subscriptions.Subscription
+}
+
+func requestFor(text, channel string) *request {
+	if match := subscribePattern.FindStringSubmatch(text); match != nil {
+		subscription := subscriptions.Subscription{Channel: channel, Kind: subscriptions.Kind(match[2]), Target: match[3]}
+		if match[4] != "" {
+			if threshold, err := strconv.Atoi(match[4]); err == nil {
+				subscription.Threshold = threshold
+			}
+		}
+		return &request{unsubscribe: match[1] == "unsubscribe", subscription: subscription}
+	}
+	if listPattern.MatchString(text) {
+		return &request{list: true}
+	}
+	return nil
+}
+
+// Handler returns a handler that knows how to respond to mentions of the robot
+// that manage the subscriptions of the channel to notifications, like:
+// `@bot subscribe periodic <job> [<failures in a row>]`, `@bot subscribe config <org>/<repo>`,
+// `@bot subscribe registry <step, chain or workflow>`, `@bot unsubscribe ...` and `@bot subscriptions`.
+// Targets are checked against the Prow config and the registry when they are set.
+func Handler(client messagePoster, store subscriptions.Store, prowConfig config.Getter, registryAgent agents.RegistryAgent) events.PartialHandler {
+	var components registryGetter
+	if registryAgent != nil {
+		components = registryAgent
+	}
+	return events.PartialHandlerFunc("subscribe", func(callback *slackevents.EventsAPIEvent, logger *logrus.Entry) (handled bool, err error) {
+		if callback.Type != slackevents.CallbackEvent {
+			return false, nil
+		}
+		event, ok := callback.InnerEvent.Data.(*slackevents.AppMentionEvent)
+		if !ok {
+			return false, nil
+		}
+		request := requestFor(event.Text, event.Channel)
+		if request == nil {
+			return false, nil
+		}
+		logger.Info("Handling subscription request...")
+		response := responseFor(context.Background(), *request, store, prowConfig, components, event.Channel, logger)
+		timestamp := event.TimeStamp
+		if event.ThreadTimeStamp != "" {
+			timestamp = event.ThreadTimeStamp
+		}
+		responseChannel, responseTimestamp, err := client.PostMessage(event.Channel, slack.MsgOptionText(response, false), slack.MsgOptionTS(timestamp))
+		if err != nil {
+			logger.WithError(err).Warn("Failed to post response to subscription request")
+		} else {
+			logger.Infof("Posted response to subscription request in channel %s at %s", responseChannel, responseTimestamp)
+		}
+		return true, err
+	})
+}
+
+func responseFor(ctx context.Context, request request, store subscriptions.Store, prowConfig config.Getter, components registryGetter, channel string, logger *logrus.Entry) string {
+	if request.list {
+		all, err := store.List(ctx)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to list subscriptions")
+			return "Sorry, I could not look up the subscriptions of this channel."
+		}
+		descriptions := subscriptions.Describe(all, channel)
+		if len(descriptions) == 0 {
+			return "This channel has no subscriptions."
+		}
+		return fmt.Sprintf("This channel is subscribed to:\n• %s", strings.Join(descriptions, "\n• "))
+	}
+
+	subscription := request.subscription
+	if request.unsubscribe {
+		removed, err := store.Remove(ctx, subscription)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to remove subscription")
+			return "Sorry, I could not remove the subscription."
+		}
+		if !removed {
+			return fmt.Sprintf("This channel is not subscribed to %s %s.", subscription.Kind, subscription.Target)
+		}
+		return fmt.Sprintf("Unsubscribed this channel from %s %s.", subscription.Kind, subscription.Target)
+	}
+
+	if message := validate(subscription, prowConfig, components); message != "" {
+		return message
+	}
+	if err := store.Add(ctx, subscription); err != nil {
+		logger.WithError(err).Warn("Failed to add subscription")
+		return "Sorry, I could not add the subscription."
+	}
+	return fmt.Sprintf("Subscribed this channel to %s.", subscription)
+}
+
+// validate returns a message for the user if the subscription cannot work
+func validate(subscription subscriptions.Subscription, prowConfig config.Getter, components registryGetter) string {
+	switch subscription.Kind {
+	case subscriptions.KindPeriodic:
+		if prowConfig == nil {
+			return ""
+		}
+		for _, periodic := range prowConfig().AllPeriodics() {
+			if periodic.Name == subscription.Target {
+				return ""
+			}
+		}
+		return fmt.Sprintf("I could not find a periodic job named %s.", subscription.Target)
+	case subscriptions.KindConfig:
+		if parts := strings.Split(subscription.Target, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Sprintf("Subscriptions to configs need an org/repo, not %s.", subscription.Target)
+		}
+	case subscriptions.KindRegistry:
+		if components == nil {
+			return ""
+		}
+		refs, chains, workflows, _, _ := components.GetRegistryComponents()
+		if _, ok := refs[subscription.Target]; ok {
+			return ""
+		}
+		if _, ok := chains[subscription.Target]; ok {
+			return ""
+		}
+		if _, ok := workflows[subscription.Target]; ok {
+			return ""
+		}
+		return fmt.Sprintf("I could not find a step, chain or workflow named %s in the registry.", subscription.Target)
+	}
+	return ""
+}
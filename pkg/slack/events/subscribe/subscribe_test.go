@@ -0,0 +1,161 @@
+package subscribe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/config"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/slack/subscriptions"
+)
+
+type fakeRegistry struct {
+	refs registry.ReferenceByName
+}
+
+func (f fakeRegistry) GetRegistryComponents() (registry.ReferenceByName, registry.ChainByName, registry.WorkflowByName, map[string]string, api.RegistryMetadata) {
+	return f.refs, nil, nil, nil, nil
+}
+
+func TestRequestFor(t *testing.T) {
+	var testCases = []struct {
+		text     string
+		expected *request
+	}{
+		{
+			text:     "<@U01B31ARZDG> subscribe periodic periodic-ci-openshift-release-master-nightly-4.8-e2e-aws 5",
+			expected: &request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindPeriodic, Target: "periodic-ci-openshift-release-master-nightly-4.8-e2e-aws", Threshold: 5}},
+		},
+		{
+			text:     "<@U01B31ARZDG> subscribe config openshift/ci-tools",
+			expected: &request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindConfig, Target: "openshift/ci-tools"}},
+		},
+		{
+			text: "<@U01B31ARZDG> should we subscribe config openshift/ci-tools to something?",
+		},
+		{
+			text:     "<@U01B31ARZDG> unsubscribe registry ipi-install",
+			expected: &request{unsubscribe: true, subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindRegistry, Target: "ipi-install"}},
+		},
+		{
+			text:     "<@U01B31ARZDG> subscriptions",
+			expected: &request{list: true},
+		},
+		{
+			text:     "<@U01B31ARZDG> list subscriptions",
+			expected: &request{list: true},
+		},
+		{
+			text: "<@U01B31ARZDG> why did the job fail after the subscriptions changed?",
+		},
+		{
+			text: "<@U01B31ARZDG> subscribe me to something",
+		},
+		{
+			text: "<@U01B31ARZDG> help me file a bug",
+		},
+	}
+	for _, testCase := range testCases {
+		if diff := cmp.Diff(testCase.expected, requestFor(testCase.text, "C1"), cmp.AllowUnexported(request{})); diff != "" {
+			t.Errorf("got incorrect request for %q: %v", testCase.text, diff)
+		}
+	}
+}
+
+type fakeStore struct {
+	subscriptions []subscriptions.Subscription
+}
+
+func (f *fakeStore) List(context.Context) ([]subscriptions.Subscription, error) {
+	return f.subscriptions, nil
+}
+
+func (f *fakeStore) Add(_ context.Context, subscription subscriptions.Subscription) error {
+	f.subscriptions = append(f.subscriptions, subscription)
+	return nil
+}
+
+func (f *fakeStore) Remove(_ context.Context, subscription subscriptions.Subscription) (bool, error) {
+	for i, existing := range f.subscriptions {
+		if existing.Matches(subscription) {
+			f.subscriptions = append(f.subscriptions[:i], f.subscriptions[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestResponseFor(t *testing.T) {
+	prowConfig := func() *config.Config {
+		return &config.Config{JobConfig: config.JobConfig{Periodics: []config.Periodic{{JobBase: config.JobBase{Name: "periodic-job"}}}}}
+	}
+	components := fakeRegistry{refs: registry.ReferenceByName{"ipi-install": {}}}
+	store := &fakeStore{}
+	var testCases = []struct {
+		name     string
+		request  request
+		expected string
+	}{
+		{
+			name:     "no subscriptions yet",
+			request:  request{list: true},
+			expected: "This channel has no subscriptions.",
+		},
+		{
+			name:     "subscribe to a periodic",
+			request:  request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindPeriodic, Target: "periodic-job"}},
+			expected: "Subscribed this channel to periodic periodic-job after 3 failures.",
+		},
+		{
+			name:     "subscribe to a missing periodic",
+			request:  request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindPeriodic, Target: "missing-job"}},
+			expected: "I could not find a periodic job named missing-job.",
+		},
+		{
+			name:     "subscribe to an invalid config",
+			request:  request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindConfig, Target: "openshift"}},
+			expected: "Subscriptions to configs need an org/repo, not openshift.",
+		},
+		{
+			name:     "subscribe to a config",
+			request:  request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindConfig, Target: "openshift/ci-tools"}},
+			expected: "Subscribed this channel to config openshift/ci-tools.",
+		},
+		{
+			name:     "subscribe to a registry component",
+			request:  request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindRegistry, Target: "ipi-install"}},
+			expected: "Subscribed this channel to registry ipi-install.",
+		},
+		{
+			name:     "subscribe to a missing registry component",
+			request:  request{subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindRegistry, Target: "ipi-instal"}},
+			expected: "I could not find a step, chain or workflow named ipi-instal in the registry.",
+		},
+		{
+			name:     "list subscriptions",
+			request:  request{list: true},
+			expected: "This channel is subscribed to:\n• config openshift/ci-tools\n• periodic periodic-job after 3 failures\n• registry ipi-install",
+		},
+		{
+			name:     "unsubscribe",
+			request:  request{unsubscribe: true, subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindConfig, Target: "openshift/ci-tools"}},
+			expected: "Unsubscribed this channel from config openshift/ci-tools.",
+		},
+		{
+			name:     "unsubscribe again",
+			request:  request{unsubscribe: true, subscription: subscriptions.Subscription{Channel: "C1", Kind: subscriptions.KindConfig, Target: "openshift/ci-tools"}},
+			expected: "This channel is not subscribed to config openshift/ci-tools.",
+		},
+	}
+	for _, testCase := range testCases {
+		actual := responseFor(context.Background(), testCase.request, store, prowConfig, components, "C1", logrus.WithField("test", testCase.name))
+		if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+			t.Errorf("%s: got incorrect response: %v", testCase.name, diff)
+		}
+	}
+}
@@ -16,8 +16,9 @@ import (
 )
 
 // ForModals returns a Handler that appropriately routes
-// interaction callbacks for the modals we know about
-func ForModals(filer jira.IssueFiler, client *slack.Client) interactions.Handler {
+// interaction callbacks for the modals we know about and
+// any declared modals, which replace those with the same ID
+func ForModals(filer jira.IssueFiler, client *slack.Client, declared ...*modals.FlowWithViewAndFollowUps) interactions.Handler {
 	router := &modalRouter{
 		slackClient:         client,
 		viewsById:           map[modals.Identifier]slack.ModalViewRequest{},
@@ -32,11 +33,17 @@ func ForModals(filer jira.IssueFiler, client *slack.Client) interactions.Handler
 		incident.Register(filer, client),
 		triage.Register(filer, client),
 	}
+	toRegister = append(toRegister, declared...)
 
 	for _, entry := range toRegister {
 		router.viewsById[entry.Identifier] = entry.View
 		router.handlersByIdAndType[entry.Identifier] = entry.FollowUps
 	}
+	// users choose what to do with similar issues in a View
+	// that any of the flows filing Jira issues can lead to
+	router.handlersByIdAndType[modals.IdentifierJiraDuplicates] = map[slack.InteractionType]interactions.Handler{
+		slack.InteractionTypeViewSubmission: modals.ToJiraDuplicateChoice(filer, client),
+	}
 
 	return router
 }
@@ -81,17 +88,17 @@ type slackClient interface {
 // to open the first modal view for them
 func (r *modalRouter) viewForShortcut(callback *slack.InteractionCallback, logger *logrus.Entry) error {
 	id := modals.Identifier(callback.CallbackID)
-	return r.openModal(id, callback.TriggerID, logger)
+	return r.openModal(id, nil, callback.TriggerID, logger)
 }
 
 // viewForButton reacts to the a user pressing a button in a bot message
-// to open the a modal view for them
+// to open the a modal view for them, filling in any values the button holds
 func (r *modalRouter) viewForButton(callback *slack.InteractionCallback, logger *logrus.Entry) error {
-	id := modals.Identifier(callback.ActionCallback.BlockActions[0].Value)
-	return r.openModal(id, callback.TriggerID, logger)
+	id, values := modals.ParseButtonValue(callback.ActionCallback.BlockActions[0].Value)
+	return r.openModal(id, values, callback.TriggerID, logger)
 }
 
-func (r *modalRouter) openModal(id modals.Identifier, triggerID string, logger *logrus.Entry) error {
+func (r *modalRouter) openModal(id modals.Identifier, values map[string]string, triggerID string, logger *logrus.Entry) error {
 	logger = logger.WithField("view_id", id)
 	logger.Infof("Opening modal view %s.", id)
 	view, exists := r.viewsById[id]
@@ -100,7 +107,7 @@ func (r *modalRouter) openModal(id modals.Identifier, triggerID string, logger *
 		return nil
 	}
 
-	response, err := r.slackClient.OpenView(triggerID, view)
+	response, err := r.slackClient.OpenView(triggerID, modals.WithInitialValues(view, values))
 	if err != nil {
 		logger.WithError(err).Warn("Failed to open a modal flow.")
 	}
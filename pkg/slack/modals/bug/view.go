@@ -123,6 +123,15 @@ func View() slack.ModalViewRequest {
 	}
 }
 
+// PrefilledButtonValue is the value for a message button that opens this
+// modal with the title and the description of the symptoms filled in
+func PrefilledButtonValue(title, symptom string) (string, error) {
+	return modals.PrefilledButtonValue(Identifier, map[string]string{
+		modals.BlockIdTitle: title,
+		blockIdSymptom:      symptom,
+	})
+}
+
 // helpdeskButtonHandler redirects the user to the helpdesk flow
 // if they request it via button push
 func helpdeskButtonHandler(updater modals.ViewUpdater) interactions.Handler {
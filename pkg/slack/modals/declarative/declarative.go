@@ -0,0 +1,457 @@
+package declarative
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/jira"
+	"github.com/openshift/ci-tools/pkg/slack/interactions"
+	"github.com/openshift/ci-tools/pkg/slack/modals"
+)
+
+// BlockType is the type of a block in a declared modal
+type BlockType string
+
+const (
+	// BlockTypeText shows text to the user, optionally with a
+	// button that takes the user to another declared modal
+	BlockTypeText BlockType = "text"
+	// BlockTypeDivider separates other blocks
+	BlockTypeDivider BlockType = "divider"
+	// BlockTypeInput asks the user for one value
+	BlockTypeInput BlockType = "input"
+	// BlockTypeActions holds selectors next to each other
+	BlockTypeActions BlockType = "actions"
+)
+
+// ElementType is the type of an input element in a declared modal
+type ElementType string
+
+const (
+	ElementTypeText      ElementType = "text"
+	ElementTypeMultiline ElementType = "multiline"
+	ElementTypeSelect    ElementType = "select"
+	ElementTypeChannel   ElementType = "channel"
+	ElementTypeUser      ElementType = "user"
+)
+
+// Config declares modals for the bot to serve
+type Config struct {
+	Modals []Modal `json:"modals"`
+}
+
+// Modal declares one form and the Jira issue its submission files
+type Modal struct {
+	// ID identifies the modal in shortcuts, buttons and callbacks
+	ID modals.Identifier `json:"id"`
+	// Title is shown at the top of the modal
+	Title string `json:"title"`
+	// Blocks make up the form, in order
+	Blocks []Block `json:"blocks"`
+	// Jira configures the issue we file when the form is submitted.
+	// Submissions of modals without it are ignored.
+	Jira *JiraIssue `json:"jira,omitempty"`
+}
+
+// Block declares one block of a modal
+type Block struct {
+	Type BlockType `json:"type"`
+	// ID identifies the values of input and actions blocks in
+	// the description template. The input with the ID "title"
+	// is used as the title of the Jira issue.
+	ID string `json:"id,omitempty"`
+	// Text is shown in text blocks
+	Text string `json:"text,omitempty"`
+	// Button is added to a text block
+	Button *Button `json:"button,omitempty"`
+	// Label is shown above an input block
+	Label string `json:"label,omitempty"`
+	// Optional inputs do not need to be filled in...
+	Optional bool `json:"optional,omitempty"`
+	// ... unless the condition is met
+	RequiredWhen *Condition `json:"requiredWhen,omitempty"`
+	// Element is the input of an input block
+	Element *Element `json:"element,omitempty"`
+	// Elements are the selectors in an actions block
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Button takes the user to another declared modal
+type Button struct {
+	Text string `json:"text"`
+	// Opens is the ID of the modal to show
+	Opens modals.Identifier `json:"opens"`
+	// Value identifies the button press, defaulting to Opens
+	Value string `json:"value,omitempty"`
+}
+
+func (b Button) value() string {
+	if b.Value != "" {
+		return b.Value
+	}
+	return string(b.Opens)
+}
+
+// Element declares an input
+type Element struct {
+	Type        ElementType `json:"type"`
+	Placeholder string      `json:"placeholder,omitempty"`
+	// Options are the choices of a select element
+	Options []string `json:"options,omitempty"`
+}
+
+// Condition is met when the user chose or entered the value in another block
+type Condition struct {
+	Block string `json:"block"`
+	Value string `json:"value"`
+	// Message is shown to the user when the condition is
+	// met and the optional input was not filled in
+	Message string `json:"message"`
+}
+
+// JiraIssue declares the Jira issue to file for a modal
+type JiraIssue struct {
+	IssueType string `json:"issueType"`
+	// Template renders the description of the issue from the values of the
+	// input and actions blocks. Values of selectors are exposed under the
+	// block ID suffixed with the selector type, like `<id>_users_select`.
+	// The toBulletList, slackUserLink and slackChannelLink functions
+	// are available to format values.
+	Template string `json:"template"`
+}
+
+// Load reads and validates the declared modals from the file
+func Load(path string) (*Config, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read modal config: %w", err)
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(raw, &config); err != nil {
+		return nil, fmt.Errorf("could not unmarshal modal config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid modal config: %w", err)
+	}
+	return &config, nil
+}
+
+var knownIssueTypes = sets.NewString(jira.IssueTypeBug, jira.IssueTypeIncident, jira.IssueTypeRequest, jira.IssueTypeStory, jira.IssueTypeTask)
+
+// Validate ensures the modals can be rendered and their buttons and conditions resolve
+func (c *Config) Validate() error {
+	ids := sets.NewString()
+	for _, modal := range c.Modals {
+		if modal.ID == "" {
+			return fmt.Errorf("modal %q: an ID is required", modal.Title)
+		}
+		if ids.Has(string(modal.ID)) {
+			return fmt.Errorf("modal %s: declared more than once", modal.ID)
+		}
+		ids.Insert(string(modal.ID))
+	}
+	for _, modal := range c.Modals {
+		if err := modal.validate(ids); err != nil {
+			return fmt.Errorf("modal %s: %w", modal.ID, err)
+		}
+	}
+	return nil
+}
+
+func (m *Modal) validate(modalIds sets.String) error {
+	if m.Title == "" {
+		return fmt.Errorf("a title is required")
+	}
+	blockIds := sets.NewString()
+	for i, block := range m.Blocks {
+		switch block.Type {
+		case BlockTypeText:
+			if block.Text == "" {
+				return fmt.Errorf("block %d: text blocks need text", i)
+			}
+			if block.Button != nil && !modalIds.Has(string(block.Button.Opens)) {
+				return fmt.Errorf("block %d: button opens unknown modal %q", i, block.Button.Opens)
+			}
+		case BlockTypeDivider:
+		case BlockTypeInput:
+			if block.Label == "" {
+				return fmt.Errorf("block %d: input blocks need a label", i)
+			}
+			if block.Element == nil {
+				return fmt.Errorf("block %d: input blocks need an element", i)
+			}
+			if err := block.Element.validate(); err != nil {
+				return fmt.Errorf("block %d: %w", i, err)
+			}
+		case BlockTypeActions:
+			if len(block.Elements) == 0 {
+				return fmt.Errorf("block %d: actions blocks need elements", i)
+			}
+			for _, element := range block.Elements {
+				if err := element.validate(); err != nil {
+					return fmt.Errorf("block %d: %w", i, err)
+				}
+			}
+		default:
+			return fmt.Errorf("block %d: unknown block type %q", i, block.Type)
+		}
+		if block.Type == BlockTypeInput || block.Type == BlockTypeActions {
+			if block.ID == "" {
+				return fmt.Errorf("block %d: %s blocks need an ID", i, block.Type)
+			}
+			if blockIds.Has(block.ID) {
+				return fmt.Errorf("block %d: ID %s is used more than once", i, block.ID)
+			}
+			blockIds.Insert(block.ID)
+		}
+	}
+	for i, block := range m.Blocks {
+		if block.RequiredWhen == nil {
+			continue
+		}
+		if !block.Optional || block.Type != BlockTypeInput {
+			return fmt.Errorf("block %d: only optional inputs can be required conditionally", i)
+		}
+		if !blockIds.Has(block.RequiredWhen.Block) {
+			return fmt.Errorf("block %d: condition refers to unknown block %q", i, block.RequiredWhen.Block)
+		}
+		if block.RequiredWhen.Message == "" {
+			return fmt.Errorf("block %d: condition needs a message", i)
+		}
+	}
+	if m.Jira != nil {
+		if !blockIds.Has(modals.BlockIdTitle) {
+			return fmt.Errorf("modals filing Jira issues need an input with the ID %q", modals.BlockIdTitle)
+		}
+		if !knownIssueTypes.Has(m.Jira.IssueType) {
+			return fmt.Errorf("unknown Jira issue type %q, expected one of %v", m.Jira.IssueType, knownIssueTypes.List())
+		}
+		if _, err := m.template(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Element) validate() error {
+	switch e.Type {
+	case ElementTypeText, ElementTypeMultiline, ElementTypeChannel, ElementTypeUser:
+	case ElementTypeSelect:
+		if len(e.Options) == 0 {
+			return fmt.Errorf("select elements need options")
+		}
+	default:
+		return fmt.Errorf("unknown element type %q", e.Type)
+	}
+	return nil
+}
+
+// View renders the modal View for the declared modal
+func (m *Modal) View() slack.ModalViewRequest {
+	var blocks []slack.Block
+	for _, block := range m.Blocks {
+		switch block.Type {
+		case BlockTypeText:
+			section := &slack.SectionBlock{
+				Type: slack.MBTSection,
+				Text: &slack.TextBlockObject{Type: slack.PlainTextType, Text: block.Text},
+			}
+			if block.Button != nil {
+				section.Accessory = &slack.Accessory{
+					ButtonElement: &slack.ButtonBlockElement{
+						Type:  slack.METButton,
+						Text:  &slack.TextBlockObject{Type: slack.PlainTextType, Text: block.Button.Text},
+						Value: block.Button.value(),
+					},
+				}
+			}
+			blocks = append(blocks, section)
+		case BlockTypeDivider:
+			blocks = append(blocks, &slack.DividerBlock{Type: slack.MBTDivider})
+		case BlockTypeInput:
+			blocks = append(blocks, &slack.InputBlock{
+				Type:     slack.MBTInput,
+				BlockID:  block.ID,
+				Optional: block.Optional,
+				Label:    &slack.TextBlockObject{Type: slack.PlainTextType, Text: block.Label},
+				Element:  block.Element.element(),
+			})
+		case BlockTypeActions:
+			var elements []slack.BlockElement
+			for _, element := range block.Elements {
+				elements = append(elements, element.element())
+			}
+			blocks = append(blocks, &slack.ActionBlock{
+				Type:     slack.MBTAction,
+				BlockID:  block.ID,
+				Elements: &slack.BlockElements{ElementSet: elements},
+			})
+		}
+	}
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		PrivateMetadata: string(m.ID),
+		Title:           &slack.TextBlockObject{Type: slack.PlainTextType, Text: m.Title},
+		Close:           &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Cancel"},
+		Submit:          &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Submit"},
+		Blocks:          slack.Blocks{BlockSet: blocks},
+	}
+}
+
+func (e *Element) element() slack.BlockElement {
+	var placeholder *slack.TextBlockObject
+	if e.Placeholder != "" {
+		placeholder = &slack.TextBlockObject{Type: slack.PlainTextType, Text: e.Placeholder}
+	}
+	switch e.Type {
+	case ElementTypeSelect:
+		var options []*slack.OptionBlockObject
+		for _, option := range e.Options {
+			options = append(options, &slack.OptionBlockObject{Value: option, Text: &slack.TextBlockObject{Type: slack.PlainTextType, Text: option}})
+		}
+		return &slack.SelectBlockElement{Type: slack.OptTypeStatic, Placeholder: placeholder, Options: options}
+	case ElementTypeChannel:
+		return &slack.SelectBlockElement{Type: slack.OptTypeChannels, Placeholder: placeholder}
+	case ElementTypeUser:
+		return &slack.SelectBlockElement{Type: slack.OptTypeUser, Placeholder: placeholder}
+	default:
+		return &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput, Placeholder: placeholder, Multiline: e.Type == ElementTypeMultiline}
+	}
+}
+
+func (m *Modal) template(client modals.InfoGetter) (*template.Template, error) {
+	funcs := modals.BulletListFunc()
+	for name, function := range modals.SlackEntityFormatFuncs(client) {
+		funcs[name] = function
+	}
+	parsed, err := template.New(string(m.ID)).Funcs(funcs).Parse(m.Jira.Template)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse Jira issue template: %w", err)
+	}
+	return parsed, nil
+}
+
+// IssueParameters determines how to file a Jira issue for the modal
+func (m *Modal) IssueParameters(client modals.InfoGetter) (modals.JiraIssueParameters, error) {
+	parsed, err := m.template(client)
+	if err != nil {
+		return modals.JiraIssueParameters{}, err
+	}
+	var fields []string
+	for _, block := range m.Blocks {
+		if block.Type == BlockTypeInput || block.Type == BlockTypeActions {
+			fields = append(fields, block.ID)
+		}
+	}
+	return modals.JiraIssueParameters{
+		Id:        m.ID,
+		IssueType: m.Jira.IssueType,
+		Template:  parsed,
+		Fields:    fields,
+	}, nil
+}
+
+// validateSubmissionHandler ensures conditionally required inputs are filled in
+func (m *Modal) validateSubmissionHandler() interactions.PartialHandler {
+	return interactions.PartialHandlerFunc(string(m.ID)+".validate", func(callback *slack.InteractionCallback, logger *logrus.Entry) (bool, []byte, error) {
+		errors := map[string]string{}
+		for _, block := range m.Blocks {
+			if block.RequiredWhen == nil || !conditionMet(callback, *block.RequiredWhen) {
+				continue
+			}
+			for _, action := range callback.View.State.Values[block.ID] {
+				if inputValue(block.Element.Type, action) == "" {
+					errors[block.ID] = block.RequiredWhen.Message
+				}
+			}
+		}
+		if len(errors) == 0 {
+			return false, nil, nil
+		}
+		logger.Debug("Detected invalid submission.")
+		response, err := json.Marshal(&slack.ViewSubmissionResponse{
+			ResponseAction: slack.RAErrors,
+			Errors:         errors,
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to marshal view submission response.")
+			return true, nil, err
+		}
+		return true, response, nil
+	})
+}
+
+// inputValue is what was entered into an input of the type, as selectors
+// hold their choice in a field of their own
+func inputValue(elementType ElementType, action slack.BlockAction) string {
+	switch elementType {
+	case ElementTypeSelect:
+		return action.SelectedOption.Value
+	case ElementTypeChannel:
+		return action.SelectedChannel
+	case ElementTypeUser:
+		return action.SelectedUser
+	default:
+		return action.Value
+	}
+}
+
+func conditionMet(callback *slack.InteractionCallback, condition Condition) bool {
+	for _, action := range callback.View.State.Values[condition.Block] {
+		if action.SelectedOption.Value == condition.Value || action.Value == condition.Value {
+			return true
+		}
+	}
+	return false
+}
+
+type slackClient interface {
+	modals.InfoGetter
+	modals.ViewUpdater
+}
+
+// Register creates registration entries for the declared modals
+func (c *Config) Register(filer jira.IssueFiler, client slackClient) ([]*modals.FlowWithViewAndFollowUps, error) {
+	views := map[modals.Identifier]slack.ModalViewRequest{}
+	for i := range c.Modals {
+		views[c.Modals[i].ID] = c.Modals[i].View()
+	}
+
+	var flows []*modals.FlowWithViewAndFollowUps
+	for i := range c.Modals {
+		modal := &c.Modals[i]
+		followUps := map[slack.InteractionType]interactions.Handler{}
+
+		var buttonHandlers []interactions.PartialHandler
+		for _, block := range modal.Blocks {
+			if block.Button != nil {
+				buttonHandlers = append(buttonHandlers, modals.UpdateViewForButtonPress(string(modal.ID)+"."+block.Button.value(), block.Button.value(), client, views[block.Button.Opens]))
+			}
+		}
+		if len(buttonHandlers) > 0 {
+			followUps[slack.InteractionTypeBlockActions] = interactions.MultiHandler(buttonHandlers...)
+		}
+
+		if modal.Jira != nil {
+			parameters, err := modal.IssueParameters(client)
+			if err != nil {
+				return nil, fmt.Errorf("modal %s: %w", modal.ID, err)
+			}
+			followUps[slack.InteractionTypeViewSubmission] = interactions.MultiHandler(
+				modal.validateSubmissionHandler(),
+				interactions.PartialFromHandler(modals.ToJiraIssue(parameters, filer, client)),
+			)
+		}
+
+		flows = append(flows, modals.ForView(modal.ID, views[modal.ID]).WithFollowUps(followUps))
+	}
+	return flows, nil
+}
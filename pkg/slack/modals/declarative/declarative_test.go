@@ -0,0 +1,237 @@
+package declarative
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift/ci-tools/pkg/slack/modals"
+	"github.com/openshift/ci-tools/pkg/slack/modals/bug"
+	"github.com/openshift/ci-tools/pkg/slack/modals/consultation"
+	"github.com/openshift/ci-tools/pkg/slack/modals/enhancement"
+	"github.com/openshift/ci-tools/pkg/slack/modals/helpdesk"
+	"github.com/openshift/ci-tools/pkg/slack/modals/incident"
+	"github.com/openshift/ci-tools/pkg/slack/modals/modaltesting"
+	"github.com/openshift/ci-tools/pkg/slack/modals/triage"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func loadBuiltIns(t *testing.T) map[modals.Identifier]*Modal {
+	config, err := Load("testdata/modals.yaml")
+	if err != nil {
+		t.Fatalf("failed to load modals: %v", err)
+	}
+	byId := map[modals.Identifier]*Modal{}
+	for i := range config.Modals {
+		byId[config.Modals[i].ID] = &config.Modals[i]
+	}
+	return byId
+}
+
+func TestBuiltInViews(t *testing.T) {
+	declared := loadBuiltIns(t)
+	for id, expected := range map[modals.Identifier]slack.ModalViewRequest{
+		bug.Identifier:          bug.View(),
+		consultation.Identifier: consultation.View(),
+		enhancement.Identifier:  enhancement.View(),
+		helpdesk.Identifier:     helpdesk.View(),
+		incident.Identifier:     incident.View(),
+		triage.Identifier:       triage.View(),
+	} {
+		modal, ok := declared[id]
+		if !ok {
+			t.Errorf("modal %s is not declared", id)
+			continue
+		}
+		if diff := cmp.Diff(expected, modal.View()); diff != "" {
+			t.Errorf("modal %s: got incorrect view: %v", id, diff)
+		}
+	}
+}
+
+func TestBugIssueParameters(t *testing.T) {
+	modal := loadBuiltIns(t)[bug.Identifier]
+	parameters, err := modal.IssueParameters(nil)
+	if err != nil {
+		t.Fatalf("failed to get issue parameters: %v", err)
+	}
+	modaltesting.ValidateBlockIds(t, modal.View(), parameters.Fields...)
+	modaltesting.ValidateParameterProcessing(t, parameters, []modaltesting.ProcessTestCase{
+		{
+			Name:          "custom component",
+			ExpectedTitle: "My Title",
+			ExpectedBody: `h3. Symptomatic Behavior
+Something wrong!
+
+h3. Expected Behavior
+Something right!
+
+h3. Impact
+I'm on fire.
+
+h3. Category
+Other: My Component
+
+h3. How to Reproduce
+Every time, just push the button.`,
+		},
+		{
+			Name:          "extant component",
+			ExpectedTitle: "My Title",
+			ExpectedBody: `h3. Symptomatic Behavior
+Something wrong!
+
+h3. Expected Behavior
+Something right!
+
+h3. Impact
+I'm on fire.
+
+h3. Category
+Release Controller
+
+h3. How to Reproduce
+Every time, just push the button.`,
+		},
+	})
+}
+
+func TestBugValidateSubmissionHandler(t *testing.T) {
+	modal := loadBuiltIns(t)[bug.Identifier]
+	var testCases = []struct {
+		name            string
+		expectedHandled bool
+	}{
+		{
+			name:            "valid because specific component chosen",
+			expectedHandled: false,
+		},
+		{
+			name:            "valid because other component chosen and written in",
+			expectedHandled: false,
+		},
+		{
+			name:            "invalid because other component chosen and not written in",
+			expectedHandled: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var callback slack.InteractionCallback
+			modaltesting.ReadCallbackFixture(t, &callback)
+			handled, out, err := modal.validateSubmissionHandler().Handle(&callback, logrus.WithField("test", testCase.name))
+			if expected, actual := testCase.expectedHandled, handled; expected != actual {
+				t.Errorf("%s: expected handled %v but got %v", testCase.name, expected, actual)
+			}
+			testhelper.CompareWithFixture(t, out)
+			if err != nil {
+				t.Errorf("%s: expected no error but got one: %v", testCase.name, err)
+			}
+		})
+	}
+}
+
+func TestValidateSubmissionHandlerSelectors(t *testing.T) {
+	modal := Modal{ID: "selectors", Blocks: []Block{
+		{Type: BlockTypeInput, ID: "kind", Label: "Kind", Element: &Element{Type: ElementTypeSelect}},
+		{Type: BlockTypeInput, ID: "owner", Label: "Owner", Optional: true, Element: &Element{Type: ElementTypeUser},
+			RequiredWhen: &Condition{Block: "kind", Value: "other", Message: "Who owns it?"}},
+	}}
+	var testCases = []struct {
+		name            string
+		owner           slack.BlockAction
+		expectedHandled bool
+	}{
+		{
+			name:            "valid because a user is chosen",
+			owner:           slack.BlockAction{SelectedUser: "U1"},
+			expectedHandled: false,
+		},
+		{
+			name:            "invalid because no user is chosen",
+			owner:           slack.BlockAction{},
+			expectedHandled: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			callback := slack.InteractionCallback{View: slack.View{State: &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+				"kind":  {"kind": {SelectedOption: slack.OptionBlockObject{Value: "other"}}},
+				"owner": {"owner": testCase.owner},
+			}}}}
+			handled, _, err := modal.validateSubmissionHandler().Handle(&callback, logrus.WithField("test", testCase.name))
+			if err != nil {
+				t.Errorf("expected no error but got one: %v", err)
+			}
+			if handled != testCase.expectedHandled {
+				t.Errorf("expected handled %v but got %v", testCase.expectedHandled, handled)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	title := Block{Type: BlockTypeInput, ID: modals.BlockIdTitle, Label: "Title:", Element: &Element{Type: ElementTypeText}}
+	var testCases = []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			name: "valid",
+			config: Config{Modals: []Modal{
+				{ID: "form", Title: "Form", Blocks: []Block{{Type: BlockTypeText, Text: "Hi", Button: &Button{Text: "Other", Opens: "other"}}, title}, Jira: &JiraIssue{IssueType: "Task", Template: "{{ .title }}"}},
+				{ID: "other", Title: "Other", Blocks: []Block{{Type: BlockTypeText, Text: "Hi"}}},
+			}},
+		},
+		{
+			name:     "duplicate modal",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form"}, {ID: "form", Title: "Form"}}},
+			expected: "modal form: declared more than once",
+		},
+		{
+			name:     "button opens unknown modal",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form", Blocks: []Block{{Type: BlockTypeText, Text: "Hi", Button: &Button{Text: "Other", Opens: "other"}}}}}},
+			expected: `modal form: block 0: button opens unknown modal "other"`,
+		},
+		{
+			name:     "select without options",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form", Blocks: []Block{{Type: BlockTypeInput, ID: "choice", Label: "Choose:", Element: &Element{Type: ElementTypeSelect}}}}}},
+			expected: "modal form: block 0: select elements need options",
+		},
+		{
+			name:     "condition on unknown block",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form", Blocks: []Block{{Type: BlockTypeInput, ID: "other", Label: "Other:", Optional: true, RequiredWhen: &Condition{Block: "choice", Value: "Other", Message: "Fill it in."}, Element: &Element{Type: ElementTypeText}}}}}},
+			expected: `modal form: block 0: condition refers to unknown block "choice"`,
+		},
+		{
+			name:     "Jira issue without a title",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form", Jira: &JiraIssue{IssueType: "Task"}}}},
+			expected: `modal form: modals filing Jira issues need an input with the ID "title"`,
+		},
+		{
+			name:     "unknown issue type",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form", Blocks: []Block{title}, Jira: &JiraIssue{IssueType: "Epic"}}}},
+			expected: `modal form: unknown Jira issue type "Epic", expected one of [Bug Incident Request Story Task]`,
+		},
+		{
+			name:     "invalid template",
+			config:   Config{Modals: []Modal{{ID: "form", Title: "Form", Blocks: []Block{title}, Jira: &JiraIssue{IssueType: "Task", Template: "{{ unknown .title }}"}}}},
+			expected: `modal form: could not parse Jira issue template: template: form:1: function "unknown" not defined`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual string
+			if err := testCase.config.Validate(); err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(testCase.expected, actual); diff != "" {
+				t.Errorf("%s: got incorrect error: %v", testCase.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package modals
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jiraapi "github.com/andygrunwald/go-jira"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift/ci-tools/pkg/jira"
+	"github.com/openshift/ci-tools/pkg/slack/interactions"
+)
+
+const (
+	IdentifierJiraDuplicates Identifier = "jira_duplicates"
+	IdentifierJiraComment    Identifier = "jira_comment"
+
+	blockIdDuplicateAction = "duplicate_action"
+	blockIdDescription     = "description"
+
+	// maxOptionTextLength is the longest text Slack allows for an option
+	maxOptionTextLength = 75
+	// maxInputLength is the longest value Slack allows for a text input
+	maxInputLength = 3000
+)
+
+// duplicateChoice is what the user chose to do with a possible duplicate,
+// serialized into the value of the option they selected so that we do not
+// need to hold any state between showing the candidates and the submission
+type duplicateChoice struct {
+	// Comment is the key of the issue to comment on, if the user chose to
+	Comment string `json:"comment,omitempty"`
+	// IssueType is the type of the new issue to file otherwise
+	IssueType string `json:"type,omitempty"`
+	// Related are the keys of the candidates to link a new issue to
+	Related []string `json:"related,omitempty"`
+}
+
+// DuplicatesView is a modal View that shows the user open issues that are
+// similar to the one they are filing and lets them comment on one of those
+// instead, or file a new issue linked to them. The title and description
+// are carried in inputs, so the user can review them before submitting.
+// Slack rejects inputs with longer values than it allows, so reports that
+// do not fit cannot be carried and no View is created for them, as we
+// would otherwise lose part of the report.
+func DuplicatesView(issueType, title, description string, candidates []jiraapi.Issue) (slack.ModalViewRequest, error) {
+	for name, value := range map[string]string{"title": title, "description": description} {
+		if len(value) > maxInputLength {
+			return slack.ModalViewRequest{}, fmt.Errorf("the %s is %d characters long, longer than the %d characters a Slack input can hold", name, len(value), maxInputLength)
+		}
+	}
+	var keys, links []string
+	var options []*slack.OptionBlockObject
+	for _, candidate := range candidates {
+		keys = append(keys, candidate.Key)
+		summary, status := "", ""
+		if candidate.Fields != nil {
+			summary = candidate.Fields.Summary
+			if candidate.Fields.Status != nil {
+				status = fmt.Sprintf(" (%s)", candidate.Fields.Status.Name)
+			}
+		}
+		links = append(links, fmt.Sprintf("• <https://issues.redhat.com/browse/%s|%s>: %s%s", candidate.Key, candidate.Key, summary, status))
+		value, err := json.Marshal(duplicateChoice{Comment: candidate.Key})
+		if err != nil {
+			return slack.ModalViewRequest{}, fmt.Errorf("could not serialize choice: %w", err)
+		}
+		options = append(options, &slack.OptionBlockObject{
+			Value: string(value),
+			Text:  &slack.TextBlockObject{Type: slack.PlainTextType, Text: truncate(fmt.Sprintf("Comment on %s: %s", candidate.Key, summary), maxOptionTextLength)},
+		})
+	}
+	value, err := json.Marshal(duplicateChoice{IssueType: issueType, Related: keys})
+	if err != nil {
+		return slack.ModalViewRequest{}, fmt.Errorf("could not serialize choice: %w", err)
+	}
+	fileNew := &slack.OptionBlockObject{
+		Value: string(value),
+		Text:  &slack.TextBlockObject{Type: slack.PlainTextType, Text: "File a new issue, linked to these"},
+	}
+	options = append(options, fileNew)
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		PrivateMetadata: string(IdentifierJiraDuplicates),
+		Title:           &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Similar Issues Found"},
+		Close:           &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Cancel"},
+		Submit:          &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Submit"},
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			&slack.SectionBlock{
+				Type: slack.MBTSection,
+				Text: &slack.TextBlockObject{
+					Type: slack.MarkdownType,
+					Text: fmt.Sprintf("These open issues look similar to the one you are filing:\n%s", strings.Join(links, "\n")),
+				},
+			},
+			&slack.InputBlock{
+				Type:    slack.MBTInput,
+				BlockID: blockIdDuplicateAction,
+				Label:   &slack.TextBlockObject{Type: slack.PlainTextType, Text: "What would you like to do?"},
+				Element: &slack.RadioButtonsBlockElement{
+					Type:          slack.METRadioButtons,
+					Options:       options,
+					InitialOption: fileNew,
+				},
+			},
+			&slack.DividerBlock{
+				Type: slack.MBTDivider,
+			},
+			&slack.InputBlock{
+				Type:    slack.MBTInput,
+				BlockID: BlockIdTitle,
+				Label:   &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Title:"},
+				Element: &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput, InitialValue: title},
+			},
+			&slack.InputBlock{
+				Type:    slack.MBTInput,
+				BlockID: blockIdDescription,
+				Label:   &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Description:"},
+				Element: &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput, Multiline: true, InitialValue: description},
+			},
+		}},
+	}, nil
+}
+
+func truncate(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+	return text[:length-3] + "..."
+}
+
+// ToJiraDuplicateChoice responds to the user with a confirmation screen and
+// behind the scenes either comments on the existing issue the user chose or
+// files a new issue linked to the candidates, updating the View once done.
+func ToJiraDuplicateChoice(filer jira.IssueFiler, updater ViewUpdater) interactions.Handler {
+	return interactions.HandlerFunc(string(IdentifierJiraDuplicates)+".jira", func(callback *slack.InteractionCallback, logger *logrus.Entry) (output []byte, err error) {
+		values := valuesFor(callback, blockIdDuplicateAction, BlockIdTitle, blockIdDescription)
+		var choice duplicateChoice
+		if err := json.Unmarshal([]byte(values[fmt.Sprintf("%s_%s", blockIdDuplicateAction, slack.METRadioButtons)]), &choice); err != nil {
+			logger.WithError(err).Error("Failed to unmarshal the choice for a possible duplicate.")
+			return nil, err
+		}
+		title, description := values[BlockIdTitle], values[blockIdDescription]
+
+		go func() {
+			overwriteView := overwriterFor(callback, updater, logger)
+			if choice.Comment != "" {
+				logger.Infof("Commenting on Jira issue %s instead of filing a duplicate.", choice.Comment)
+				if err := filer.CommentOnIssue(choice.Comment, fmt.Sprintf("*%s*\n\n%s", title, description), callback.User.ID, logger); err != nil {
+					logger.WithError(err).Errorf("Failed to comment on Jira issue %s.", choice.Comment)
+					overwriteView(ErrorView(fmt.Sprintf("comment on Jira issue %s", choice.Comment), err))
+					return
+				}
+				overwriteView(JiraCommentView(choice.Comment))
+				return
+			}
+
+			logger.Infof("Submitting new %s to Jira despite similar issues.", choice.IssueType)
+			issue, err := filer.FileIssue(choice.IssueType, title, description, callback.User.ID, logger)
+			if err != nil {
+				logger.WithError(err).Errorf("Failed to create %s Jira.", choice.IssueType)
+				overwriteView(ErrorView(fmt.Sprintf("create %s Jira issue", choice.IssueType), err))
+				return
+			}
+			if err := filer.LinkIssues(issue.Key, choice.Related, logger); err != nil {
+				// the issue exists, so we still want to tell the user about it
+				logger.WithError(err).Warnf("Failed to link Jira issue %s to similar issues.", issue.Key)
+			}
+			overwriteView(JiraView(issue.Key))
+		}()
+
+		return pendingJiraResponse(logger)
+	})
+}
+
+// JiraCommentView is a modal View to show the user the
+// Jira issue we just commented on for them
+func JiraCommentView(key string) slack.ModalViewRequest {
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		PrivateMetadata: string(IdentifierJiraComment),
+		Title:           &slack.TextBlockObject{Type: slack.PlainTextType, Text: "Jira Comment Added"},
+		Close:           &slack.TextBlockObject{Type: slack.PlainTextType, Text: "OK"},
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			&slack.SectionBlock{
+				Type: slack.MBTSection,
+				Text: &slack.TextBlockObject{
+					Type: slack.MarkdownType,
+					Text: fmt.Sprintf("Your report was added as a comment on: <https://issues.redhat.com/browse/%s|%s>", key, key),
+				},
+			},
+		}},
+	}
+}
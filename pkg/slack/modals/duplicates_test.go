@@ -0,0 +1,166 @@
+package modals
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	jiraapi "github.com/andygrunwald/go-jira"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/openshift/ci-tools/pkg/jira"
+	"github.com/openshift/ci-tools/pkg/slack/interactions"
+)
+
+func callbackWithValues(privateMetadata string, values map[string]map[string]slack.BlockAction) *slack.InteractionCallback {
+	callback := &slack.InteractionCallback{
+		Type: slack.InteractionTypeViewSubmission,
+		User: slack.User{ID: "U01B31ARZDG"},
+	}
+	callback.View.ID = "V01C3AAJMNW"
+	callback.View.PrivateMetadata = privateMetadata
+	callback.View.State = &slack.ViewState{Values: values}
+	return callback
+}
+
+func choiceValue(t *testing.T, choice duplicateChoice) string {
+	raw, err := json.Marshal(choice)
+	if err != nil {
+		t.Fatalf("failed to marshal choice: %v", err)
+	}
+	return string(raw)
+}
+
+func handleAndWait(t *testing.T, handler interactions.Handler, callback *slack.InteractionCallback, updater *FakeViewUpdater) {
+	if _, err := handler.Handle(callback, logrus.WithField("test", t.Name())); err != nil {
+		t.Fatalf("expected no error but got one: %v", err)
+	}
+	select {
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for the view to be updated")
+	case <-updater.Called().Done():
+	}
+}
+
+func TestToJiraIssueWithSimilarIssues(t *testing.T) {
+	candidates := []jiraapi.Issue{
+		{Key: "DPTP-1", Fields: &jiraapi.IssueFields{Summary: "Image builds fail", Status: &jiraapi.Status{Name: "In Progress"}}},
+		{Key: "DPTP-2", Fields: &jiraapi.IssueFields{Summary: "Builds time out"}},
+	}
+	view, err := DuplicatesView(jira.IssueTypeBug, "Builds fail", "body: Builds fail", candidates)
+	if err != nil {
+		t.Fatalf("failed to create view: %v", err)
+	}
+	filer := jira.NewFake(map[jira.IssueRequest]jira.IssueResponse{}).WithSearches(map[jira.SearchRequest]jira.SearchResponse{
+		{Title: "Builds fail", Description: "body: Builds fail"}: {Issues: candidates},
+	})
+	updater := NewFake([]ViewUpdate{{ViewUpdateRequest: ViewUpdateRequest{View: view, ViewID: "V01C3AAJMNW"}}})
+	parameters := JiraIssueParameters{
+		Id:        "bug",
+		IssueType: jira.IssueTypeBug,
+		Template:  template.Must(template.New("bug").Parse("body: {{ .title }}")),
+		Fields:    []string{BlockIdTitle},
+	}
+	callback := callbackWithValues("bug", map[string]map[string]slack.BlockAction{
+		BlockIdTitle: {"input": {Type: "plain_text_input", Value: "Builds fail"}},
+	})
+	handleAndWait(t, ToJiraIssue(parameters, filer, updater), callback, updater)
+	filer.Validate(t)
+	updater.Validate(t)
+}
+
+func TestToJiraIssueFallsBackToFiling(t *testing.T) {
+	candidates := []jiraapi.Issue{{Key: "DPTP-1", Fields: &jiraapi.IssueFields{Summary: "Image builds fail"}}}
+	view, err := DuplicatesView(jira.IssueTypeBug, "Builds fail", "body: Builds fail", candidates)
+	if err != nil {
+		t.Fatalf("failed to create view: %v", err)
+	}
+	longTitle := strings.Repeat("Builds fail ", maxInputLength/10)
+	var testCases = []struct {
+		name    string
+		title   string
+		updates []ViewUpdate
+	}{
+		{
+			name:  "similar issues cannot be shown",
+			title: "Builds fail",
+			updates: []ViewUpdate{
+				{ViewUpdateRequest: ViewUpdateRequest{View: view, ViewID: "V01C3AAJMNW"}, ViewUpdateResponse: ViewUpdateResponse{Error: errors.New("invalid_arguments")}},
+				{ViewUpdateRequest: ViewUpdateRequest{View: JiraView("DPTP-3"), ViewID: "V01C3AAJMNW"}},
+			},
+		},
+		{
+			name:    "report is too long to carry in the view",
+			title:   longTitle,
+			updates: []ViewUpdate{{ViewUpdateRequest: ViewUpdateRequest{View: JiraView("DPTP-3"), ViewID: "V01C3AAJMNW"}}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			body := "body: " + testCase.title
+			filer := jira.NewFake(map[jira.IssueRequest]jira.IssueResponse{
+				{IssueType: jira.IssueTypeBug, Title: testCase.title, Description: body, Reporter: "U01B31ARZDG"}: {Issue: &jiraapi.Issue{Key: "DPTP-3"}},
+			}).WithSearches(map[jira.SearchRequest]jira.SearchResponse{
+				{Title: testCase.title, Description: body}: {Issues: candidates},
+			})
+			updater := NewFake(testCase.updates)
+			parameters := JiraIssueParameters{
+				Id:        "bug",
+				IssueType: jira.IssueTypeBug,
+				Template:  template.Must(template.New("bug").Parse("body: {{ .title }}")),
+				Fields:    []string{BlockIdTitle},
+			}
+			callback := callbackWithValues("bug", map[string]map[string]slack.BlockAction{
+				BlockIdTitle: {"input": {Type: "plain_text_input", Value: testCase.title}},
+			})
+			handleAndWait(t, ToJiraIssue(parameters, filer, updater), callback, updater)
+			filer.Validate(t)
+			updater.Validate(t)
+		})
+	}
+}
+
+func TestToJiraDuplicateChoice(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		choice  duplicateChoice
+		filer   *jira.Fake
+		updater *FakeViewUpdater
+	}{
+		{
+			name:   "comment on an existing issue",
+			choice: duplicateChoice{Comment: "DPTP-1"},
+			filer: jira.NewFake(map[jira.IssueRequest]jira.IssueResponse{}).WithComments(map[jira.CommentRequest]error{
+				{Key: "DPTP-1", Comment: "*Builds fail*\n\nThey fail.", Reporter: "U01B31ARZDG"}: nil,
+			}),
+			updater: NewFake([]ViewUpdate{{ViewUpdateRequest: ViewUpdateRequest{View: JiraCommentView("DPTP-1"), ViewID: "V01C3AAJMNW"}}}),
+		},
+		{
+			name:   "file a new issue linked to the candidates",
+			choice: duplicateChoice{IssueType: jira.IssueTypeBug, Related: []string{"DPTP-1", "DPTP-2"}},
+			filer: jira.NewFake(map[jira.IssueRequest]jira.IssueResponse{
+				{IssueType: jira.IssueTypeBug, Title: "Builds fail", Description: "They fail.", Reporter: "U01B31ARZDG"}: {Issue: &jiraapi.Issue{Key: "DPTP-3"}},
+			}).WithLinks(map[jira.LinkRequest]error{
+				{Key: "DPTP-3", Related: "DPTP-1"}: nil,
+				{Key: "DPTP-3", Related: "DPTP-2"}: nil,
+			}),
+			updater: NewFake([]ViewUpdate{{ViewUpdateRequest: ViewUpdateRequest{View: JiraView("DPTP-3"), ViewID: "V01C3AAJMNW"}}}),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			callback := callbackWithValues(string(IdentifierJiraDuplicates), map[string]map[string]slack.BlockAction{
+				blockIdDuplicateAction: {"choice": {Type: "radio_buttons", SelectedOption: slack.OptionBlockObject{Value: choiceValue(t, testCase.choice)}}},
+				BlockIdTitle:           {"input": {Type: "plain_text_input", Value: "Builds fail"}},
+				blockIdDescription:     {"input": {Type: "plain_text_input", Value: "They fail."}},
+			})
+			handleAndWait(t, ToJiraDuplicateChoice(testCase.filer, testCase.updater), callback, testCase.updater)
+			testCase.filer.Validate(t)
+			testCase.updater.Validate(t)
+		})
+	}
+}
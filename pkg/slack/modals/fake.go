@@ -32,13 +32,12 @@ type FakeViewUpdater struct {
 	behavior []ViewUpdate
 	unwanted []ViewUpdateRequest
 
-	// we expect to be called once but we don't know when
+	// we expect to be called but we don't know when
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
 func (f *FakeViewUpdater) UpdateView(view slack.ModalViewRequest, externalID, hash, viewID string) (*slack.ViewResponse, error) {
-	defer f.cancel()
 	request := ViewUpdateRequest{
 		View:       view,
 		ExternalID: externalID,
@@ -55,9 +54,14 @@ func (f *FakeViewUpdater) UpdateView(view slack.ModalViewRequest, externalID, ha
 	}
 	if index == -1 {
 		f.unwanted = append(f.unwanted, request)
+		f.cancel()
 		return nil, errors.New("no such issue request behavior in fake")
 	}
+	response = f.behavior[index]
 	f.behavior = append(f.behavior[:index], f.behavior[index+1:]...)
+	if len(f.behavior) == 0 {
+		f.cancel()
+	}
 	return response.Response, response.Error
 }
 
@@ -69,7 +73,8 @@ func NewFake(calls []ViewUpdate) *FakeViewUpdater {
 	return &FakeViewUpdater{behavior: calls, unwanted: []ViewUpdateRequest{}, ctx: ctx, cancel: cancel}
 }
 
-// Called allows a consumer to know we have been called
+// Called allows a consumer to know we have been called with
+// all the requests we expected, or with one we did not expect
 func (f *FakeViewUpdater) Called() context.Context {
 	return f.ctx
 }
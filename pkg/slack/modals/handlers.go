@@ -68,20 +68,15 @@ func (p *JiraIssueParameters) Process(callback *slack.InteractionCallback) (stri
 // a Jira issue behind the scenes, updating the View once the operation
 // has finished. We need this asynchronous response mechanism as the API
 // calls needed to file the issue often take longer than the 3sec TTL on
-// responding to the interaction payload we have.
+// responding to the interaction payload we have. When there are open
+// issues similar to the one we would file, we show them to the user
+// instead and let them choose what to do with the DuplicatesView.
 func ToJiraIssue(parameters JiraIssueParameters, filer jira.IssueFiler, updater ViewUpdater) interactions.Handler {
 	return interactions.HandlerFunc(string(parameters.Id)+".jira", func(callback *slack.InteractionCallback, logger *logrus.Entry) (output []byte, err error) {
 		logger.Infof("Submitting new %s to Jira.", parameters.Id)
 
 		go func() {
-			overwriteView := func(view slack.ModalViewRequest) {
-				// don't pass a hash so we overwrite the View always
-				response, err := updater.UpdateView(view, "", "", callback.View.ID)
-				if err != nil {
-					logger.WithError(err).Warn("Failed to update a modal View.")
-				}
-				logger.WithField("response", response).Trace("Got a modal response.")
-			}
+			overwriteView := overwriterFor(callback, updater, logger)
 			title, body, err := parameters.Process(callback)
 			if err != nil {
 				logger.WithError(err).Warnf("Failed to render %s template.", parameters.Id)
@@ -89,6 +84,23 @@ func ToJiraIssue(parameters JiraIssueParameters, filer jira.IssueFiler, updater
 				return
 			}
 
+			candidates, err := filer.FindSimilarIssues(title, body, logger)
+			if err != nil {
+				// searching is best-effort, we can always file a new issue
+				logger.WithError(err).Warnf("Failed to search for issues similar to the %s.", parameters.Id)
+			}
+			if len(candidates) > 0 {
+				logger.Infof("Found %d issues similar to the %s.", len(candidates), parameters.Id)
+				// if we cannot show the similar issues, we file the issue
+				// anyway so that the report the user submitted is not lost
+				view, err := DuplicatesView(parameters.IssueType, title, body, candidates)
+				if err != nil {
+					logger.WithError(err).Warn("Failed to create a View for similar issues.")
+				} else if err := overwriteView(view); err == nil {
+					return
+				}
+			}
+
 			issue, err := filer.FileIssue(parameters.IssueType, title, body, callback.User.ID, logger)
 			if err != nil {
 				logger.WithError(err).Errorf("Failed to create %s Jira.", parameters.Id)
@@ -98,17 +110,35 @@ func ToJiraIssue(parameters JiraIssueParameters, filer jira.IssueFiler, updater
 			overwriteView(JiraView(issue.Key))
 		}()
 
-		// respond to the HTTP payload from Slack with a submission response
-		response, err := json.Marshal(&slack.ViewSubmissionResponse{
-			ResponseAction: slack.RAUpdate,
-			View:           PendingJiraView(),
-		})
+		return pendingJiraResponse(logger)
+	})
+}
+
+// overwriterFor returns a function that replaces the View of the callback
+func overwriterFor(callback *slack.InteractionCallback, updater ViewUpdater, logger *logrus.Entry) func(view slack.ModalViewRequest) error {
+	return func(view slack.ModalViewRequest) error {
+		// don't pass a hash so we overwrite the View always
+		response, err := updater.UpdateView(view, "", "", callback.View.ID)
 		if err != nil {
-			logger.WithError(err).Error("Failed to marshal View update submission response.")
-			return nil, err
+			logger.WithError(err).Warn("Failed to update a modal View.")
 		}
-		return response, nil
+		logger.WithField("response", response).Trace("Got a modal response.")
+		return err
+	}
+}
+
+// pendingJiraResponse responds to the HTTP payload from Slack with a submission
+// response that shows the user we are working on their request
+func pendingJiraResponse(logger *logrus.Entry) ([]byte, error) {
+	response, err := json.Marshal(&slack.ViewSubmissionResponse{
+		ResponseAction: slack.RAUpdate,
+		View:           PendingJiraView(),
 	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal View update submission response.")
+		return nil, err
+	}
+	return response, nil
 }
 
 const (
@@ -200,6 +230,8 @@ func valuesFor(callback *slack.InteractionCallback, blockIds ...string) map[stri
 				values[fmt.Sprintf("%s_%s", id, slack.OptTypeUser)] = action.SelectedUser
 			case slack.OptTypeStatic:
 				values[fmt.Sprintf("%s_%s", id, slack.OptTypeStatic)] = action.SelectedOption.Value
+			case string(slack.METRadioButtons):
+				values[fmt.Sprintf("%s_%s", id, slack.METRadioButtons)] = action.SelectedOption.Value
 			}
 		}
 
@@ -221,3 +253,32 @@ func BulletListFunc() template.FuncMap {
 		},
 	}
 }
+
+// InfoGetter is a subset of the Slack client
+type InfoGetter interface {
+	GetUserInfo(user string) (*slack.User, error)
+	GetConversationInfo(channelID string, includeLocale bool) (*slack.Channel, error)
+}
+
+// SlackEntityFormatFuncs exposes functions to turn Slack user
+// and channel identifiers into links for Jira issues
+func SlackEntityFormatFuncs(client InfoGetter) template.FuncMap {
+	return template.FuncMap{
+		"slackUserLink": func(input string) string {
+			user, err := client.GetUserInfo(input)
+			if err != nil {
+				logrus.WithError(err).Warn("Could not look up user-provided Slack user ID for pretty printing.")
+				return fmt.Sprintf("[user profile|https://coreos.slack.com/team/%s]", input)
+			}
+			return fmt.Sprintf("[%s|https://coreos.slack.com/team/%s]", user.RealName, user.ID)
+		},
+		"slackChannelLink": func(input string) string {
+			channel, err := client.GetConversationInfo(input, false)
+			if err != nil {
+				logrus.WithError(err).Warn("Could not look up user-provided Slack channel ID for pretty printing.")
+				return fmt.Sprintf("[channel|https://coreos.slack.com/archives/%s]", input)
+			}
+			return fmt.Sprintf("[#%s|https://coreos.slack.com/archives/%s]", channel.Name, channel.ID)
+		},
+	}
+}
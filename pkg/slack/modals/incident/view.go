@@ -1,10 +1,8 @@
 package incident
 
 import (
-	"fmt"
 	"text/template"
 
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 
 	"github.com/openshift/ci-tools/pkg/jira"
@@ -116,15 +114,15 @@ func triageButtonHandler(updater modals.ViewUpdater) interactions.Handler {
 }
 
 type slackClient interface {
-	infoGetter
+	modals.InfoGetter
 	modals.ViewUpdater
 }
 
-func issueParameters(client infoGetter) modals.JiraIssueParameters {
+func issueParameters(client modals.InfoGetter) modals.JiraIssueParameters {
 	return modals.JiraIssueParameters{
 		Id:        Identifier,
 		IssueType: jira.IssueTypeIncident,
-		Template: template.Must(template.New(string(Identifier)).Funcs(slackEntityFormatFuncs(client)).Parse(`h3. Summary
+		Template: template.Must(template.New(string(Identifier)).Funcs(modals.SlackEntityFormatFuncs(client)).Parse(`h3. Summary
 {{ .` + blockIdSummary + ` }}
 
 ||Name||Link||
@@ -144,32 +142,6 @@ h3. Additional Details
 	}
 }
 
-type infoGetter interface {
-	GetUserInfo(user string) (*slack.User, error)
-	GetConversationInfo(channelID string, includeLocale bool) (*slack.Channel, error)
-}
-
-func slackEntityFormatFuncs(client infoGetter) template.FuncMap {
-	return template.FuncMap{
-		"slackUserLink": func(input string) string {
-			user, err := client.GetUserInfo(input)
-			if err != nil {
-				logrus.WithError(err).Warn("Could not look up user-provided Slack user ID for pretty printing.")
-				return fmt.Sprintf("[user profile|https://coreos.slack.com/team/%s]", input)
-			}
-			return fmt.Sprintf("[%s|https://coreos.slack.com/team/%s]", user.RealName, user.ID)
-		},
-		"slackChannelLink": func(input string) string {
-			channel, err := client.GetConversationInfo(input, false)
-			if err != nil {
-				logrus.WithError(err).Warn("Could not look up user-provided Slack channel ID for pretty printing.")
-				return fmt.Sprintf("[channel|https://coreos.slack.com/archives/%s]", input)
-			}
-			return fmt.Sprintf("[#%s|https://coreos.slack.com/archives/%s]", channel.Name, channel.ID)
-		},
-	}
-}
-
 // processSubmissionHandler files a Jira issue for this form
 func processSubmissionHandler(filer jira.IssueFiler, client slackClient) interactions.Handler {
 	return modals.ToJiraIssue(issueParameters(client), filer, client)
@@ -0,0 +1,72 @@
+package modals
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// maxButtonValueLength is the longest value Slack accepts for a button
+const maxButtonValueLength = 2000
+
+// prefilledButton is the value of a message button that opens a
+// modal View with some of the inputs already filled in
+type prefilledButton struct {
+	Identifier Identifier        `json:"id"`
+	Values     map[string]string `json:"values"`
+}
+
+// PrefilledButtonValue encodes the value for a message button that opens
+// the modal View with the plain-text inputs in the given blocks filled in
+func PrefilledButtonValue(id Identifier, values map[string]string) (string, error) {
+	raw, err := json.Marshal(prefilledButton{Identifier: id, Values: values})
+	if err != nil {
+		return "", fmt.Errorf("could not marshal button value: %w", err)
+	}
+	if len(raw) > maxButtonValueLength {
+		return "", fmt.Errorf("button value is %d characters long, more than the limit of %d", len(raw), maxButtonValueLength)
+	}
+	return string(raw), nil
+}
+
+// ParseButtonValue determines the modal View a message button opens and
+// the values to fill in, if the button was created with PrefilledButtonValue
+func ParseButtonValue(value string) (Identifier, map[string]string) {
+	var button prefilledButton
+	if err := json.Unmarshal([]byte(value), &button); err != nil || button.Identifier == "" {
+		return Identifier(value), nil
+	}
+	return button.Identifier, button.Values
+}
+
+// WithInitialValues returns a copy of the View with the plain-text
+// inputs in the given blocks filled in with initial values
+func WithInitialValues(view slack.ModalViewRequest, values map[string]string) slack.ModalViewRequest {
+	if len(values) == 0 {
+		return view
+	}
+	blocks := make([]slack.Block, len(view.Blocks.BlockSet))
+	for i, block := range view.Blocks.BlockSet {
+		blocks[i] = block
+		input, ok := block.(*slack.InputBlock)
+		if !ok {
+			continue
+		}
+		element, ok := input.Element.(*slack.PlainTextInputBlockElement)
+		if !ok {
+			continue
+		}
+		value, ok := values[input.BlockID]
+		if !ok {
+			continue
+		}
+		prefilledElement := *element
+		prefilledElement.InitialValue = value
+		prefilledInput := *input
+		prefilledInput.Element = &prefilledElement
+		blocks[i] = &prefilledInput
+	}
+	view.Blocks = slack.Blocks{BlockSet: blocks}
+	return view
+}
@@ -0,0 +1,77 @@
+package modals
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/slack-go/slack"
+)
+
+func TestParseButtonValue(t *testing.T) {
+	prefilled, err := PrefilledButtonValue("bug", map[string]string{BlockIdTitle: "title"})
+	if err != nil {
+		t.Fatalf("failed to create button value: %v", err)
+	}
+	var testCases = []struct {
+		name           string
+		value          string
+		expectedId     Identifier
+		expectedValues map[string]string
+	}{
+		{
+			name:       "plain identifier",
+			value:      "bug",
+			expectedId: "bug",
+		},
+		{
+			name:           "pre-filled values",
+			value:          prefilled,
+			expectedId:     "bug",
+			expectedValues: map[string]string{BlockIdTitle: "title"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			id, values := ParseButtonValue(testCase.value)
+			if diff := cmp.Diff(testCase.expectedId, id); diff != "" {
+				t.Errorf("got incorrect identifier: %v", diff)
+			}
+			if diff := cmp.Diff(testCase.expectedValues, values); diff != "" {
+				t.Errorf("got incorrect values: %v", diff)
+			}
+		})
+	}
+}
+
+func TestPrefilledButtonValueTooLong(t *testing.T) {
+	if _, err := PrefilledButtonValue("bug", map[string]string{BlockIdTitle: strings.Repeat("a", maxButtonValueLength)}); err == nil {
+		t.Error("expected an error for a value over the limit, got none")
+	}
+}
+
+func TestWithInitialValues(t *testing.T) {
+	view := slack.ModalViewRequest{
+		Type: slack.VTModal,
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			&slack.SectionBlock{Type: slack.MBTSection, BlockID: BlockIdTitle},
+			&slack.InputBlock{Type: slack.MBTInput, BlockID: BlockIdTitle, Element: &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput}},
+			&slack.InputBlock{Type: slack.MBTInput, BlockID: "other", Element: &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput}},
+		}},
+	}
+	expected := slack.ModalViewRequest{
+		Type: slack.VTModal,
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			&slack.SectionBlock{Type: slack.MBTSection, BlockID: BlockIdTitle},
+			&slack.InputBlock{Type: slack.MBTInput, BlockID: BlockIdTitle, Element: &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput, InitialValue: "title"}},
+			&slack.InputBlock{Type: slack.MBTInput, BlockID: "other", Element: &slack.PlainTextInputBlockElement{Type: slack.METPlainTextInput}},
+		}},
+	}
+	actual := WithInitialValues(view, map[string]string{BlockIdTitle: "title"})
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("got incorrect view: %v", diff)
+	}
+	if value := view.Blocks.BlockSet[1].(*slack.InputBlock).Element.(*slack.PlainTextInputBlockElement).InitialValue; value != "" {
+		t.Errorf("expected the original view to be unchanged, got initial value %q", value)
+	}
+}
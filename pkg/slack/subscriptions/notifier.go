@@ -0,0 +1,363 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/GoogleCloudPlatform/testgrid/metadata"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+	"github.com/openshift/ci-tools/pkg/slack/artifacts"
+)
+
+// configIndexName is the index of ci-operator configs by org/repo
+// whose changes the Notifier subscribes to
+const configIndexName = "slack-bot-subscriptions-org-repo"
+
+type messagePoster interface {
+	PostMessage(channelID string, options ...slack.MsgOption) (string, string, error)
+}
+
+// artifactReader knows how to find and read the artifacts periodic jobs upload
+type artifactReader interface {
+	artifacts.Getter
+	artifacts.Lister
+}
+
+// periodicState tracks the results of a periodic job a channel subscribed to
+type periodicState struct {
+	// lastBuild is the ID of the last finished build we saw
+	lastBuild uint64
+	// failures is the number of consecutive failures up to that build
+	failures int
+}
+
+// Notifier posts messages to channels when the events they subscribed to occur
+type Notifier struct {
+	store     Store
+	client    messagePoster
+	artifacts artifactReader
+	// bucket is where periodic jobs upload their results
+	bucket string
+	// configs and registry are the sources of change events, and may be nil
+	configs  agents.ConfigAgent
+	registry agents.RegistryAgent
+
+	periodics map[string]*periodicState
+	// registryGeneration and components are the state of the
+	// registry we compare the next generation against
+	registryGeneration int
+	components         map[string]registryComponent
+	logger             *logrus.Entry
+}
+
+// registryComponent is an element of the registry as we compare it across generations
+type registryComponent struct {
+	nodeType registry.Type
+	value    interface{}
+}
+
+// NewNotifier creates a Notifier that reads periodic job results from the
+// bucket and watches the config and registry agents for changes, if set
+func NewNotifier(store Store, client messagePoster, gcsClient *storage.Client, bucket string, configs agents.ConfigAgent, registryAgent agents.RegistryAgent) *Notifier {
+	return &Notifier{
+		store:     store,
+		client:    client,
+		artifacts: artifacts.NewGCS(gcsClient),
+		bucket:    bucket,
+		configs:   configs,
+		registry:  registryAgent,
+		periodics: map[string]*periodicState{},
+		logger:    logrus.WithField("component", "subscriptions"),
+	}
+}
+
+// Run checks for events on the interval and as configs change, until the context is cancelled
+func (n *Notifier) Run(ctx context.Context, interval time.Duration) error {
+	var configChanges <-chan agents.IndexDelta
+	if n.configs != nil {
+		if err := n.configs.AddIndex(configIndexName, func(config api.ReleaseBuildConfiguration) []string {
+			return []string{fmt.Sprintf("%s/%s", config.Metadata.Org, config.Metadata.Repo)}
+		}); err != nil {
+			return fmt.Errorf("could not index configs: %w", err)
+		}
+		changes, err := n.configs.SubscribeToIndexChanges(configIndexName)
+		if err != nil {
+			return fmt.Errorf("could not subscribe to config changes: %w", err)
+		}
+		configChanges = changes
+	}
+	n.checkRegistry(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delta := <-configChanges:
+			n.notifyConfigChange(ctx, delta)
+		case <-ticker.C:
+			n.checkRegistry(ctx)
+			n.checkPeriodics(ctx)
+		}
+	}
+}
+
+// subscriptionsFor lists the subscriptions to events of the kind by their target
+func (n *Notifier) subscriptionsFor(ctx context.Context, kind Kind) map[string][]Subscription {
+	subscriptions, err := n.store.List(ctx)
+	if err != nil {
+		n.logger.WithError(err).Warn("Failed to list subscriptions.")
+		return nil
+	}
+	byTarget := map[string][]Subscription{}
+	for _, subscription := range subscriptions {
+		if subscription.Kind == kind {
+			byTarget[subscription.Target] = append(byTarget[subscription.Target], subscription)
+		}
+	}
+	return byTarget
+}
+
+func (n *Notifier) post(subscription Subscription, message string) {
+	logger := n.logger.WithFields(logrus.Fields{"channel": subscription.Channel, "kind": subscription.Kind, "target": subscription.Target})
+	if _, _, err := n.client.PostMessage(subscription.Channel, slack.MsgOptionText(message, false)); err != nil {
+		logger.WithError(err).Warn("Failed to post notification.")
+		return
+	}
+	logger.Info("Posted notification.")
+}
+
+// notifyConfigChange notifies channels subscribed to the org/repo that its configs changed
+func (n *Notifier) notifyConfigChange(ctx context.Context, delta agents.IndexDelta) {
+	subscriptions := n.subscriptionsFor(ctx, KindConfig)[delta.IndexKey]
+	if len(subscriptions) == 0 {
+		return
+	}
+	added, removed := sets.NewString(), sets.NewString()
+	for _, config := range delta.Added {
+		added.Insert(config.Metadata.Basename())
+	}
+	for _, config := range delta.Removed {
+		removed.Insert(config.Metadata.Basename())
+	}
+	var changes []string
+	for _, change := range []struct {
+		description string
+		files       sets.String
+	}{
+		{description: "added", files: added.Difference(removed)},
+		{description: "changed", files: added.Intersection(removed)},
+		{description: "removed", files: removed.Difference(added)},
+	} {
+		if change.files.Len() > 0 {
+			changes = append(changes, fmt.Sprintf("%s `%s`", change.description, strings.Join(change.files.List(), "`, `")))
+		}
+	}
+	message := fmt.Sprintf("The ci-operator configuration for `%s` changed: %s.", delta.IndexKey, strings.Join(changes, "; "))
+	for _, subscription := range subscriptions {
+		n.post(subscription, message)
+	}
+}
+
+// checkRegistry notifies channels subscribed to registry components that changed since the last generation
+func (n *Notifier) checkRegistry(ctx context.Context) {
+	if n.registry == nil {
+		return
+	}
+	generation := n.registry.GetGeneration()
+	if n.components != nil && generation == n.registryGeneration {
+		return
+	}
+	refs, chains, workflows, _, _ := n.registry.GetRegistryComponents()
+	components := componentsOf(refs, chains, workflows)
+	previous := n.components
+	n.components, n.registryGeneration = components, generation
+	if previous == nil {
+		return
+	}
+
+	changes := registryChanges(previous, components)
+	for name, subscriptions := range n.subscriptionsFor(ctx, KindRegistry) {
+		message, changed := changes[name]
+		if !changed {
+			continue
+		}
+		for _, subscription := range subscriptions {
+			n.post(subscription, message)
+		}
+	}
+}
+
+func componentsOf(refs registry.ReferenceByName, chains registry.ChainByName, workflows registry.WorkflowByName) map[string]registryComponent {
+	components := map[string]registryComponent{}
+	for name, ref := range refs {
+		components[name] = registryComponent{nodeType: registry.Reference, value: ref}
+	}
+	for name, chain := range chains {
+		components[name] = registryComponent{nodeType: registry.Chain, value: chain}
+	}
+	for name, workflow := range workflows {
+		components[name] = registryComponent{nodeType: registry.Workflow, value: workflow}
+	}
+	return components
+}
+
+// registryChanges describes the components that were added, removed or changed between generations
+func registryChanges(previous, current map[string]registryComponent) map[string]string {
+	changes := map[string]string{}
+	for name, old := range previous {
+		if component, exists := current[name]; !exists {
+			changes[name] = fmt.Sprintf("The %s `%s` was removed from the step registry.", old.nodeType, name)
+		} else if !reflect.DeepEqual(old, component) {
+			changes[name] = fmt.Sprintf("The %s `%s` in the step registry changed.", component.nodeType, name)
+		}
+	}
+	for name, component := range current {
+		if _, existed := previous[name]; !existed {
+			changes[name] = fmt.Sprintf("The %s `%s` was added to the step registry.", component.nodeType, name)
+		}
+	}
+	return changes
+}
+
+// checkPeriodics notifies channels subscribed to periodic jobs that failed as many times
+// in a row as they asked for. Every build that finished since the last check is counted,
+// so builds that start and finish between checks are not missed.
+func (n *Notifier) checkPeriodics(ctx context.Context) {
+	subscriptionsByJob := n.subscriptionsFor(ctx, KindPeriodic)
+	for job := range n.periodics {
+		if _, subscribed := subscriptionsByJob[job]; !subscribed {
+			delete(n.periodics, job)
+		}
+	}
+	for job, subscriptions := range subscriptionsByJob {
+		logger := n.logger.WithField("job", job)
+		builds, err := n.builds(ctx, job)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to list the builds.")
+			continue
+		}
+		state, tracked := n.periodics[job]
+		if !tracked {
+			state, err = n.initialState(ctx, job, builds, subscriptions)
+			if err != nil {
+				logger.WithError(err).Warn("Failed to determine the recent results.")
+				continue
+			}
+			n.periodics[job] = state
+			continue
+		}
+		for _, build := range builds {
+			if build <= state.lastBuild {
+				continue
+			}
+			passed, finished, err := n.result(ctx, job, build)
+			if err != nil {
+				logger.WithError(err).Warnf("Failed to determine the result of build %d.", build)
+				break
+			}
+			if !finished {
+				// builds that are still running are counted once they finish,
+				// unless a later build finishes before them
+				continue
+			}
+			state.lastBuild = build
+			if passed {
+				state.failures = 0
+				continue
+			}
+			state.failures++
+			url := fmt.Sprintf("%s/view/gs/%s/logs/%s/%d", api.URLForService(api.ServiceProw), n.bucket, job, build)
+			message := fmt.Sprintf("The periodic job `%s` failed %d times in a row, most recently in <%s|#%d>.", job, state.failures, url, build)
+			for _, subscription := range subscriptions {
+				if state.failures == subscription.threshold() {
+					n.post(subscription, message)
+				}
+			}
+		}
+	}
+}
+
+// initialState counts the failures in a row up to the latest finished build of a job we
+// were not tracking, like after a restart. We cannot know whether channels were notified
+// of these failures, so we do not notify them now and only count up to the highest
+// threshold, as longer streaks do not notify anyone.
+func (n *Notifier) initialState(ctx context.Context, job string, builds []uint64, subscriptions []Subscription) (*periodicState, error) {
+	var maxThreshold int
+	for _, subscription := range subscriptions {
+		if threshold := subscription.threshold(); threshold > maxThreshold {
+			maxThreshold = threshold
+		}
+	}
+	state := &periodicState{}
+	for i := len(builds) - 1; i >= 0 && state.failures < maxThreshold; i-- {
+		passed, finished, err := n.result(ctx, job, builds[i])
+		if err != nil {
+			return nil, err
+		}
+		if !finished {
+			continue
+		}
+		if state.lastBuild == 0 {
+			state.lastBuild = builds[i]
+		}
+		if passed {
+			break
+		}
+		state.failures++
+	}
+	return state, nil
+}
+
+// builds lists the IDs of the builds of the periodic job, oldest first
+func (n *Notifier) builds(ctx context.Context, job string) ([]uint64, error) {
+	directories, err := n.artifacts.ListDirectories(ctx, n.bucket, path.Join("logs", job))
+	if err != nil {
+		return nil, err
+	}
+	var builds []uint64
+	for _, directory := range directories {
+		// there may be other directories, but builds are numbered
+		if build, err := strconv.ParseUint(directory, 10, 64); err == nil {
+			builds = append(builds, build)
+		}
+	}
+	sort.Slice(builds, func(i, j int) bool { return builds[i] < builds[j] })
+	return builds, nil
+}
+
+// result determines whether the build of the periodic job finished and whether it passed
+func (n *Notifier) result(ctx context.Context, job string, build uint64) (passed, finished bool, err error) {
+	raw, err := n.artifacts.Get(ctx, n.bucket, path.Join("logs", job, strconv.FormatUint(build, 10), "finished.json"))
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	var result metadata.Finished
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return false, false, fmt.Errorf("could not unmarshal finished.json for build %d: %w", build, err)
+	}
+	if result.Passed == nil {
+		return false, false, nil
+	}
+	return *result.Passed, true, nil
+}
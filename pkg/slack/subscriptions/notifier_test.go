@@ -0,0 +1,230 @@
+package subscriptions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+type fakeStore []Subscription
+
+func (f fakeStore) List(context.Context) ([]Subscription, error)       { return f, nil }
+func (f fakeStore) Add(context.Context, Subscription) error            { return nil }
+func (f fakeStore) Remove(context.Context, Subscription) (bool, error) { return false, nil }
+
+type fakeArtifactReader map[string]string
+
+func (f fakeArtifactReader) Get(_ context.Context, bucket, object string) ([]byte, error) {
+	content, ok := f[bucket+"/"+object]
+	if !ok {
+		return nil, storage.ErrObjectNotExist
+	}
+	return []byte(content), nil
+}
+
+func (f fakeArtifactReader) Tail(ctx context.Context, bucket, object string, size int64) ([]byte, error) {
+	content, err := f.Get(ctx, bucket, object)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > size {
+		content = content[int64(len(content))-size:]
+	}
+	return content, nil
+}
+
+func (f fakeArtifactReader) ListDirectories(_ context.Context, bucket, prefix string) ([]string, error) {
+	prefix = bucket + "/" + prefix + "/"
+	directories := sets.NewString()
+	for key := range f {
+		if strings.HasPrefix(key, prefix) && strings.Contains(strings.TrimPrefix(key, prefix), "/") {
+			directories.Insert(strings.SplitN(strings.TrimPrefix(key, prefix), "/", 2)[0])
+		}
+	}
+	return directories.List(), nil
+}
+
+// fakePoster records the messages posted, prefixed with their channel
+type fakePoster struct {
+	messages []string
+}
+
+func (f *fakePoster) PostMessage(channelID string, options ...slack.MsgOption) (string, string, error) {
+	_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+	if err != nil {
+		return "", "", err
+	}
+	f.messages = append(f.messages, channelID+": "+values.Get("text"))
+	return channelID, "", nil
+}
+
+func TestCheckPeriodics(t *testing.T) {
+	store := fakeStore{
+		{Channel: "C1", Kind: KindPeriodic, Target: "periodic-job", Threshold: 2},
+		{Channel: "C2", Kind: KindPeriodic, Target: "periodic-job"},
+		{Channel: "C3", Kind: KindConfig, Target: "org/repo"},
+	}
+	poster := &fakePoster{}
+	artifacts := fakeArtifactReader{}
+	notifier := &Notifier{
+		store:     store,
+		client:    poster,
+		artifacts: artifacts,
+		bucket:    "bucket",
+		periodics: map[string]*periodicState{},
+		logger:    logrus.WithField("test", "TestCheckPeriodics"),
+	}
+	ctx := context.Background()
+
+	var testCases = []struct {
+		name string
+		// builds maps the builds that started since the last check to
+		// their finished.json, which is empty while they are running
+		builds           map[string]string
+		expectedFailures int
+		expectedMessages []string
+	}{
+		{
+			name:             "failures before we started tracking the job are counted but not notified",
+			builds:           map[string]string{"1": `{"passed": true}`, "2": `{"passed": false}`},
+			expectedFailures: 1,
+		},
+		{
+			name:             "the same build does not count again",
+			expectedFailures: 1,
+		},
+		{
+			name:             "a running build does not count",
+			builds:           map[string]string{"3": ""},
+			expectedFailures: 1,
+		},
+		{
+			name:             "every build that failed since the last check counts",
+			builds:           map[string]string{"3": `{"passed": false}`, "4": `{"passed": false}`},
+			expectedFailures: 3,
+			expectedMessages: []string{
+				"C1: The periodic job `periodic-job` failed 2 times in a row, most recently in <https://prow.ci.openshift.org/view/gs/bucket/logs/periodic-job/3|#3>.",
+				"C2: The periodic job `periodic-job` failed 3 times in a row, most recently in <https://prow.ci.openshift.org/view/gs/bucket/logs/periodic-job/4|#4>.",
+			},
+		},
+		{
+			name:             "fourth failure notifies no one",
+			builds:           map[string]string{"5": `{"passed": false}`},
+			expectedFailures: 4,
+		},
+		{
+			name:             "passing resets the count",
+			builds:           map[string]string{"6": `{"passed": true}`, "7": ""},
+			expectedFailures: 0,
+		},
+		{
+			name:             "a build that is still running is skipped once a later build finishes",
+			builds:           map[string]string{"8": `{"passed": false}`},
+			expectedFailures: 1,
+		},
+	}
+	for _, testCase := range testCases {
+		poster.messages = nil
+		for build, finished := range testCase.builds {
+			artifacts["bucket/logs/periodic-job/"+build+"/started.json"] = "{}"
+			if finished != "" {
+				artifacts["bucket/logs/periodic-job/"+build+"/finished.json"] = finished
+			}
+		}
+		notifier.checkPeriodics(ctx)
+		if diff := cmp.Diff(testCase.expectedFailures, notifier.periodics["periodic-job"].failures); diff != "" {
+			t.Errorf("%s: got incorrect failure count: %v", testCase.name, diff)
+		}
+		if diff := cmp.Diff(testCase.expectedMessages, poster.messages); diff != "" {
+			t.Errorf("%s: got incorrect notifications: %v", testCase.name, diff)
+		}
+	}
+}
+
+func TestCheckPeriodicsAfterRestart(t *testing.T) {
+	artifacts := fakeArtifactReader{"bucket/logs/periodic-job/1/finished.json": `{"passed": true}`}
+	for _, build := range []string{"2", "10", "11", "12"} {
+		artifacts["bucket/logs/periodic-job/"+build+"/finished.json"] = `{"passed": false}`
+	}
+	artifacts["bucket/logs/periodic-job/13/started.json"] = "{}"
+	poster := &fakePoster{}
+	notifier := &Notifier{
+		store:     fakeStore{{Channel: "C1", Kind: KindPeriodic, Target: "periodic-job", Threshold: 2}},
+		client:    poster,
+		artifacts: artifacts,
+		bucket:    "bucket",
+		periodics: map[string]*periodicState{},
+		logger:    logrus.WithField("test", "TestCheckPeriodicsAfterRestart"),
+	}
+	notifier.checkPeriodics(context.Background())
+	// builds are ordered numerically and the streak is only counted up to the highest threshold
+	if diff := cmp.Diff(&periodicState{lastBuild: 12, failures: 2}, notifier.periodics["periodic-job"], cmp.AllowUnexported(periodicState{})); diff != "" {
+		t.Errorf("got incorrect state: %v", diff)
+	}
+	if len(poster.messages) != 0 {
+		t.Errorf("expected no notifications after a restart, got %v", poster.messages)
+	}
+}
+
+func TestRegistryChanges(t *testing.T) {
+	previous := componentsOf(
+		registry.ReferenceByName{"changed": {As: "changed", Commands: "old"}, "removed": {As: "removed"}, "same": {As: "same"}},
+		registry.ChainByName{"chain": {As: "chain"}},
+		registry.WorkflowByName{},
+	)
+	current := componentsOf(
+		registry.ReferenceByName{"changed": {As: "changed", Commands: "new"}, "same": {As: "same"}},
+		registry.ChainByName{"chain": {As: "chain"}},
+		registry.WorkflowByName{"added": {}},
+	)
+	expected := map[string]string{
+		"added":   "The workflow `added` was added to the step registry.",
+		"changed": "The reference `changed` in the step registry changed.",
+		"removed": "The reference `removed` was removed from the step registry.",
+	}
+	if diff := cmp.Diff(expected, registryChanges(previous, current)); diff != "" {
+		t.Errorf("got incorrect changes: %v", diff)
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	poster := &fakePoster{}
+	notifier := &Notifier{
+		store:    fakeStore{{Channel: "C1", Kind: KindRegistry, Target: "changed"}, {Channel: "C2", Kind: KindRegistry, Target: "same"}},
+		client:   poster,
+		registry: agents.NewFakeRegistryAgent(registry.ReferenceByName{"changed": {As: "changed", Commands: "new"}, "same": {As: "same"}}, nil, nil, nil, api.RegistryMetadata{}),
+		logger:   logrus.WithField("test", "TestCheckRegistry"),
+	}
+	// the generation of the fake agent never changes, so pretend it was loaded before
+	notifier.components = componentsOf(registry.ReferenceByName{"changed": {As: "changed", Commands: "old"}, "same": {As: "same"}}, nil, nil)
+	notifier.registryGeneration = -1
+	notifier.checkRegistry(context.Background())
+	if diff := cmp.Diff([]string{"C1: The reference `changed` in the step registry changed."}, poster.messages); diff != "" {
+		t.Errorf("got incorrect notifications: %v", diff)
+	}
+}
+
+func TestNotifyConfigChange(t *testing.T) {
+	poster := &fakePoster{}
+	notifier := &Notifier{
+		store:  fakeStore{{Channel: "C1", Kind: KindConfig, Target: "org/repo"}, {Channel: "C2", Kind: KindConfig, Target: "org/other"}},
+		client: poster,
+		logger: logrus.WithField("test", "TestNotifyConfigChange"),
+	}
+	config := &api.ReleaseBuildConfiguration{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}}
+	notifier.notifyConfigChange(context.Background(), agents.IndexDelta{IndexKey: "org/repo", Added: []*api.ReleaseBuildConfiguration{config}, Removed: []*api.ReleaseBuildConfiguration{config}})
+	if diff := cmp.Diff([]string{"C1: The ci-operator configuration for `org/repo` changed: changed `org-repo-master.yaml`."}, poster.messages); diff != "" {
+		t.Errorf("got incorrect notifications: %v", diff)
+	}
+}
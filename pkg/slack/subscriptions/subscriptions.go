@@ -0,0 +1,202 @@
+package subscriptions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Kind is the type of event a channel subscribes to
+type Kind string
+
+const (
+	// KindPeriodic notifies when a periodic job fails a number of times in a row
+	KindPeriodic Kind = "periodic"
+	// KindConfig notifies when the ci-operator configuration for a repository changes
+	KindConfig Kind = "config"
+	// KindRegistry notifies when a step, chain or workflow in the step registry changes
+	KindRegistry Kind = "registry"
+
+	// defaultThreshold is the number of consecutive failures we notify
+	// after when a subscription to a periodic does not specify one
+	defaultThreshold = 3
+
+	// storeKey is the key in the ConfigMap that holds the subscriptions
+	storeKey = "subscriptions.yaml"
+)
+
+// Subscription records that a channel wants to be notified of events
+type Subscription struct {
+	// Channel is the Slack channel to notify
+	Channel string `json:"channel"`
+	// Kind is the type of the events
+	Kind Kind `json:"kind"`
+	// Target identifies the source of the events: the name of a periodic
+	// job, an org/repo or the name of a registry component
+	Target string `json:"target"`
+	// Threshold is the number of consecutive failures of a periodic job
+	// after which the channel is notified
+	Threshold int `json:"threshold,omitempty"`
+}
+
+// Matches determines whether both subscriptions are for the same events
+// in the same channel, regardless of the threshold
+func (s Subscription) Matches(other Subscription) bool {
+	return s.Channel == other.Channel && s.Kind == other.Kind && s.Target == other.Target
+}
+
+func (s Subscription) String() string {
+	if s.Kind == KindPeriodic {
+		return fmt.Sprintf("%s %s after %d failures", s.Kind, s.Target, s.threshold())
+	}
+	return fmt.Sprintf("%s %s", s.Kind, s.Target)
+}
+
+// Describe lists the subscriptions of the channel for display
+func Describe(subscriptions []Subscription, channel string) []string {
+	var descriptions []string
+	for _, subscription := range subscriptions {
+		if subscription.Channel == channel {
+			descriptions = append(descriptions, subscription.String())
+		}
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
+
+func (s Subscription) threshold() int {
+	if s.Threshold < 1 {
+		return defaultThreshold
+	}
+	return s.Threshold
+}
+
+// Store persists subscriptions
+type Store interface {
+	// List returns all subscriptions
+	List(ctx context.Context) ([]Subscription, error)
+	// Add records a subscription, replacing one for the same events in the same channel
+	Add(ctx context.Context, subscription Subscription) error
+	// Remove deletes the subscription for the same events in the same channel,
+	// returning whether there was one
+	Remove(ctx context.Context, subscription Subscription) (bool, error)
+}
+
+// NewConfigMapStore returns a Store that keeps subscriptions in a ConfigMap,
+// creating it when the first subscription is added
+func NewConfigMapStore(client ctrlruntimeclient.Client, namespace, name string) Store {
+	return &configMapStore{client: client, namespace: namespace, name: name}
+}
+
+type configMapStore struct {
+	client          ctrlruntimeclient.Client
+	namespace, name string
+}
+
+func (s *configMapStore) get(ctx context.Context) (*coreapi.ConfigMap, []Subscription, error) {
+	configMap := &coreapi.ConfigMap{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.namespace, Name: s.name}, configMap); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("could not get ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	var subscriptions []Subscription
+	if err := yaml.Unmarshal([]byte(configMap.Data[storeKey]), &subscriptions); err != nil {
+		return nil, nil, fmt.Errorf("could not unmarshal subscriptions from ConfigMap %s/%s: %w", s.namespace, s.name, err)
+	}
+	return configMap, subscriptions, nil
+}
+
+// update applies the mutation to the stored subscriptions, retrying on conflicts
+func (s *configMapStore) update(ctx context.Context, mutate func([]Subscription) []Subscription) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, subscriptions, err := s.get(ctx)
+		if err != nil {
+			return err
+		}
+		subscriptions = mutate(subscriptions)
+		sort.Slice(subscriptions, func(i, j int) bool {
+			if subscriptions[i].Channel != subscriptions[j].Channel {
+				return subscriptions[i].Channel < subscriptions[j].Channel
+			}
+			if subscriptions[i].Kind != subscriptions[j].Kind {
+				return subscriptions[i].Kind < subscriptions[j].Kind
+			}
+			return subscriptions[i].Target < subscriptions[j].Target
+		})
+		raw, err := yaml.Marshal(subscriptions)
+		if err != nil {
+			return fmt.Errorf("could not marshal subscriptions: %w", err)
+		}
+		if configMap == nil {
+			if len(subscriptions) == 0 {
+				return nil
+			}
+			configMap = &coreapi.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name},
+				Data:       map[string]string{storeKey: string(raw)},
+			}
+			if err := s.client.Create(ctx, configMap); err != nil {
+				if kerrors.IsAlreadyExists(err) {
+					// someone else created it in the meantime, so try again
+					return kerrors.NewConflict(coreapi.Resource("configmaps"), s.name, err)
+				}
+				return fmt.Errorf("could not create ConfigMap %s/%s: %w", s.namespace, s.name, err)
+			}
+			return nil
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[storeKey] = string(raw)
+		if err := s.client.Update(ctx, configMap); err != nil {
+			if kerrors.IsConflict(err) {
+				return err
+			}
+			return fmt.Errorf("could not update ConfigMap %s/%s: %w", s.namespace, s.name, err)
+		}
+		return nil
+	})
+}
+
+func (s *configMapStore) List(ctx context.Context) ([]Subscription, error) {
+	_, subscriptions, err := s.get(ctx)
+	return subscriptions, err
+}
+
+func (s *configMapStore) Add(ctx context.Context, subscription Subscription) error {
+	return s.update(ctx, func(subscriptions []Subscription) []Subscription {
+		for i := range subscriptions {
+			if subscriptions[i].Matches(subscription) {
+				subscriptions[i] = subscription
+				return subscriptions
+			}
+		}
+		return append(subscriptions, subscription)
+	})
+}
+
+func (s *configMapStore) Remove(ctx context.Context, subscription Subscription) (bool, error) {
+	var removed bool
+	err := s.update(ctx, func(subscriptions []Subscription) []Subscription {
+		removed = false
+		var kept []Subscription
+		for _, existing := range subscriptions {
+			if existing.Matches(subscription) {
+				removed = true
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		return kept
+	})
+	return removed, err
+}
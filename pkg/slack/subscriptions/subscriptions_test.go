@@ -0,0 +1,80 @@
+package subscriptions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapStore(t *testing.T) {
+	ctx := context.Background()
+	client := fakectrlruntimeclient.NewFakeClient()
+	store := NewConfigMapStore(client, "ci", "slack-bot-subscriptions")
+
+	if removed, err := store.Remove(ctx, Subscription{Channel: "C1", Kind: KindConfig, Target: "org/repo"}); err != nil || removed {
+		t.Fatalf("expected nothing to remove from a missing ConfigMap, got removed=%v, err=%v", removed, err)
+	}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ci", Name: "slack-bot-subscriptions"}, &coreapi.ConfigMap{}); err == nil {
+		t.Fatal("expected the ConfigMap not to be created by a removal")
+	}
+
+	for _, subscription := range []Subscription{
+		{Channel: "C2", Kind: KindRegistry, Target: "ipi-install"},
+		{Channel: "C1", Kind: KindPeriodic, Target: "periodic-job", Threshold: 2},
+		{Channel: "C1", Kind: KindConfig, Target: "org/repo"},
+		{Channel: "C1", Kind: KindPeriodic, Target: "periodic-job", Threshold: 5},
+	} {
+		if err := store.Add(ctx, subscription); err != nil {
+			t.Fatalf("failed to add subscription: %v", err)
+		}
+	}
+	removed, err := store.Remove(ctx, Subscription{Channel: "C2", Kind: KindRegistry, Target: "ipi-install"})
+	if err != nil || !removed {
+		t.Fatalf("expected the subscription to be removed, got removed=%v, err=%v", removed, err)
+	}
+
+	actual, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("failed to list subscriptions: %v", err)
+	}
+	expected := []Subscription{
+		{Channel: "C1", Kind: KindConfig, Target: "org/repo"},
+		{Channel: "C1", Kind: KindPeriodic, Target: "periodic-job", Threshold: 5},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("got incorrect subscriptions: %v", diff)
+	}
+
+	configMap := &coreapi.ConfigMap{}
+	if err := client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ci", Name: "slack-bot-subscriptions"}, configMap); err != nil {
+		t.Fatalf("failed to get ConfigMap: %v", err)
+	}
+	expectedData := `- channel: C1
+  kind: config
+  target: org/repo
+- channel: C1
+  kind: periodic
+  target: periodic-job
+  threshold: 5
+`
+	if diff := cmp.Diff(expectedData, configMap.Data[storeKey]); diff != "" {
+		t.Errorf("got incorrect ConfigMap data: %v", diff)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	subscriptions := []Subscription{
+		{Channel: "C1", Kind: KindRegistry, Target: "ipi-install"},
+		{Channel: "C1", Kind: KindPeriodic, Target: "periodic-job"},
+		{Channel: "C2", Kind: KindConfig, Target: "org/repo"},
+	}
+	expected := []string{"periodic periodic-job after 3 failures", "registry ipi-install"}
+	if diff := cmp.Diff(expected, Describe(subscriptions, "C1")); diff != "" {
+		t.Errorf("got incorrect descriptions: %v", diff)
+	}
+}
@@ -0,0 +1,258 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	coreapi "k8s.io/api/core/v1"
+	rbacapi "k8s.io/api/rbac/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps/utils"
+	"github.com/openshift/ci-tools/pkg/util"
+)
+
+const (
+	bundleValidateContainer = "bundle-validate"
+	upgradeGraphContainer   = "upgrade-graph"
+	scorecardContainer      = "scorecard"
+	indexDatabaseVolume     = "index-database"
+)
+
+var (
+	// operatorSDKImage provides the operator-sdk binary used to validate bundles and run the scorecard
+	operatorSDKImage = "quay.io/operator-framework/operator-sdk:v1.10.0"
+	// upgradeGraphImage provides the platform Python, which ships with the sqlite3
+	// module, used to query the index database
+	upgradeGraphImage = "registry.access.redhat.com/ubi8/ubi:8.4"
+	// platformPython is the interpreter in the upgradeGraphImage
+	platformPython = "/usr/libexec/platform-python"
+)
+
+// upgradeGraphQueries list the inconsistencies in the upgrade graph of an index database:
+// bundles replacing bundles that are not in the index, channels whose head is not in the
+// index and bundles that are not in any channel
+var upgradeGraphQueries = []string{
+	`SELECT 'bundle ' || name || ' replaces ' || replaces || ', which is not in the index' FROM operatorbundle WHERE replaces IS NOT NULL AND replaces != '' AND replaces NOT IN (SELECT name FROM operatorbundle)`,
+	`SELECT 'channel ' || name || ' of package ' || package_name || ' has head ' || head_operatorbundle_name || ', which is not in the index' FROM channel WHERE head_operatorbundle_name NOT IN (SELECT name FROM operatorbundle)`,
+	`SELECT 'bundle ' || name || ' is not in any channel' FROM operatorbundle WHERE name NOT IN (SELECT operatorbundle_name FROM channel_entry)`,
+}
+
+// bundleValidationStep validates an operator bundle with `operator-sdk bundle validate`,
+// checks the upgrade graph of the index generated for the bundle and optionally runs
+// the scorecard tests of the bundle. Every check is reported as a jUnit test case.
+type bundleValidationStep struct {
+	config         api.BundleValidation
+	bundle         string
+	indexGenerator api.PipelineImageStreamTagReference
+	client         PodClient
+	jobSpec        *api.JobSpec
+
+	subTests []*junit.TestCase
+}
+
+func (s *bundleValidationStep) Inputs() (api.InputDefinition, error) {
+	return nil, nil
+}
+
+func (*bundleValidationStep) Validate() error { return nil }
+
+func (s *bundleValidationStep) Run(ctx context.Context) error {
+	return results.ForReason("validating_bundle").ForError(s.run(ctx))
+}
+
+func (s *bundleValidationStep) run(ctx context.Context) error {
+	bundle, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, api.PipelineImageStream, s.bundle)()
+	if err != nil {
+		return fmt.Errorf("failed to get image digest for bundle `%s`: %w", s.bundle, err)
+	}
+	index, err := utils.ImageDigestFor(s.client, s.jobSpec.Namespace, api.PipelineImageStream, string(s.indexGenerator))()
+	if err != nil {
+		return fmt.Errorf("failed to get image digest for index `%s`: %w", s.indexGenerator, err)
+	}
+	if err := s.setupRBAC(ctx); err != nil {
+		return err
+	}
+	pod := bundleValidationPod(s.config, s.podName(), bundle, index, s.jobSpec)
+	notifier := NewTestCaseNotifier(NopNotifier)
+	if _, err := createOrRestartPod(ctx, s.client, pod); err != nil {
+		return fmt.Errorf("failed to create or restart %s pod: %w", pod.Name, err)
+	}
+	defer func() {
+		s.subTests = notifier.SubTests(s.Description() + " - ")
+	}()
+	if _, err := waitForPodCompletion(ctx, s.client, pod.Namespace, pod.Name, notifier, false); err != nil {
+		return fmt.Errorf("validation of bundle %s failed: %w", s.bundle, err)
+	}
+	return nil
+}
+
+func (s *bundleValidationStep) podName() string {
+	return fmt.Sprintf("%s-validation", s.bundle)
+}
+
+// setupRBAC creates the service account the validation runs as, which can pull
+// images from the namespace and, for the scorecard, create the test pods
+func (s *bundleValidationStep) setupRBAC(ctx context.Context) error {
+	m := meta.ObjectMeta{Namespace: s.jobSpec.Namespace(), Name: s.podName()}
+	sa := &coreapi.ServiceAccount{ObjectMeta: m}
+	role := &rbacapi.Role{
+		ObjectMeta: m,
+		Rules: []rbacapi.PolicyRule{{
+			APIGroups: []string{"", "image.openshift.io"},
+			Resources: []string{"imagestreams/layers"},
+			Verbs:     []string{"get"},
+		}},
+	}
+	subjects := []rbacapi.Subject{{Kind: "ServiceAccount", Name: s.podName()}}
+	bindings := []rbacapi.RoleBinding{{
+		ObjectMeta: m,
+		RoleRef:    rbacapi.RoleRef{Kind: "Role", Name: s.podName()},
+		Subjects:   subjects,
+	}}
+	if s.config.Scorecard {
+		bindings = append(bindings, rbacapi.RoleBinding{
+			ObjectMeta: meta.ObjectMeta{Namespace: s.jobSpec.Namespace(), Name: fmt.Sprintf("%s-edit", s.podName())},
+			RoleRef:    rbacapi.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   subjects,
+		})
+	}
+	return util.CreateRBACs(ctx, sa, role, bindings, s.client, 1*time.Second, 1*time.Minute)
+}
+
+func bundleValidationPod(config api.BundleValidation, name, bundle, index string, jobSpec *api.JobSpec) *coreapi.Pod {
+	// operator-sdk pulls the bundle itself and reads the credentials from $HOME/.docker
+	validate := fmt.Sprintf("operator-sdk bundle validate %s --image-builder none", bundle)
+	if config.SelectOptional != "" {
+		validate = fmt.Sprintf("%s --select-optional %s", validate, config.SelectOptional)
+	}
+	var queries []string
+	for _, query := range upgradeGraphQueries {
+		// Go quoting is valid Python quoting for these queries
+		queries = append(queries, fmt.Sprintf("%q", query))
+	}
+	graph := strings.Join([]string{
+		fmt.Sprintf("%s - <<'EOF'", platformPython),
+		"import sqlite3",
+		"import sys",
+		fmt.Sprintf("queries = [%s]", strings.Join(queries, ", ")),
+		`database = sqlite3.connect("/index/index.db")`,
+		"failures = [row[0] for query in queries for row in database.execute(query)]",
+		"if failures:",
+		`    print("The upgrade graph of the index is inconsistent:")`,
+		`    print("\n".join(failures))`,
+		"    sys.exit(1)",
+		"EOF",
+	}, "\n")
+	home := []coreapi.EnvVar{{Name: "HOME", Value: "/tmp"}}
+	containers := []coreapi.Container{
+		{
+			Name:                     bundleValidateContainer,
+			Image:                    operatorSDKImage,
+			Command:                  []string{"/bin/sh", "-c"},
+			Args:                     []string{strings.Join(append(registryAuthScript(bundle, "${HOME}/.docker"), validate), "\n")},
+			Env:                      home,
+			TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+		},
+		{
+			Name:                     upgradeGraphContainer,
+			Image:                    upgradeGraphImage,
+			Command:                  []string{"/bin/sh", "-c"},
+			Args:                     []string{graph},
+			VolumeMounts:             []coreapi.VolumeMount{{Name: indexDatabaseVolume, MountPath: "/index"}},
+			TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+		},
+	}
+	if config.Scorecard {
+		containers = append(containers, coreapi.Container{
+			Name:    scorecardContainer,
+			Image:   operatorSDKImage,
+			Command: []string{"/bin/sh", "-c"},
+			Args: []string{strings.Join(append(registryAuthScript(bundle, "${HOME}/.docker"),
+				fmt.Sprintf("operator-sdk scorecard %s --namespace %s --service-account %s --wait-time 300s", bundle, jobSpec.Namespace(), name),
+			), "\n")},
+			Env:                      home,
+			TerminationMessagePolicy: coreapi.TerminationMessageFallbackToLogsOnError,
+		})
+	}
+	var names []string
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	pod := &coreapi.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:        name,
+			Namespace:   jobSpec.Namespace(),
+			Labels:      labelsFor(jobSpec, nil),
+			Annotations: map[string]string{annotationContainersForSubTestResults: strings.Join(names, ",")},
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy:      coreapi.RestartPolicyNever,
+			ServiceAccountName: name,
+			InitContainers: []coreapi.Container{{
+				Name:         indexDatabaseVolume,
+				Image:        index,
+				Command:      []string{"cp", fmt.Sprintf("%s/database/index.db", IndexDataDirectory), "/index/index.db"},
+				VolumeMounts: []coreapi.VolumeMount{{Name: indexDatabaseVolume, MountPath: "/index"}},
+			}},
+			Containers: containers,
+			Volumes: []coreapi.Volume{{
+				Name:         indexDatabaseVolume,
+				VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}},
+			}},
+		},
+	}
+	if owner := jobSpec.Owner(); owner != nil {
+		pod.OwnerReferences = append(pod.OwnerReferences, *owner)
+	}
+	return pod
+}
+
+func (s *bundleValidationStep) SubTests() []*junit.TestCase {
+	return s.subTests
+}
+
+func (s *bundleValidationStep) Requires() []api.StepLink {
+	return []api.StepLink{
+		api.InternalImageLink(api.PipelineImageStreamTagReference(s.bundle)),
+		api.InternalImageLink(s.indexGenerator),
+	}
+}
+
+func (s *bundleValidationStep) Creates() []api.StepLink {
+	return nil
+}
+
+func (s *bundleValidationStep) Provides() api.ParameterMap {
+	return nil
+}
+
+func (s *bundleValidationStep) Name() string {
+	return fmt.Sprintf("[validate-bundle:%s]", s.bundle)
+}
+
+func (s *bundleValidationStep) Description() string {
+	return fmt.Sprintf("Validate the operator bundle %s and the upgrade graph of its index", s.bundle)
+}
+
+func (s *bundleValidationStep) Objects() []ctrlruntimeclient.Object {
+	return s.client.Objects()
+}
+
+// BundleValidationStep validates the bundle and the upgrade graph of the index
+// built by the index generator
+func BundleValidationStep(config api.BundleValidation, bundle string, indexGenerator api.PipelineImageStreamTagReference, client PodClient, jobSpec *api.JobSpec) api.Step {
+	return &bundleValidationStep{
+		config:         config,
+		bundle:         bundle,
+		indexGenerator: indexGenerator,
+		client:         client,
+		jobSpec:        jobSpec,
+	}
+}
@@ -0,0 +1,32 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func TestBundleValidationPod(t *testing.T) {
+	const (
+		bundle = "image-registry.openshift-image-registry.svc:5000/namespace/pipeline@sha256:bundle"
+		index  = "image-registry.openshift-image-registry.svc:5000/namespace/pipeline@sha256:index"
+	)
+	testCases := []struct {
+		name   string
+		config api.BundleValidation
+	}{
+		{
+			name: "bundle and upgrade graph validation",
+		},
+		{
+			name:   "optional validators and scorecard",
+			config: api.BundleValidation{SelectOptional: "suite=operatorframework", Scorecard: true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testhelper.CompareWithFixture(t, bundleValidationPod(tc.config, "ci-bundle0-validation", bundle, index, multiArchJobSpec()))
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package steps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+// PipelineImageCache is an image stream shared by all jobs on a cluster that
+// holds pipeline images by the hash of everything that went into building them
+type PipelineImageCache struct {
+	Namespace string
+	Name      string
+}
+
+// cachedImageStep wraps a step building a pipeline image: when an image built
+// from the same inputs exists in the cache, it is tagged into the pipeline
+// instead of running the step. Images built by the step are added to the cache.
+type cachedImageStep struct {
+	api.Step
+	to          api.PipelineImageStreamTagReference
+	fingerprint interface{}
+	from        []api.PipelineImageStreamTagReference
+	cache       PipelineImageCache
+	client      loggingclient.LoggingClient
+	jobSpec     *api.JobSpec
+}
+
+func (s *cachedImageStep) Run(ctx context.Context) error {
+	logger := logrus.WithField("image", s.to)
+	key, err := s.key(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Could not determine the pipeline image cache key, building the image.")
+		return s.Step.Run(ctx)
+	}
+	tag := cacheTag(s.to, key)
+	restored, err := s.restore(ctx, tag)
+	if err != nil {
+		logger.WithError(err).Warn("Could not restore the image from the pipeline image cache, building the image.")
+	}
+	if restored {
+		logger.Infof("Reusing %s from the pipeline image cache %s/%s:%s.", s.to, s.cache.Namespace, s.cache.Name, tag)
+		return nil
+	}
+	if err := s.Step.Run(ctx); err != nil {
+		return err
+	}
+	if err := s.store(ctx, tag); err != nil {
+		logger.WithError(err).Warn("Could not add the image to the pipeline image cache.")
+	}
+	return nil
+}
+
+// key hashes the configuration of the step, its inputs and the images it is built from
+func (s *cachedImageStep) key(ctx context.Context) (string, error) {
+	hash := sha256.New()
+	raw, err := json.Marshal(s.fingerprint)
+	if err != nil {
+		return "", fmt.Errorf("could not serialize step configuration: %w", err)
+	}
+	hash.Write(raw)
+	inputs, err := s.Step.Inputs()
+	if err != nil {
+		return "", fmt.Errorf("could not resolve step inputs: %w", err)
+	}
+	for _, input := range inputs {
+		hash.Write([]byte(input))
+	}
+	for _, from := range s.from {
+		digest, err := resolvePipelineImageStreamTagReference(ctx, s.client, from, s.jobSpec)
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte(fmt.Sprintf("%s=%s", from, digest)))
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16]), nil
+}
+
+func cacheTag(to api.PipelineImageStreamTagReference, key string) string {
+	return fmt.Sprintf("%s-%s", to, key)
+}
+
+// restore tags the cached image into the pipeline, if it exists
+func (s *cachedImageStep) restore(ctx context.Context, tag string) (bool, error) {
+	cached := &imagev1.ImageStreamTag{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.cache.Namespace, Name: fmt.Sprintf("%s:%s", s.cache.Name, tag)}, cached); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get cached image: %w", err)
+	}
+	ist := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s:%s", api.PipelineImageStream, s.to),
+			Namespace: s.jobSpec.Namespace(),
+		},
+		Tag: &imagev1.TagReference{
+			ReferencePolicy: imagev1.TagReferencePolicy{Type: imagev1.LocalTagReferencePolicy},
+			From: &coreapi.ObjectReference{
+				Kind:      "ImageStreamImage",
+				Name:      fmt.Sprintf("%s@%s", s.cache.Name, cached.Image.Name),
+				Namespace: s.cache.Namespace,
+			},
+		},
+	}
+	if err := s.client.Create(ctx, ist); err != nil && !kerrors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("could not tag cached image into the pipeline: %w", err)
+	}
+	return true, waitForPipelineTag(ctx, s.client, s.jobSpec.Namespace(), s.to)
+}
+
+// store tags the image the step built into the cache
+func (s *cachedImageStep) store(ctx context.Context, tag string) error {
+	digest, err := resolvePipelineImageStreamTagReference(ctx, s.client, s.to, s.jobSpec)
+	if err != nil {
+		return err
+	}
+	ist := &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s:%s", s.cache.Name, tag),
+			Namespace: s.cache.Namespace,
+		},
+		Tag: &imagev1.TagReference{
+			From: &coreapi.ObjectReference{
+				Kind:      "ImageStreamImage",
+				Name:      fmt.Sprintf("%s@%s", api.PipelineImageStream, digest),
+				Namespace: s.jobSpec.Namespace(),
+			},
+		},
+	}
+	if err := s.client.Create(ctx, ist); err != nil && !kerrors.IsAlreadyExists(err) {
+		return fmt.Errorf("could not tag image into the cache: %w", err)
+	}
+	return nil
+}
+
+// CachedImageStep wraps a step that builds the `to` pipeline image so that the image
+// is reused from the cache when it was built before from the same configuration,
+// step inputs and `from` pipeline images.
+func CachedImageStep(step api.Step, to api.PipelineImageStreamTagReference, fingerprint interface{}, from []api.PipelineImageStreamTagReference, cache PipelineImageCache, client loggingclient.LoggingClient, jobSpec *api.JobSpec) api.Step {
+	return &cachedImageStep{
+		Step:        step,
+		to:          to,
+		fingerprint: fingerprint,
+		from:        from,
+		cache:       cache,
+		client:      client,
+		jobSpec:     jobSpec,
+	}
+}
@@ -0,0 +1,122 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+// buildingStep simulates a build by tagging an image into the pipeline
+type buildingStep struct {
+	api.Step
+	client loggingclient.LoggingClient
+	runs   int
+}
+
+func (s *buildingStep) Inputs() (api.InputDefinition, error) {
+	return api.InputDefinition{"refs"}, nil
+}
+
+func (s *buildingStep) Run(ctx context.Context) error {
+	s.runs++
+	return s.client.Create(ctx, pipelineTag("bin", "sha256:built"))
+}
+
+func pipelineTag(tag, digest string) *imagev1.ImageStreamTag {
+	return &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprintf("%s:%s", api.PipelineImageStream, tag)},
+		Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: digest}},
+	}
+}
+
+func TestCachedImageStep(t *testing.T) {
+	cache := PipelineImageCache{Namespace: "ci", Name: "pipeline-cache"}
+	jobSpec := &api.JobSpec{}
+	jobSpec.SetNamespace("ns")
+	config := api.PipelineImageCacheStepConfiguration{From: "src", To: "bin", Commands: "make"}
+	newStep := func(objects ...runtime.Object) (*cachedImageStep, *buildingStep) {
+		client := loggingclient.New(fakectrlruntimeclient.NewFakeClient(append(objects, pipelineTag("src", "sha256:src"))...))
+		wrapped := &buildingStep{client: client}
+		return CachedImageStep(wrapped, config.To, config, []api.PipelineImageStreamTagReference{config.From}, cache, client, jobSpec).(*cachedImageStep), wrapped
+	}
+	ctx := context.Background()
+
+	step, wrapped := newStep()
+	key, err := step.key(ctx)
+	if err != nil {
+		t.Fatalf("failed to determine key: %v", err)
+	}
+	tag := fmt.Sprintf("pipeline-cache:bin-%s", key)
+
+	t.Run("cache miss builds the image and caches it", func(t *testing.T) {
+		if err := step.Run(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if wrapped.runs != 1 {
+			t.Errorf("expected the image to be built once, was built %d times", wrapped.runs)
+		}
+		cached := &imagev1.ImageStreamTag{}
+		if err := step.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ci", Name: tag}, cached); err != nil {
+			t.Fatalf("expected the image to be cached: %v", err)
+		}
+		expected := &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ns", Name: "pipeline@sha256:built"}
+		if diff := cmp.Diff(expected, cached.Tag.From); diff != "" {
+			t.Errorf("unexpected cached image: %s", diff)
+		}
+	})
+
+	t.Run("cache hit reuses the image", func(t *testing.T) {
+		cached := &imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ci", Name: tag},
+			Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: "sha256:cached"}},
+		}
+		// the import of the tag into the pipeline happens on the server
+		pipeline := &imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: api.PipelineImageStream},
+			Status: imagev1.ImageStreamStatus{
+				DockerImageRepository: "registry/ns/pipeline",
+				Tags:                  []imagev1.NamedTagEventList{{Tag: "bin", Items: []imagev1.TagEvent{{Image: "sha256:cached"}}}},
+			},
+		}
+		step, wrapped := newStep(cached, pipeline)
+		if err := step.Run(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if wrapped.runs != 0 {
+			t.Errorf("expected the image not to be built, was built %d times", wrapped.runs)
+		}
+		ist := &imagev1.ImageStreamTag{}
+		if err := step.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ns", Name: "pipeline:bin"}, ist); err != nil {
+			t.Fatalf("expected the cached image to be tagged into the pipeline: %v", err)
+		}
+		expected := &coreapi.ObjectReference{Kind: "ImageStreamImage", Namespace: "ci", Name: "pipeline-cache@sha256:cached"}
+		if diff := cmp.Diff(expected, ist.Tag.From); diff != "" {
+			t.Errorf("unexpected pipeline image: %s", diff)
+		}
+	})
+
+	t.Run("different source image changes the key", func(t *testing.T) {
+		client := loggingclient.New(fakectrlruntimeclient.NewFakeClient(pipelineTag("src", "sha256:other")))
+		other := CachedImageStep(&buildingStep{client: client}, config.To, config, []api.PipelineImageStreamTagReference{config.From}, cache, client, jobSpec).(*cachedImageStep)
+		otherKey, err := other.key(ctx)
+		if err != nil {
+			t.Fatalf("failed to determine key: %v", err)
+		}
+		if otherKey == key {
+			t.Error("expected the key to depend on the digest of the source image")
+		}
+	})
+}
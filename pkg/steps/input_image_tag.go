@@ -94,21 +94,25 @@ func (s *inputImageTagStep) run(ctx context.Context) error {
 		return fmt.Errorf("failed to create imagestreamtag for input image: %w", err)
 	}
 
-	// Wait image is ready
+	return waitForPipelineTag(ctx, s.client, s.jobSpec.Namespace(), s.config.To)
+}
+
+// waitForPipelineTag waits for the image of a tag in the pipeline image stream to be imported
+func waitForPipelineTag(ctx context.Context, client ctrlruntimeclient.Client, namespace string, tag api.PipelineImageStreamTagReference) error {
 	importCtx, cancel := context.WithTimeout(ctx, 35*time.Minute)
 	defer cancel()
 	if err := wait.PollImmediateUntil(10*time.Second, func() (bool, error) {
 		pipeline := &imagev1.ImageStream{}
-		if err := s.client.Get(importCtx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: api.PipelineImageStream}, pipeline); err != nil {
+		if err := client.Get(importCtx, ctrlruntimeclient.ObjectKey{Namespace: namespace, Name: api.PipelineImageStream}, pipeline); err != nil {
 			return false, err
 		}
-		_, exists := util.ResolvePullSpec(pipeline, string(s.config.To), true)
+		_, exists := util.ResolvePullSpec(pipeline, string(tag), true)
 		if !exists {
-			logrus.Debugf("Waiting to import %s ...", ist.ObjectMeta.Name)
+			logrus.Debugf("Waiting to import %s:%s ...", api.PipelineImageStream, tag)
 		}
 		return exists, nil
 	}, importCtx.Done()); err != nil {
-		logrus.WithError(err).Errorf("Could not resolve tag %s in imagestream %s.", s.config.To, api.PipelineImageStream)
+		logrus.WithError(err).Errorf("Could not resolve tag %s in imagestream %s.", tag, api.PipelineImageStream)
 		return err
 	}
 	return nil
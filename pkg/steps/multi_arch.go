@@ -0,0 +1,157 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	coreapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildapi "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+const (
+	// architectureNodeLabel is set on every node to the architecture it runs
+	architectureNodeLabel = "kubernetes.io/arch"
+	// builderServiceAccount may push into the image streams of the namespace
+	builderServiceAccount = "builder"
+	// serviceCAConfigMap is published in every namespace by the cluster and holds
+	// the CA that signs the serving certificate of the integrated registry
+	serviceCAConfigMap = "openshift-service-ca.crt"
+	serviceCAKey       = "service-ca.crt"
+	serviceCAVolume    = "service-ca"
+	serviceCAMountPath = "/etc/service-ca"
+)
+
+// manifestToolImage provides the manifest-tool binary used to assemble manifest
+// lists; the alpine variant of the release image ships a shell to run it from
+const manifestToolImage = "docker.io/mplatform/manifest-tool:alpine-v2.0.8"
+
+// architectureBuilds derives a build for every architecture of a multi-architecture
+// image from the build of the image, scheduling each on nodes of the architecture
+// and tagging the output to the architecture's tag in the pipeline image stream.
+func architectureBuilds(build *buildapi.Build, image api.ProjectDirectoryImageBuildStepConfiguration, config *api.ReleaseBuildConfiguration) []*buildapi.Build {
+	var builds []*buildapi.Build
+	for _, architecture := range image.Architectures {
+		archBuild := build.DeepCopy()
+		tag := api.ArchitectureTag(image.To, architecture)
+		archBuild.Name = string(tag)
+		archBuild.Labels[CreatesLabel] = string(tag)
+		archBuild.Spec.NodeSelector = buildapi.OptionalNodeSelector{architectureNodeLabel: string(architecture)}
+		archBuild.Spec.Output.To.Name = fmt.Sprintf("%s:%s", api.PipelineImageStream, tag)
+		if from := archBuild.Spec.Strategy.DockerStrategy.From; from != nil && isMultiArchImage(config, image.From) {
+			from.Name = fmt.Sprintf("%s:%s", api.PipelineImageStream, api.ArchitectureTag(image.From, architecture))
+		}
+		builds = append(builds, archBuild)
+	}
+	return builds
+}
+
+// isMultiArchImage determines whether the image is built for multiple architectures
+func isMultiArchImage(config *api.ReleaseBuildConfiguration, tag api.PipelineImageStreamTagReference) bool {
+	for _, image := range config.Images {
+		if image.To == tag {
+			return len(image.Architectures) > 0
+		}
+	}
+	return false
+}
+
+// handleBuilds runs the builds in parallel
+func handleBuilds(ctx context.Context, buildClient BuildClient, builds []*buildapi.Build) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(builds))
+	for i := range builds {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = handleBuild(ctx, buildClient, builds[i])
+		}(i)
+	}
+	wg.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// assembleManifestList pushes a manifest list referencing the images built for
+// every architecture to the tag of the image in the pipeline image stream
+func assembleManifestList(ctx context.Context, podClient PodClient, image api.ProjectDirectoryImageBuildStepConfiguration, jobSpec *api.JobSpec) error {
+	pipeline := &imagev1.ImageStream{}
+	if err := podClient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: jobSpec.Namespace(), Name: api.PipelineImageStream}, pipeline); err != nil {
+		return fmt.Errorf("could not resolve pipeline imagestream: %w", err)
+	}
+	if pipeline.Status.DockerImageRepository == "" {
+		return fmt.Errorf("pipeline imagestream has no image repository")
+	}
+	pod := manifestListPod(image, pipeline.Status.DockerImageRepository, jobSpec)
+	if _, err := RunPod(ctx, podClient, pod); err != nil {
+		return fmt.Errorf("could not assemble manifest list for %s: %w", image.To, err)
+	}
+	return nil
+}
+
+// registryAuthScript writes a docker configuration into the directory that
+// authenticates to the registry of the repository as the service account of the pod
+func registryAuthScript(repository, dir string) []string {
+	registry := strings.SplitN(repository, "/", 2)[0]
+	return []string{
+		"set -o errexit",
+		"set -o nounset",
+		"set -o pipefail",
+		fmt.Sprintf("mkdir -p %s", dir),
+		fmt.Sprintf(`printf '{"auths":{"%s":{"auth":"%%s"}}}' "$(printf 'serviceaccount:%%s' "$(cat /var/run/secrets/kubernetes.io/serviceaccount/token)" | base64 -w 0)" > %s/config.json`, registry, dir),
+	}
+}
+
+func manifestListPod(image api.ProjectDirectoryImageBuildStepConfiguration, repository string, jobSpec *api.JobSpec) *coreapi.Pod {
+	var platforms []string
+	for _, architecture := range image.Architectures {
+		platforms = append(platforms, "linux/"+string(architecture))
+	}
+	// manifest-tool replaces ARCH in the template with the architecture of each platform
+	script := strings.Join(append(registryAuthScript(repository, "/tmp/docker"),
+		fmt.Sprintf("/manifest-tool --docker-cfg /tmp/docker push from-args --platforms %s --template %s:%s-ARCH --target %s:%s", strings.Join(platforms, ","), repository, image.To, repository, image.To),
+	), "\n")
+	pod := &coreapi.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-manifest-list", image.To),
+			Namespace: jobSpec.Namespace(),
+			Labels:    labelsFor(jobSpec, map[string]string{CreatesLabel: string(image.To)}),
+		},
+		Spec: coreapi.PodSpec{
+			RestartPolicy:      coreapi.RestartPolicyNever,
+			ServiceAccountName: builderServiceAccount,
+			Containers: []coreapi.Container{{
+				Name:    "manifest-list",
+				Image:   manifestToolImage,
+				Command: []string{"/bin/sh", "-c"},
+				Args:    []string{script},
+				// verify the registry with the service CA rather than the system roots
+				Env: []coreapi.EnvVar{{Name: "SSL_CERT_FILE", Value: fmt.Sprintf("%s/%s", serviceCAMountPath, serviceCAKey)}},
+				VolumeMounts: []coreapi.VolumeMount{{
+					Name:      serviceCAVolume,
+					MountPath: serviceCAMountPath,
+					ReadOnly:  true,
+				}},
+			}},
+			Volumes: []coreapi.Volume{{
+				Name: serviceCAVolume,
+				VolumeSource: coreapi.VolumeSource{
+					ConfigMap: &coreapi.ConfigMapVolumeSource{
+						LocalObjectReference: coreapi.LocalObjectReference{Name: serviceCAConfigMap},
+					},
+				},
+			}},
+		},
+	}
+	if owner := jobSpec.Owner(); owner != nil {
+		pod.OwnerReferences = append(pod.OwnerReferences, *owner)
+	}
+	return pod
+}
@@ -0,0 +1,69 @@
+package steps
+
+import (
+	"testing"
+
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+
+	buildapi "github.com/openshift/api/build/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/testhelper"
+)
+
+func multiArchJobSpec() *api.JobSpec {
+	jobSpec := &api.JobSpec{JobSpec: downwardapi.JobSpec{
+		Job:       "job",
+		BuildID:   "1",
+		ProwJobID: "prowjob",
+		Type:      prowapi.PresubmitJob,
+		Refs: &prowapi.Refs{
+			Org:     "org",
+			Repo:    "repo",
+			BaseRef: "master",
+			BaseSHA: "masterSHA",
+			Pulls:   []prowapi.Pull{{Number: 1, SHA: "pullSHA"}},
+		},
+	}}
+	jobSpec.SetNamespace("namespace")
+	return jobSpec
+}
+
+func TestArchitectureBuilds(t *testing.T) {
+	image := api.ProjectDirectoryImageBuildStepConfiguration{
+		From:          "base",
+		To:            "app",
+		Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+	}
+	testCases := []struct {
+		name   string
+		config *api.ReleaseBuildConfiguration
+	}{
+		{
+			name:   "built from a single-architecture image",
+			config: &api.ReleaseBuildConfiguration{Images: []api.ProjectDirectoryImageBuildStepConfiguration{image}},
+		},
+		{
+			name: "built from a multi-architecture image",
+			config: &api.ReleaseBuildConfiguration{Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "base", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64}},
+				image,
+			}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			build := buildFromSource(multiArchJobSpec(), image.From, image.To, buildapi.BuildSource{Type: buildapi.BuildSourceImage}, "sha256:base", "", api.ResourceConfiguration{}, nil, nil)
+			testhelper.CompareWithFixture(t, architectureBuilds(build, image, tc.config))
+		})
+	}
+}
+
+func TestManifestListPod(t *testing.T) {
+	image := api.ProjectDirectoryImageBuildStepConfiguration{
+		To:            "app",
+		Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64},
+	}
+	testhelper.CompareWithFixture(t, manifestListPod(image, "image-registry.openshift-image-registry.svc:5000/namespace/pipeline", multiArchJobSpec()))
+}
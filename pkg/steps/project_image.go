@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path"
+	"strings"
 
 	coreapi "k8s.io/api/core/v1"
 	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,6 +24,7 @@ type projectDirectoryImageBuildStep struct {
 	releaseBuildConfig *api.ReleaseBuildConfiguration
 	resources          api.ResourceConfiguration
 	client             BuildClient
+	podClient          PodClient
 	jobSpec            *api.JobSpec
 	pullSecret         *coreapi.Secret
 }
@@ -61,7 +63,13 @@ func (s *projectDirectoryImageBuildStep) run(ctx context.Context) error {
 		s.pullSecret,
 		s.config.BuildArgs,
 	)
-	return handleBuild(ctx, s.client, build)
+	if len(s.config.Architectures) == 0 {
+		return handleBuild(ctx, s.client, build)
+	}
+	if err := handleBuilds(ctx, s.client, architectureBuilds(build, s.config, s.releaseBuildConfig)); err != nil {
+		return err
+	}
+	return assembleManifestList(ctx, s.podClient, s.config, s.jobSpec)
 }
 
 type workingDir func(tag string) (string, error)
@@ -154,6 +162,13 @@ func (s *projectDirectoryImageBuildStep) Provides() api.ParameterMap {
 func (s *projectDirectoryImageBuildStep) Name() string { return string(s.config.To) }
 
 func (s *projectDirectoryImageBuildStep) Description() string {
+	if len(s.config.Architectures) > 0 {
+		var architectures []string
+		for _, architecture := range s.config.Architectures {
+			architectures = append(architectures, string(architecture))
+		}
+		return fmt.Sprintf("Build image %s from the repository for %s", s.config.To, strings.Join(architectures, ", "))
+	}
 	return fmt.Sprintf("Build image %s from the repository", s.config.To)
 }
 
@@ -161,12 +176,13 @@ func (s *projectDirectoryImageBuildStep) Objects() []ctrlruntimeclient.Object {
 	return s.client.Objects()
 }
 
-func ProjectDirectoryImageBuildStep(config api.ProjectDirectoryImageBuildStepConfiguration, releaseBuildConfig *api.ReleaseBuildConfiguration, resources api.ResourceConfiguration, buildClient BuildClient, jobSpec *api.JobSpec, pullSecret *coreapi.Secret) api.Step {
+func ProjectDirectoryImageBuildStep(config api.ProjectDirectoryImageBuildStepConfiguration, releaseBuildConfig *api.ReleaseBuildConfiguration, resources api.ResourceConfiguration, buildClient BuildClient, podClient PodClient, jobSpec *api.JobSpec, pullSecret *coreapi.Secret) api.Step {
 	return &projectDirectoryImageBuildStep{
 		config:             config,
 		releaseBuildConfig: releaseBuildConfig,
 		resources:          resources,
 		client:             buildClient,
+		podClient:          podClient,
 		jobSpec:            jobSpec,
 		pullSecret:         pullSecret,
 	}
@@ -0,0 +1,107 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/test-infra/prow/secretutil"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/release/diff"
+	"github.com/openshift/ci-tools/pkg/results"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+// diffReleaseStep compares the components of two release payloads in
+// the job, like `initial` and `latest`, using the stable image streams
+// they were imported or assembled from. Changed components, their source
+// commits and changelogs are saved in the artifacts and reported in jUnit.
+type diffReleaseStep struct {
+	config  api.ReleaseDiff
+	client  loggingclient.LoggingClient
+	jobSpec *api.JobSpec
+	censor  secretutil.Censorer
+
+	report *diff.Report
+}
+
+func (s *diffReleaseStep) Inputs() (api.InputDefinition, error) {
+	return nil, nil
+}
+
+func (*diffReleaseStep) Validate() error { return nil }
+
+func (s *diffReleaseStep) Run(ctx context.Context) error {
+	return results.ForReason("diffing_releases").ForError(s.run(ctx))
+}
+
+func (s *diffReleaseStep) run(ctx context.Context) error {
+	components := map[string]map[string]diff.Component{}
+	for _, name := range []string{s.config.From, s.config.To} {
+		stream := &imagev1.ImageStream{}
+		if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: s.jobSpec.Namespace(), Name: api.ReleaseStreamFor(name)}, stream); err != nil {
+			return fmt.Errorf("could not get image stream for release %s: %w", name, err)
+		}
+		components[name] = diff.ComponentsFor(stream)
+	}
+	report := diff.Diff(s.config.From, s.config.To, components[s.config.From], components[s.config.To])
+	s.report = &report
+	logrus.Info(report.String())
+
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize release diff: %w", err)
+	}
+	if err := api.SaveArtifact(s.censor, fmt.Sprintf("release-diff-%s-%s.json", s.config.From, s.config.To), raw); err != nil {
+		return fmt.Errorf("could not save release diff: %w", err)
+	}
+	return nil
+}
+
+func (s *diffReleaseStep) SubTests() []*junit.TestCase {
+	if s.report == nil {
+		return nil
+	}
+	return s.report.TestCases()
+}
+
+func (s *diffReleaseStep) Requires() []api.StepLink {
+	return []api.StepLink{api.ReleasePayloadImageLink(s.config.From), api.ReleasePayloadImageLink(s.config.To)}
+}
+
+func (s *diffReleaseStep) Creates() []api.StepLink {
+	return nil
+}
+
+func (s *diffReleaseStep) Provides() api.ParameterMap {
+	return nil
+}
+
+func (s *diffReleaseStep) Name() string {
+	return fmt.Sprintf("[release-diff:%s-%s]", s.config.From, s.config.To)
+}
+
+func (s *diffReleaseStep) Description() string {
+	return fmt.Sprintf("Compare the components of the release payloads %q and %q", s.config.From, s.config.To)
+}
+
+func (s *diffReleaseStep) Objects() []ctrlruntimeclient.Object {
+	return s.client.Objects()
+}
+
+// DiffReleaseStep compares the components of two release payloads
+func DiffReleaseStep(config api.ReleaseDiff, client loggingclient.LoggingClient, jobSpec *api.JobSpec, censor secretutil.Censorer) api.Step {
+	return &diffReleaseStep{
+		config:  config,
+		client:  client,
+		jobSpec: jobSpec,
+		censor:  censor,
+	}
+}
@@ -0,0 +1,77 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	coreapi "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/test-infra/prow/secretutil"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/junit"
+	"github.com/openshift/ci-tools/pkg/release/diff"
+	"github.com/openshift/ci-tools/pkg/steps/loggingclient"
+)
+
+func stableStream(name string, commits map[string]string) *imagev1.ImageStream {
+	stream := &imagev1.ImageStream{ObjectMeta: meta.ObjectMeta{Namespace: "ns", Name: name}}
+	for _, tag := range sortedKeys(commits) {
+		stream.Spec.Tags = append(stream.Spec.Tags, imagev1.TagReference{
+			Name: tag,
+			Annotations: map[string]string{
+				diff.CommitAnnotation: commits[tag],
+				diff.SourceAnnotation: "https://github.com/openshift/" + tag,
+			},
+			From: &coreapi.ObjectReference{Kind: "DockerImage", Name: "quay.io/ocp@sha256:" + commits[tag]},
+		})
+	}
+	return stream
+}
+
+func TestDiffReleaseStep(t *testing.T) {
+	artifacts := t.TempDir()
+	if err := os.Setenv("ARTIFACTS", artifacts); err != nil {
+		t.Fatalf("could not set artifact directory: %v", err)
+	}
+	defer os.Unsetenv("ARTIFACTS")
+	client := loggingclient.New(fakectrlruntimeclient.NewFakeClient(
+		stableStream("stable-initial", map[string]string{"cli": "abc", "installer": "def"}),
+		stableStream("stable", map[string]string{"cli": "abc", "installer": "ghi"}),
+	))
+	jobSpec := &api.JobSpec{}
+	jobSpec.SetNamespace("ns")
+	step := DiffReleaseStep(api.ReleaseDiff{From: api.InitialReleaseName, To: api.LatestReleaseName}, client, jobSpec, secretutil.NewCensorer())
+	if err := step.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := ioutil.ReadFile(filepath.Join(artifacts, "release-diff-initial-latest.json"))
+	if err != nil {
+		t.Fatalf("expected the diff to be saved: %v", err)
+	}
+	var report diff.Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("could not parse the saved diff: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Changelog != "https://github.com/openshift/installer/compare/def...ghi" {
+		t.Errorf("unexpected report: %#v", report)
+	}
+
+	expected := []*junit.TestCase{{
+		Name:      "Release component installer changed between initial and latest",
+		SystemOut: "from: quay.io/ocp@sha256:def (https://github.com/openshift/installer@def)\nto: quay.io/ocp@sha256:ghi (https://github.com/openshift/installer@ghi)\nchangelog: https://github.com/openshift/installer/compare/def...ghi",
+	}}
+	if diff := cmp.Diff(expected, step.(*diffReleaseStep).SubTests()); diff != "" {
+		t.Errorf("unexpected test cases: %s", diff)
+	}
+}
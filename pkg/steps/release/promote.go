@@ -29,6 +29,9 @@ type promotionStep struct {
 	jobSpec        *api.JobSpec
 	client         steps.PodClient
 	pushSecret     *coreapi.Secret
+	// registryClient has access to the image streams we promote to and is used
+	// to record the promotion history, it may be nil
+	registryClient ctrlruntimeclient.Client
 }
 
 func targetName(config api.PromotionConfiguration) string {
@@ -70,9 +73,25 @@ func (s *promotionStep) run(ctx context.Context) error {
 		return nil
 	}
 
-	if _, err := steps.RunPod(ctx, s.client, getPromotionPod(imageMirrorTarget, s.jobSpec.Namespace())); err != nil {
+	externalMirrors, err := s.getExternalMirrors(ctx, tags, pipeline)
+	if err != nil {
+		return fmt.Errorf("could not prepare promotion to external destinations: %w", err)
+	}
+
+	var previous map[ctrlruntimeclient.ObjectKey]map[string]string
+	if s.recordsHistory() {
+		previous = s.previousDigests(ctx, tags)
+	}
+
+	if _, err := steps.RunPod(ctx, s.client, getPromotionPod(imageMirrorTarget, externalMirrors, s.configuration.PromotionConfiguration.Signing, s.jobSpec.Namespace())); err != nil {
 		return fmt.Errorf("unable to run promotion pod: %w", err)
 	}
+
+	if s.recordsHistory() {
+		if err := s.recordHistory(ctx, tags, pipeline, previous); err != nil {
+			logrus.WithError(err).Warn("Failed to record the promotion history.")
+		}
+	}
 	return nil
 }
 
@@ -124,20 +143,19 @@ func getPublicImageReference(dockerImageReference, publicDockerImageRepository s
 	return strings.Replace(dockerImageReference, splits[0], publicHost, 1)
 }
 
-func getPromotionPod(imageMirrorTarget map[string]string, namespace string) *coreapi.Pod {
-	keys := make([]string, 0, len(imageMirrorTarget))
-	for k := range imageMirrorTarget {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
+func getPromotionPod(imageMirrorTarget map[string]string, externalMirrors []externalMirror, signing *api.PromotionSigningConfiguration, namespace string) *coreapi.Pod {
 	var images []string
-	for _, k := range keys {
+	for _, k := range sortedKeys(imageMirrorTarget) {
 		images = append(images, fmt.Sprintf("%s=%s", k, imageMirrorTarget[k]))
 	}
 	command := []string{"/bin/sh", "-c"}
-	args := []string{fmt.Sprintf("oc image mirror --registry-config=%s --continue-on-error=true --max-per-registry=20 %s", filepath.Join(api.RegistryPushCredentialsCICentralSecretMountPath, coreapi.DockerConfigJsonKey), strings.Join(images, " "))}
-	return &coreapi.Pod{
+	script := fmt.Sprintf("oc image mirror --registry-config=%s --continue-on-error=true --max-per-registry=20 %s", filepath.Join(api.RegistryPushCredentialsCICentralSecretMountPath, coreapi.DockerConfigJsonKey), strings.Join(images, " "))
+	if len(externalMirrors) != 0 {
+		lines := append([]string{"set -o errexit", "set -o nounset", "set -o pipefail", script}, externalPromotionCommands(externalMirrors, signing)...)
+		script = strings.Join(lines, "\n")
+	}
+	args := []string{script}
+	pod := &coreapi.Pod{
 		ObjectMeta: meta.ObjectMeta{
 			Name:      "promotion",
 			Namespace: namespace,
@@ -169,6 +187,10 @@ func getPromotionPod(imageMirrorTarget map[string]string, namespace string) *cor
 			},
 		},
 	}
+	if len(externalMirrors) != 0 {
+		addExternalPromotion(pod, externalMirrors, signing)
+	}
+	return pod
 }
 
 // findDockerImageReference returns DockerImageReference, the string that can be used to pull this image,
@@ -261,6 +283,30 @@ func PromotedTagsWithRequiredImages(configuration *api.ReleaseBuildConfiguration
 	return promotedTags, names
 }
 
+// PromotionPlan maps the tags of the pipeline image stream that are promoted for
+// the given ReleaseBuildConfiguration to the pull specs they are mirrored to: the
+// integration image stream first, then the external destinations in the order
+// they are configured. Tags missing in the pipeline image stream at the time of
+// promotion are skipped.
+func PromotionPlan(configuration *api.ReleaseBuildConfiguration, requiredImages sets.String) map[string][]string {
+	tags, _ := PromotedTagsWithRequiredImages(configuration, requiredImages)
+	if len(tags) == 0 {
+		return nil
+	}
+	registry := registryDomain(configuration.PromotionConfiguration)
+	plan := map[string][]string{}
+	for src, dst := range tags {
+		plan[src] = append(plan[src], fmt.Sprintf("%s/%s", registry, dst.ISTagName()))
+		if src == string(api.PipelineImageStreamTagReferenceBinaries) {
+			continue
+		}
+		for _, destination := range configuration.PromotionConfiguration.ExternalDestinations {
+			plan[src] = append(plan[src], externalDestination(destination.Repository, dst))
+		}
+	}
+	return plan
+}
+
 func (s *promotionStep) Requires() []api.StepLink {
 	return []api.StepLink{api.AllStepsLink()}
 }
@@ -284,13 +330,15 @@ func (s *promotionStep) Objects() []ctrlruntimeclient.Object {
 }
 
 // PromotionStep copies tags from the pipeline image stream to the destination defined in the promotion config.
-// If the source tag does not exist it is silently skipped.
-func PromotionStep(configuration *api.ReleaseBuildConfiguration, requiredImages sets.String, jobSpec *api.JobSpec, client steps.PodClient, pushSecret *coreapi.Secret) api.Step {
+// If the source tag does not exist it is silently skipped. When registryClient is set, the
+// promotion is recorded in the history of the image streams promoted to.
+func PromotionStep(configuration *api.ReleaseBuildConfiguration, requiredImages sets.String, jobSpec *api.JobSpec, client steps.PodClient, pushSecret *coreapi.Secret, registryClient ctrlruntimeclient.Client) api.Step {
 	return &promotionStep{
 		configuration:  configuration,
 		requiredImages: requiredImages,
 		jobSpec:        jobSpec,
 		client:         client,
 		pushSecret:     pushSecret,
+		registryClient: registryClient,
 	}
 }
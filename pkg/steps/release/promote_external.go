@@ -0,0 +1,323 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	coreapi "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/kubernetes/pkg/credentialprovider"
+)
+
+const (
+	// externalCredentialsNamespace holds the secrets referenced by external
+	// promotion destinations and signing configurations
+	externalCredentialsNamespace = "test-credentials"
+
+	externalCredentialsMountPath = "/etc/promotion"
+	// externalCredentialsKey is the name docker and cosign expect for a
+	// configuration file in $DOCKER_CONFIG
+	externalCredentialsKey = "config.json"
+
+	signingKeySecret    = "promotion-signing-key"
+	signingKeyMountPath = "/etc/cosign"
+	signingKeyKey       = "cosign.key"
+	signingPubKey       = "cosign.pub"
+	signingPasswordKey  = "cosign.password"
+
+	toolsMountPath = "/tools"
+	sbomDirectory  = "/tmp/sbom"
+)
+
+// cosignImage provides the cosign binary for the promotion pod; the -dev
+// variant of the release image ships a busybox shell to copy it out with
+const cosignImage = "gcr.io/projectsigstore/cosign:v1.13.1-dev"
+
+// externalMirror is the promotion of images to one external destination
+type externalMirror struct {
+	// secret holds the credentials used to pull the promoted images and
+	// to push them to the destination
+	secret string
+	// images maps the pull specs of the promoted images to their
+	// destinations, which are pinned to the promoted digests if possible
+	images map[string]string
+}
+
+func externalCredentialsSecret(index int) string {
+	return fmt.Sprintf("promotion-external-credentials-%d", index)
+}
+
+// externalDestination is the pull spec an image promoted to the image stream
+// tag is pushed to in the external repository
+func externalDestination(repository string, tag api.ImageStreamTagReference) string {
+	return fmt.Sprintf("%s/%s:%s", repository, tag.Name, tag.Tag)
+}
+
+// getExternalMirrorTarget maps the promoted images to their destinations in
+// the external repository. The build cache is only meant for consumption by
+// later CI jobs and is never pushed to external destinations.
+func getExternalMirrorTarget(tags map[string]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, repository string) map[string]string {
+	if pipeline == nil {
+		return nil
+	}
+	imageMirror := map[string]string{}
+	for src, dst := range tags {
+		if src == string(api.PipelineImageStreamTagReferenceBinaries) {
+			continue
+		}
+		dockerImageReference := findDockerImageReference(pipeline, src)
+		if dockerImageReference == "" {
+			continue
+		}
+		dockerImageReference = getPublicImageReference(dockerImageReference, pipeline.Status.PublicDockerImageRepository)
+		imageMirror[dockerImageReference] = externalDestination(repository, dst)
+	}
+	if len(imageMirror) == 0 {
+		return nil
+	}
+	return imageMirror
+}
+
+// pinnedReference returns the destination pinned to the digest of the source
+// image, which oc image mirror preserves, so that we sign and verify exactly
+// the image that was promoted rather than whatever the tag points to
+func pinnedReference(source, destination string) string {
+	digest := strings.Index(source, "@")
+	if digest == -1 {
+		return destination
+	}
+	repository := destination
+	if tag := strings.LastIndex(destination, ":"); tag > strings.LastIndex(destination, "/") {
+		repository = destination[:tag]
+	}
+	return repository + source[digest:]
+}
+
+// mergeDockerConfigs merges the auths of the given docker configurations, with
+// later configurations taking precedence
+func mergeDockerConfigs(configs ...[]byte) ([]byte, error) {
+	merged := credentialprovider.DockerConfigJSON{Auths: credentialprovider.DockerConfig{}}
+	for _, raw := range configs {
+		if len(raw) == 0 {
+			continue
+		}
+		var config credentialprovider.DockerConfigJSON
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return nil, fmt.Errorf("could not parse docker config: %w", err)
+		}
+		for registry, auth := range config.Auths {
+			merged.Auths[registry] = auth
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// replaceSecret creates the secret in the test namespace, replacing a secret
+// left over from a previous promotion attempt
+func (s *promotionStep) replaceSecret(ctx context.Context, secret *coreapi.Secret) error {
+	if err := s.client.Delete(ctx, secret); err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete secret %s: %w", secret.Name, err)
+	}
+	if err := s.client.Create(ctx, secret); err != nil {
+		return fmt.Errorf("could not create secret %s: %w", secret.Name, err)
+	}
+	return nil
+}
+
+// createExternalCredentials combines the credentials for the destination with
+// the credentials used to pull the promoted images, as neither oc nor cosign
+// accept more than one registry configuration
+func (s *promotionStep) createExternalCredentials(ctx context.Context, index int, destination api.ExternalPromotionDestination) (string, error) {
+	source := &coreapi.Secret{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: externalCredentialsNamespace, Name: destination.Credentials}, source); err != nil {
+		return "", fmt.Errorf("could not read credentials for %s: %w", destination.Repository, err)
+	}
+	var pullCredentials []byte
+	if s.pushSecret != nil {
+		pullCredentials = s.pushSecret.Data[coreapi.DockerConfigJsonKey]
+	}
+	config, err := mergeDockerConfigs(pullCredentials, source.Data[coreapi.DockerConfigJsonKey])
+	if err != nil {
+		return "", fmt.Errorf("could not merge credentials for %s: %w", destination.Repository, err)
+	}
+	name := externalCredentialsSecret(index)
+	return name, s.replaceSecret(ctx, &coreapi.Secret{
+		ObjectMeta: meta.ObjectMeta{Namespace: s.jobSpec.Namespace(), Name: name},
+		Data:       map[string][]byte{externalCredentialsKey: config},
+	})
+}
+
+func (s *promotionStep) createSigningKey(ctx context.Context, signing *api.PromotionSigningConfiguration) error {
+	source := &coreapi.Secret{}
+	if err := s.client.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: externalCredentialsNamespace, Name: signing.KeySecret}, source); err != nil {
+		return fmt.Errorf("could not read signing key: %w", err)
+	}
+	for _, key := range []string{signingKeyKey, signingPubKey} {
+		if _, ok := source.Data[key]; !ok {
+			return fmt.Errorf("signing key secret %s has no %s key", signing.KeySecret, key)
+		}
+	}
+	return s.replaceSecret(ctx, &coreapi.Secret{
+		ObjectMeta: meta.ObjectMeta{Namespace: s.jobSpec.Namespace(), Name: signingKeySecret},
+		Data:       source.Data,
+	})
+}
+
+// getExternalMirrors prepares the credentials and determines the images for
+// every external destination
+func (s *promotionStep) getExternalMirrors(ctx context.Context, tags map[string]api.ImageStreamTagReference, pipeline *imagev1.ImageStream) ([]externalMirror, error) {
+	configuration := s.configuration.PromotionConfiguration
+	var mirrors []externalMirror
+	for i, destination := range configuration.ExternalDestinations {
+		images := getExternalMirrorTarget(tags, pipeline, destination.Repository)
+		if len(images) == 0 {
+			continue
+		}
+		secret, err := s.createExternalCredentials(ctx, i, destination)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, externalMirror{secret: secret, images: images})
+	}
+	if len(mirrors) != 0 && configuration.Signing != nil {
+		if err := s.createSigningKey(ctx, configuration.Signing); err != nil {
+			return nil, err
+		}
+	}
+	return mirrors, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// externalPromotionCommands pushes the images to the external destinations
+// and signs them and attaches their SBOMs if configured. Every pushed image is
+// verified afterwards, so the promotion fails unless each image is present
+// with its signature in the destination.
+func externalPromotionCommands(mirrors []externalMirror, signing *api.PromotionSigningConfiguration) []string {
+	var commands []string
+	sboms := map[string]string{}
+	if signing != nil && signing.SBOMPath != "" {
+		sources := map[string]string{}
+		for _, mirror := range mirrors {
+			for source := range mirror.images {
+				// any of the credentials allow pulling the promoted images
+				sources[source] = mirror.secret
+			}
+		}
+		for i, source := range sortedKeys(sources) {
+			directory := fmt.Sprintf("%s/%d", sbomDirectory, i)
+			commands = append(commands,
+				fmt.Sprintf("mkdir -p %s", directory),
+				fmt.Sprintf("oc image extract --registry-config=%s %s --path=%s:%s", credentialsPath(sources[source]), source, signing.SBOMPath, directory),
+			)
+			sboms[source] = path.Join(directory, path.Base(signing.SBOMPath))
+		}
+	}
+
+	cosign := filepath.Join(toolsMountPath, "cosign")
+	key := filepath.Join(signingKeyMountPath, signingKeyKey)
+	pub := filepath.Join(signingKeyMountPath, signingPubKey)
+	for _, mirror := range mirrors {
+		sources := sortedKeys(mirror.images)
+		var images []string
+		for _, source := range sources {
+			images = append(images, fmt.Sprintf("%s=%s", source, mirror.images[source]))
+		}
+		commands = append(commands,
+			fmt.Sprintf("export DOCKER_CONFIG=%s", filepath.Join(externalCredentialsMountPath, mirror.secret)),
+			fmt.Sprintf("oc image mirror --registry-config=%s --max-per-registry=20 %s", credentialsPath(mirror.secret), strings.Join(images, " ")),
+		)
+		for _, source := range sources {
+			destination := pinnedReference(source, mirror.images[source])
+			if signing == nil {
+				commands = append(commands, fmt.Sprintf("oc image info --registry-config=%s %s >/dev/null", credentialsPath(mirror.secret), destination))
+				continue
+			}
+			commands = append(commands, fmt.Sprintf("%s sign --key %s %s", cosign, key, destination))
+			if sbom, ok := sboms[source]; ok {
+				commands = append(commands,
+					fmt.Sprintf("%s attach sbom --sbom %s %s", cosign, sbom, destination),
+					fmt.Sprintf("%s sign --key %s --attachment sbom %s", cosign, key, destination),
+				)
+			}
+			commands = append(commands, fmt.Sprintf("%s verify --key %s %s >/dev/null", cosign, pub, destination))
+			if _, ok := sboms[source]; ok {
+				commands = append(commands, fmt.Sprintf("%s verify --key %s --attachment sbom %s >/dev/null", cosign, pub, destination))
+			}
+		}
+	}
+	return commands
+}
+
+func credentialsPath(secret string) string {
+	return filepath.Join(externalCredentialsMountPath, secret, externalCredentialsKey)
+}
+
+// addExternalPromotion extends the promotion pod with the credentials and
+// tools needed to push to the external destinations
+func addExternalPromotion(pod *coreapi.Pod, mirrors []externalMirror, signing *api.PromotionSigningConfiguration) {
+	container := &pod.Spec.Containers[0]
+	for _, mirror := range mirrors {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, coreapi.Volume{
+			Name:         mirror.secret,
+			VolumeSource: coreapi.VolumeSource{Secret: &coreapi.SecretVolumeSource{SecretName: mirror.secret}},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, coreapi.VolumeMount{
+			Name:      mirror.secret,
+			MountPath: filepath.Join(externalCredentialsMountPath, mirror.secret),
+			ReadOnly:  true,
+		})
+	}
+	if signing == nil {
+		return
+	}
+
+	optional := true
+	container.Env = append(container.Env, coreapi.EnvVar{
+		Name: "COSIGN_PASSWORD",
+		ValueFrom: &coreapi.EnvVarSource{SecretKeyRef: &coreapi.SecretKeySelector{
+			LocalObjectReference: coreapi.LocalObjectReference{Name: signingKeySecret},
+			Key:                  signingPasswordKey,
+			Optional:             &optional,
+		}},
+	})
+	container.VolumeMounts = append(container.VolumeMounts,
+		coreapi.VolumeMount{Name: signingKeySecret, MountPath: signingKeyMountPath, ReadOnly: true},
+		coreapi.VolumeMount{Name: "tools", MountPath: toolsMountPath},
+	)
+	pod.Spec.Volumes = append(pod.Spec.Volumes,
+		coreapi.Volume{
+			Name:         signingKeySecret,
+			VolumeSource: coreapi.VolumeSource{Secret: &coreapi.SecretVolumeSource{SecretName: signingKeySecret}},
+		},
+		coreapi.Volume{
+			Name:         "tools",
+			VolumeSource: coreapi.VolumeSource{EmptyDir: &coreapi.EmptyDirVolumeSource{}},
+		},
+	)
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, coreapi.Container{
+		Name:         "cosign",
+		Image:        cosignImage,
+		Command:      []string{"/busybox/sh", "-c"},
+		Args:         []string{fmt.Sprintf("/busybox/cp /ko-app/cosign %s", filepath.Join(toolsMountPath, "cosign"))},
+		VolumeMounts: []coreapi.VolumeMount{{Name: "tools", MountPath: toolsMountPath}},
+	})
+}
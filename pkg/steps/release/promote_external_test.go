@@ -0,0 +1,120 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+)
+
+func TestGetExternalMirrorTarget(t *testing.T) {
+	pipeline := &imagev1.ImageStream{
+		Status: imagev1.ImageStreamStatus{
+			PublicDockerImageRepository: "registry.ci.openshift.org/ci-op-y2n8rsh3/pipeline",
+			Tags: []imagev1.NamedTagEventList{
+				{
+					Tag:   "src",
+					Items: []imagev1.TagEvent{{DockerImageReference: "image-registry.openshift-image-registry.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:aaa"}},
+				},
+				{
+					Tag:   "cli",
+					Items: []imagev1.TagEvent{{DockerImageReference: "image-registry.openshift-image-registry.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb"}},
+				},
+				{
+					Tag:   "bin",
+					Items: []imagev1.TagEvent{{DockerImageReference: "image-registry.openshift-image-registry.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:ccc"}},
+				},
+			},
+		},
+	}
+	tags := map[string]api.ImageStreamTagReference{
+		"src":     {Namespace: "ci", Name: "4.8", Tag: "src"},
+		"cli":     {Namespace: "ci", Name: "4.8", Tag: "cli"},
+		"missing": {Namespace: "ci", Name: "4.8", Tag: "missing"},
+		"bin":     {Namespace: "build-cache", Name: "org-repo", Tag: "master"},
+	}
+	expected := map[string]string{
+		"registry.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:aaa": "quay.io/org/4.8:src",
+		"registry.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb": "quay.io/org/4.8:cli",
+	}
+	if diff := cmp.Diff(expected, getExternalMirrorTarget(tags, pipeline, "quay.io/org")); diff != "" {
+		t.Errorf("unexpected mirror target: %s", diff)
+	}
+}
+
+func TestPinnedReference(t *testing.T) {
+	testCases := []struct {
+		name        string
+		source      string
+		destination string
+		expected    string
+	}{
+		{
+			name:        "tag is replaced by the digest of the source",
+			source:      "registry.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb",
+			destination: "quay.io/org/bin:latest",
+			expected:    "quay.io/org/bin@sha256:bbb",
+		},
+		{
+			name:        "registry port is not mistaken for a tag",
+			source:      "registry.ci.openshift.org/ci-op-y2n8rsh3/pipeline@sha256:bbb",
+			destination: "registry.local:5000/org/bin",
+			expected:    "registry.local:5000/org/bin@sha256:bbb",
+		},
+		{
+			name:        "source without a digest keeps the tag",
+			source:      "registry.ci.openshift.org/ci-op-y2n8rsh3/pipeline:bin",
+			destination: "quay.io/org/bin:latest",
+			expected:    "quay.io/org/bin:latest",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := pinnedReference(tc.source, tc.destination); actual != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMergeDockerConfigs(t *testing.T) {
+	testCases := []struct {
+		name        string
+		configs     [][]byte
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "later configs take precedence",
+			configs: [][]byte{
+				[]byte(`{"auths":{"registry.ci.openshift.org":{"auth":"Y2k6cHVzaA=="},"quay.io":{"auth":"b2xkOm9sZA=="}}}`),
+				nil,
+				[]byte(`{"auths":{"quay.io":{"auth":"cXVheTpwdXNo"}}}`),
+			},
+			expected: `{"auths":{"quay.io":{"username":"quay","password":"push","auth":"cXVheTpwdXNo"},"registry.ci.openshift.org":{"username":"ci","password":"push","auth":"Y2k6cHVzaA=="}}}`,
+		},
+		{
+			name:        "invalid config",
+			configs:     [][]byte{[]byte(`{`)},
+			expectedErr: "could not parse docker config: unexpected end of JSON input",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := mergeDockerConfigs(tc.configs...)
+			var actualErr string
+			if err != nil {
+				actualErr = err.Error()
+			}
+			if diff := cmp.Diff(tc.expectedErr, actualErr); diff != "" {
+				t.Fatalf("unexpected error: %s", diff)
+			}
+			if diff := cmp.Diff(tc.expected, string(merged)); diff != "" {
+				t.Errorf("unexpected config: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/pod-utils/gcs"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/promotionhistory"
+)
+
+const jobURLPrefix = "https://prow.ci.openshift.org/view/"
+
+// promotedStreams groups the promoted tags by the image stream they are promoted
+// to, mapping the destination tag to the source tag in the pipeline image stream
+func promotedStreams(tags map[string]api.ImageStreamTagReference) map[ctrlruntimeclient.ObjectKey]map[string]string {
+	streams := map[ctrlruntimeclient.ObjectKey]map[string]string{}
+	for src, dst := range tags {
+		key := ctrlruntimeclient.ObjectKey{Namespace: dst.Namespace, Name: dst.Name}
+		if streams[key] == nil {
+			streams[key] = map[string]string{}
+		}
+		streams[key][dst.Tag] = src
+	}
+	return streams
+}
+
+// recordsHistory determines whether the promotion history can be recorded: this
+// requires access to the image streams we promote to
+func (s *promotionStep) recordsHistory() bool {
+	return s.registryClient != nil && s.configuration.PromotionConfiguration.RegistryOverride == ""
+}
+
+// previousDigests returns the digests the promoted tags point to before the promotion
+func (s *promotionStep) previousDigests(ctx context.Context, tags map[string]api.ImageStreamTagReference) map[ctrlruntimeclient.ObjectKey]map[string]string {
+	digests := map[ctrlruntimeclient.ObjectKey]map[string]string{}
+	for key, streamTags := range promotedStreams(tags) {
+		stream := &imagev1.ImageStream{}
+		if err := s.registryClient.Get(ctx, key, stream); err != nil {
+			if !kerrors.IsNotFound(err) {
+				logrus.WithError(err).Warnf("Could not determine the images currently promoted to %s.", key)
+			}
+			continue
+		}
+		digests[key] = map[string]string{}
+		for tag := range streamTags {
+			digests[key][tag] = promotionhistory.CurrentDigest(stream, tag)
+		}
+	}
+	return digests
+}
+
+// recordHistory adds a record for every tag that changed in the promotion to the
+// history of the image stream it was promoted to
+func (s *promotionStep) recordHistory(ctx context.Context, tags map[string]api.ImageStreamTagReference, pipeline *imagev1.ImageStream, previous map[ctrlruntimeclient.ObjectKey]map[string]string) error {
+	now := time.Now().UTC()
+	jobURL, commit := jobURL(s.jobSpec), promotedCommit(s.jobSpec)
+	for key, streamTags := range promotedStreams(tags) {
+		records := map[string]promotionhistory.Record{}
+		for tag, src := range streamTags {
+			digest := promotionhistory.CurrentDigest(pipeline, src)
+			if digest == "" || digest == previous[key][tag] {
+				continue
+			}
+			records[tag] = promotionhistory.Record{
+				Digest:         digest,
+				PreviousDigest: previous[key][tag],
+				JobURL:         jobURL,
+				Commit:         commit,
+				Time:           now,
+			}
+		}
+		if len(records) == 0 {
+			continue
+		}
+		if err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			stream := &imagev1.ImageStream{}
+			if err := s.registryClient.Get(ctx, key, stream); err != nil {
+				return err
+			}
+			for _, tag := range sortedKeys(streamTags) {
+				record, changed := records[tag]
+				if !changed {
+					continue
+				}
+				if err := promotionhistory.AddRecord(stream, tag, record); err != nil {
+					return err
+				}
+			}
+			return s.registryClient.Update(ctx, stream)
+		}); err != nil {
+			return fmt.Errorf("could not record promotion history of %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// jobURL determines the URL under which the job can be viewed, if the job
+// uploads its artifacts
+func jobURL(jobSpec *api.JobSpec) string {
+	if jobSpec.DecorationConfig == nil || jobSpec.DecorationConfig.GCSConfiguration == nil || jobSpec.DecorationConfig.GCSConfiguration.Bucket == "" {
+		return ""
+	}
+	config := jobSpec.DecorationConfig.GCSConfiguration
+	var builder gcs.RepoPathBuilder
+	switch config.PathStrategy {
+	case prowapi.PathStrategyLegacy:
+		builder = gcs.NewLegacyRepoPathBuilder(config.DefaultOrg, config.DefaultRepo)
+	case prowapi.PathStrategySingle:
+		builder = gcs.NewSingleDefaultRepoPathBuilder(config.DefaultOrg, config.DefaultRepo)
+	default:
+		builder = gcs.NewExplicitRepoPathBuilder()
+	}
+	bucket := config.Bucket
+	if !strings.Contains(bucket, "://") {
+		bucket = "gs://" + bucket
+	}
+	bucket = strings.Replace(bucket, "://", "/", 1)
+	return jobURLPrefix + path.Join(bucket, config.PathPrefix, gcs.PathForSpec(&jobSpec.JobSpec, builder))
+}
+
+// promotedCommit is the commit of the repository the promoted images were built from
+func promotedCommit(jobSpec *api.JobSpec) string {
+	if jobSpec.Refs == nil {
+		return ""
+	}
+	return jobSpec.Refs.BaseSHA
+}
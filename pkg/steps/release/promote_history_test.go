@@ -0,0 +1,125 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	prowapi "k8s.io/test-infra/prow/apis/prowjobs/v1"
+	"k8s.io/test-infra/prow/pod-utils/downwardapi"
+	ctrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakectrlruntimeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/api/promotionhistory"
+)
+
+func init() {
+	if err := imagev1.AddToScheme(scheme.Scheme); err != nil {
+		panic(fmt.Sprintf("failed to add imagev1 to scheme: %v", err))
+	}
+}
+
+func streamWithTags(namespace, name string, digests map[string]string) *imagev1.ImageStream {
+	stream := &imagev1.ImageStream{ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: name}}
+	for _, tag := range sortedKeys(digests) {
+		stream.Status.Tags = append(stream.Status.Tags, imagev1.NamedTagEventList{
+			Tag:   tag,
+			Items: []imagev1.TagEvent{{Image: digests[tag]}},
+		})
+	}
+	return stream
+}
+
+func TestRecordHistory(t *testing.T) {
+	jobSpec := &api.JobSpec{JobSpec: downwardapi.JobSpec{
+		Type:    prowapi.PostsubmitJob,
+		Job:     "branch-ci-org-repo-master-images",
+		BuildID: "1",
+		Refs:    &prowapi.Refs{Org: "org", Repo: "repo", BaseRef: "master", BaseSHA: "abcdef"},
+	}}
+	step := &promotionStep{
+		configuration:  &api.ReleaseBuildConfiguration{PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ocp", Name: "4.9"}},
+		jobSpec:        jobSpec,
+		registryClient: fakectrlruntimeclient.NewFakeClient(streamWithTags("ocp", "4.9", map[string]string{"foo": "sha256:old", "bar": "sha256:bar"})),
+	}
+	tags := map[string]api.ImageStreamTagReference{
+		"foo": {Namespace: "ocp", Name: "4.9", Tag: "foo"},
+		"bar": {Namespace: "ocp", Name: "4.9", Tag: "bar"},
+		"baz": {Namespace: "ocp", Name: "4.9", Tag: "baz"},
+	}
+	ctx := context.Background()
+	previous := step.previousDigests(ctx, tags)
+	pipeline := streamWithTags("ns", api.PipelineImageStream, map[string]string{"foo": "sha256:new", "bar": "sha256:bar", "baz": "sha256:baz"})
+	if err := step.recordHistory(ctx, tags, pipeline, previous); err != nil {
+		t.Fatalf("failed to record history: %v", err)
+	}
+
+	stream := &imagev1.ImageStream{}
+	if err := step.registryClient.Get(ctx, ctrlruntimeclient.ObjectKey{Namespace: "ocp", Name: "4.9"}, stream); err != nil {
+		t.Fatalf("failed to get stream: %v", err)
+	}
+	history, err := promotionhistory.History(stream)
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	expected := map[string][]promotionhistory.Record{
+		"foo": {{Digest: "sha256:new", PreviousDigest: "sha256:old", Commit: "abcdef"}},
+		"baz": {{Digest: "sha256:baz", Commit: "abcdef"}},
+	}
+	if diff := cmp.Diff(expected, history, cmpopts.IgnoreTypes(time.Time{})); diff != "" {
+		t.Errorf("unexpected history: %s", diff)
+	}
+}
+
+func TestJobURL(t *testing.T) {
+	spec := func(strategy, bucket string) *api.JobSpec {
+		return &api.JobSpec{JobSpec: downwardapi.JobSpec{
+			Type:    prowapi.PostsubmitJob,
+			Job:     "branch-ci-openshift-ci-tools-master-images",
+			BuildID: "1234",
+			Refs:    &prowapi.Refs{Org: "openshift", Repo: "ci-tools", BaseRef: "master"},
+			DecorationConfig: &prowapi.DecorationConfig{GCSConfiguration: &prowapi.GCSConfiguration{
+				Bucket:       bucket,
+				PathStrategy: strategy,
+				DefaultOrg:   "openshift",
+				DefaultRepo:  "origin",
+			}},
+		}}
+	}
+	testCases := []struct {
+		name     string
+		jobSpec  *api.JobSpec
+		expected string
+	}{
+		{
+			name:    "no decoration",
+			jobSpec: &api.JobSpec{},
+		},
+		{
+			name:     "single strategy",
+			jobSpec:  spec(prowapi.PathStrategySingle, "origin-ci-test"),
+			expected: "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/branch-ci-openshift-ci-tools-master-images/1234",
+		},
+		{
+			name:     "explicit strategy with scheme",
+			jobSpec:  spec(prowapi.PathStrategyExplicit, "gs://origin-ci-test"),
+			expected: "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/branch-ci-openshift-ci-tools-master-images/1234",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, jobURL(tc.jobSpec)); diff != "" {
+				t.Errorf("unexpected URL: %s", diff)
+			}
+		})
+	}
+}
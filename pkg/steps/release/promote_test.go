@@ -457,6 +457,66 @@ func TestPromotedTagsWithRequiredImages(t *testing.T) {
 	}
 }
 
+func TestPromotionPlan(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		config         *api.ReleaseBuildConfiguration
+		requiredImages sets.String
+		expected       map[string][]string
+	}{
+		{
+			name: "no promotion",
+			config: &api.ReleaseBuildConfiguration{
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{{To: "foo"}},
+			},
+		},
+		{
+			name: "disabled promotion",
+			config: &api.ReleaseBuildConfiguration{
+				PromotionConfiguration: &api.PromotionConfiguration{Namespace: "ci", Name: "4.8", Disabled: true},
+				Images:                 []api.ProjectDirectoryImageBuildStepConfiguration{{To: "foo"}},
+			},
+		},
+		{
+			name: "promotion to the integration stream and external destinations",
+			config: &api.ReleaseBuildConfiguration{
+				Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+				PromotionConfiguration: &api.PromotionConfiguration{
+					Namespace:        "ci",
+					Name:             "4.8",
+					ExcludedImages:   []string{"excluded"},
+					AdditionalImages: map[string]string{"extra": "src"},
+					ExternalDestinations: []api.ExternalPromotionDestination{
+						{Repository: "quay.io/org", Credentials: "quay"},
+						{Repository: "registry.example.com/mirror", Credentials: "example"},
+					},
+				},
+				BinaryBuildCommands: "make",
+				Images: []api.ProjectDirectoryImageBuildStepConfiguration{
+					{To: "foo"},
+					{To: "excluded"},
+					{To: "optional", Optional: true},
+					{To: "required", Optional: true},
+				},
+			},
+			requiredImages: sets.NewString("required"),
+			expected: map[string][]string{
+				"foo":      {"registry.ci.openshift.org/ci/4.8:foo", "quay.io/org/4.8:foo", "registry.example.com/mirror/4.8:foo"},
+				"required": {"registry.ci.openshift.org/ci/4.8:required", "quay.io/org/4.8:required", "registry.example.com/mirror/4.8:required"},
+				"src":      {"registry.ci.openshift.org/ci/4.8:extra", "quay.io/org/4.8:extra", "registry.example.com/mirror/4.8:extra"},
+				"bin":      {"registry.ci.openshift.org/build-cache/org-repo:master"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if diff := cmp.Diff(testCase.expected, PromotionPlan(testCase.config, testCase.requiredImages)); diff != "" {
+				t.Errorf("unexpected promotion plan: %s", diff)
+			}
+		})
+	}
+}
+
 func TestBuildCacheFor(t *testing.T) {
 	var testCases = []struct {
 		input  api.Metadata
@@ -499,6 +559,8 @@ func TestGetPromotionPod(t *testing.T) {
 	var testCases = []struct {
 		name        string
 		imageMirror map[string]string
+		external    []externalMirror
+		signing     *api.PromotionSigningConfiguration
 		namespace   string
 		expected    *coreapi.Pod
 	}{
@@ -510,11 +572,42 @@ func TestGetPromotionPod(t *testing.T) {
 			},
 			namespace: "ci-op-zyvwvffx",
 		},
+		{
+			name: "external destinations",
+			imageMirror: map[string]string{
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": "registy.ci.openshift.org/ci/bin:latest",
+			},
+			external: []externalMirror{
+				{
+					secret: "promotion-external-credentials-0",
+					images: map[string]string{"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": "quay.io/org/bin:latest"},
+				},
+				{
+					secret: "promotion-external-credentials-1",
+					images: map[string]string{"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": "quay.io/other/bin:latest"},
+				},
+			},
+			namespace: "ci-op-zyvwvffx",
+		},
+		{
+			name: "signed external destination with SBOM",
+			imageMirror: map[string]string{
+				"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": "registy.ci.openshift.org/ci/bin:latest",
+			},
+			external: []externalMirror{
+				{
+					secret: "promotion-external-credentials-0",
+					images: map[string]string{"docker-registry.default.svc:5000/ci-op-y2n8rsh3/pipeline@sha256:bbb": "quay.io/org/bin:latest"},
+				},
+			},
+			signing:   &api.PromotionSigningConfiguration{KeySecret: "cosign", SBOMPath: "/usr/share/buildinfo/sbom.spdx.json"},
+			namespace: "ci-op-zyvwvffx",
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			testhelper.CompareWithFixture(t, getPromotionPod(testCase.imageMirror, testCase.namespace))
+			testhelper.CompareWithFixture(t, getPromotionPod(testCase.imageMirror, testCase.external, testCase.signing, testCase.namespace))
 		})
 	}
 }
@@ -3,6 +3,7 @@ package steps
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -123,6 +124,10 @@ func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 	}
 	duration := time.Since(start)
 	failed := err != nil
+	var reason string
+	if failed {
+		reason = strings.Join(results.Reasons(err), ",")
+	}
 	finishedAt := start.Add(duration)
 
 	var subSteps []api.CIOperatorStepDetailInfo
@@ -144,6 +149,7 @@ func runStep(ctx context.Context, node *api.StepNode, out chan<- message) {
 				Duration:    &duration,
 				Manifests:   node.Step.Objects(),
 				Failed:      &failed,
+				Reason:      reason,
 			},
 			Substeps: subSteps,
 		},
@@ -3,6 +3,7 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -77,7 +78,8 @@ func validateConfiguration(config *api.ReleaseBuildConfiguration, org, repo stri
 		validationErrors = append(validationErrors, validatePromotionConfiguration("promotion", *config.PromotionConfiguration)...)
 	}
 
-	validationErrors = append(validationErrors, validateReleases("releases", config.Releases, config.ReleaseTagConfiguration != nil)...)
+	validationErrors = append(validationErrors, validateReleases("releases", config.Releases, config.ReleaseTagConfiguration != nil, config.Metadata.Branch)...)
+	validationErrors = append(validationErrors, validateReleaseDiffs("release_diffs", config.ReleaseDiffs, config.Releases, config.ReleaseTagConfiguration != nil)...)
 
 	var lines []string
 	for _, err := range validationErrors {
@@ -162,6 +164,51 @@ func validateImages(fieldRoot string, input []api.ProjectDirectoryImageBuildStep
 			validationErrors = append(validationErrors, fmt.Errorf("%s: dockerfile_literal is mutually exclusive with context_dir and dockerfile_path", fieldRootN))
 		}
 	}
+	validationErrors = append(validationErrors, validateImageArchitectures(fieldRoot, input)...)
+	return validationErrors
+}
+
+// imageArchitectures are the architectures images can be built for
+var imageArchitectures = sets.NewString(string(api.ReleaseArchitectureAMD64), string(api.ReleaseArchitectureARM64), string(api.ReleaseArchitecturePPC64le), string(api.ReleaseArchitectureS390x))
+
+// validateImageArchitectures ensures multi-architecture images can be built:
+// images they are built from need to exist for every architecture as well
+// and the tags holding the single-architecture images must not be taken
+func validateImageArchitectures(fieldRoot string, input []api.ProjectDirectoryImageBuildStepConfiguration) []error {
+	var validationErrors []error
+	architecturesByImage := map[api.PipelineImageStreamTagReference]sets.String{}
+	for _, image := range input {
+		architectures := sets.NewString()
+		for _, architecture := range image.Architectures {
+			architectures.Insert(string(architecture))
+		}
+		architecturesByImage[image.To] = architectures
+	}
+	for num, image := range input {
+		fieldRootN := fmt.Sprintf("%s[%d]", fieldRoot, num)
+		seen := sets.NewString()
+		for i, architecture := range image.Architectures {
+			fieldRootArch := fmt.Sprintf("%s.architectures[%d]", fieldRootN, i)
+			if !imageArchitectures.Has(string(architecture)) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: must be one of %s", fieldRootArch, strings.Join(imageArchitectures.List(), ", ")))
+			}
+			if seen.Has(string(architecture)) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: duplicate architecture %s", fieldRootArch, architecture))
+			}
+			seen.Insert(string(architecture))
+			if _, taken := architecturesByImage[api.ArchitectureTag(image.To, architecture)]; taken {
+				validationErrors = append(validationErrors, fmt.Errorf("%s: the %s image of %s conflicts with the image named %s", fieldRootArch, architecture, image.To, api.ArchitectureTag(image.To, architecture)))
+			}
+		}
+		if len(image.Architectures) == 0 {
+			continue
+		}
+		if from, built := architecturesByImage[image.From]; built {
+			if missing := seen.Difference(from); missing.Len() > 0 {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.architectures: image %s is built from %s, which is not built for %s", fieldRootN, image.To, image.From, strings.Join(missing.List(), ", ")))
+			}
+		}
+	}
 	return validationErrors
 }
 
@@ -188,6 +235,9 @@ func validateOperator(fieldRoot string, input *api.OperatorStepConfiguration, li
 				validationErrors = append(validationErrors, fmt.Errorf("%s.update_graph: update_graph must be %s, %s, or %s", fieldRootN, api.IndexUpdateSemver, api.IndexUpdateSemverSkippatch, api.IndexUpdateReplaces))
 			}
 		}
+		if bundle.Validation != nil && bundle.Validation.SelectOptional != "" && !strings.Contains(bundle.Validation.SelectOptional, "=") {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.validation.select_optional: must be a label selector like suite=operatorframework", fieldRootN))
+		}
 	}
 	for num, sub := range input.Substitutions {
 		fieldRootN := fmt.Sprintf("%s.substitute[%d]", fieldRoot, num)
@@ -249,9 +299,51 @@ func validatePromotionConfiguration(fieldRoot string, input api.PromotionConfigu
 	if len(input.Name) != 0 && len(input.Tag) != 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s: both name and tag defined", fieldRoot))
 	}
+
+	repositories := sets.NewString()
+	for i, destination := range input.ExternalDestinations {
+		field := fmt.Sprintf("%s.external_destinations[%d]", fieldRoot, i)
+		if err := validateExternalRepository(destination.Repository); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.repository: %v", field, err))
+		} else if repositories.Has(destination.Repository) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.repository: %s is already a destination", field, destination.Repository))
+		}
+		repositories.Insert(destination.Repository)
+		if len(destination.Credentials) == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.credentials: no credentials defined", field))
+		}
+	}
+
+	if input.Signing != nil {
+		if len(input.ExternalDestinations) == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.signing: only images pushed to external destinations can be signed", fieldRoot))
+		}
+		if len(input.Signing.KeySecret) == 0 {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.signing.key_secret: no key secret defined", fieldRoot))
+		}
+		if len(input.Signing.SBOMPath) != 0 && !filepath.IsAbs(input.Signing.SBOMPath) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.signing.sbom_path: %s is not absolute", fieldRoot, input.Signing.SBOMPath))
+		}
+	}
 	return validationErrors
 }
 
+// validateExternalRepository ensures the repository is a prefix that
+// image names and tags can be appended to
+func validateExternalRepository(repository string) error {
+	switch {
+	case len(repository) == 0:
+		return errors.New("no repository defined")
+	case strings.Contains(repository, "://"):
+		return fmt.Errorf("%s must not contain a scheme", repository)
+	case !strings.Contains(repository, "/") || strings.HasSuffix(repository, "/"):
+		return fmt.Errorf("%s must be in the <registry>/<organization> form", repository)
+	case strings.ContainsAny(repository[strings.LastIndex(repository, "/"):], ":@"):
+		return fmt.Errorf("%s must not contain a tag or digest", repository)
+	}
+	return nil
+}
+
 func validateReleaseTagConfiguration(fieldRoot string, input api.ReleaseTagConfiguration) []error {
 	var validationErrors []error
 
@@ -287,6 +287,49 @@ func TestValidatePromotion(t *testing.T) {
 			input:    api.PromotionConfiguration{Namespace: "foo", Name: "bar", Tag: "baz"},
 			expected: []error{errors.New("promotion: both name and tag defined")},
 		},
+		{
+			name: "signed external destinations are valid",
+			input: api.PromotionConfiguration{
+				Namespace:            "foo",
+				Name:                 "bar",
+				ExternalDestinations: []api.ExternalPromotionDestination{{Repository: "quay.io/org", Credentials: "quay-push"}},
+				Signing:              &api.PromotionSigningConfiguration{KeySecret: "cosign", SBOMPath: "/usr/share/sbom.spdx.json"},
+			},
+		},
+		{
+			name: "invalid external destinations yield errors",
+			input: api.PromotionConfiguration{
+				Namespace: "foo",
+				Name:      "bar",
+				ExternalDestinations: []api.ExternalPromotionDestination{
+					{Repository: "quay.io/org", Credentials: "quay-push"},
+					{Repository: "quay.io/org"},
+					{Repository: "https://quay.io/org", Credentials: "quay-push"},
+					{Repository: "quay.io", Credentials: "quay-push"},
+					{Repository: "quay.io/org/repo:latest", Credentials: "quay-push"},
+				},
+			},
+			expected: []error{
+				errors.New("promotion.external_destinations[1].repository: quay.io/org is already a destination"),
+				errors.New("promotion.external_destinations[1].credentials: no credentials defined"),
+				errors.New("promotion.external_destinations[2].repository: https://quay.io/org must not contain a scheme"),
+				errors.New("promotion.external_destinations[3].repository: quay.io must be in the <registry>/<organization> form"),
+				errors.New("promotion.external_destinations[4].repository: quay.io/org/repo:latest must not contain a tag or digest"),
+			},
+		},
+		{
+			name: "invalid signing configuration yields errors",
+			input: api.PromotionConfiguration{
+				Namespace: "foo",
+				Name:      "bar",
+				Signing:   &api.PromotionSigningConfiguration{SBOMPath: "sbom.json"},
+			},
+			expected: []error{
+				errors.New("promotion.signing: only images pushed to external destinations can be signed"),
+				errors.New("promotion.signing.key_secret: no key secret defined"),
+				errors.New("promotion.signing.sbom_path: sbom.json is not absolute"),
+			},
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
@@ -398,6 +441,44 @@ func TestValidateImages(t *testing.T) {
 				errors.New("images[0]: dockerfile_literal is mutually exclusive with context_dir and dockerfile_path"),
 			},
 		},
+		{
+			name: "multi-architecture images are valid",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "base", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64}},
+				{To: "amsterdam", From: "base", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureARM64}},
+			},
+		},
+		{
+			name: "invalid and duplicate architectures",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{{
+				To:            "amsterdam",
+				Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureARM64, "sparc", api.ReleaseArchitectureARM64},
+			}},
+			output: []error{
+				errors.New("images[0].architectures[1]: must be one of amd64, arm64, ppc64le, s390x"),
+				errors.New("images[0].architectures[2]: duplicate architecture arm64"),
+			},
+		},
+		{
+			name: "architecture image conflicts with another image",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "amsterdam", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureARM64}},
+				{To: "amsterdam-arm64"},
+			},
+			output: []error{
+				errors.New("images[0].architectures[0]: the arm64 image of amsterdam conflicts with the image named amsterdam-arm64"),
+			},
+		},
+		{
+			name: "multi-architecture image built from an image missing an architecture",
+			input: []api.ProjectDirectoryImageBuildStepConfiguration{
+				{To: "base"},
+				{To: "amsterdam", From: "base", Architectures: []api.ReleaseArchitecture{api.ReleaseArchitectureAMD64, api.ReleaseArchitectureARM64}},
+			},
+			output: []error{
+				errors.New("images[1].architectures: image amsterdam is built from base, which is not built for amd64, arm64"),
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -551,6 +632,19 @@ func TestValidateOperator(t *testing.T) {
 				errors.New("operator.bundles[0].update_graph: update_graph must be semver, semver-skippatch, or replaces"),
 			},
 		},
+		{
+			name: "invalid validation selector",
+			input: &api.OperatorStepConfiguration{
+				Bundles: []api.Bundle{{
+					As:         "valid bundle",
+					Validation: &api.BundleValidation{SelectOptional: "operatorframework", Scorecard: true},
+				}},
+			},
+			withResolvesTo: goodStepLink,
+			output: []error{
+				errors.New("operator.bundles[0].validation.select_optional: must be a label selector like suite=operatorframework"),
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -3,14 +3,17 @@ package validation
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/blang/semver"
+
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/openshift/ci-tools/pkg/api"
 )
 
-func validateReleases(fieldRoot string, releases map[string]api.UnresolvedRelease, hasTagSpec bool) []error {
+func validateReleases(fieldRoot string, releases map[string]api.UnresolvedRelease, hasTagSpec bool, branch string) []error {
 	var validationErrors []error
 	// we need a deterministic iteration for testing
 	names := sets.NewString()
@@ -38,7 +41,7 @@ func validateReleases(fieldRoot string, releases map[string]api.UnresolvedReleas
 		} else if set == 0 {
 			validationErrors = append(validationErrors, fmt.Errorf("%s.%s: must set candidate, prerelease or release", fieldRoot, name))
 		} else if release.Candidate != nil {
-			validationErrors = append(validationErrors, validateCandidate(fmt.Sprintf("%s.%s", fieldRoot, name), *release.Candidate)...)
+			validationErrors = append(validationErrors, validateCandidate(fmt.Sprintf("%s.%s", fieldRoot, name), *release.Candidate, branch)...)
 		} else if release.Release != nil {
 			validationErrors = append(validationErrors, validateRelease(fmt.Sprintf("%s.%s", fieldRoot, name), *release.Release)...)
 		} else if release.Prerelease != nil {
@@ -48,9 +51,40 @@ func validateReleases(fieldRoot string, releases map[string]api.UnresolvedReleas
 	return validationErrors
 }
 
+func validateReleaseDiffs(fieldRoot string, diffs []api.ReleaseDiff, releases map[string]api.UnresolvedRelease, hasTagSpec bool) []error {
+	var validationErrors []error
+	available := sets.NewString()
+	for name := range releases {
+		available.Insert(name)
+	}
+	if hasTagSpec {
+		available.Insert(api.InitialReleaseName, api.LatestReleaseName)
+	}
+	seen := sets.NewString()
+	for i, diff := range diffs {
+		fieldRoot := fmt.Sprintf("%s[%d]", fieldRoot, i)
+		for _, field := range []struct{ name, release string }{{name: "from", release: diff.From}, {name: "to", release: diff.To}} {
+			if field.release == "" {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.%s: must be set", fieldRoot, field.name))
+			} else if !available.Has(field.release) {
+				validationErrors = append(validationErrors, fmt.Errorf("%s.%s: release %q is not defined in releases or by tag_specification", fieldRoot, field.name, field.release))
+			}
+		}
+		if diff.From != "" && diff.From == diff.To {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: cannot compare release %q with itself", fieldRoot, diff.From))
+		}
+		key := fmt.Sprintf("%s..%s", diff.From, diff.To)
+		if seen.Has(key) {
+			validationErrors = append(validationErrors, fmt.Errorf("%s: releases %q and %q are already compared", fieldRoot, diff.From, diff.To))
+		}
+		seen.Insert(key)
+	}
+	return validationErrors
+}
+
 var minorVersionMatcher = regexp.MustCompile(`[0-9]\.[0-9]+`)
 
-func validateCandidate(fieldRoot string, candidate api.Candidate) []error {
+func validateCandidate(fieldRoot string, candidate api.Candidate, branch string) []error {
 	var validationErrors []error
 	if err := validateProduct(fmt.Sprintf("%s.product", fieldRoot), candidate.Product); err != nil {
 		validationErrors = append(validationErrors, err)
@@ -72,14 +106,41 @@ func validateCandidate(fieldRoot string, candidate api.Candidate) []error {
 		validationErrors = append(validationErrors, fmt.Errorf("%s.stream: must be one of %s", fieldRoot, strings.Join(streamsByProduct[candidate.Product].List(), ", ")))
 	}
 
-	if err := validateVersion(fmt.Sprintf("%s.version", fieldRoot), candidate.Version); err != nil {
+	version := candidate.Version
+	if version == "" {
+		// we allow an unset version for branches that track one, we will default it later
+		if branchVersion, ok := api.VersionForBranch(branch); ok {
+			version = branchVersion
+		} else {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.version: must be set when the branch %q does not track a minor version", fieldRoot, branch))
+		}
+	} else if err := validateVersion(fmt.Sprintf("%s.version", fieldRoot), version); err != nil {
 		validationErrors = append(validationErrors, err)
+		version = ""
+	}
+
+	if candidate.RelativeMinor < 0 {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.relative_minor: must be a positive integer", fieldRoot))
+	} else if version != "" {
+		if minor, err := strconv.Atoi(version[strings.LastIndex(version, ".")+1:]); err == nil && candidate.RelativeMinor > minor {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.relative_minor: must not be greater than the minor version of %s", fieldRoot, version))
+		}
 	}
 
 	if candidate.Relative < 0 {
 		validationErrors = append(validationErrors, fmt.Errorf("%s.relative: must be a positive integer", fieldRoot))
 	}
 
+	stream := candidate.Stream
+	if candidate.Product == api.ReleaseProductOKD && stream == "" {
+		stream = api.ReleaseStreamOKD
+	}
+	if candidate.MaxAgeDays < 0 {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.max_age_days: must be a positive integer", fieldRoot))
+	} else if candidate.MaxAgeDays > 0 && !stream.HasTimestampedNames() {
+		validationErrors = append(validationErrors, fmt.Errorf("%s.max_age_days: cannot be set for the %q stream, as the names of its releases do not record when they were created", fieldRoot, stream))
+	}
+
 	return validationErrors
 }
 
@@ -142,10 +203,20 @@ func validatePrerelease(fieldRoot string, prerelease api.Prerelease) []error {
 		}
 	}
 
-	if prerelease.VersionBounds.Lower == "" {
+	bounds := prerelease.VersionBounds
+	if bounds.Range != "" {
+		if bounds.Lower != "" || bounds.Upper != "" {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.version_bounds: cannot set range together with lower and upper", fieldRoot))
+		}
+		if _, err := semver.ParseRange(bounds.Range); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s.version_bounds.range: must be a semantic version range: %v", fieldRoot, err))
+		}
+		return validationErrors
+	}
+	if bounds.Lower == "" {
 		validationErrors = append(validationErrors, fmt.Errorf("%s.version_bounds.lower: must be set", fieldRoot))
 	}
-	if prerelease.VersionBounds.Upper == "" {
+	if bounds.Upper == "" {
 		validationErrors = append(validationErrors, fmt.Errorf("%s.version_bounds.upper: must be set", fieldRoot))
 	}
 
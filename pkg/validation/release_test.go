@@ -141,7 +141,63 @@ func TestValidateReleases(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			if actual, expected := validateReleases("root", testCase.input, testCase.hasTagSpec), testCase.output; !reflect.DeepEqual(actual, expected) {
+			if actual, expected := validateReleases("root", testCase.input, testCase.hasTagSpec, "master"), testCase.output; !reflect.DeepEqual(actual, expected) {
+				t.Errorf("%s: got incorrect errors: %s", testCase.name, cmp.Diff(actual, expected, cmp.Comparer(func(x, y error) bool {
+					return x.Error() == y.Error()
+				})))
+			}
+		})
+	}
+}
+
+func TestValidateReleaseDiffs(t *testing.T) {
+	releases := map[string]api.UnresolvedRelease{"previous": {Release: &api.Release{Version: "4.7", Channel: api.ReleaseChannelStable}}}
+	var testCases = []struct {
+		name       string
+		input      []api.ReleaseDiff
+		hasTagSpec bool
+		output     []error
+	}{
+		{
+			name:       "valid diffs",
+			input:      []api.ReleaseDiff{{From: "initial", To: "latest"}, {From: "previous", To: "latest"}},
+			hasTagSpec: true,
+		},
+		{
+			name:  "releases from tag_specification without one",
+			input: []api.ReleaseDiff{{From: "initial", To: "latest"}},
+			output: []error{
+				errors.New(`root[0].from: release "initial" is not defined in releases or by tag_specification`),
+				errors.New(`root[0].to: release "latest" is not defined in releases or by tag_specification`),
+			},
+		},
+		{
+			name:  "missing releases",
+			input: []api.ReleaseDiff{{To: "previous"}},
+			output: []error{
+				errors.New("root[0].from: must be set"),
+			},
+		},
+		{
+			name:  "release compared with itself",
+			input: []api.ReleaseDiff{{From: "previous", To: "previous"}},
+			output: []error{
+				errors.New(`root[0]: cannot compare release "previous" with itself`),
+			},
+		},
+		{
+			name:       "duplicate diff",
+			input:      []api.ReleaseDiff{{From: "initial", To: "latest"}, {From: "initial", To: "latest"}},
+			hasTagSpec: true,
+			output: []error{
+				errors.New(`root[1]: releases "initial" and "latest" are already compared`),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual, expected := validateReleaseDiffs("root", testCase.input, releases, testCase.hasTagSpec), testCase.output; !reflect.DeepEqual(actual, expected) {
 				t.Errorf("%s: got incorrect errors: %s", testCase.name, cmp.Diff(actual, expected, cmp.Comparer(func(x, y error) bool {
 					return x.Error() == y.Error()
 				})))
@@ -154,6 +210,7 @@ func TestValidateCandidate(t *testing.T) {
 	var testCases = []struct {
 		name   string
 		input  api.Candidate
+		branch string
 		output []error
 	}{
 		{
@@ -263,11 +320,79 @@ func TestValidateCandidate(t *testing.T) {
 				errors.New("root.relative: must be a positive integer"),
 			},
 		},
+		{
+			name: "valid candidate with version of the branch, relative minor and max age",
+			input: api.Candidate{
+				Product:       api.ReleaseProductOCP,
+				Stream:        api.ReleaseStreamNightly,
+				RelativeMinor: 1,
+				MaxAgeDays:    7,
+			},
+			branch: "release-4.9",
+		},
+		{
+			name: "missing version on a branch without one",
+			input: api.Candidate{
+				Product: api.ReleaseProductOCP,
+				Stream:  api.ReleaseStreamNightly,
+			},
+			branch: "master",
+			output: []error{
+				errors.New(`root.version: must be set when the branch "master" does not track a minor version`),
+			},
+		},
+		{
+			name: "invalid relative minor and max age",
+			input: api.Candidate{
+				Product:       api.ReleaseProductOCP,
+				Stream:        api.ReleaseStreamCI,
+				Version:       "4.4",
+				RelativeMinor: 5,
+				MaxAgeDays:    -1,
+			},
+			output: []error{
+				errors.New("root.relative_minor: must not be greater than the minor version of 4.4"),
+				errors.New("root.max_age_days: must be a positive integer"),
+			},
+		},
+		{
+			name: "max age for a stream without timestamped release names",
+			input: api.Candidate{
+				Product:    api.ReleaseProductOCP,
+				Stream:     "stable",
+				Version:    "4.4",
+				MaxAgeDays: 7,
+			},
+			output: []error{
+				errors.New("root.stream: must be one of ci, nightly"),
+				errors.New(`root.max_age_days: cannot be set for the "stable" stream, as the names of its releases do not record when they were created`),
+			},
+		},
+		{
+			name: "max age for the default OKD stream",
+			input: api.Candidate{
+				Product:    api.ReleaseProductOKD,
+				Version:    "4.4",
+				MaxAgeDays: 7,
+			},
+		},
+		{
+			name: "negative relative minor",
+			input: api.Candidate{
+				Product:       api.ReleaseProductOCP,
+				Stream:        api.ReleaseStreamCI,
+				Version:       "4.4",
+				RelativeMinor: -1,
+			},
+			output: []error{
+				errors.New("root.relative_minor: must be a positive integer"),
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			if actual, expected := validateCandidate("root", testCase.input), testCase.output; !reflect.DeepEqual(actual, expected) {
+			if actual, expected := validateCandidate("root", testCase.input, testCase.branch), testCase.output; !reflect.DeepEqual(actual, expected) {
 				t.Errorf("%s: got incorrect errors: %s", testCase.name, cmp.Diff(actual, expected, cmp.Comparer(func(x, y error) bool {
 					return x.Error() == y.Error()
 				})))
@@ -422,6 +547,33 @@ func TestValidatePrerelease(t *testing.T) {
 				errors.New("root.version_bounds.upper: must be set"),
 			},
 		},
+		{
+			name: "valid prerelease with a version range",
+			input: api.Prerelease{
+				Product:       api.ReleaseProductOCP,
+				VersionBounds: api.VersionBounds{Range: ">=4.8.0 <4.8.10 || >4.8.12 <4.9.0"},
+			},
+		},
+		{
+			name: "invalid prerelease with a version range and bounds",
+			input: api.Prerelease{
+				Product:       api.ReleaseProductOCP,
+				VersionBounds: api.VersionBounds{Lower: "4.1.0", Range: ">=4.8.0 <4.9.0"},
+			},
+			output: []error{
+				errors.New("root.version_bounds: cannot set range together with lower and upper"),
+			},
+		},
+		{
+			name: "invalid prerelease with a malformed version range",
+			input: api.Prerelease{
+				Product:       api.ReleaseProductOCP,
+				VersionBounds: api.VersionBounds{Range: ">=4.8"},
+			},
+			output: []error{
+				errors.New(`root.version_bounds.range: must be a semantic version range: Could not parse Range ">=4.8": Could not parse version "4.8" in ">=4.8": No Major.Minor.Patch elements found`),
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,275 @@
+package webreg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// APIPrefix is the path under which the versioned JSON API is served
+const APIPrefix = "/api/v1/"
+
+const (
+	referencesPath = "references"
+	chainsPath     = "chains"
+	workflowsPath  = "workflows"
+)
+
+// Generations lists the generations of the configs and the registry retained by the server, newest first
+type Generations struct {
+	Config   []load.Generation `json:"config"`
+	Registry []load.Generation `json:"registry"`
+}
+
+// RegistryElement is a step, chain or workflow of the registry as served by the API.
+// The definition of the element is only set when a single element is requested.
+type RegistryElement struct {
+	Name          string           `json:"name"`
+	Type          string           `json:"type"`
+	Documentation string           `json:"documentation,omitempty"`
+	Metadata      api.RegistryInfo `json:"metadata"`
+
+	Reference *api.LiteralTestStep             `json:"reference,omitempty"`
+	Chain     *api.RegistryChain               `json:"chain,omitempty"`
+	Workflow  *api.MultiStageTestConfiguration `json:"workflow,omitempty"`
+}
+
+// JobReference identifies a multi-stage test in a ci-operator config
+type JobReference struct {
+	api.Metadata `json:",inline"`
+	Test         string `json:"test"`
+}
+
+// APIError is the body of every unsuccessful API response
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// APIHandler serves the versioned JSON API for configs and the step registry:
+//
+//	GET /api/v1/generations                                      lists the retained generations of the configs and the registry
+//	GET /api/v1/configs?org=&repo=                               lists the configs, optionally for an org or repo
+//	GET /api/v1/configs/unresolved?org=&repo=&branch=&variant=   returns a config as written
+//	GET /api/v1/configs/resolved?org=&repo=&branch=&variant=     returns a config resolved with the registry
+//	GET /api/v1/registry/{references,chains,workflows}           lists the registry elements of the type
+//	GET /api/v1/registry/{references,chains,workflows}/NAME      returns a registry element
+//	GET /api/v1/registry/{references,chains,workflows}/NAME/jobs lists the tests using a registry element
+//
+// Configs are served as of a retained generation when the configGeneration, registryGeneration
+// or sha queries are set, with the generations used recorded in the response headers.
+func APIHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeAPIError(w, fmt.Errorf("expected GET, got %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		trimmedPath := strings.Trim(strings.TrimPrefix(req.URL.Path, APIPrefix), "/")
+		splitURI := strings.Split(trimmedPath, "/")
+		switch {
+		case splitURI[0] == "generations" && len(splitURI) == 1:
+			writeAPIResponse(w, Generations{Config: confAgent.GetGenerations(), Registry: regAgent.GetGenerations()})
+		case splitURI[0] == "configs":
+			configsAPIHandler(regAgent, confAgent, splitURI[1:], w, req)
+		case splitURI[0] == "registry" && len(splitURI) > 1:
+			registryAPIHandler(regAgent, confAgent, splitURI[1:], w)
+		default:
+			writeAPIError(w, fmt.Errorf("invalid path %s", req.URL.Path), http.StatusNotFound)
+		}
+	}
+}
+
+func configsAPIHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, path []string, w http.ResponseWriter, req *http.Request) {
+	if len(path) == 0 {
+		writeAPIResponse(w, listConfigs(confAgent, req.URL.Query().Get(OrgQuery), req.URL.Query().Get(RepoQuery)))
+		return
+	}
+	if len(path) != 1 || (path[0] != "unresolved" && path[0] != "resolved") {
+		writeAPIError(w, fmt.Errorf("invalid path %s", req.URL.Path), http.StatusNotFound)
+		return
+	}
+	metadata, err := apiMetadataFromQuery(req)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	configRevision, registryRevision := load.RevisionsFromQuery(req.URL.Query())
+	config, generation, err := confAgent.GetMatchingConfigAt(metadata, configRevision)
+	if err != nil {
+		writeAPIError(w, fmt.Errorf("failed to get config: %w", err), http.StatusNotFound)
+		return
+	}
+	load.SetGenerationHeaders(w.Header(), load.ConfigGenerationHeader, load.ConfigSHAHeader, generation)
+	if path[0] == "resolved" {
+		if config, generation, err = regAgent.ResolveConfigAt(config, registryRevision); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, agents.ErrGenerationNotRetained) {
+				status = http.StatusNotFound
+			}
+			writeAPIError(w, fmt.Errorf("failed to resolve config with registry: %w", err), status)
+			return
+		}
+		load.SetGenerationHeaders(w.Header(), load.RegistryGenerationHeader, load.RegistrySHAHeader, generation)
+	}
+	writeAPIResponse(w, config)
+}
+
+func apiMetadataFromQuery(req *http.Request) (api.Metadata, error) {
+	query := req.URL.Query()
+	metadata := api.Metadata{
+		Org:     query.Get(OrgQuery),
+		Repo:    query.Get(RepoQuery),
+		Branch:  query.Get(BranchQuery),
+		Variant: query.Get(VariantQuery),
+	}
+	var missing []string
+	for field, value := range map[string]string{OrgQuery: metadata.Org, RepoQuery: metadata.Repo, BranchQuery: metadata.Branch} {
+		if value == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return api.Metadata{}, fmt.Errorf("missing queries: %s", strings.Join(missing, ", "))
+	}
+	return metadata, nil
+}
+
+// listConfigs returns the metadata of all configs, optionally only for an org or repo
+func listConfigs(confAgent agents.ConfigAgent, org, repo string) []api.Metadata {
+	metadata := []api.Metadata{}
+	for orgName, orgConfigs := range confAgent.GetAll() {
+		if org != "" && orgName != org {
+			continue
+		}
+		for repoName, repoConfigs := range orgConfigs {
+			if repo != "" && repoName != repo {
+				continue
+			}
+			for _, config := range repoConfigs {
+				metadata = append(metadata, config.Metadata)
+			}
+		}
+	}
+	sort.Slice(metadata, func(i, j int) bool {
+		return metadata[i].Basename() < metadata[j].Basename()
+	})
+	return metadata
+}
+
+func registryAPIHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, path []string, w http.ResponseWriter) {
+	refs, chains, workflows, docs, metadata := regAgent.GetRegistryComponents()
+	var names sets.String
+	var nodeType registry.Type
+	switch path[0] {
+	case referencesPath:
+		names, nodeType = sets.StringKeySet(refs), registry.Reference
+	case chainsPath:
+		names, nodeType = sets.StringKeySet(chains), registry.Chain
+	case workflowsPath:
+		names, nodeType = sets.StringKeySet(workflows), registry.Workflow
+	default:
+		writeAPIError(w, fmt.Errorf("component type %s not found", path[0]), http.StatusNotFound)
+		return
+	}
+
+	if len(path) == 1 {
+		elements := []RegistryElement{}
+		for _, name := range names.List() {
+			elements = append(elements, registryElement(name, nodeType, docs, metadata))
+		}
+		writeAPIResponse(w, elements)
+		return
+	}
+	name := path[1]
+	if !names.Has(name) {
+		writeAPIError(w, fmt.Errorf("could not find %s %s", nodeType, name), http.StatusNotFound)
+		return
+	}
+	switch {
+	case len(path) == 2:
+		element := registryElement(name, nodeType, docs, metadata)
+		switch nodeType {
+		case registry.Reference:
+			ref := refs[name]
+			element.Reference = &ref
+		case registry.Chain:
+			chain := chains[name]
+			element.Chain = &chain
+		case registry.Workflow:
+			workflow := workflows[name]
+			element.Workflow = &workflow
+		}
+		writeAPIResponse(w, element)
+	case len(path) == 3 && path[2] == "jobs":
+		graph, err := registry.NewGraph(refs, chains, workflows)
+		if err != nil {
+			writeAPIError(w, fmt.Errorf("failed to build the registry graph: %w", err), http.StatusInternalServerError)
+			return
+		}
+		writeAPIResponse(w, jobsUsing(confAgent, workflows, graph, name, nodeType))
+	default:
+		writeAPIError(w, fmt.Errorf("invalid path %s", strings.Join(path, "/")), http.StatusNotFound)
+	}
+}
+
+func registryElement(name string, nodeType registry.Type, docs map[string]string, metadata api.RegistryMetadata) RegistryElement {
+	suffix := map[registry.Type]string{
+		registry.Reference: load.RefSuffix,
+		registry.Chain:     load.ChainSuffix,
+		registry.Workflow:  load.WorkflowSuffix,
+	}[nodeType]
+	return RegistryElement{
+		Name:          name,
+		Type:          nodeType.String(),
+		Documentation: docs[name],
+		Metadata:      metadata[name+suffix],
+	}
+}
+
+// jobsUsing lists the multi-stage tests that run the registry element, directly
+// or through a chain or workflow that contains it
+func jobsUsing(confAgent agents.ConfigAgent, workflows registry.WorkflowByName, graph registry.NodeByName, name string, nodeType registry.Type) []JobReference {
+	jobs := usageOf(confAgent, workflows, graph)[nodeType][name]
+	if jobs == nil {
+		jobs = []JobReference{}
+	}
+	return jobs
+}
+
+func writeAPIResponse(w http.ResponseWriter, data interface{}) {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		writeAPIError(w, fmt.Errorf("failed to marshal response: %w", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(raw); err != nil {
+		logrus.WithError(err).Error("Failed to write response")
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, apiErr error, status int) {
+	raw, err := json.Marshal(APIError{Error: apiErr.Error()})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to marshal error")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(raw); err != nil {
+		logrus.WithError(err).Error("Failed to write response")
+	}
+}
@@ -0,0 +1,227 @@
+package webreg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func apiTestAgents() (agents.RegistryAgent, agents.ConfigAgent) {
+	install, e2e, unused := "ipi-install", "e2e", "unused"
+	ipi, aws := "ipi", "ipi-aws"
+	refs := registry.ReferenceByName{
+		install: {As: install, From: "installer", Commands: "install"},
+		e2e:     {As: e2e, From: "tests", Commands: "test"},
+		unused:  {As: unused, From: "src", Commands: "true"},
+	}
+	chains := registry.ChainByName{
+		ipi: {As: ipi, Steps: []api.TestStep{{Reference: &install}}},
+	}
+	workflows := registry.WorkflowByName{
+		aws: {Pre: []api.TestStep{{Chain: &ipi}}, Test: []api.TestStep{{Reference: &e2e}}},
+	}
+	docs := map[string]string{install: "Installs a cluster.", ipi: "Installs a cluster with IPI.", aws: "Tests on AWS."}
+	metadata := api.RegistryMetadata{
+		"ipi-install-ref.yaml":  {Path: "ipi/install"},
+		"ipi-chain.yaml":        {Path: "ipi"},
+		"ipi-aws-workflow.yaml": {Path: "ipi/aws"},
+		"e2e-ref.yaml":          {Path: "e2e"},
+		"unused-ref.yaml":       {Path: "unused"},
+	}
+	configs := load.ByOrgRepo{
+		"org": {
+			"repo": {
+				{
+					Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+					Tests: []api.TestStepConfiguration{
+						{As: "e2e-aws", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &aws}},
+						{As: "e2e-custom", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Workflow: &aws, Pre: []api.TestStep{}}},
+					},
+				},
+				{
+					Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"},
+					Tests: []api.TestStepConfiguration{
+						{As: "install", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Chain: &ipi}}}},
+					},
+				},
+			},
+		},
+		"other": {
+			"repo": {{Metadata: api.Metadata{Org: "other", Repo: "repo", Branch: "release-4.8"}}},
+		},
+	}
+	return agents.NewFakeRegistryAgent(refs, chains, workflows, docs, metadata), agents.NewFakeConfigAgent(configs)
+}
+
+func TestAPIHandler(t *testing.T) {
+	regAgent, confAgent := apiTestAgents()
+	handler := APIHandler(regAgent, confAgent)
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		into           func() interface{}
+		expected       interface{}
+	}{
+		{
+			name:           "list all configs",
+			path:           "/api/v1/configs",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &[]api.Metadata{} },
+			expected: &[]api.Metadata{
+				{Org: "org", Repo: "repo", Branch: "master"},
+				{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"},
+				{Org: "other", Repo: "repo", Branch: "release-4.8"},
+			},
+		},
+		{
+			name:           "list configs of an org",
+			path:           "/api/v1/configs?org=other",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &[]api.Metadata{} },
+			expected:       &[]api.Metadata{{Org: "other", Repo: "repo", Branch: "release-4.8"}},
+		},
+		{
+			name:           "unresolved config",
+			path:           "/api/v1/configs/unresolved?org=org&repo=repo&branch=master&variant=ipi",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &api.ReleaseBuildConfiguration{} },
+			expected: func() interface{} {
+				ipi := "ipi"
+				return &api.ReleaseBuildConfiguration{
+					Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"},
+					Tests: []api.TestStepConfiguration{
+						{As: "install", MultiStageTestConfiguration: &api.MultiStageTestConfiguration{Test: []api.TestStep{{Chain: &ipi}}}},
+					},
+				}
+			}(),
+		},
+		{
+			name:           "resolved config",
+			path:           "/api/v1/configs/resolved?org=org&repo=repo&branch=master&variant=ipi",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &api.ReleaseBuildConfiguration{} },
+			expected: &api.ReleaseBuildConfiguration{
+				Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"},
+				Tests: []api.TestStepConfiguration{
+					{As: "install", MultiStageTestConfigurationLiteral: &api.MultiStageTestConfigurationLiteral{
+						Test: []api.LiteralTestStep{{As: "ipi-install", From: "installer", Commands: "install"}},
+					}},
+				},
+			},
+		},
+		{
+			name:           "config at a generation that is not retained",
+			path:           "/api/v1/configs/resolved?org=org&repo=repo&branch=master&sha=4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			expectedStatus: http.StatusNotFound,
+			into:           func() interface{} { return &APIError{} },
+			expected:       &APIError{Error: "failed to get config: configs at 4b825dc642cb6eb9a060e54bf8d69288fbee4904: generation is not retained"},
+		},
+		{
+			name:           "list generations",
+			path:           "/api/v1/generations",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &Generations{} },
+			expected:       &Generations{Config: []load.Generation{{Generation: 0}}, Registry: []load.Generation{{Generation: 0}}},
+		},
+		{
+			name:           "config without branch",
+			path:           "/api/v1/configs/unresolved?org=org&repo=repo",
+			expectedStatus: http.StatusBadRequest,
+			into:           func() interface{} { return &APIError{} },
+			expected:       &APIError{Error: "missing queries: branch"},
+		},
+		{
+			name:           "list references",
+			path:           "/api/v1/registry/references",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &[]RegistryElement{} },
+			expected: &[]RegistryElement{
+				{Name: "e2e", Type: "reference", Metadata: api.RegistryInfo{Path: "e2e"}},
+				{Name: "ipi-install", Type: "reference", Documentation: "Installs a cluster.", Metadata: api.RegistryInfo{Path: "ipi/install"}},
+				{Name: "unused", Type: "reference", Metadata: api.RegistryInfo{Path: "unused"}},
+			},
+		},
+		{
+			name:           "get a chain",
+			path:           "/api/v1/registry/chains/ipi",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &RegistryElement{} },
+			expected: func() interface{} {
+				install := "ipi-install"
+				return &RegistryElement{
+					Name:          "ipi",
+					Type:          "chain",
+					Documentation: "Installs a cluster with IPI.",
+					Metadata:      api.RegistryInfo{Path: "ipi"},
+					Chain:         &api.RegistryChain{As: "ipi", Steps: []api.TestStep{{Reference: &install}}},
+				}
+			}(),
+		},
+		{
+			name:           "missing workflow",
+			path:           "/api/v1/registry/workflows/missing",
+			expectedStatus: http.StatusNotFound,
+			into:           func() interface{} { return &APIError{} },
+			expected:       &APIError{Error: "could not find workflow missing"},
+		},
+		{
+			name:           "jobs using a reference through a chain and a workflow",
+			path:           "/api/v1/registry/references/ipi-install/jobs",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &[]JobReference{} },
+			expected: &[]JobReference{
+				{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}, Test: "e2e-aws"},
+				{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"}, Test: "install"},
+			},
+		},
+		{
+			name:           "jobs using a workflow",
+			path:           "/api/v1/registry/workflows/ipi-aws/jobs",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &[]JobReference{} },
+			expected: &[]JobReference{
+				{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}, Test: "e2e-aws"},
+				{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}, Test: "e2e-custom"},
+			},
+		},
+		{
+			name:           "unused reference",
+			path:           "/api/v1/registry/references/unused/jobs",
+			expectedStatus: http.StatusOK,
+			into:           func() interface{} { return &[]JobReference{} },
+			expected:       &[]JobReference{},
+		},
+		{
+			name:           "invalid path",
+			path:           "/api/v1/something",
+			expectedStatus: http.StatusNotFound,
+			into:           func() interface{} { return &APIError{} },
+			expected:       &APIError{Error: "invalid path /api/v1/something"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler(recorder, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+			actual := tc.into()
+			if err := json.Unmarshal(recorder.Body.Bytes(), actual); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected response: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package webreg
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/validation"
+)
+
+const (
+	workflowQuery       = "workflow"
+	asQuery             = "as"
+	clusterProfileQuery = "cluster_profile"
+	envQuery            = "env"
+	dependenciesQuery   = "dependencies"
+	preQuery            = "pre"
+	testStepsQuery      = "test_steps"
+	postQuery           = "post"
+
+	defaultComposedTestName = "e2e"
+)
+
+// composerForm holds the choices made by the user in the workflow composer
+type composerForm struct {
+	Workflow       string
+	As             string
+	ClusterProfile string
+	Env            string
+	Dependencies   string
+	Pre            string
+	Test           string
+	Post           string
+	// Metadata optionally identifies an existing config the test is validated in
+	Metadata api.Metadata
+}
+
+// composerResult is what the workflow composer shows for the choices made
+type composerResult struct {
+	Form      composerForm
+	Workflows []string
+	// Snippet is the test as it should be added to the tests of a ci-operator config
+	Snippet string
+	// Resolved is the test after resolution with the registry
+	Resolved string
+	// Errors are problems with the input, the resolution or the validation of the test
+	Errors []string
+}
+
+func composerFormFromQuery(query url.Values) composerForm {
+	form := composerForm{
+		Workflow:       query.Get(workflowQuery),
+		As:             query.Get(asQuery),
+		ClusterProfile: query.Get(clusterProfileQuery),
+		Env:            query.Get(envQuery),
+		Dependencies:   query.Get(dependenciesQuery),
+		Pre:            query.Get(preQuery),
+		Test:           query.Get(testStepsQuery),
+		Post:           query.Get(postQuery),
+		Metadata: api.Metadata{
+			Org:     query.Get(OrgQuery),
+			Repo:    query.Get(RepoQuery),
+			Branch:  query.Get(BranchQuery),
+			Variant: query.Get(VariantQuery),
+		},
+	}
+	if form.As == "" {
+		form.As = defaultComposedTestName
+	}
+	return form
+}
+
+// parseKeyValues parses lines of KEY=VALUE, ignoring empty lines
+func parseKeyValues(field, raw string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%s: expected KEY=VALUE, got %q", field, line)
+		}
+		values[parts[0]] = parts[1]
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values, nil
+}
+
+// parseSteps parses a YAML list of steps; an empty phase is taken from the workflow
+func parseSteps(field, raw string) ([]api.TestStep, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var steps []api.TestStep
+	if err := yaml.UnmarshalStrict([]byte(raw), &steps); err != nil {
+		return nil, fmt.Errorf("%s: invalid list of steps: %w", field, err)
+	}
+	return steps, nil
+}
+
+// composedTest builds the multi-stage test for the choices in the form
+func composedTest(form composerForm) (api.TestStepConfiguration, []error) {
+	var errs []error
+	test := api.MultiStageTestConfiguration{ClusterProfile: api.ClusterProfile(form.ClusterProfile)}
+	if form.Workflow != "" {
+		workflow := form.Workflow
+		test.Workflow = &workflow
+	}
+	env, err := parseKeyValues("env", form.Env)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	test.Environment = env
+	dependencies, err := parseKeyValues("dependencies", form.Dependencies)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	test.Dependencies = dependencies
+	for _, phase := range []struct {
+		field string
+		raw   string
+		into  *[]api.TestStep
+	}{
+		{field: "pre", raw: form.Pre, into: &test.Pre},
+		{field: "test", raw: form.Test, into: &test.Test},
+		{field: "post", raw: form.Post, into: &test.Post},
+	} {
+		steps, err := parseSteps(phase.field, phase.raw)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		*phase.into = steps
+	}
+	return api.TestStepConfiguration{As: form.As, MultiStageTestConfiguration: &test}, errs
+}
+
+// baseConfig returns the config the composed test is validated in: the config
+// identified in the form or a minimal config that is only missing tests
+func baseConfig(confAgent agents.ConfigAgent, metadata api.Metadata) (api.ReleaseBuildConfiguration, error) {
+	if metadata.Org == "" && metadata.Repo == "" && metadata.Branch == "" {
+		return api.ReleaseBuildConfiguration{
+			Resources: api.ResourceConfiguration{
+				"*": {Requests: api.ResourceList{"cpu": "100m", "memory": "200Mi"}},
+			},
+		}, nil
+	}
+	config, err := confAgent.GetMatchingConfig(metadata)
+	if err != nil {
+		return api.ReleaseBuildConfiguration{}, fmt.Errorf("failed to get config to validate the test in: %w", err)
+	}
+	return config, nil
+}
+
+// withTest returns the config with the test added, replacing a test with the same name
+func withTest(config api.ReleaseBuildConfiguration, test api.TestStepConfiguration) api.ReleaseBuildConfiguration {
+	tests := []api.TestStepConfiguration{test}
+	for _, existing := range config.Tests {
+		if existing.As != test.As {
+			tests = append(tests, existing)
+		}
+	}
+	config.Tests = tests
+	return config
+}
+
+// compose builds, resolves and validates the test the user composed
+func compose(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, form composerForm) composerResult {
+	_, _, workflows, _, _ := regAgent.GetRegistryComponents()
+	result := composerResult{Form: form}
+	for name := range workflows {
+		result.Workflows = append(result.Workflows, name)
+	}
+	sort.Strings(result.Workflows)
+	addErrors := func(errs ...error) {
+		for _, err := range errs {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	if form.Workflow == "" && form.Pre == "" && form.Test == "" && form.Post == "" {
+		return result
+	}
+
+	test, errs := composedTest(form)
+	if len(errs) != 0 {
+		addErrors(errs...)
+		return result
+	}
+	snippet, err := yaml.Marshal(struct {
+		Tests []api.TestStepConfiguration `json:"tests"`
+	}{Tests: []api.TestStepConfiguration{test}})
+	if err != nil {
+		addErrors(fmt.Errorf("failed to marshal the test: %w", err))
+		return result
+	}
+	result.Snippet = string(snippet)
+
+	config, err := baseConfig(confAgent, form.Metadata)
+	if err != nil {
+		addErrors(err)
+		return result
+	}
+	resolvedConfig, err := regAgent.ResolveConfig(withTest(config, test))
+	if err != nil {
+		addErrors(fmt.Errorf("failed to resolve the test with the registry: %w", err))
+		return result
+	}
+	resolved, err := yaml.Marshal(resolvedConfig.Tests[0].MultiStageTestConfigurationLiteral)
+	if err != nil {
+		addErrors(fmt.Errorf("failed to marshal the resolved test: %w", err))
+		return result
+	}
+	result.Resolved = string(resolved)
+	if err := validation.IsValidResolvedConfiguration(&resolvedConfig); err != nil {
+		addErrors(err)
+	}
+	return result
+}
+
+const composerPage = `
+<h2 id="title"><a href="#title">Compose a Multi-Stage Test</a></h2>
+<p>
+Select a workflow and override its parameters or steps to see how the test is resolved by the registry. Steps are written
+as YAML lists, for example <code>- ref: ipi-install</code> or <code>- chain: ipi-deprovision</code>; phases left empty are
+taken from the workflow. Optionally, identify an existing ci-operator config to validate the test in.
+</p>
+<form action="/compose" method="get">
+  <div class="form-row">
+    <div class="form-group col-md-4">
+      <label for="workflow">Workflow</label>
+      <select class="form-control" id="workflow" name="workflow">
+        <option value="">(none)</option>
+        {{ range .Workflows }}<option value="{{ . }}"{{ if eq . $.Form.Workflow }} selected{{ end }}>{{ . }}</option>{{ end }}
+      </select>
+    </div>
+    <div class="form-group col-md-4">
+      <label for="as">Test name</label>
+      <input class="form-control" id="as" name="as" value="{{ .Form.As }}">
+    </div>
+    <div class="form-group col-md-4">
+      <label for="cluster_profile">Cluster profile</label>
+      <input class="form-control" id="cluster_profile" name="cluster_profile" value="{{ .Form.ClusterProfile }}" placeholder="from the workflow">
+    </div>
+  </div>
+  <div class="form-row">
+    <div class="form-group col-md-6">
+      <label for="env">Environment (<code>KEY=VALUE</code> per line)</label>
+      <textarea class="form-control text-monospace" id="env" name="env" rows="4">{{ .Form.Env }}</textarea>
+    </div>
+    <div class="form-group col-md-6">
+      <label for="dependencies">Dependencies (<code>ENV=image</code> per line)</label>
+      <textarea class="form-control text-monospace" id="dependencies" name="dependencies" rows="4">{{ .Form.Dependencies }}</textarea>
+    </div>
+  </div>
+  <div class="form-row">
+    <div class="form-group col-md-4">
+      <label for="pre">Pre steps</label>
+      <textarea class="form-control text-monospace" id="pre" name="pre" rows="4">{{ .Form.Pre }}</textarea>
+    </div>
+    <div class="form-group col-md-4">
+      <label for="test_steps">Test steps</label>
+      <textarea class="form-control text-monospace" id="test_steps" name="test_steps" rows="4">{{ .Form.Test }}</textarea>
+    </div>
+    <div class="form-group col-md-4">
+      <label for="post">Post steps</label>
+      <textarea class="form-control text-monospace" id="post" name="post" rows="4">{{ .Form.Post }}</textarea>
+    </div>
+  </div>
+  <div class="form-row">
+    <div class="form-group col-md-3"><input class="form-control" name="org" value="{{ .Form.Metadata.Org }}" placeholder="org (optional)"></div>
+    <div class="form-group col-md-3"><input class="form-control" name="repo" value="{{ .Form.Metadata.Repo }}" placeholder="repo (optional)"></div>
+    <div class="form-group col-md-3"><input class="form-control" name="branch" value="{{ .Form.Metadata.Branch }}" placeholder="branch (optional)"></div>
+    <div class="form-group col-md-3"><input class="form-control" name="variant" value="{{ .Form.Metadata.Variant }}" placeholder="variant (optional)"></div>
+  </div>
+  <button class="btn btn-primary" type="submit">Preview</button>
+</form>
+{{ if .Errors }}
+<h3 id="errors"><a href="#errors">Errors</a></h3>
+<div class="alert alert-danger" role="alert">
+  <ul class="mb-0">{{ range .Errors }}<li><pre class="mb-0" style="white-space: pre-wrap">{{ . }}</pre></li>{{ end }}</ul>
+</div>
+{{ else if .Resolved }}
+<div class="alert alert-success" role="alert">The test resolves and passes validation.</div>
+{{ end }}
+{{ if .Snippet }}
+<h3 id="snippet"><a href="#snippet">Configuration</a></h3>
+<p>Add the test to the <code>tests</code> of your ci-operator config:</p>
+<textarea class="form-control text-monospace" id="snippet-text" rows="{{ lines .Snippet }}" readonly>{{ .Snippet }}</textarea>
+<button class="btn btn-outline-secondary mt-2" type="button" onclick="document.getElementById('snippet-text').select(); document.execCommand('copy');">Copy</button>
+{{ end }}
+{{ if .Resolved }}
+<h3 id="resolved"><a href="#resolved">Resolved Test</a></h3>
+<p>The steps ci-operator runs for the test, with all references, chains and the workflow resolved:</p>
+{{ syntaxedYAML .Resolved }}
+{{ end }}
+`
+
+func composerHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	page, err = page.Funcs(
+		template.FuncMap{
+			"syntaxedYAML": func(source string) template.HTML {
+				formatted, err := syntaxYAML(source)
+				if err != nil {
+					logrus.Errorf("Failed to format YAML: %v", err)
+					return template.HTML(template.HTMLEscapeString(source))
+				}
+				return template.HTML(formatted)
+			},
+			"lines": func(s string) int {
+				return strings.Count(s, "\n") + 1
+			},
+		},
+	).Parse(composerPage)
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Multi-Stage Test Composer", page, compose(regAgent, confAgent, composerFormFromQuery(req.URL.Query())))
+}
@@ -0,0 +1,162 @@
+package webreg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	utilpointer "k8s.io/utils/pointer"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestCompose(t *testing.T) {
+	install, e2e, workflow := "ipi-install", "e2e", "ipi-aws"
+	resources := api.ResourceRequirements{Requests: api.ResourceList{"cpu": "100m"}}
+	refs := registry.ReferenceByName{
+		install: {As: install, From: "installer", Commands: "install", Resources: resources},
+		e2e: {As: e2e, From: "tests", Commands: "test", Resources: resources, Environment: []api.StepParameter{
+			{Name: "SUITE", Default: utilpointer.StringPtr("all")},
+		}},
+	}
+	workflows := registry.WorkflowByName{
+		workflow: {ClusterProfile: api.ClusterProfileAWS, Pre: []api.TestStep{{Reference: &install}}, Test: []api.TestStep{{Reference: &e2e}}},
+	}
+	regAgent := agents.NewFakeRegistryAgent(refs, registry.ChainByName{}, workflows, nil, nil)
+	confAgent := agents.NewFakeConfigAgent(load.ByOrgRepo{"org": {"repo": {{
+		Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+		Tests:    []api.TestStepConfiguration{{As: "unit", Commands: "make test", ContainerTestConfiguration: &api.ContainerTestConfiguration{From: "src"}}},
+	}}}})
+
+	testCases := []struct {
+		name     string
+		form     composerForm
+		expected composerResult
+	}{
+		{
+			name: "nothing composed yet",
+			form: composerForm{As: "e2e"},
+			expected: composerResult{
+				Form:      composerForm{As: "e2e"},
+				Workflows: []string{workflow},
+			},
+		},
+		{
+			name: "workflow with overridden environment",
+			form: composerForm{Workflow: workflow, As: "e2e", Env: "SUITE=serial\n"},
+			expected: composerResult{
+				Form:      composerForm{Workflow: workflow, As: "e2e", Env: "SUITE=serial\n"},
+				Workflows: []string{workflow},
+				Snippet: `tests:
+- as: e2e
+  steps:
+    env:
+      SUITE: serial
+    workflow: ipi-aws
+`,
+				Resolved: `cluster_profile: aws
+pre:
+- as: ipi-install
+  commands: install
+  from: installer
+  resources:
+    requests:
+      cpu: 100m
+test:
+- as: e2e
+  commands: test
+  env:
+  - default: serial
+    name: SUITE
+  from: tests
+  resources:
+    requests:
+      cpu: 100m
+`,
+			},
+		},
+		{
+			name: "overridden steps in an existing config",
+			form: composerForm{
+				Workflow: workflow,
+				As:       "unit",
+				Test:     "- ref: e2e\n",
+				Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+			},
+			expected: composerResult{
+				Form: composerForm{
+					Workflow: workflow,
+					As:       "unit",
+					Test:     "- ref: e2e\n",
+					Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"},
+				},
+				Workflows: []string{workflow},
+				Snippet: `tests:
+- as: unit
+  steps:
+    test:
+    - ref: e2e
+    workflow: ipi-aws
+`,
+				Resolved: `cluster_profile: aws
+pre:
+- as: ipi-install
+  commands: install
+  from: installer
+  resources:
+    requests:
+      cpu: 100m
+test:
+- as: e2e
+  commands: test
+  env:
+  - default: all
+    name: SUITE
+  from: tests
+  resources:
+    requests:
+      cpu: 100m
+`,
+				Errors: []string{"invalid configuration: 'resources' should be specified to provide resource requests"},
+			},
+		},
+		{
+			name: "invalid input",
+			form: composerForm{Workflow: workflow, As: "e2e", Env: "SUITE", Pre: "ref: ipi-install"},
+			expected: composerResult{
+				Form:      composerForm{Workflow: workflow, As: "e2e", Env: "SUITE", Pre: "ref: ipi-install"},
+				Workflows: []string{workflow},
+				Errors: []string{
+					`env: expected KEY=VALUE, got "SUITE"`,
+					"pre: invalid list of steps: error unmarshaling JSON: while decoding JSON: json: cannot unmarshal object into Go value of type []api.TestStep",
+				},
+			},
+		},
+		{
+			name: "unknown parameter",
+			form: composerForm{Workflow: workflow, As: "e2e", Env: "UNKNOWN=value"},
+			expected: composerResult{
+				Form:      composerForm{Workflow: workflow, As: "e2e", Env: "UNKNOWN=value"},
+				Workflows: []string{workflow},
+				Snippet: `tests:
+- as: e2e
+  steps:
+    env:
+      UNKNOWN: value
+    workflow: ipi-aws
+`,
+				Errors: []string{`failed to resolve the test with the registry: Failed resolve MultiStageTestConfiguration: test/e2e: workflow/ipi-aws: parameter "UNKNOWN" is overridden in [test/e2e] but not declared in any step`},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, compose(regAgent, confAgent, tc.form)); diff != "" {
+				t.Errorf("unexpected result: %s", diff)
+			}
+		})
+	}
+}
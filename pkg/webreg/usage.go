@@ -0,0 +1,216 @@
+package webreg
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/load/agents"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+// registryUsage maps the elements of each type in the registry to the tests that use them
+type registryUsage map[registry.Type]map[string][]JobReference
+
+// usageOf determines the multi-stage tests that run each registry element, directly
+// or through a chain or workflow that contains it
+func usageOf(confAgent agents.ConfigAgent, workflows registry.WorkflowByName, graph registry.NodeByName) registryUsage {
+	usage := registryUsage{registry.Reference: {}, registry.Chain: {}, registry.Workflow: {}}
+	for _, orgConfigs := range confAgent.GetAll() {
+		for _, repoConfigs := range orgConfigs {
+			for _, config := range repoConfigs {
+				for _, test := range config.Tests {
+					if test.MultiStageTestConfiguration == nil {
+						continue
+					}
+					job := JobReference{Metadata: config.Metadata, Test: test.As}
+					for nodeType, names := range elementsUsedBy(*test.MultiStageTestConfiguration, workflows, graph) {
+						for name := range names {
+							usage[nodeType][name] = append(usage[nodeType][name], job)
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, byName := range usage {
+		for _, jobs := range byName {
+			sort.Slice(jobs, func(i, j int) bool {
+				if jobs[i].Basename() != jobs[j].Basename() {
+					return jobs[i].Basename() < jobs[j].Basename()
+				}
+				return jobs[i].Test < jobs[j].Test
+			})
+		}
+	}
+	return usage
+}
+
+// elementsUsedBy lists the registry elements a multi-stage test runs
+func elementsUsedBy(test api.MultiStageTestConfiguration, workflows registry.WorkflowByName, graph registry.NodeByName) map[registry.Type]sets.String {
+	used := map[registry.Type]sets.String{registry.Reference: sets.NewString(), registry.Chain: sets.NewString(), registry.Workflow: sets.NewString()}
+	if test.Workflow != nil {
+		used[registry.Workflow].Insert(*test.Workflow)
+		// phases of the test override the ones of the workflow
+		if workflow, ok := workflows[*test.Workflow]; ok {
+			if test.Pre == nil {
+				test.Pre = workflow.Pre
+			}
+			if test.Test == nil {
+				test.Test = workflow.Test
+			}
+			if test.Post == nil {
+				test.Post = workflow.Post
+			}
+		}
+	}
+	for _, step := range append(test.Pre, append(test.Test, test.Post...)...) {
+		switch {
+		case step.Reference != nil:
+			used[registry.Reference].Insert(*step.Reference)
+		case step.Chain != nil:
+			used[registry.Chain].Insert(*step.Chain)
+			if node, ok := graph.Chains[*step.Chain]; ok {
+				for _, descendant := range node.Descendants() {
+					used[descendant.Type()].Insert(descendant.Name())
+				}
+			}
+		}
+	}
+	return used
+}
+
+// jobsFor arranges the tests by org, repo, branch and variant for display
+func jobsFor(references []JobReference) *Jobs {
+	jobs := &Jobs{}
+	for _, job := range references {
+		jobs.addJob(job.Org, job.Repo, job.Branch, job.Variant, job.Test)
+	}
+	return jobs
+}
+
+// testCount is the number of tests in the jobs
+func testCount(jobs *Jobs) int {
+	var count int
+	for _, org := range jobs.Orgs {
+		for _, repo := range org.Repos {
+			for _, branch := range repo.Branches {
+				count += len(branch.Tests)
+				for _, variant := range branch.Variants {
+					count += len(variant.Tests)
+				}
+			}
+		}
+	}
+	return count
+}
+
+// componentUsage determines the tests that use a single registry element for its page
+func componentUsage(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, name string, nodeType registry.Type) *Jobs {
+	refs, chains, workflows, _, _ := regAgent.GetRegistryComponents()
+	graph, err := registry.NewGraph(refs, chains, workflows)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build the registry graph")
+		return nil
+	}
+	return jobsFor(jobsUsing(confAgent, workflows, graph, name, nodeType))
+}
+
+// usageCount is the number of tests using a registry element
+type usageCount struct {
+	Name  string
+	Count int
+}
+
+// usageSummary ranks the elements of a type in the registry by the number of tests using them
+type usageSummary struct {
+	Type   string
+	Path   string
+	Used   []usageCount
+	Unused []string
+}
+
+func summarizeUsage(usage registryUsage, refs registry.ReferenceByName, chains registry.ChainByName, workflows registry.WorkflowByName) []usageSummary {
+	var summaries []usageSummary
+	for _, element := range []struct {
+		nodeType registry.Type
+		path     string
+		names    sets.String
+	}{
+		{nodeType: registry.Workflow, path: "workflow", names: sets.StringKeySet(workflows)},
+		{nodeType: registry.Chain, path: "chain", names: sets.StringKeySet(chains)},
+		{nodeType: registry.Reference, path: "reference", names: sets.StringKeySet(refs)},
+	} {
+		summary := usageSummary{Type: element.nodeType.String(), Path: element.path, Used: []usageCount{}, Unused: []string{}}
+		for _, name := range element.names.List() {
+			if count := len(usage[element.nodeType][name]); count > 0 {
+				summary.Used = append(summary.Used, usageCount{Name: name, Count: count})
+			} else {
+				summary.Unused = append(summary.Unused, name)
+			}
+		}
+		sort.SliceStable(summary.Used, func(i, j int) bool {
+			return summary.Used[i].Count > summary.Used[j].Count
+		})
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+const usagePage = `
+<h2 id="title"><a href="#title">Registry Usage</a></h2>
+<p>The number of tests in ci-operator configs that run each element of the registry, directly or through a chain or workflow.</p>
+{{ range . }}
+<h3 id="{{ .Path }}"><a href="#{{ .Path }}">{{ .Type }}s</a></h3>
+<div class="row">
+<div class="col-md-8">
+<h4>Most used</h4>
+<table class="table table-sm">
+	<thead><tr><th>Name</th><th>Tests</th></tr></thead>
+	<tbody>
+	{{ $path := .Path }}
+	{{ range .Used }}
+		<tr><td><nobr><a href="/{{ $path }}/{{ .Name }}#usage" style="font-family:monospace">{{ .Name }}</a></nobr></td><td>{{ .Count }}</td></tr>
+	{{ end }}
+	</tbody>
+</table>
+</div>
+<div class="col-md-4">
+<h4>Unused ({{ len .Unused }})</h4>
+<ul>
+{{ range .Unused }}
+	<li><nobr><a href="/{{ $path }}/{{ . }}" style="font-family:monospace">{{ . }}</a></nobr></li>
+{{ end }}
+</ul>
+</div>
+</div>
+{{ end }}
+`
+
+func usageHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, _ *http.Request) {
+	start := time.Now()
+	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
+	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
+	refs, chains, workflows, _, _ := regAgent.GetRegistryComponents()
+	graph, err := registry.NewGraph(refs, chains, workflows)
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to build the registry graph: %w", err), http.StatusInternalServerError)
+		return
+	}
+	page, err := baseTemplate.Clone()
+	if err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	if page, err = page.Parse(usagePage); err != nil {
+		writeErrorPage(w, fmt.Errorf("Failed to render page: %w", err), http.StatusInternalServerError)
+		return
+	}
+	writePage(w, "Registry Usage", page, summarizeUsage(usageOf(confAgent, workflows, graph), refs, chains, workflows))
+}
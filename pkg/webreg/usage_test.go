@@ -0,0 +1,58 @@
+package webreg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openshift/ci-tools/pkg/api"
+	"github.com/openshift/ci-tools/pkg/registry"
+)
+
+func TestUsageOf(t *testing.T) {
+	regAgent, confAgent := apiTestAgents()
+	refs, chains, workflows, _, _ := regAgent.GetRegistryComponents()
+	graph, err := registry.NewGraph(refs, chains, workflows)
+	if err != nil {
+		t.Fatalf("failed to build graph: %v", err)
+	}
+	master := api.Metadata{Org: "org", Repo: "repo", Branch: "master"}
+	variant := api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"}
+	expected := registryUsage{
+		registry.Reference: {
+			"ipi-install": {{Metadata: master, Test: "e2e-aws"}, {Metadata: variant, Test: "install"}},
+			"e2e":         {{Metadata: master, Test: "e2e-aws"}, {Metadata: master, Test: "e2e-custom"}},
+		},
+		registry.Chain: {
+			"ipi": {{Metadata: master, Test: "e2e-aws"}, {Metadata: variant, Test: "install"}},
+		},
+		registry.Workflow: {
+			"ipi-aws": {{Metadata: master, Test: "e2e-aws"}, {Metadata: master, Test: "e2e-custom"}},
+		},
+	}
+	usage := usageOf(confAgent, workflows, graph)
+	if diff := cmp.Diff(expected, usage); diff != "" {
+		t.Errorf("unexpected usage: %s", diff)
+	}
+
+	expectedSummary := []usageSummary{
+		{Type: "workflow", Path: "workflow", Used: []usageCount{{Name: "ipi-aws", Count: 2}}, Unused: []string{}},
+		{Type: "chain", Path: "chain", Used: []usageCount{{Name: "ipi", Count: 2}}, Unused: []string{}},
+		{Type: "reference", Path: "reference", Used: []usageCount{{Name: "e2e", Count: 2}, {Name: "ipi-install", Count: 2}}, Unused: []string{"unused"}},
+	}
+	if diff := cmp.Diff(expectedSummary, summarizeUsage(usage, refs, chains, workflows)); diff != "" {
+		t.Errorf("unexpected summary: %s", diff)
+	}
+}
+
+func TestTestCount(t *testing.T) {
+	jobs := jobsFor([]JobReference{
+		{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}, Test: "e2e"},
+		{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master"}, Test: "unit"},
+		{Metadata: api.Metadata{Org: "org", Repo: "repo", Branch: "master", Variant: "ipi"}, Test: "e2e"},
+		{Metadata: api.Metadata{Org: "other", Repo: "repo", Branch: "release-4.8"}, Test: "e2e"},
+	})
+	if count := testCount(jobs); count != 4 {
+		t.Errorf("expected 4 tests, got %d", count)
+	}
+}
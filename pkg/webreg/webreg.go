@@ -136,6 +136,12 @@ td {
       <li class="nav-item">
         <a class="nav-link" href="/search">Jobs</a>
       </li>
+      <li class="nav-item">
+        <a class="nav-link" href="/compose">Compose</a>
+      </li>
+      <li class="nav-item">
+        <a class="nav-link" href="/usage">Usage</a>
+      </li>
       <li class="nav-item">
         <a class="nav-link" href="http://docs.ci.openshift.org">Help</a>
       </li>
@@ -181,6 +187,7 @@ const referencePage = `
 {{ template "referenceProperties" .Reference }}
 <h3 id="github"><p><a href="#github">GitHub Link:</a></h3></p>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
+{{ template "usage" .Usage }}
 `
 
 const chainPage = `
@@ -197,6 +204,7 @@ const chainPage = `
 {{ chainGraph .Chain.As }}
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
+{{ template "usage" .Usage }}
 `
 
 // workflowJobPage defines the template for both jobs and workflows
@@ -223,8 +231,10 @@ const workflowJobPage = `
 <h3 id="graph" title="Visual representation of steps run by this {{ toLower $type }}"><a href="#graph">Step Graph</a></h3>
 {{ workflowGraph .Workflow.As .Workflow.Type }}
 {{ if eq $type "Workflow" }}
+<p><a href="/compose?workflow={{ .Workflow.As }}">Compose a test using this workflow</a></p>
 <h3 id="github"><a href="#github">GitHub Link:</a></h3>{{ githubLink .Metadata.Path }}
 {{ ownersBlock .Metadata.Owners }}
+{{ template "usage" .Usage }}
 {{ end }}
 `
 
@@ -556,8 +566,25 @@ const templateDefinitions = `
 	</table>
 {{ end }}
 
+{{ define "usage" }}
+{{ if . }}
+	<h3 id="usage" title="Tests in ci-operator configs that run this component, directly or through a chain or workflow"><a href="#usage">Usage</a></h3>
+	{{ $count := testCount . }}
+	{{ if eq $count 0 }}
+		<p>No tests use this component.</p>
+	{{ else }}
+		<p>Used by {{ $count }} test{{ if gt $count 1 }}s{{ end }}.</p>
+		{{ template "jobList" . }}
+	{{ end }}
+{{ end }}
+{{ end }}
+
 {{ define "jobTable" }}
     <h2 id="jobs"><a href="#jobs">Jobs</a></h2>
+	{{ template "jobList" . }}
+{{ end }}
+
+{{ define "jobList" }}
 	<table class="table">
 	{{ $containsVariant := .ContainsVariant }}
 		<thead>
@@ -749,6 +776,7 @@ func getBaseTemplate() (*template.Template, error) {
 			},
 			"githubLink":  githubLink,
 			"ownersBlock": ownersBlock,
+			"testCount":   testCount,
 		},
 	)
 	return base.Parse(templateDefinitions)
@@ -1153,6 +1181,10 @@ func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent)
 				searchHandler(confAgent, w, req)
 			case "job":
 				jobHandler(regAgent, confAgent, w, req)
+			case "compose":
+				composerHandler(regAgent, confAgent, w, req)
+			case "usage":
+				usageHandler(regAgent, confAgent, w, req)
 			case "ci-operator-reference":
 				ciOpConfigRefHandler(w)
 			default:
@@ -1162,13 +1194,13 @@ func WebRegHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent)
 		} else if len(splitURI) == 2 {
 			switch splitURI[0] {
 			case "reference":
-				referenceHandler(regAgent, w, req)
+				referenceHandler(regAgent, confAgent, w, req)
 				return
 			case "chain":
-				chainHandler(regAgent, w, req)
+				chainHandler(regAgent, confAgent, w, req)
 				return
 			case "workflow":
-				workflowHandler(regAgent, w, req)
+				workflowHandler(regAgent, confAgent, w, req)
 				return
 			default:
 				writeErrorPage(w, fmt.Errorf("Component type %s not found", splitURI[0]), http.StatusNotFound)
@@ -1331,7 +1363,7 @@ func fromImageDescription(name string, reference *api.ImageStreamTagReference) t
 	return template.HTML(fmt.Sprintf("%s image built or imported by the ci-operator configuration (<a href=\"%s\">documentation</a>).", prefix, fromDocumentation))
 }
 
-func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.Request) {
+func referenceHandler(agent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
@@ -1374,6 +1406,7 @@ func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *ht
 	ref := struct {
 		Reference api.RegistryReference
 		Metadata  api.RegistryInfo
+		Usage     *Jobs
 	}{
 		Reference: api.RegistryReference{
 			LiteralTestStep: api.LiteralTestStep{
@@ -1394,11 +1427,12 @@ func referenceHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *ht
 			Documentation: docs[name],
 		},
 		Metadata: metadata[refMetadataName],
+		Usage:    componentUsage(agent, confAgent, name, registry.Reference),
 	}
 	writePage(w, "Registry Step Help Page", page, ref)
 }
 
-func chainHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.Request) {
+func chainHandler(agent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
@@ -1430,6 +1464,7 @@ func chainHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.R
 	chain := struct {
 		Chain    api.RegistryChain
 		Metadata api.RegistryInfo
+		Usage    *Jobs
 	}{
 		Chain: api.RegistryChain{
 			As:            name,
@@ -1437,11 +1472,12 @@ func chainHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.R
 			Steps:         chains[name].Steps,
 		},
 		Metadata: metadata[chainMetadataName],
+		Usage:    componentUsage(agent, confAgent, name, registry.Chain),
 	}
 	writePage(w, "Registry Chain Help Page", page, chain)
 }
 
-func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *http.Request) {
+func workflowHandler(agent agents.RegistryAgent, confAgent agents.ConfigAgent, w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
 	defer func() { logrus.Infof("rendered in %s", time.Since(start)) }()
 	w.Header().Set("Content-Type", "text/html;charset=UTF-8")
@@ -1476,6 +1512,7 @@ func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *htt
 	workflow := struct {
 		Workflow workflowJob
 		Metadata api.RegistryInfo
+		Usage    *Jobs
 	}{
 		Workflow: workflowJob{
 			RegistryWorkflow: api.RegistryWorkflow{
@@ -1485,6 +1522,7 @@ func workflowHandler(agent agents.RegistryAgent, w http.ResponseWriter, req *htt
 			},
 			Type: workflowType},
 		Metadata: metadata[workflowMetadataName],
+		Usage:    componentUsage(agent, confAgent, name, registry.Workflow),
 	}
 	writePage(w, "Registry Workflow Help Page", page, workflow)
 }
@@ -1588,6 +1626,7 @@ func jobHandler(regAgent agents.RegistryAgent, confAgent agents.ConfigAgent, w h
 	workflow := struct {
 		Workflow workflowJob
 		Metadata api.RegistryInfo
+		Usage    *Jobs
 	}{
 		Workflow: jobWorkflow,
 		Metadata: api.RegistryInfo{},
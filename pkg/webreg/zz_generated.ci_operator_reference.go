@@ -82,7 +82,14 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"# process. The name of each image is its \"to\" value\n" +
 	"# and can be used to build only a specific image.\n" +
 	"images:\n" +
-	"    - # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
+	"    - # Architectures the image is built for. When set, the image is\n" +
+	"      # built once for every architecture on nodes of that architecture\n" +
+	"      # and the results are assembled into a manifest list, which is\n" +
+	"      # what is tagged into the pipeline and promoted. When unset, the\n" +
+	"      # image is built for the architecture of the build cluster only.\n" +
+	"      architectures:\n" +
+	"        - \"\"\n" +
+	"      # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
 	"      # See https://docs.docker.com/engine/reference/builder/#/arg for more details.\n" +
 	"      build_args:\n" +
 	"        - # Name of the build arg.\n" +
@@ -135,6 +142,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"          # UpdateGraph defines the update mode to use when adding the bundle to the base index.\n" +
 	"          # Can be: semver (default), semver-skippatch, or replaces\n" +
 	"          update_graph: ' '\n" +
+	"          # Validation configures checks of the bundle and of the upgrade graph of the\n" +
+	"          # index the bundle is added to. The results are reported in jUnit.\n" +
+	"          validation:\n" +
+	"            # SelectOptional selects optional validators of `operator-sdk bundle validate`,\n" +
+	"            # like `suite=operatorframework`. By default, only the CSV schema and the\n" +
+	"            # required annotations of the bundle are validated.\n" +
+	"            select_optional: ' '\n" +
 	"    # Substitutions describes the pullspecs in the operator manifests that must be subsituted\n" +
 	"    # with the pull specs of the images in the CI registry\n" +
 	"    substitutions:\n" +
@@ -161,6 +175,19 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"    # but not promote them afterwards.\n" +
 	"    excluded_images:\n" +
 	"        - \"\"\n" +
+	"    # ExternalDestinations are registries outside of the CI\n" +
+	"    # cluster that the promoted images are additionally pushed to\n" +
+	"    # once they were promoted to the integration image streams.\n" +
+	"    external_destinations:\n" +
+	"        - # Credentials is the name of a secret in the test-credentials\n" +
+	"          # namespace holding a .dockerconfigjson that allows pushing to\n" +
+	"          # the repository.\n" +
+	"          credentials: ' '\n" +
+	"          # Repository is the prefix of the repositories the images are\n" +
+	"          # pushed to, for example quay.io/organization. Images are pushed\n" +
+	"          # as <repository>/<name>:<tag> with the name and tag of the image\n" +
+	"          # stream tag they are promoted to.\n" +
+	"          repository: ' '\n" +
 	"    # Name is an optional image stream name to use that\n" +
 	"    # contains all component tags. If specified, tag is\n" +
 	"    # ignored.\n" +
@@ -173,6 +200,18 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"    # should *not* be used in common test workflows. The CI chat\n" +
 	"    # bot uses this option to facilitate image sharing.\n" +
 	"    registry_override: ' '\n" +
+	"    # Signing configures signatures for the images pushed to the\n" +
+	"    # external destinations.\n" +
+	"    signing:\n" +
+	"        # KeySecret is the name of a secret in the test-credentials\n" +
+	"        # namespace holding the cosign.key and cosign.pub key pair and,\n" +
+	"        # if the private key is encrypted, its cosign.password.\n" +
+	"        key_secret: ' '\n" +
+	"        # SBOMPath is the path of a software bill of materials written\n" +
+	"        # into the images during their build. When set, the SBOM is\n" +
+	"        # extracted from every image, attached to it in the external\n" +
+	"        # destinations and signed.\n" +
+	"        sbom_path: ' '\n" +
 	"    # Tag is the ImageStreamTag tagged in for each\n" +
 	"    # build image's ImageStream.\n" +
 	"    tag: ' '\n" +
@@ -262,6 +301,13 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"                      # SourcePath is a file or directory in the source image to copy from.\n" +
 	"                      source_path: ' '\n" +
 	"      project_directory_image_build_step:\n" +
+	"        # Architectures the image is built for. When set, the image is\n" +
+	"        # built once for every architecture on nodes of that architecture\n" +
+	"        # and the results are assembled into a manifest list, which is\n" +
+	"        # what is tagged into the pipeline and promoted. When unset, the\n" +
+	"        # image is built for the architecture of the build cluster only.\n" +
+	"        architectures:\n" +
+	"            - \"\"\n" +
 	"        # BuildArgs contains build arguments that will be resolved in the Dockerfile.\n" +
 	"        # See https://docs.docker.com/engine/reference/builder/#/arg for more details.\n" +
 	"        build_args:\n" +
@@ -318,7 +364,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            product: ' '\n" +
 	"            # ReleaseStream is the stream from which we pick the latest candidate\n" +
 	"            stream: ' '\n" +
-	"            # Version is the minor version to search for\n" +
+	"            # Version is the minor version to search for. Defaults\n" +
+	"            # to the version of the tested branch, like 4.9 for the\n" +
+	"            # release-4.9 branch.\n" +
 	"            version: ' '\n" +
 	"        name: ' '\n" +
 	"        # Prerelease describes a yet-to-be released payload\n" +
@@ -331,6 +379,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # VersionBounds describe the allowable version bounds to search in\n" +
 	"            version_bounds:\n" +
 	"                lower: ' '\n" +
+	"                # Range is a semantic version range expression, like\n" +
+	"                # \">=4.8.0 <4.9.0\" or \">=4.8.0 <4.8.10 || >4.8.12 <4.9.0\"\n" +
+	"                range: ' '\n" +
 	"                upper: ' '\n" +
 	"        # Release describes a released payload\n" +
 	"        release:\n" +
@@ -986,6 +1037,15 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # Workflow is the name of the workflow to be used for this configuration. For fields defined in both\n" +
 	"            # the config and the workflow, the fields from the config will override what is set in Workflow.\n" +
 	"            workflow: \"\"\n" +
+	"# ReleaseDiffs lists pairs of release payloads, like\n" +
+	"# 'initial' and 'latest', to compare at the component\n" +
+	"# level. The changed components are reported in the\n" +
+	"# artifacts and in jUnit.\n" +
+	"release_diffs:\n" +
+	"    - # From is the name of the release payload compared against\n" +
+	"      from: ' '\n" +
+	"      # To is the name of the release payload compared\n" +
+	"      to: ' '\n" +
 	"# Releases maps semantic release payload identifiers\n" +
 	"# to the names that they will be exposed under. For\n" +
 	"# instance, an 'initial' name will be exposed as\n" +
@@ -1003,7 +1063,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            product: ' '\n" +
 	"            # ReleaseStream is the stream from which we pick the latest candidate\n" +
 	"            stream: ' '\n" +
-	"            # Version is the minor version to search for\n" +
+	"            # Version is the minor version to search for. Defaults\n" +
+	"            # to the version of the tested branch, like 4.9 for the\n" +
+	"            # release-4.9 branch.\n" +
 	"            version: ' '\n" +
 	"        # Prerelease describes a yet-to-be released payload\n" +
 	"        prerelease:\n" +
@@ -1015,6 +1077,9 @@ const ciOperatorReferenceYaml = "# The list of base images describe\n" +
 	"            # VersionBounds describe the allowable version bounds to search in\n" +
 	"            version_bounds:\n" +
 	"                lower: ' '\n" +
+	"                # Range is a semantic version range expression, like\n" +
+	"                # \">=4.8.0 <4.9.0\" or \">=4.8.0 <4.8.10 || >4.8.12 <4.9.0\"\n" +
+	"                range: ' '\n" +
 	"                upper: ' '\n" +
 	"        # Release describes a released payload\n" +
 	"        release:\n" +